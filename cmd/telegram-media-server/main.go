@@ -11,15 +11,21 @@ import (
 
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/api"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/bandwidth"
 	tmsbot "github.com/NikitaDmitryuk/telegram-media-server/internal/bot"
 	tmsconfig "github.com/NikitaDmitryuk/telegram-media-server/internal/config"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/deletion"
 	tmsfactory "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/factory"
 	tmsdownloadmanager "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/manager"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/dropfolder"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/feeds"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/common"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/retention"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/seeding"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/subscriptions"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
@@ -56,7 +62,13 @@ func main() {
 	downloadManager := tmsdownloadmanager.NewDownloadManager(config, db)
 	logutils.Log.Info("Download manager initialized")
 
-	deleteQueue := deletion.NewQueue(config.MoviePath, db, downloadManager)
+	speedScheduler := bandwidth.NewScheduler(config)
+	downloadManager.SetSpeedLimitSource(func() (download, upload int64) {
+		limits, _ := speedScheduler.Current()
+		return limits.Download, limits.Upload
+	})
+
+	deleteQueue := deletion.NewQueue(config, db, downloadManager)
 
 	botInstance, err := tmsbot.InitBot(config)
 	if err != nil {
@@ -69,9 +81,11 @@ func main() {
 		Config:          config,
 		DownloadManager: downloadManager,
 		DeleteQueue:     deleteQueue,
+		Bandwidth:       speedScheduler,
 	}
 
 	app.ResumeIncompleteDownloads(a)
+	app.ResumeScheduledDownloads(a)
 	downloadManager.ResumePendingTVConversions(context.Background())
 
 	var apiServer *api.Server
@@ -90,6 +104,13 @@ func main() {
 	defer cancel()
 
 	tmsfactory.StartPeriodicUpdaters(ctx, config)
+	go deletion.StartTrashPurger(ctx, config, db)
+	go retention.StartEnforcer(ctx, a)
+	go seeding.StartMonitor(ctx, config, db)
+	go speedScheduler.Run(ctx)
+	go subscriptions.StartWatcher(ctx, a)
+	go feeds.StartWatcher(ctx, a)
+	go dropfolder.StartWatcher(ctx, a)
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -12,14 +13,20 @@ import (
 
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/cookies"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/factory"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/usenet"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/filemanager"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/notifier"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/prowlarr"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/proxy"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/seeding"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/subscriptions"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/utils"
+	"gorm.io/gorm"
 )
 
 // Health returns 200 and {"status":"ok"}.
@@ -39,13 +46,18 @@ func ListDownloads(w http.ResponseWriter, r *http.Request, a *app.App) {
 		movieID := uintFromMap(q, "movie_id")
 		title, _ := q["title"].(string)
 		pos, _ := q["position"].(int)
-		items = append(items, DownloadItem{
+		item := DownloadItem{
 			ID:              movieID,
 			Title:           title,
 			Status:          "queued",
 			Progress:        0,
 			PositionInQueue: &pos,
-		})
+		}
+		if startAt, ok := q["start_at"].(time.Time); ok {
+			item.Status = statusScheduled
+			item.StartAt = &startAt
+		}
+		items = append(items, item)
 		if movieID != 0 {
 			seen[movieID] = struct{}{}
 		}
@@ -61,18 +73,7 @@ func ListDownloads(w http.ResponseWriter, r *http.Request, a *app.App) {
 			}).Warn("ListDownloads: skip active download (GetMovieByID failed)")
 			continue
 		}
-		status := downloadStatusFromMovie(&movie)
-		items = append(items, DownloadItem{
-			ID:                 movie.ID,
-			Title:              movie.Name,
-			Status:             status,
-			Progress:           movie.DownloadedPercentage,
-			ConversionProgress: movie.ConversionPercentage,
-			ConversionStatus:   movie.ConversionStatus,
-			TvCompatibility:    movie.TvCompatibility,
-			SizeBytes:          movie.FileSize,
-			SizeGB:             formatDownloadSizeGB(movie.FileSize),
-		})
+		items = append(items, downloadItemFromMovie(&movie))
 		seen[movie.ID] = struct{}{}
 	}
 
@@ -86,23 +87,35 @@ func ListDownloads(w http.ResponseWriter, r *http.Request, a *app.App) {
 		if _, ok := seen[movies[i].ID]; ok {
 			continue
 		}
-		status := downloadStatusFromMovie(&movies[i])
-		items = append(items, DownloadItem{
-			ID:                 movies[i].ID,
-			Title:              movies[i].Name,
-			Status:             status,
-			Progress:           movies[i].DownloadedPercentage,
-			ConversionProgress: movies[i].ConversionPercentage,
-			ConversionStatus:   movies[i].ConversionStatus,
-			TvCompatibility:    movies[i].TvCompatibility,
-			SizeBytes:          movies[i].FileSize,
-			SizeGB:             formatDownloadSizeGB(movies[i].FileSize),
-		})
+		items = append(items, downloadItemFromMovie(&movies[i]))
 	}
 
 	writeJSON(w, http.StatusOK, items)
 }
 
+func downloadItemFromMovie(m *database.Movie) DownloadItem {
+	return DownloadItem{
+		ID:                   m.ID,
+		Title:                m.Name,
+		Status:               downloadStatusFromMovie(m),
+		Progress:             m.DownloadedPercentage,
+		ExtractionProgress:   m.ExtractionPercentage,
+		ExtractionStatus:     m.ExtractionStatus,
+		ConversionProgress:   m.ConversionPercentage,
+		ConversionStatus:     m.ConversionStatus,
+		ConversionFile:       m.ConversionFile,
+		ConversionSpeed:      m.ConversionSpeed,
+		ConversionETASeconds: m.ConversionETA,
+		TvCompatibility:      m.TvCompatibility,
+		SizeBytes:            m.FileSize,
+		SizeGB:               formatDownloadSizeGB(m.FileSize),
+		Seeding:              m.Seeding,
+		SeedRatio:            m.SeedRatio,
+		SeedMode:             m.SeedMode,
+		StartAt:              m.StartAt,
+	}
+}
+
 func formatDownloadSizeGB(size int64) string {
 	if size <= 0 {
 		return ""
@@ -114,6 +127,12 @@ func formatDownloadSizeGB(size int64) string {
 const downloadPercentComplete = 100
 
 func downloadStatusFromMovie(m *database.Movie) string {
+	if m.StartAt != nil {
+		return statusScheduled
+	}
+	if m.ExtractionStatus == "in_progress" {
+		return "extracting"
+	}
 	if m.DownloadedPercentage < downloadPercentComplete {
 		return "downloading"
 	}
@@ -151,6 +170,39 @@ func uintFromMap(m map[string]any, key string) uint {
 	return 0
 }
 
+// UpdateDownload handles PATCH /api/v1/downloads/:id; {"seed": bool} switches the seed toggle.
+func UpdateDownload(w http.ResponseWriter, r *http.Request, a *app.App, id uint) {
+	ctx := r.Context()
+	var req UpdateDownloadRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAddDownloadBodyBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.Seed == nil {
+		writeError(w, http.StatusBadRequest, "seed is required")
+		return
+	}
+	movie, err := a.DB.GetMovieByID(ctx, id)
+	if err != nil || movie.IsTrashed() {
+		writeError(w, http.StatusNotFound, "download not found")
+		return
+	}
+	if err := app.SetSeeding(ctx, a, &movie, *req.Seed); err != nil {
+		switch {
+		case errors.Is(err, app.ErrSeedingUnsupported), errors.Is(err, app.ErrSeedingFinished):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			logutils.Log.WithError(err).WithFields(map[string]any{
+				"movie_id":   id,
+				"request_id": RequestIDFromContext(ctx),
+			}).Error("UpdateDownload: SetSeeding failed")
+			writeError(w, http.StatusInternalServerError, "failed to update download")
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, downloadItemFromMovie(&movie))
+}
+
 // DeleteDownload handles DELETE /api/v1/downloads/:id.
 func DeleteDownload(w http.ResponseWriter, r *http.Request, a *app.App, id uint) {
 	if err := deleteDownloadEverywhere(r.Context(), a, id); err != nil {
@@ -218,6 +270,7 @@ func newDownloaderForAdd(
 	ctx context.Context,
 	req AddDownloadRequest,
 	hasTorrent bool,
+	opts factory.VideoOptions,
 	moviePath string,
 	cfg *config.Config,
 ) (downloader.Downloader, error) {
@@ -228,14 +281,40 @@ func newDownloaderForAdd(
 		}
 		return factory.CreateDownloaderFromTorrentData(raw, moviePath, cfg)
 	}
-	return factory.CreateDownloaderFromURL(ctx, req.URL, moviePath, cfg)
+	return factory.CreateDownloaderFromURLWithOptions(ctx, req.URL, moviePath, cfg, opts)
+}
+
+// selectTorrentFiles limits a multi-file torrent download to the files with the given 1-based indices.
+func selectTorrentFiles(dl downloader.Downloader, indices []int) error {
+	selector, ok := dl.(downloader.FileSelector)
+	if !ok || len(selector.TorrentFiles()) == 0 {
+		return errors.New("files is only supported for multi-file .torrent downloads")
+	}
+	return selector.SelectFiles(indices)
+}
+
+func videoOptionsFromRequest(req *AddDownloadRequest) factory.VideoOptions {
+	return factory.VideoOptions{
+		Quality:      req.Quality,
+		AudioLang:    req.AudioLang,
+		SubtitleLang: req.SubtitleLang,
+		WriteSubs:    req.WriteSubs,
+		Format:       req.Format,
+	}
 }
 
 func writeValidateDownloadStartError(w http.ResponseWriter, ctx context.Context, validateErr error) {
 	logutils.Log.WithError(validateErr).
 		WithField("request_id", RequestIDFromContext(ctx)).
 		Debug("AddDownload: ValidateDownloadStart failed")
+	var duplicate *app.DuplicateError
 	switch {
+	case errors.As(validateErr, &duplicate):
+		writeJSON(w, http.StatusConflict, DuplicateDownloadResponse{
+			Error:         "torrent already exists",
+			ExistingID:    duplicate.MovieID,
+			ExistingTitle: duplicate.Title,
+		})
 	case errors.Is(validateErr, app.ErrAlreadyExists):
 		writeError(w, http.StatusConflict, "media already exists")
 	case errors.Is(validateErr, app.ErrNotEnoughSpace):
@@ -256,7 +335,16 @@ func AddDownload(w http.ResponseWriter, r *http.Request, a *app.App) {
 	if !ok {
 		return
 	}
-	dl, err := newDownloaderForAdd(ctx, req, hasTorrent, a.Config.MoviePath, a.Config)
+	opts := videoOptionsFromRequest(&req)
+	if err := opts.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Seed != nil && *req.Seed && a.Config.QBittorrentURL == "" {
+		writeError(w, http.StatusBadRequest, app.ErrSeedingUnsupported.Error())
+		return
+	}
+	dl, err := newDownloaderForAdd(ctx, req, hasTorrent, opts, a.Config.MoviePath, a.Config)
 	if err != nil {
 		if errors.Is(err, errInvalidTorrentBase64) {
 			writeError(w, http.StatusBadRequest, "invalid torrent_base64")
@@ -266,6 +354,12 @@ func AddDownload(w http.ResponseWriter, r *http.Request, a *app.App) {
 		writeError(w, http.StatusBadRequest, utils.DownloadErrorMessage(err))
 		return
 	}
+	if len(req.Files) > 0 {
+		if err := selectTorrentFiles(dl, req.Files); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 	title, err := dl.GetTitle()
 	if err != nil {
 		logutils.Log.WithError(err).WithField("request_id", RequestIDFromContext(ctx)).Debug("AddDownload: GetTitle failed")
@@ -276,12 +370,32 @@ func AddDownload(w http.ResponseWriter, r *http.Request, a *app.App) {
 		writeValidateDownloadStartError(w, ctx, validateErr)
 		return
 	}
-	movieID, _, completionChan, err := a.DownloadManager.StartDownload(dl, notifier.Noop)
+	var (
+		movieID        uint
+		completionChan chan error
+		startAt        *time.Time
+	)
+	if req.StartAt != nil && req.StartAt.After(time.Now()) {
+		startAt = req.StartAt
+		options := factory.StartOptions{Video: opts, Files: req.Files}.Encode()
+		movieID, _, completionChan, err = a.DownloadManager.ScheduleDownload(dl, *startAt, req.URL, options, notifier.Noop)
+	} else {
+		movieID, _, completionChan, err = a.DownloadManager.StartDownload(dl, notifier.Noop)
+	}
 	if err != nil {
 		logutils.Log.WithError(err).WithField("request_id", RequestIDFromContext(ctx)).Error("AddDownload: StartDownload failed")
 		writeError(w, http.StatusInternalServerError, utils.DownloadErrorMessage(err))
 		return
 	}
+	if req.Seed != nil {
+		mode := seeding.ModeOff
+		if *req.Seed {
+			mode = seeding.ModeOn
+		}
+		if seedErr := a.DB.SetMovieSeedMode(ctx, movieID, mode); seedErr != nil {
+			logutils.Log.WithError(seedErr).WithField("movie_id", movieID).Warn("AddDownload: SetMovieSeedMode failed")
+		}
+	}
 	if strings.TrimSpace(req.Title) != "" {
 		if updateErr := a.DB.UpdateMovieName(ctx, movieID, strings.TrimSpace(req.Title)); updateErr != nil {
 			logutils.Log.WithError(updateErr).
@@ -296,7 +410,7 @@ func AddDownload(w http.ResponseWriter, r *http.Request, a *app.App) {
 		webhookToken:  a.Config.TMSWebhookToken,
 		webhookFormat: a.Config.TMSWebhookFormat,
 	})
-	writeJSON(w, http.StatusCreated, AddDownloadResponse{ID: movieID, Title: title})
+	writeJSON(w, http.StatusCreated, AddDownloadResponse{ID: movieID, Title: title, StartAt: startAt})
 }
 
 // webhookNotifier implements notifier.CompletionNotifier for API-originated downloads.
@@ -339,13 +453,16 @@ func Search(w http.ResponseWriter, r *http.Request, a *app.App) {
 	}
 	ctx, cancel := context.WithTimeout(r.Context(), prowlarrSearchTimeout)
 	defer cancel()
-	client := prowlarr.NewProwlarr(a.Config.ProwlarrURL, a.Config.ProwlarrAPIKey)
+	client := prowlarr.NewProwlarr(a.Config.ProwlarrURL, a.Config.ProwlarrAPIKey, proxy.Transport(a.Config))
 	page, err := client.SearchTorrentsWithContext(ctx, q, 0, limit, nil, nil)
 	if err != nil {
 		logutils.Log.WithError(err).WithField("request_id", RequestIDFromContext(r.Context())).Error("Search: Prowlarr request failed")
 		writeError(w, http.StatusServiceUnavailable, "search unavailable")
 		return
 	}
+	if !usenet.Configured(a.Config) {
+		page.Results = prowlarr.WithoutUsenet(page.Results)
+	}
 	quality := strings.TrimSpace(r.URL.Query().Get("quality"))
 	items := make([]SearchResultItem, 0, len(page.Results))
 	for _, res := range page.Results {
@@ -359,7 +476,212 @@ func Search(w http.ResponseWriter, r *http.Request, a *app.App) {
 			TorrentURL:  res.TorrentURL,
 			IndexerName: res.IndexerName,
 			Peers:       res.Peers,
+			Protocol:    res.Protocol,
+		})
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+// Storage returns GET /api/v1/storage — free space in MOVIE_PATH minus space reserved by downloads.
+func Storage(w http.ResponseWriter, r *http.Request, a *app.App) {
+	space, err := app.GetDiskSpace(r.Context(), a)
+	if err != nil {
+		logutils.Log.WithError(err).WithField("request_id", RequestIDFromContext(r.Context())).Error("Storage: GetDiskSpace failed")
+		writeError(w, http.StatusInternalServerError, "failed to get disk space")
+		return
+	}
+	writeJSON(w, http.StatusOK, StorageResponse{
+		FreeBytes:      space.FreeBytes,
+		ReservedBytes:  space.ReservedBytes,
+		AvailableBytes: space.AvailableBytes(),
+	})
+}
+
+// ListTrash returns GET /api/v1/trash — movies deleted into the trash that can still be restored.
+func ListTrash(w http.ResponseWriter, r *http.Request, a *app.App) {
+	trashed, err := a.DB.GetTrashedMovies(r.Context())
+	if err != nil {
+		logutils.Log.WithError(err).WithField("request_id", RequestIDFromContext(r.Context())).Error("ListTrash: GetTrashedMovies failed")
+		writeError(w, http.StatusInternalServerError, "failed to list trash")
+		return
+	}
+	retention := a.Config.TrashSettings.Retention
+	items := make([]TrashItem, 0, len(trashed))
+	for i := range trashed {
+		m := &trashed[i]
+		items = append(items, TrashItem{
+			ID:        m.ID,
+			Title:     m.Name,
+			SizeBytes: m.FileSize,
+			TrashedAt: *m.TrashedAt,
+			ExpiresAt: m.TrashedAt.Add(retention),
 		})
 	}
 	writeJSON(w, http.StatusOK, items)
 }
+
+// RestoreFromTrash handles POST /api/v1/trash/{id}/restore.
+func RestoreFromTrash(w http.ResponseWriter, r *http.Request, a *app.App, id uint) {
+	if a.DeleteQueue != nil && a.DeleteQueue.IsPendingDeletion(id) {
+		writeError(w, http.StatusConflict, "movie is still being moved to trash")
+		return
+	}
+	movie, err := filemanager.RestoreMovie(id, a.Config.MoviePath, a.DB)
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, AddDownloadResponse{ID: movie.ID, Title: movie.Name})
+	case errors.Is(err, filemanager.ErrNotInTrash):
+		writeError(w, http.StatusNotFound, "not in trash")
+	case errors.Is(err, filemanager.ErrRestoreTarget):
+		writeError(w, http.StatusConflict, "files with the same names already exist in the library")
+	default:
+		logutils.Log.WithError(err).WithFields(map[string]any{
+			"movie_id":   id,
+			"request_id": RequestIDFromContext(r.Context()),
+		}).Error("RestoreFromTrash failed")
+		writeError(w, http.StatusInternalServerError, "failed to restore")
+	}
+}
+
+// ListSubscriptions returns GET /api/v1/subscriptions — all channel and playlist subscriptions.
+func ListSubscriptions(w http.ResponseWriter, r *http.Request, a *app.App) {
+	subs, err := a.DB.GetSubscriptions(r.Context())
+	if err != nil {
+		logutils.Log.WithError(err).WithField("request_id", RequestIDFromContext(r.Context())).Error("ListSubscriptions failed")
+		writeError(w, http.StatusInternalServerError, "failed to list subscriptions")
+		return
+	}
+	items := make([]Subscription, 0, len(subs))
+	for i := range subs {
+		items = append(items, subscriptionFromDB(&subs[i]))
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+// AddSubscription handles POST /api/v1/subscriptions. New videos are reported to the admins in Telegram.
+func AddSubscription(w http.ResponseWriter, r *http.Request, a *app.App) {
+	var req AddSubscriptionRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAddDownloadBodyBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	sub := &database.Subscription{
+		URL:            req.URL,
+		TitleRegex:     req.TitleRegex,
+		MaxDurationSec: req.MaxDurationSec,
+		DateAfter:      req.DateAfter,
+	}
+	if err := subscriptions.Validate(sub); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := subscriptions.Create(r.Context(), a, sub); err != nil {
+		logutils.Log.WithError(err).WithField("request_id", RequestIDFromContext(r.Context())).Error("AddSubscription failed")
+		writeError(w, http.StatusInternalServerError, "failed to save subscription")
+		return
+	}
+	writeJSON(w, http.StatusCreated, subscriptionFromDB(sub))
+}
+
+// DeleteSubscription handles DELETE /api/v1/subscriptions/:id. Videos already downloaded are kept.
+func DeleteSubscription(w http.ResponseWriter, r *http.Request, a *app.App, id uint) {
+	if _, err := a.DB.GetSubscriptionByID(r.Context(), id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			writeError(w, http.StatusNotFound, "subscription not found")
+			return
+		}
+		logutils.Log.WithError(err).WithField("request_id", RequestIDFromContext(r.Context())).Error("DeleteSubscription: lookup failed")
+		writeError(w, http.StatusInternalServerError, "failed to delete subscription")
+		return
+	}
+	if err := a.DB.RemoveSubscription(r.Context(), id); err != nil {
+		logutils.Log.WithError(err).WithField("request_id", RequestIDFromContext(r.Context())).Error("DeleteSubscription failed")
+		writeError(w, http.StatusInternalServerError, "failed to delete subscription")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListCookies returns GET /api/v1/cookies — the domains with a yt-dlp cookie profile and their expiry.
+func ListCookies(w http.ResponseWriter, r *http.Request, a *app.App) {
+	profiles, err := cookies.List(cookies.Dir(a.Config))
+	if err != nil {
+		logutils.Log.WithError(err).WithField("request_id", RequestIDFromContext(r.Context())).Error("ListCookies failed")
+		writeError(w, http.StatusInternalServerError, "failed to list cookie profiles")
+		return
+	}
+	items := make([]CookieProfile, 0, len(profiles))
+	for i := range profiles {
+		items = append(items, cookieProfileFrom(&profiles[i]))
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+// PutCookies handles PUT /api/v1/cookies/:domain with a Netscape cookies.txt body; it replaces the profile
+// yt-dlp uses for the domain and its subdomains.
+func PutCookies(w http.ResponseWriter, r *http.Request, a *app.App, domain string) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, cookies.MaxFileBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "cookies file too large")
+			return
+		}
+		writeError(w, http.StatusBadRequest, "failed to read body")
+		return
+	}
+	profile, err := cookies.Save(cookies.Dir(a.Config), domain, data)
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, cookieProfileFrom(&profile))
+	case errors.Is(err, cookies.ErrInvalidDomain), errors.Is(err, cookies.ErrInvalidFile):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		logutils.Log.WithError(err).WithField("request_id", RequestIDFromContext(r.Context())).Error("PutCookies failed")
+		writeError(w, http.StatusInternalServerError, "failed to save cookies")
+	}
+}
+
+// DeleteCookies handles DELETE /api/v1/cookies/:domain.
+func DeleteCookies(w http.ResponseWriter, r *http.Request, a *app.App, domain string) {
+	err := cookies.Delete(cookies.Dir(a.Config), domain)
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, cookies.ErrNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, cookies.ErrInvalidDomain):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		logutils.Log.WithError(err).WithField("request_id", RequestIDFromContext(r.Context())).Error("DeleteCookies failed")
+		writeError(w, http.StatusInternalServerError, "failed to delete cookies")
+	}
+}
+
+func cookieProfileFrom(p *cookies.Profile) CookieProfile {
+	item := CookieProfile{
+		Domain:    p.Domain,
+		Cookies:   p.Cookies,
+		Expired:   p.Expired,
+		UpdatedAt: p.UpdatedAt,
+	}
+	if !p.Expires.IsZero() {
+		expires := p.Expires
+		item.ExpiresAt = &expires
+	}
+	return item
+}
+
+func subscriptionFromDB(sub *database.Subscription) Subscription {
+	return Subscription{
+		ID:             sub.ID,
+		URL:            sub.URL,
+		Title:          sub.Title,
+		TitleRegex:     sub.TitleRegex,
+		MaxDurationSec: sub.MaxDurationSec,
+		DateAfter:      sub.DateAfter,
+		ChatID:         sub.ChatID,
+		LastCheckedAt:  sub.LastCheckedAt,
+		CreatedAt:      sub.CreatedAt,
+	}
+}
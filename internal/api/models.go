@@ -1,5 +1,7 @@
 package api
 
+import "time"
+
 // HealthResponse is returned by GET /api/v1/health.
 type HealthResponse struct {
 	Status string `json:"status"`
@@ -9,29 +11,84 @@ type HealthResponse struct {
 type DownloadItem struct {
 	ID                 uint   `json:"id"`
 	Title              string `json:"title"`
-	Status             string `json:"status"` // queued, downloading, converting, completed, failed, stopped
+	Status             string `json:"status"` // scheduled, queued, downloading, extracting, converting, completed, failed, stopped
 	Progress           int    `json:"progress"`
+	ExtractionProgress int    `json:"extraction_progress,omitempty"`
+	ExtractionStatus   string `json:"extraction_status,omitempty"`
 	ConversionProgress int    `json:"conversion_progress,omitempty"`
 	ConversionStatus   string `json:"conversion_status,omitempty"`
-	TvCompatibility    string `json:"tv_compatibility,omitempty"`
-	SizeBytes          int64  `json:"size_bytes,omitempty"`
-	SizeGB             string `json:"size_gb,omitempty"`
-	Error              string `json:"error,omitempty"`
-	PositionInQueue    *int   `json:"position_in_queue,omitempty"`
+	// ConversionFile, ConversionSpeed (ffmpeg speed factor) and ConversionETASeconds describe a running conversion.
+	ConversionFile       string  `json:"conversion_file,omitempty"`
+	ConversionSpeed      float64 `json:"conversion_speed,omitempty"`
+	ConversionETASeconds int     `json:"conversion_eta_seconds,omitempty"`
+	TvCompatibility      string  `json:"tv_compatibility,omitempty"`
+	SizeBytes            int64   `json:"size_bytes,omitempty"`
+	SizeGB               string  `json:"size_gb,omitempty"`
+	Error                string  `json:"error,omitempty"`
+	PositionInQueue      *int    `json:"position_in_queue,omitempty"`
+	// Seeding: the completed torrent is kept in qBittorrent until its seeding rule is met; SeedRatio is its upload ratio.
+	Seeding   bool    `json:"seeding,omitempty"`
+	SeedRatio float64 `json:"seed_ratio,omitempty"`
+	SeedMode  string  `json:"seed_mode,omitempty"` // "on" or "off" when set with the seed toggle; empty follows the policy
+	// StartAt: a scheduled download waits in the queue until this time.
+	StartAt *time.Time `json:"start_at,omitempty"`
+}
+
+// StorageResponse is returned by GET /api/v1/storage.
+type StorageResponse struct {
+	FreeBytes      int64 `json:"free_bytes"`
+	ReservedBytes  int64 `json:"reserved_bytes"`  // still to be written by active and queued downloads
+	AvailableBytes int64 `json:"available_bytes"` // free minus reserved; what a new download can use
+}
+
+// TrashItem is one entry in GET /api/v1/trash.
+type TrashItem struct {
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	SizeBytes int64     `json:"size_bytes,omitempty"`
+	TrashedAt time.Time `json:"trashed_at"`
+	ExpiresAt time.Time `json:"expires_at"` // purged after this time (or earlier when disk space runs low)
 }
 
 // AddDownloadRequest is the body for POST /api/v1/downloads.
 // Exactly one of URL or TorrentBase64 must be set (not both, not neither).
+// Quality, AudioLang, SubtitleLang, WriteSubs and Format override the VIDEO_* settings for this download (yt-dlp URLs only).
 type AddDownloadRequest struct {
 	URL           string `json:"url,omitempty"`
 	TorrentBase64 string `json:"torrent_base64,omitempty"` // standard Base64 of a .torrent file
 	Title         string `json:"title,omitempty"`          // optional display name (e.g. from search result)
+	Quality       string `json:"quality,omitempty"`        // "720p", "1080p", "best" or "audio"
+	AudioLang     string `json:"audio_lang,omitempty"`
+	SubtitleLang  string `json:"subtitle_lang,omitempty"` // e.g. "en" or "en,ru"; enables subtitles
+	WriteSubs     *bool  `json:"write_subs,omitempty"`
+	Format        string `json:"format,omitempty"` // explicit yt-dlp -f selector, e.g. "137+ba"; overrides quality
+	Files         []int  `json:"files,omitempty"`  // 1-based indices of the files of a multi-file .torrent to download
+	// Seed: true keeps the qBittorrent torrent seeding after completion until switched off, false never seeds;
+	// empty follows SEED_RATIO / SEED_TIME / SEED_TRACKER_RULES.
+	Seed *bool `json:"seed,omitempty"`
+	// StartAt: RFC 3339 time to start the download at; it waits in the queue (status "scheduled") until then.
+	// Empty or a time in the past starts it right away.
+	StartAt *time.Time `json:"start_at,omitempty"`
+}
+
+// UpdateDownloadRequest is the body of PATCH /api/v1/downloads/:id.
+type UpdateDownloadRequest struct {
+	Seed *bool `json:"seed"` // seed toggle, see AddDownloadRequest.Seed
 }
 
 // AddDownloadResponse is returned on success by POST /api/v1/downloads.
 type AddDownloadResponse struct {
 	ID    uint   `json:"id"`
 	Title string `json:"title"`
+	// StartAt is echoed for scheduled downloads.
+	StartAt *time.Time `json:"start_at,omitempty"`
+}
+
+// DuplicateDownloadResponse is the 409 body of POST /api/v1/downloads for a torrent already in the library.
+type DuplicateDownloadResponse struct {
+	Error         string `json:"error"`
+	ExistingID    uint   `json:"existing_id"`
+	ExistingTitle string `json:"existing_title"`
 }
 
 // SearchResultItem is one entry in GET /api/v1/search (Prowlarr).
@@ -42,4 +99,35 @@ type SearchResultItem struct {
 	TorrentURL  string `json:"torrent_url,omitempty"`
 	IndexerName string `json:"indexer_name,omitempty"`
 	Peers       int    `json:"peers"`
+	Protocol    string `json:"protocol"` // "torrent" or "usenet" (torrent_url is then the NZB URL)
+}
+
+// Subscription is one entry in GET /api/v1/subscriptions and the response of POST /api/v1/subscriptions.
+type Subscription struct {
+	ID             uint       `json:"id"`
+	URL            string     `json:"url"`
+	Title          string     `json:"title,omitempty"` // channel or playlist name, known after the first check
+	TitleRegex     string     `json:"title_regex,omitempty"`
+	MaxDurationSec int        `json:"max_duration_sec,omitempty"`
+	DateAfter      string     `json:"date_after,omitempty"` // YYYYMMDD
+	ChatID         int64      `json:"chat_id,omitempty"`    // subscriber chat; 0 for subscriptions created via the API
+	LastCheckedAt  *time.Time `json:"last_checked_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// CookieProfile is one entry in GET /api/v1/cookies and the response of PUT /api/v1/cookies/{domain}.
+type CookieProfile struct {
+	Domain    string     `json:"domain"`
+	Cookies   int        `json:"cookies"`
+	Expired   int        `json:"expired"`              // cookies already past their expiry
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // latest expiry; omitted when all are session cookies
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// AddSubscriptionRequest is the body for POST /api/v1/subscriptions. Only URL is required.
+type AddSubscriptionRequest struct {
+	URL            string `json:"url"`
+	TitleRegex     string `json:"title_regex,omitempty"`
+	MaxDurationSec int    `json:"max_duration_sec,omitempty"`
+	DateAfter      string `json:"date_after,omitempty"` // YYYY-MM-DD or YYYYMMDD
 }
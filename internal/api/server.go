@@ -20,13 +20,17 @@ import (
 const jsonContentType = "application/json"
 
 const (
-	apiV1Prefix     = "/api/v1"
-	healthPath      = apiV1Prefix + "/health"
-	downloadsPath   = apiV1Prefix + "/downloads"
-	searchPath      = apiV1Prefix + "/search"
-	openapiYAMLPath = apiV1Prefix + "/openapi.yaml"
-	openapiLLMPath  = apiV1Prefix + "/openapi-llm.yaml"
-	swaggerDocsPath = apiV1Prefix + "/docs"
+	apiV1Prefix       = "/api/v1"
+	healthPath        = apiV1Prefix + "/health"
+	downloadsPath     = apiV1Prefix + "/downloads"
+	searchPath        = apiV1Prefix + "/search"
+	trashPath         = apiV1Prefix + "/trash"
+	storagePath       = apiV1Prefix + "/storage"
+	subscriptionsPath = apiV1Prefix + "/subscriptions"
+	cookiesPath       = apiV1Prefix + "/cookies"
+	openapiYAMLPath   = apiV1Prefix + "/openapi.yaml"
+	openapiLLMPath    = apiV1Prefix + "/openapi-llm.yaml"
+	swaggerDocsPath   = apiV1Prefix + "/docs"
 )
 
 //go:embed openapi/openapi.yaml openapi/openapi-llm.yaml openapi/swagger-ui.html
@@ -57,6 +61,13 @@ func NewServer(a *app.App, listenAddr, apiKey string) *Server {
 	mux.HandleFunc(downloadsPath, s.chain(s.downloadsHandler))
 	mux.HandleFunc(downloadsPath+"/", s.chain(s.downloadByIDHandler))
 	mux.HandleFunc(searchPath, s.chain(s.searchHandler))
+	mux.HandleFunc(storagePath, s.chain(s.storageHandler))
+	mux.HandleFunc(trashPath, s.chain(s.trashHandler))
+	mux.HandleFunc(trashPath+"/", s.chain(s.trashItemHandler))
+	mux.HandleFunc(subscriptionsPath, s.chain(s.subscriptionsHandler))
+	mux.HandleFunc(subscriptionsPath+"/", s.chain(s.subscriptionByIDHandler))
+	mux.HandleFunc(cookiesPath, s.chain(s.cookiesHandler))
+	mux.HandleFunc(cookiesPath+"/", s.chain(s.cookieProfileHandler))
 
 	s.srv = &http.Server{
 		Addr:         listenAddr,
@@ -172,7 +183,7 @@ func (*Server) downloadsHandler(w http.ResponseWriter, r *http.Request, a *app.A
 }
 
 func (*Server) downloadByIDHandler(w http.ResponseWriter, r *http.Request, a *app.App) {
-	if r.Method != http.MethodDelete {
+	if r.Method != http.MethodDelete && r.Method != http.MethodPatch {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
@@ -182,6 +193,10 @@ func (*Server) downloadByIDHandler(w http.ResponseWriter, r *http.Request, a *ap
 		writeError(w, http.StatusBadRequest, "invalid download id")
 		return
 	}
+	if r.Method == http.MethodPatch {
+		UpdateDownload(w, r, a, uint(id))
+		return
+	}
 	DeleteDownload(w, r, a, uint(id))
 }
 
@@ -193,6 +208,87 @@ func (*Server) searchHandler(w http.ResponseWriter, r *http.Request, a *app.App)
 	Search(w, r, a)
 }
 
+func (*Server) storageHandler(w http.ResponseWriter, r *http.Request, a *app.App) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	Storage(w, r, a)
+}
+
+func (*Server) trashHandler(w http.ResponseWriter, r *http.Request, a *app.App) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	ListTrash(w, r, a)
+}
+
+func (*Server) subscriptionsHandler(w http.ResponseWriter, r *http.Request, a *app.App) {
+	switch r.Method {
+	case http.MethodGet:
+		ListSubscriptions(w, r, a)
+	case http.MethodPost:
+		AddSubscription(w, r, a)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (*Server) subscriptionByIDHandler(w http.ResponseWriter, r *http.Request, a *app.App) {
+	if r.Method != http.MethodDelete {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	id, err := strconv.ParseUint(path.Base(r.URL.Path), 10, 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid subscription id")
+		return
+	}
+	DeleteSubscription(w, r, a, uint(id))
+}
+
+func (*Server) cookiesHandler(w http.ResponseWriter, r *http.Request, a *app.App) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	ListCookies(w, r, a)
+}
+
+// cookieProfileHandler serves PUT and DELETE /api/v1/cookies/{domain}.
+func (*Server) cookieProfileHandler(w http.ResponseWriter, r *http.Request, a *app.App) {
+	domain := path.Base(r.URL.Path)
+	switch r.Method {
+	case http.MethodPut:
+		PutCookies(w, r, a, domain)
+	case http.MethodDelete:
+		DeleteCookies(w, r, a, domain)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// trashItemHandler serves POST /api/v1/trash/{id}/restore.
+func (*Server) trashItemHandler(w http.ResponseWriter, r *http.Request, a *app.App) {
+	rest := strings.TrimPrefix(r.URL.Path, trashPath+"/")
+	idStr, action, found := strings.Cut(rest, "/")
+	if !found || action != "restore" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	id, err := strconv.ParseUint(idStr, 10, 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid download id")
+		return
+	}
+	RestoreFromTrash(w, r, a, uint(id))
+}
+
 func serveOpenAPIYAML(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.Header().Set("Allow", http.MethodGet)
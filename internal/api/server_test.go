@@ -9,6 +9,8 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,6 +38,9 @@ type mockDM struct {
 	startReturn uint
 	stoppedIDs  []uint
 	removedIDs  []uint
+	reserved    int64
+	scheduledAt time.Time
+	source      string
 }
 
 func (m *mockDM) StartDownload(
@@ -52,6 +57,22 @@ func (m *mockDM) StartDownload(
 	return id, make(chan float64), make(chan error), nil
 }
 
+func (m *mockDM) ScheduleDownload(
+	dl tmsdownloader.Downloader,
+	startAt time.Time,
+	source string,
+	_ string,
+	queueNotifier notifier.QueueNotifier,
+) (id uint, progressChan chan float64, errChan chan error, err error) {
+	m.scheduledAt = startAt
+	m.source = source
+	return m.StartDownload(dl, queueNotifier)
+}
+
+func (*mockDM) ResumeScheduledDownload(uint, tmsdownloader.Downloader, string, time.Time, notifier.QueueNotifier) chan error {
+	return make(chan error)
+}
+
 func (*mockDM) ResumeDownload(
 	_ uint,
 	_ tmsdownloader.Downloader,
@@ -102,6 +123,8 @@ func (m *mockDM) RemoveQBittorrentTorrent(_ context.Context, id uint) error {
 }
 func (*mockDM) ResumePendingTVConversions(_ context.Context) {}
 
+func (m *mockDM) ReservedBytes(_ context.Context) int64 { return m.reserved }
+
 // mockDMCompletion is like mockDM but returns channels that are closed/sent after a short delay,
 // so that app.RunCompletionLoop can drain them and exit (tests API download completion flow).
 type mockDMCompletion struct {
@@ -131,6 +154,19 @@ func (m *mockDMCompletion) StartDownload(
 	}()
 	return id, progressChan, errChan, nil
 }
+func (m *mockDMCompletion) ScheduleDownload(
+	dl tmsdownloader.Downloader,
+	_ time.Time,
+	_, _ string,
+	queueNotifier notifier.QueueNotifier,
+) (id uint, progressChan chan float64, errChan chan error, err error) {
+	return m.StartDownload(dl, queueNotifier)
+}
+
+func (*mockDMCompletion) ResumeScheduledDownload(uint, tmsdownloader.Downloader, string, time.Time, notifier.QueueNotifier) chan error {
+	return make(chan error)
+}
+
 func (*mockDMCompletion) ResumeDownload(
 	_ uint,
 	_ tmsdownloader.Downloader,
@@ -147,6 +183,7 @@ func (*mockDMCompletion) GetActiveDownloads() []uint
 func (*mockDMCompletion) GetQueueItems() []map[string]any                          { return nil }
 func (*mockDMCompletion) RemoveQBittorrentTorrent(_ context.Context, _ uint) error { return nil }
 func (*mockDMCompletion) ResumePendingTVConversions(_ context.Context)             {}
+func (*mockDMCompletion) ReservedBytes(_ context.Context) int64                    { return 0 }
 
 // dbWithMovie returns a movie for GetMovieByID(1); other methods from stub.
 type dbWithMovie struct {
@@ -296,6 +333,32 @@ func TestAPI_Health_401WrongKey(t *testing.T) {
 	}
 }
 
+func TestAPI_Storage_SubtractsReserved(t *testing.T) {
+	cfg := &config.Config{TMSAPIEnabled: true, TMSAPIKey: "secret", MoviePath: t.TempDir()}
+	const reserved = 1024
+	a := &app.App{Config: cfg, DownloadManager: &mockDM{reserved: reserved}}
+	srv := NewServer(a, "127.0.0.1:0", "secret")
+
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/api/v1/storage", http.NoBody)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	srv.srv.Handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Storage: got status %d, want 200", rec.Code)
+	}
+	var resp StorageResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.ReservedBytes != reserved {
+		t.Errorf("reserved_bytes = %d, want %d", resp.ReservedBytes, reserved)
+	}
+	if resp.FreeBytes <= reserved || resp.AvailableBytes != resp.FreeBytes-reserved {
+		t.Errorf("unexpected storage response: %+v", resp)
+	}
+}
+
 func TestAPI_ListDownloads_200(t *testing.T) {
 	cfg := &config.Config{TMSAPIEnabled: true, TMSAPIKey: "secret"}
 	db := &dbWithMovie{}
@@ -353,6 +416,32 @@ func TestAPI_ListDownloads_QueueItem(t *testing.T) {
 	}
 }
 
+func TestAPI_ListDownloads_ScheduledItem(t *testing.T) {
+	cfg := &config.Config{TMSAPIEnabled: true, TMSAPIKey: "secret"}
+	startAt := time.Date(2030, 1, 2, 1, 0, 0, 0, time.UTC)
+	dm := &mockDM{queueItems: []map[string]any{
+		{"movie_id": uint(2), "title": "Tonight Movie", "position": 1, "start_at": startAt},
+	}}
+	a := &app.App{Config: cfg, DB: &dbWithMovie{}, DownloadManager: dm}
+	srv := NewServer(a, "127.0.0.1:0", "secret")
+
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/api/v1/downloads", http.NoBody)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	srv.srv.Handler.ServeHTTP(rec, req)
+
+	var items []DownloadItem
+	if err := json.NewDecoder(rec.Body).Decode(&items); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(items))
+	}
+	if items[0].Status != "scheduled" || items[0].StartAt == nil || !items[0].StartAt.Equal(startAt) {
+		t.Errorf("unexpected item: %+v", items[0])
+	}
+}
+
 func TestAPI_ListDownloads_IncludesCompletedLibraryItems(t *testing.T) {
 	ctx := context.Background()
 	cfg := &config.Config{TMSAPIEnabled: true, TMSAPIKey: "secret"}
@@ -421,6 +510,58 @@ func TestAPI_DeleteDownload_204(t *testing.T) {
 	}
 }
 
+func TestAPI_UpdateDownload_Seed(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{TMSAPIEnabled: true, TMSAPIKey: "secret", QBittorrentURL: "http://127.0.0.1:1"}
+	db := testutils.TestDatabase(t)
+	downloadingID, err := db.AddMovie(ctx, "Downloading", 2048, []string{"downloading.mkv"}, nil, 0)
+	if err != nil {
+		t.Fatalf("AddMovie: %v", err)
+	}
+	completedID, err := db.AddMovie(ctx, "Completed", 2048, []string{"completed.mkv"}, nil, 0)
+	if err != nil {
+		t.Fatalf("AddMovie: %v", err)
+	}
+	if err := db.SetLoaded(ctx, completedID, ""); err != nil {
+		t.Fatalf("SetLoaded: %v", err)
+	}
+	a := &app.App{Config: cfg, DB: db, DownloadManager: &mockDM{}}
+	srv := NewServer(a, "127.0.0.1:0", "secret")
+	patch := func(id uint, body string) *httptest.ResponseRecorder {
+		target := "/api/v1/downloads/" + strconv.FormatUint(uint64(id), 10)
+		req := httptest.NewRequestWithContext(ctx, http.MethodPatch, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		srv.srv.Handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := patch(downloadingID, `{"seed":true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PATCH seed: got status %d, want 200 (%s)", rec.Code, rec.Body.String())
+	}
+	var item DownloadItem
+	if err := json.NewDecoder(rec.Body).Decode(&item); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if item.ID != downloadingID || item.SeedMode != "on" {
+		t.Errorf("unexpected item: %+v", item)
+	}
+	if movie, _ := db.GetMovieByID(ctx, downloadingID); movie.SeedMode != "on" {
+		t.Errorf("seed_mode = %q, want on", movie.SeedMode)
+	}
+
+	if rec := patch(completedID, `{"seed":true}`); rec.Code != http.StatusConflict {
+		t.Errorf("PATCH seed on finished download: got status %d, want 409", rec.Code)
+	}
+	if rec := patch(downloadingID, `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("PATCH without seed: got status %d, want 400", rec.Code)
+	}
+	if rec := patch(999, `{"seed":false}`); rec.Code != http.StatusNotFound {
+		t.Errorf("PATCH unknown id: got status %d, want 404", rec.Code)
+	}
+}
+
 func TestAPI_DeleteDownload_RemoveEverywhere(t *testing.T) {
 	ctx := context.Background()
 	moviePath := t.TempDir()
@@ -555,6 +696,88 @@ func TestAPI_AddDownload_400URLAndTorrentBoth(t *testing.T) {
 	}
 }
 
+func TestAPI_AddDownload_400InvalidVideoOptions(t *testing.T) {
+	cfg := &config.Config{TMSAPIEnabled: true, TMSAPIKey: "secret", MoviePath: t.TempDir()}
+	a := &app.App{Config: cfg, DownloadManager: &mockDM{}}
+	srv := NewServer(a, "127.0.0.1:0", "secret")
+
+	body, _ := json.Marshal(AddDownloadRequest{URL: "https://example.com/watch?v=1", Quality: "ultra"})
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/api/v1/downloads", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.srv.Handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("AddDownload invalid quality: got status %d, want 400", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "invalid quality") {
+		t.Errorf("AddDownload invalid quality: body %q should name the option", rec.Body.String())
+	}
+}
+
+func TestAPI_AddDownload_400FilesWithoutTorrentFile(t *testing.T) {
+	cfg := &config.Config{TMSAPIEnabled: true, TMSAPIKey: "secret", MoviePath: t.TempDir()}
+	a := &app.App{Config: cfg, DownloadManager: &mockDM{}, DB: &testutils.DatabaseStub{}}
+	srv := NewServer(a, "127.0.0.1:0", "secret")
+
+	body, _ := json.Marshal(AddDownloadRequest{
+		URL:   "magnet:?xt=urn:btih:1234567890abcdef1234567890abcdef12345678&dn=Test+Movie",
+		Files: []int{1},
+	})
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/api/v1/downloads", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.srv.Handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("AddDownload files for a magnet: got status %d, want 400", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "multi-file") {
+		t.Errorf("AddDownload files for a magnet: body %q should explain the restriction", rec.Body.String())
+	}
+}
+
+// duplicateDB reports one library movie for every info hash lookup.
+type duplicateDB struct {
+	testutils.DatabaseStub
+}
+
+func (*duplicateDB) FindMovieByInfoHash(_ context.Context, infoHash string) (*database.Movie, error) {
+	if infoHash == "" {
+		return nil, nil
+	}
+	return &database.Movie{ID: 7, Name: "Existing Movie"}, nil
+}
+
+func TestAPI_AddDownload_DuplicateTorrent(t *testing.T) {
+	cfg := &config.Config{TMSAPIEnabled: true, TMSAPIKey: "secret", MoviePath: t.TempDir()}
+	dm := &mockDM{startReturn: 42}
+	a := &app.App{Config: cfg, DownloadManager: dm, DB: &duplicateDB{}}
+	srv := NewServer(a, "127.0.0.1:0", "secret")
+
+	body, _ := json.Marshal(AddDownloadRequest{
+		URL: "magnet:?xt=urn:btih:1234567890ABCDEF1234567890ABCDEF12345678&dn=Other+Name",
+	})
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/api/v1/downloads", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.srv.Handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("AddDownload duplicate: got status %d, want 409, body=%s", rec.Code, rec.Body.String())
+	}
+	var resp DuplicateDownloadResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.ExistingID != 7 || resp.ExistingTitle != "Existing Movie" {
+		t.Errorf("409 body = %+v, want a link to movie 7", resp)
+	}
+}
+
 func TestAPI_AddDownload_201(t *testing.T) {
 	cfg := &config.Config{TMSAPIEnabled: true, TMSAPIKey: "secret", MoviePath: t.TempDir()}
 	dm := &mockDM{startReturn: 42}
@@ -581,6 +804,57 @@ func TestAPI_AddDownload_201(t *testing.T) {
 	}
 }
 
+func TestAPI_AddDownload_StartAt(t *testing.T) {
+	cfg := &config.Config{TMSAPIEnabled: true, TMSAPIKey: "secret", MoviePath: t.TempDir()}
+	db := &testutils.DatabaseStub{}
+	const link = "magnet:?xt=urn:btih:1234567890abcdef1234567890abcdef12345678&dn=Test+Movie"
+
+	tests := []struct {
+		name          string
+		startAt       time.Time
+		wantScheduled bool
+	}{
+		{name: "future", startAt: time.Now().Add(time.Hour).Truncate(time.Second), wantScheduled: true},
+		{name: "past starts now", startAt: time.Now().Add(-time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &mockDM{startReturn: 42}
+			srv := NewServer(&app.App{Config: cfg, DownloadManager: dm, DB: db}, "127.0.0.1:0", "secret")
+
+			body, _ := json.Marshal(AddDownloadRequest{URL: link, StartAt: &tt.startAt})
+			req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/api/v1/downloads", bytes.NewReader(body))
+			req.Header.Set("Authorization", "Bearer secret")
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			srv.srv.Handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("AddDownload: got status %d, want 201", rec.Code)
+			}
+			var resp AddDownloadResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if scheduled := !dm.scheduledAt.IsZero(); scheduled != tt.wantScheduled {
+				t.Fatalf("scheduled = %v, want %v", scheduled, tt.wantScheduled)
+			}
+			if !tt.wantScheduled {
+				if resp.StartAt != nil {
+					t.Errorf("start_at = %v, want none for a download started right away", resp.StartAt)
+				}
+				return
+			}
+			if !dm.scheduledAt.Equal(tt.startAt) || dm.source != link {
+				t.Errorf("ScheduleDownload(%v, %q), want (%v, %q)", dm.scheduledAt, dm.source, tt.startAt, link)
+			}
+			if resp.StartAt == nil || !resp.StartAt.Equal(tt.startAt) {
+				t.Errorf("start_at = %v, want %v", resp.StartAt, tt.startAt)
+			}
+		})
+	}
+}
+
 // TestAPI_AddDownload_CompletionDrainsChannels verifies that when a download is added via API,
 // the handler starts a completion goroutine that drains progressChan and errChan, so the manager
 // (or mock) can complete and release resources. Uses mockDMCompletion which simulates completion.
@@ -635,6 +909,51 @@ func TestAPI_Search_400NoQuery(t *testing.T) {
 	}
 }
 
+func TestAPI_Cookies_PutListDelete(t *testing.T) {
+	cfg := &config.Config{TMSAPIEnabled: true, TMSAPIKey: "secret", MoviePath: t.TempDir()}
+	srv := NewServer(&app.App{Config: cfg}, "127.0.0.1:0", "secret")
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequestWithContext(context.Background(), method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		srv.srv.Handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(http.MethodPut, "/api/v1/cookies/vimeo.com", "not a cookies file"); rec.Code != http.StatusBadRequest {
+		t.Errorf("PUT invalid file: got status %d, want 400", rec.Code)
+	}
+	expires := time.Now().Add(24 * time.Hour).Unix()
+	file := ".youtube.com\tTRUE\t/\tTRUE\t" + strconv.FormatInt(expires, 10) + "\tSID\tsecret\n"
+	rec := do(http.MethodPut, "/api/v1/cookies/www.youtube.com", file)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT: got status %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	var saved CookieProfile
+	if err := json.NewDecoder(rec.Body).Decode(&saved); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if saved.Domain != "youtube.com" || saved.Cookies != 1 || saved.ExpiresAt == nil || saved.ExpiresAt.Unix() != expires {
+		t.Errorf("unexpected profile: %+v", saved)
+	}
+
+	rec = do(http.MethodGet, "/api/v1/cookies", "")
+	var profiles []CookieProfile
+	if err := json.NewDecoder(rec.Body).Decode(&profiles); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(profiles) != 1 || profiles[0].Domain != "youtube.com" {
+		t.Errorf("GET: got %+v, want the youtube.com profile", profiles)
+	}
+
+	if rec := do(http.MethodDelete, "/api/v1/cookies/youtube.com", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE: got status %d, want 204", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/api/v1/cookies/youtube.com", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("DELETE again: got status %d, want 404", rec.Code)
+	}
+}
+
 func TestAPI_OpenAPIYAML_200(t *testing.T) {
 	a := &app.App{Config: &config.Config{}}
 	srv := NewServer(a, "127.0.0.1:0", "secret")
@@ -26,6 +26,7 @@ const (
 	statusCompleted = "completed"
 	statusFailed    = "failed"
 	statusStopped   = "stopped"
+	statusScheduled = "scheduled"
 )
 
 // WebhookPayload is the default JSON body (TMS_WEBHOOK_FORMAT=json or tms, or custom OpenClaw mappings).
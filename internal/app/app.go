@@ -1,6 +1,7 @@
 package app
 
 import (
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/bandwidth"
 	tmsbot "github.com/NikitaDmitryuk/telegram-media-server/internal/bot"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
@@ -15,6 +16,8 @@ type App struct {
 	DB              database.Database
 	Config          *config.Config
 	DownloadManager tmsdmanager.Service
-	// DeleteQueue: background stop+delete. Set at startup with deletion.NewQueue(Config.MoviePath, DB, DownloadManager).
+	// DeleteQueue: background stop+delete (or move to trash). Set at startup with deletion.NewQueue(Config, DB, DownloadManager).
 	DeleteQueue deletion.Queue
+	// Bandwidth: speed schedule and /speed overrides. Set at startup with bandwidth.NewScheduler(Config).
+	Bandwidth *bandwidth.Scheduler
 }
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"errors"
 
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
@@ -11,6 +12,7 @@ import (
 
 // RunCompletionLoop waits for the download to complete (via completionChan from the manager),
 // then performs cleanup (temp files, delete on failure) and notifies via the given notifier.
+// A direct download that can continue from its partial file is resumed instead of deleted.
 // It must not read progressChan — the manager's monitor is the only consumer of progress so it can write progress to the DB.
 func RunCompletionLoop(
 	a *App,
@@ -21,6 +23,18 @@ func RunCompletionLoop(
 	compl notifier.CompletionNotifier,
 ) {
 	err := <-completionChan
+	for err != nil && !dl.StoppedManually() && !errors.Is(err, downloader.ErrStoppedByDeletion) {
+		resumable, ok := dl.(downloader.ResumableDownloader)
+		if !ok || !resumable.CanResume(err) {
+			break
+		}
+		logutils.Log.WithError(err).WithField("movie_id", movieID).Warn("Download interrupted; resuming it from the partial file")
+		dl, completionChan = resumeDirectDownload(context.Background(), a, movieID, title, resumable.SourceURL(), true)
+		if dl == nil {
+			return
+		}
+		err = <-completionChan
+	}
 	if errors.Is(err, downloader.ErrStoppedByDeletion) {
 		logutils.Log.Info("Download stopped by deletion queue (no user notification)")
 		return
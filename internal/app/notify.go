@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/utils"
+)
+
+// ChatsNotifier implements notifier.CompletionNotifier and notifier.QueueNotifier for downloads started
+// in the background (subscriptions, feed rules, the drop folder); every message goes to all ChatIDs.
+type ChatsNotifier struct {
+	App     *App
+	ChatIDs []int64
+}
+
+// Send sends msg to every chat.
+func (n ChatsNotifier) Send(msg string) {
+	for _, chatID := range n.ChatIDs {
+		n.App.Bot.SendMessage(chatID, msg, nil)
+	}
+}
+
+func (ChatsNotifier) OnStopped(uint, string) {}
+
+func (n ChatsNotifier) OnFailed(_ uint, _ string, err error) {
+	n.Send(lang.Translate("error.downloads.video_download_error", map[string]any{
+		"Error": utils.DownloadErrorMessage(err),
+	}))
+}
+
+func (n ChatsNotifier) OnCompleted(_ uint, title string) {
+	n.Send(lang.Translate("general.video_successfully_downloaded", map[string]any{"Title": title}))
+}
+
+// OnQueued is silent: StartAndNotify already announced the download.
+func (ChatsNotifier) OnQueued(uint, string, int, int) {}
+
+func (ChatsNotifier) OnStarted(uint, string) {}
+
+func (n ChatsNotifier) OnFirstEpisodeReady(_ uint, title string) {
+	n.Send(lang.Translate("general.first_episode_ready", map[string]any{"Title": title}))
+}
+
+func (n ChatsNotifier) OnVideoNotSupported(_ uint, title string) {
+	n.Send(lang.Translate("general.video_not_supported", map[string]any{"Title": title}))
+}
+
+// StartAndNotify checks and starts dl through the download manager and reports its progress to chatIDs.
+// announce builds the message sent once the download is started from its title; nil or "" sends nothing.
+// Returns the download title.
+func StartAndNotify(
+	ctx context.Context,
+	a *App,
+	dl downloader.Downloader,
+	chatIDs []int64,
+	announce func(title string) string,
+) (string, error) {
+	if err := ValidateDownloadStart(ctx, a, dl); err != nil {
+		return "", err
+	}
+	title, err := dl.GetTitle()
+	if err != nil {
+		return "", err
+	}
+	n := ChatsNotifier{App: a, ChatIDs: chatIDs}
+	movieID, _, completionChan, err := a.DownloadManager.StartDownload(dl, n)
+	if err != nil {
+		return "", err
+	}
+	if announce != nil {
+		if msg := announce(title); msg != "" {
+			n.Send(msg)
+		}
+	}
+	go RunCompletionLoop(a, completionChan, dl, movieID, title, n)
+	return title, nil
+}
+
+// RecipientChats is chatID, or the chats of all admins when chatID is 0 (e.g. rules created through the API).
+func RecipientChats(ctx context.Context, a *App, chatID int64) []int64 {
+	if chatID != 0 {
+		return []int64{chatID}
+	}
+	admins, err := a.DB.GetUsersByRole(ctx, database.AdminRole)
+	if err != nil {
+		logutils.Log.WithError(err).Warn("Failed to load admins for notifications")
+		return nil
+	}
+	chatIDs := make([]int64, 0, len(admins))
+	for i := range admins {
+		chatIDs = append(chatIDs, admins[i].ChatID)
+	}
+	return chatIDs
+}
@@ -2,68 +2,151 @@ package app
 
 import (
 	"context"
+	"strings"
 	"time"
 
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/direct"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/factory"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/qbittorrent"
+	aria2 "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/torrent"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/transmission"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/filemanager"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/notifier"
 )
 
 const (
-	qbittorrentResumeInitialDelay = 2 * time.Second
-	qbittorrentResumeMaxDelay     = 1 * time.Minute
-	qbittorrentReadyTimeout       = 10 * time.Second
+	torrentResumeInitialDelay = 2 * time.Second
+	torrentResumeMaxDelay     = 1 * time.Minute
+	torrentClientReadyTimeout = 10 * time.Second
 )
 
-// ResumeIncompleteDownloads finds movies with an active qBittorrent hash and downloaded_percentage < 100,
-// then reattaches monitoring so progress and completion are tracked again after a bot restart.
-func ResumeIncompleteDownloads(a *App) {
-	if a.Config.QBittorrentURL == "" {
-		return
+// torrentClient is the configured torrent daemon whose downloads survive a bot restart.
+type torrentClient struct {
+	name     string
+	url      string
+	username string
+	// checkReady logs in (when needed) and returns the daemon version.
+	checkReady func(ctx context.Context) (string, error)
+	// newResumeDownloader reattaches to the torrent with the stored hash.
+	newResumeDownloader func(movie *database.Movie) (downloader.Downloader, error)
+}
+
+// torrentClientFor returns the daemon named by kind (config.TorrentClient*), or nil when it is not configured.
+func torrentClientFor(cfg *config.Config, kind string) *torrentClient {
+	switch {
+	case kind == config.TorrentClientQBittorrent && cfg.QBittorrentURL != "":
+		return &torrentClient{
+			name:     "qBittorrent",
+			url:      cfg.QBittorrentURL,
+			username: cfg.QBittorrentUsername,
+			checkReady: func(ctx context.Context) (string, error) {
+				client, err := qbittorrent.NewClient(cfg.QBittorrentURL, cfg.QBittorrentUsername, cfg.QBittorrentPassword)
+				if err != nil {
+					return "", err
+				}
+				return client.CheckLogin(ctx)
+			},
+			newResumeDownloader: func(movie *database.Movie) (downloader.Downloader, error) {
+				return qbittorrent.NewQBittorrentResumeDownloader(
+					movie.QBittorrentHash, cfg.MoviePath, movie.TotalEpisodes, movie.CompletedEpisodes, cfg)
+			},
+		}
+	case kind == config.TorrentClientTransmission && cfg.TransmissionURL != "":
+		return &torrentClient{
+			name:     "Transmission",
+			url:      cfg.TransmissionURL,
+			username: cfg.TransmissionUsername,
+			checkReady: func(ctx context.Context) (string, error) {
+				client, err := transmission.NewClient(cfg.TransmissionURL, cfg.TransmissionUsername, cfg.TransmissionPassword)
+				if err != nil {
+					return "", err
+				}
+				return client.SessionVersion(ctx)
+			},
+			newResumeDownloader: func(movie *database.Movie) (downloader.Downloader, error) {
+				return transmission.NewTransmissionResumeDownloader(
+					movie.QBittorrentHash, cfg.MoviePath, movie.TotalEpisodes, movie.CompletedEpisodes, cfg)
+			},
+		}
+	case kind == config.TorrentClientAria2 && cfg.Aria2Settings.RPCURL != "":
+		return &torrentClient{
+			name: "aria2",
+			url:  cfg.Aria2Settings.RPCURL,
+			checkReady: func(ctx context.Context) (string, error) {
+				client, err := aria2.NewRPCClient(cfg.Aria2Settings.RPCURL, cfg.Aria2Settings.RPCSecret)
+				if err != nil {
+					return "", err
+				}
+				return client.GetVersion(ctx)
+			},
+			newResumeDownloader: func(movie *database.Movie) (downloader.Downloader, error) {
+				return aria2.NewAria2RPCResumeDownloader(
+					movie.QBittorrentHash, cfg.MoviePath, movie.TotalEpisodes, movie.CompletedEpisodes, cfg)
+			},
+		}
+	default:
+		return nil
 	}
+}
+
+// ResumeIncompleteDownloads finds movies with an active torrent client hash (qBittorrent, Transmission or aria2 GID)
+// and downloaded_percentage < 100, then reattaches monitoring so progress and completion are tracked
+// again after a bot restart. Each movie is resumed in the daemon that started it (movie.TorrentClient);
+// rows stored before the daemon was recorded use the configured one. Interrupted direct downloads continue
+// from their partial file.
+func ResumeIncompleteDownloads(a *App) {
 	ctx := context.Background()
+	resumeIncompleteDirectDownloads(ctx, a)
 	movies, err := a.DB.GetIncompleteQBittorrentDownloads(ctx)
 	if err != nil {
-		logutils.Log.WithError(err).Error("Failed to get incomplete qBittorrent downloads for resume")
+		logutils.Log.WithError(err).Error("Failed to get incomplete torrent downloads for resume")
 		return
 	}
 	if len(movies) == 0 {
 		return
 	}
-	logutils.Log.WithField("count", len(movies)).Info("Resuming incomplete qBittorrent downloads")
+	logutils.Log.WithField("count", len(movies)).Info("Resuming incomplete torrent downloads")
 	for i := range movies {
-		go resumeIncompleteQBittorrentDownload(ctx, a, &movies[i])
+		kind := movies[i].TorrentClient
+		if kind == "" {
+			kind = a.Config.TorrentClient()
+		}
+		tc := torrentClientFor(a.Config, kind)
+		if tc == nil {
+			logutils.Log.WithFields(map[string]any{
+				"movie_id": movies[i].ID,
+				"client":   kind,
+			}).Warn("Torrent client of an incomplete download is not configured; cannot resume it")
+			continue
+		}
+		go resumeIncompleteTorrentDownload(ctx, a, tc, &movies[i])
 	}
 }
 
-func resumeIncompleteQBittorrentDownload(ctx context.Context, a *App, movie *database.Movie) {
-	delay := qbittorrentResumeInitialDelay
+func resumeIncompleteTorrentDownload(ctx context.Context, a *App, tc *torrentClient, movie *database.Movie) {
+	delay := torrentResumeInitialDelay
 	for {
 		if ctx.Err() != nil {
 			return
 		}
-		if err := waitForQBittorrentReady(ctx, a); err != nil {
+		if err := waitForTorrentClientReady(ctx, tc); err != nil {
 			logutils.Log.WithError(err).WithFields(map[string]any{
 				"movie_id": movie.ID,
-				"url":      a.Config.QBittorrentURL,
-				"username": a.Config.QBittorrentUsername,
-			}).Warn("qBittorrent is not ready for resume; will retry")
+				"url":      tc.url,
+				"username": tc.username,
+			}).Warnf("%s is not ready for resume; will retry", tc.name)
 			sleepWithContext(ctx, delay)
 			delay = nextResumeDelay(delay)
 			continue
 		}
 
-		dl, err := qbittorrent.NewQBittorrentResumeDownloader(
-			movie.QBittorrentHash,
-			a.Config.MoviePath,
-			movie.TotalEpisodes,
-			movie.CompletedEpisodes,
-			a.Config,
-		)
+		dl, err := tc.newResumeDownloader(movie)
 		if err != nil {
-			logutils.Log.WithError(err).WithField("movie_id", movie.ID).Warn("Failed to create qBittorrent resume downloader; will retry")
+			logutils.Log.WithError(err).WithField("movie_id", movie.ID).Warnf("Failed to create %s resume downloader; will retry", tc.name)
 			sleepWithContext(ctx, delay)
 			delay = nextResumeDelay(delay)
 			continue
@@ -77,16 +160,16 @@ func resumeIncompleteQBittorrentDownload(ctx context.Context, a *App, movie *dat
 			notifier.Noop,
 		)
 		if err != nil {
-			logutils.Log.WithError(err).WithField("movie_id", movie.ID).Warn("Failed to attach resumed qBittorrent download; will retry")
+			logutils.Log.WithError(err).WithField("movie_id", movie.ID).Warnf("Failed to attach resumed %s download; will retry", tc.name)
 			sleepWithContext(ctx, delay)
 			delay = nextResumeDelay(delay)
 			continue
 		}
 
-		delay = qbittorrentResumeInitialDelay
+		delay = torrentResumeInitialDelay
 		err = <-completionChan
 		if err == nil {
-			logutils.Log.WithField("movie_id", movie.ID).Info("Resumed qBittorrent download completed successfully")
+			logutils.Log.WithField("movie_id", movie.ID).Infof("Resumed %s download completed successfully", tc.name)
 			if cleanupErr := filemanager.DeleteTemporaryFilesByMovieID(movie.ID, a.Config.MoviePath, a.DB, a.DownloadManager); cleanupErr != nil {
 				logutils.Log.WithError(cleanupErr).WithField("movie_id", movie.ID).Warn("Failed to delete temporary files after resumed download")
 			}
@@ -96,35 +179,103 @@ func resumeIncompleteQBittorrentDownload(ctx context.Context, a *App, movie *dat
 		logutils.Log.WithError(err).WithFields(map[string]any{
 			"movie_id": movie.ID,
 			"hash":     movie.QBittorrentHash,
-		}).Warn("Resumed qBittorrent download stopped with error; keeping database record and retrying")
+		}).Warnf("Resumed %s download stopped with error; keeping database record and retrying", tc.name)
 		sleepWithContext(ctx, delay)
 		delay = nextResumeDelay(delay)
 	}
 }
 
-func waitForQBittorrentReady(ctx context.Context, a *App) error {
-	client, err := qbittorrent.NewClient(a.Config.QBittorrentURL, a.Config.QBittorrentUsername, a.Config.QBittorrentPassword)
+// resumeIncompleteDirectDownloads restarts the direct downloads cut off by a bot restart: rows with a stored source
+// and the segment state of their partial file.
+func resumeIncompleteDirectDownloads(ctx context.Context, a *App) {
+	movies, err := a.DB.GetMovieList(ctx)
 	if err != nil {
-		return err
+		logutils.Log.WithError(err).Error("Failed to get movies for direct download resume")
+		return
 	}
-	checkCtx, cancel := context.WithTimeout(ctx, qbittorrentReadyTimeout)
+	for i := range movies {
+		movie := &movies[i]
+		if movie.StartAt != nil || movie.Source == "" || movie.DownloadedPercentage >= 100 || !hasDirectState(ctx, a, movie.ID) {
+			continue
+		}
+		logutils.Log.WithField("movie_id", movie.ID).Info("Resuming interrupted direct download")
+		go func() {
+			dl, completionChan := resumeDirectDownload(ctx, a, movie.ID, movie.Name, movie.Source, false)
+			if dl != nil {
+				RunCompletionLoop(a, completionChan, dl, movie.ID, movie.Name, notifier.CompletionNoop)
+			}
+		}()
+	}
+}
+
+func hasDirectState(ctx context.Context, a *App, movieID uint) bool {
+	files, err := a.DB.GetTempFilesByMovieID(ctx, movieID)
+	if err != nil {
+		return false
+	}
+	for _, f := range files {
+		if strings.HasSuffix(f.FilePath, direct.StateSuffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// resumeDirectDownload recreates the direct downloader from source and hands it to the download manager, which
+// continues from the partial file. Failures are retried with backoff (wait also delays the first attempt).
+// Returns a nil downloader when ctx ends or the movie is deleted meanwhile.
+func resumeDirectDownload(
+	ctx context.Context,
+	a *App,
+	movieID uint,
+	title, source string,
+	wait bool,
+) (downloader.Downloader, <-chan error) {
+	delay := torrentResumeInitialDelay
+	for ; ; wait = true {
+		if wait {
+			sleepWithContext(ctx, delay)
+			delay = nextResumeDelay(delay)
+		}
+		if ctx.Err() != nil {
+			return nil, nil
+		}
+		if exists, err := a.DB.MovieExistsId(ctx, movieID); err == nil && !exists {
+			return nil, nil
+		}
+		dl, err := factory.NewDirectDownloader(ctx, source, a.Config)
+		if err != nil {
+			logutils.Log.WithError(err).WithField("movie_id", movieID).Warn("Failed to recreate direct download; will retry")
+			continue
+		}
+		completionChan, err := a.DownloadManager.ResumeDownload(movieID, dl, title, 0, notifier.Noop)
+		if err != nil {
+			logutils.Log.WithError(err).WithField("movie_id", movieID).Warn("Failed to resume direct download; will retry")
+			continue
+		}
+		return dl, completionChan
+	}
+}
+
+func waitForTorrentClientReady(ctx context.Context, tc *torrentClient) error {
+	checkCtx, cancel := context.WithTimeout(ctx, torrentClientReadyTimeout)
 	defer cancel()
-	version, err := client.CheckLogin(checkCtx)
+	version, err := tc.checkReady(checkCtx)
 	if err != nil {
 		return err
 	}
 	logutils.Log.WithFields(map[string]any{
-		"url":      a.Config.QBittorrentURL,
-		"username": a.Config.QBittorrentUsername,
+		"url":      tc.url,
+		"username": tc.username,
 		"version":  version,
-	}).Info("qBittorrent Web API login check succeeded")
+	}).Infof("%s login check succeeded", tc.name)
 	return nil
 }
 
 func nextResumeDelay(current time.Duration) time.Duration {
 	next := current * 2
-	if next > qbittorrentResumeMaxDelay {
-		return qbittorrentResumeMaxDelay
+	if next > torrentResumeMaxDelay {
+		return torrentResumeMaxDelay
 	}
 	return next
 }
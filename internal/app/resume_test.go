@@ -0,0 +1,87 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	tmsdmanager "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/manager"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/testutils"
+)
+
+func TestResumeIncompleteDownloadsContinuesDirectDownload(t *testing.T) {
+	logutils.InitLogger("error")
+	ctx := context.Background()
+
+	content := bytes.Repeat([]byte("0123456789abcdef"), 16*1024)
+	half := len(content) / 2
+	var (
+		mu     sync.Mutex
+		ranges []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			mu.Lock()
+			ranges = append(ranges, r.Header.Get("Range"))
+			mu.Unlock()
+		}
+		w.Header().Set("Content-Type", "video/mp4")
+		http.ServeContent(w, r, "movie.mp4", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	// The bot stopped halfway: the partial file and its segment state are on disk and the row is incomplete.
+	dir := testutils.TempDir(t)
+	cfg := testutils.TestConfig(dir)
+	if err := os.WriteFile(filepath.Join(dir, "movie.mp4.part"), content[:half], 0o600); err != nil {
+		t.Fatal(err)
+	}
+	state := fmt.Sprintf(`{"size":%d,"segments":[{"start":0,"end":%d,"done":%d}]}`, len(content), len(content)-1, half)
+	if err := os.WriteFile(filepath.Join(dir, "movie.mp4.part.json"), []byte(state), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	db := testutils.TestDatabase(t)
+	movieID, err := db.AddMovie(ctx, "movie", int64(len(content)), []string{"movie.mp4"},
+		[]string{"movie.mp4.part", "movie.mp4.part.json"}, 0)
+	if err != nil {
+		t.Fatalf("AddMovie: %v", err)
+	}
+	if err := db.SetMovieSource(ctx, movieID, srv.URL+"/movie.mp4"); err != nil {
+		t.Fatalf("SetMovieSource: %v", err)
+	}
+
+	a := &App{Config: cfg, DB: db, DownloadManager: tmsdmanager.NewDownloadManager(cfg, db)}
+	ResumeIncompleteDownloads(a)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		movie, getErr := db.GetMovieByID(ctx, movieID)
+		if getErr == nil && movie.DownloadedPercentage == 100 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("resumed direct download did not complete")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "movie.mp4"))
+	if err != nil || !bytes.Equal(got, content) {
+		t.Fatalf("downloaded file differs from the served one (err %v)", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	want := fmt.Sprintf("bytes=%d-%d", half, len(content)-1)
+	if !slices.Equal(ranges, []string{want}) {
+		t.Errorf("GET Range headers = %q, want only %q", ranges, want)
+	}
+}
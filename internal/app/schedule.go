@@ -0,0 +1,113 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/factory"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/filemanager"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/notifier"
+)
+
+// scheduledSourceSuffixes are the temp files a scheduled download can be recreated from.
+var scheduledSourceSuffixes = []string{".torrent", ".magnet", ".nzb"}
+
+// errNoScheduledSource means the scheduled movie has nothing left to recreate its download from.
+var errNoScheduledSource = errors.New("no torrent, NZB or link to start the download from")
+
+// ResumeScheduledDownloads puts downloads that were waiting for their start time back into the queue
+// after a bot restart. Torrent and NZB downloads are recreated from their temp file, links from the stored source,
+// both with the stored start options (video options, selected files).
+func ResumeScheduledDownloads(a *App) {
+	ctx := context.Background()
+	movies, err := a.DB.GetMovieList(ctx)
+	if err != nil {
+		logutils.Log.WithError(err).Error("Failed to get movies for scheduled download resume")
+		return
+	}
+	for i := range movies {
+		movie := &movies[i]
+		if movie.StartAt == nil || movie.DownloadedPercentage >= 100 {
+			continue
+		}
+		go resumeScheduledDownload(ctx, a, movie)
+	}
+}
+
+// resumeScheduledDownload recreates the downloader of a scheduled movie and queues it. A failure such as a
+// yt-dlp, network or torrent client error is retried with backoff; only a movie without any source is removed.
+func resumeScheduledDownload(ctx context.Context, a *App, movie *database.Movie) {
+	delay := torrentResumeInitialDelay
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		if exists, err := a.DB.MovieExistsId(ctx, movie.ID); err == nil && !exists {
+			return // deleted by the user while the retries went on
+		}
+		dl, err := scheduledDownloader(ctx, a, movie)
+		if errors.Is(err, errNoScheduledSource) {
+			logutils.Log.WithError(err).WithField("movie_id", movie.ID).Warn("Scheduled download cannot be recreated; removing it")
+			if deleteErr := filemanager.DeleteMovie(movie.ID, a.Config.MoviePath, a.DB, a.DownloadManager); deleteErr != nil {
+				logutils.Log.WithError(deleteErr).WithField("movie_id", movie.ID).Error("Failed to delete scheduled download")
+			}
+			return
+		}
+		if err != nil {
+			logutils.Log.WithError(err).WithField("movie_id", movie.ID).Warn("Failed to recreate scheduled download; will retry")
+			sleepWithContext(ctx, delay)
+			delay = nextResumeDelay(delay)
+			continue
+		}
+		completionChan := a.DownloadManager.ResumeScheduledDownload(movie.ID, dl, movie.Name, *movie.StartAt, notifier.Noop)
+		logutils.Log.WithFields(map[string]any{
+			"movie_id": movie.ID,
+			"start_at": *movie.StartAt,
+		}).Info("Resumed scheduled download")
+		RunCompletionLoop(a, completionChan, dl, movie.ID, movie.Name, notifier.CompletionNoop)
+		return
+	}
+}
+
+func scheduledDownloader(ctx context.Context, a *App, movie *database.Movie) (downloader.Downloader, error) {
+	opts, err := factory.DecodeStartOptions(movie.StartOptions)
+	if err != nil {
+		logutils.Log.WithError(err).WithField("movie_id", movie.ID).Warn("Ignoring stored start options of scheduled download")
+	}
+	dl, err := scheduledSourceDownloader(ctx, a, movie, opts.Video)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.SelectFiles(dl); err != nil {
+		logutils.Log.WithError(err).WithField("movie_id", movie.ID).Warn("Failed to select stored files; downloading all of them")
+	}
+	return dl, nil
+}
+
+func scheduledSourceDownloader(
+	ctx context.Context,
+	a *App,
+	movie *database.Movie,
+	videoOpts factory.VideoOptions,
+) (downloader.Downloader, error) {
+	files, err := a.DB.GetTempFilesByMovieID(ctx, movie.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		name := strings.ToLower(f.FilePath)
+		for _, suffix := range scheduledSourceSuffixes {
+			if strings.HasSuffix(name, suffix) {
+				return factory.NewDownloaderFromFile(f.FilePath, a.Config.MoviePath, a.Config)
+			}
+		}
+	}
+	if movie.Source != "" {
+		return factory.CreateDownloaderFromURLWithOptions(ctx, movie.Source, a.Config.MoviePath, a.Config, videoOpts)
+	}
+	return nil, errNoScheduledSource
+}
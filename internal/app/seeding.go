@@ -0,0 +1,44 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/seeding"
+)
+
+var (
+	ErrSeedingUnsupported = errors.New("seeding is only supported with qBittorrent")
+	ErrSeedingFinished    = errors.New("download is finished and its torrent is no longer in the client")
+)
+
+// SetSeeding switches the seed toggle of the movie: "on" keeps the torrent seeding until switched off,
+// "off" removes it from qBittorrent (files are kept) right away if it is seeding, or at completion otherwise.
+// Call from both API and Telegram.
+func SetSeeding(ctx context.Context, a *App, movie *database.Movie, seed bool) error {
+	if a.Config.QBittorrentURL == "" {
+		return ErrSeedingUnsupported
+	}
+	mode := seeding.ModeOff
+	if seed {
+		mode = seeding.ModeOn
+		if movie.DownloadedPercentage >= 100 && !movie.Seeding {
+			return ErrSeedingFinished
+		}
+	}
+	if err := a.DB.SetMovieSeedMode(ctx, movie.ID, mode); err != nil {
+		return err
+	}
+	movie.SeedMode = mode
+	if !seed && movie.Seeding {
+		go func() {
+			// Detached from the request: removing the torrent should not be cut short when it returns.
+			if stopped := seeding.Check(context.Background(), a.Config, a.DB); len(stopped) > 0 {
+				logutils.Log.WithField("movie_ids", stopped).Info("Seeding stopped after the seed toggle was switched off")
+			}
+		}()
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"context"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/filemanager"
+)
+
+// DiskSpace is the free space in MOVIE_PATH and the part of it already promised to active and queued downloads.
+type DiskSpace struct {
+	FreeBytes     int64
+	ReservedBytes int64
+}
+
+// AvailableBytes is free space minus reservations; it is what a new download can actually use.
+func (s DiskSpace) AvailableBytes() int64 {
+	return max(s.FreeBytes-s.ReservedBytes, 0)
+}
+
+// GetDiskSpace reports free and reserved space for MOVIE_PATH.
+func GetDiskSpace(ctx context.Context, a *App) (DiskSpace, error) {
+	free, err := filemanager.GetAvailableSpaceBytes(a.Config.MoviePath)
+	if err != nil {
+		return DiskSpace{}, err
+	}
+	space := DiskSpace{FreeBytes: free}
+	if a.DownloadManager != nil {
+		space.ReservedBytes = a.DownloadManager.ReservedBytes(ctx)
+	}
+	return space, nil
+}
@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/filemanager"
@@ -13,13 +14,36 @@ var (
 	ErrNotEnoughSpace = errors.New("not enough space")
 )
 
+// DuplicateError reports a torrent whose info hash is already in the library; errors.Is matches ErrAlreadyExists.
+type DuplicateError struct {
+	MovieID uint
+	Title   string
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("torrent already added as #%d %s", e.MovieID, e.Title)
+}
+
+func (*DuplicateError) Is(target error) bool {
+	return target == ErrAlreadyExists
+}
+
 // ValidateDownloadStart checks that the download can be started: files are not already present
-// and there is enough disk space. Call from both API and Telegram before StartDownload.
+// and there is enough disk space left after reservations of other downloads. Call from both API and Telegram before StartDownload.
 func ValidateDownloadStart(ctx context.Context, a *App, dl downloader.Downloader) error {
 	mainFiles, tempFiles, err := dl.GetFiles()
 	if err != nil {
 		return err
 	}
+	if hashed, ok := dl.(downloader.InfoHashDownloader); ok {
+		existing, findErr := a.DB.FindMovieByInfoHash(ctx, hashed.InfoHash())
+		if findErr != nil {
+			return findErr
+		}
+		if existing != nil {
+			return &DuplicateError{MovieID: existing.ID, Title: existing.Name}
+		}
+	}
 	allFiles := make([]string, 0, len(mainFiles)+len(tempFiles))
 	allFiles = append(allFiles, mainFiles...)
 	allFiles = append(allFiles, tempFiles...)
@@ -34,7 +58,13 @@ func ValidateDownloadStart(ctx context.Context, a *App, dl downloader.Downloader
 	if err != nil {
 		return err
 	}
-	if !filemanager.HasEnoughSpace(a.Config.MoviePath, fileSize) {
+	// Space still to be written by active and queued downloads is already taken, so several large
+	// torrents added back-to-back cannot all pass against the same free space.
+	var reserved int64
+	if a.DownloadManager != nil {
+		reserved = a.DownloadManager.ReservedBytes(ctx)
+	}
+	if !filemanager.HasEnoughSpace(a.Config.MoviePath, fileSize+reserved) {
 		return ErrNotEnoughSpace
 	}
 	return nil
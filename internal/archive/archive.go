@@ -0,0 +1,331 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	tmsdb "github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+)
+
+// Volume names of the supported archive sets, matched against lowercase file names:
+// "x.part01.rar" sets, old-style "x.rar" + "x.r00", "x.zip" + "x.z01" and split "x.7z.001"/"x.zip.001".
+var (
+	rarPartRe  = regexp.MustCompile(`^(.+)\.part(\d+)\.rar$`)
+	rarRe      = regexp.MustCompile(`^(.+)\.rar$`)
+	rarOldRe   = regexp.MustCompile(`^(.+)\.r\d{2,3}$`)
+	zipRe      = regexp.MustCompile(`^(.+)\.(zip|7z)$`)
+	zipSplitRe = regexp.MustCompile(`^(.+)\.z\d{2}$`)
+	splitRe    = regexp.MustCompile(`^(.+\.(?:zip|7z))\.(\d{3})$`)
+	progressRe = regexp.MustCompile(`(\d{1,3})%`)
+)
+
+// Set is one archive: the volume to pass to the extractor and all volumes to delete afterwards.
+// Paths are relative to the movie root, as stored in movie_files.
+type Set struct {
+	First   string
+	Volumes []string
+}
+
+// FindSets groups the archive volumes among paths into sets; sets without their first volume are skipped.
+func FindSets(paths []string) []Set {
+	byKey := make(map[string]*Set)
+	var keys []string
+	for _, p := range paths {
+		key, first, ok := classify(p)
+		if !ok {
+			continue
+		}
+		set, exists := byKey[key]
+		if !exists {
+			set = &Set{}
+			byKey[key] = set
+			keys = append(keys, key)
+		}
+		set.Volumes = append(set.Volumes, p)
+		if first {
+			set.First = p
+		}
+	}
+	slices.Sort(keys)
+	sets := make([]Set, 0, len(keys))
+	for _, key := range keys {
+		if set := byKey[key]; set.First != "" {
+			slices.Sort(set.Volumes)
+			sets = append(sets, *set)
+		}
+	}
+	return sets
+}
+
+// classify returns the set key of an archive volume and whether it is the volume to extract from.
+func classify(path string) (key string, first, ok bool) {
+	dir := filepath.Dir(path)
+	name := strings.ToLower(filepath.Base(path))
+	if m := rarPartRe.FindStringSubmatch(name); m != nil {
+		n, _ := strconv.Atoi(m[2])
+		return filepath.Join(dir, m[1]) + "|rar", n == 1, true
+	}
+	if m := rarRe.FindStringSubmatch(name); m != nil {
+		return filepath.Join(dir, m[1]) + "|rar", true, true
+	}
+	if m := rarOldRe.FindStringSubmatch(name); m != nil {
+		return filepath.Join(dir, m[1]) + "|rar", false, true
+	}
+	if m := splitRe.FindStringSubmatch(name); m != nil {
+		n, _ := strconv.Atoi(m[2])
+		return filepath.Join(dir, m[1]) + "|split", n == 1, true
+	}
+	if m := zipRe.FindStringSubmatch(name); m != nil {
+		return filepath.Join(dir, m[1]) + "|" + m[2], true, true
+	}
+	if m := zipSplitRe.FindStringSubmatch(name); m != nil {
+		return filepath.Join(dir, m[1]) + "|zip", false, true
+	}
+	return "", false, false
+}
+
+// ExtractMovieArchives extracts the archive sets among the main files of the movie next to their first volume,
+// registers the extracted files as main files and deletes the archives. progress gets the overall percentage.
+// With keepArchives (the torrent is still seeding) the archives stay on disk and in the file list until
+// RemoveArchives is called. On error the archives and the file list are left as they were.
+func ExtractMovieArchives(
+	ctx context.Context,
+	movieID uint,
+	moviePath string,
+	db tmsdb.Database,
+	sevenZipPath string,
+	keepArchives bool,
+	progress func(percent int),
+) error {
+	paths, err := MainFilePaths(ctx, movieID, db)
+	if err != nil {
+		return err
+	}
+	sets := FindSets(paths)
+	if len(sets) == 0 {
+		return nil
+	}
+
+	var extracted []string
+	for i := range sets {
+		setProgress := func(percent int) {
+			progress((i*100 + percent) / len(sets))
+		}
+		names, extractErr := extractSet(ctx, moviePath, &sets[i], sevenZipPath, setProgress)
+		if extractErr != nil {
+			return fmt.Errorf("failed to extract %s: %w", sets[i].First, extractErr)
+		}
+		extracted = append(extracted, names...)
+	}
+
+	volumes := volumeSet(sets)
+	if keepArchives {
+		volumes = nil
+	}
+	mainFiles := withoutVolumes(paths, volumes)
+	for _, p := range extracted {
+		if !slices.Contains(mainFiles, p) {
+			mainFiles = append(mainFiles, p)
+		}
+	}
+	if err := db.ReplaceMainMovieFiles(ctx, movieID, mainFiles); err != nil {
+		return fmt.Errorf("failed to register extracted files: %w", err)
+	}
+	deleteVolumes(moviePath, volumes)
+	progress(100)
+	return nil
+}
+
+// RemoveArchives deletes the archive sets of an already extracted movie, e.g. the ones kept while its torrent seeded.
+func RemoveArchives(ctx context.Context, movieID uint, moviePath string, db tmsdb.Database) error {
+	paths, err := MainFilePaths(ctx, movieID, db)
+	if err != nil {
+		return err
+	}
+	volumes := volumeSet(FindSets(paths))
+	if len(volumes) == 0 {
+		return nil
+	}
+	if err := db.ReplaceMainMovieFiles(ctx, movieID, withoutVolumes(paths, volumes)); err != nil {
+		return fmt.Errorf("failed to unregister archives: %w", err)
+	}
+	deleteVolumes(moviePath, volumes)
+	return nil
+}
+
+func volumeSet(sets []Set) map[string]struct{} {
+	volumes := make(map[string]struct{})
+	for i := range sets {
+		for _, v := range sets[i].Volumes {
+			volumes[v] = struct{}{}
+		}
+	}
+	return volumes
+}
+
+func withoutVolumes(paths []string, volumes map[string]struct{}) []string {
+	kept := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if _, ok := volumes[p]; !ok {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+func deleteVolumes(moviePath string, volumes map[string]struct{}) {
+	for v := range volumes {
+		if removeErr := os.Remove(filepath.Join(moviePath, v)); removeErr != nil && !os.IsNotExist(removeErr) {
+			logutils.Log.WithError(removeErr).WithField("path", v).Warn("Archive extraction: failed to delete archive volume")
+		}
+	}
+}
+
+// MainFilePaths returns the main (non-temp) file paths of the movie, relative to the movie root.
+func MainFilePaths(ctx context.Context, movieID uint, db tmsdb.Database) ([]string, error) {
+	files, err := db.GetFilesByMovieID(ctx, movieID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get movie files: %w", err)
+	}
+	paths := make([]string, 0, len(files))
+	for i := range files {
+		paths = append(paths, files[i].FilePath)
+	}
+	return paths, nil
+}
+
+// extractSet runs 7z on the first volume and returns the extracted files, relative to moviePath.
+func extractSet(ctx context.Context, moviePath string, set *Set, sevenZipPath string, progress func(int)) ([]string, error) {
+	archivePath := filepath.Join(moviePath, set.First)
+	dir := filepath.Dir(archivePath)
+	names, err := listArchive(ctx, sevenZipPath, archivePath)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, errors.New("archive contains no files")
+	}
+
+	// #nosec G204 -- the binary comes from SEVENZIP_PATH and the archive path from the movie's own files.
+	cmd := exec.CommandContext(ctx, sevenZipPath, "x", "-y", "-bso0", "-bsp1", "-o"+dir, archivePath)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	readProgress(stdout, progress)
+	if err := cmd.Wait(); err != nil {
+		return nil, commandError(err, &stderr)
+	}
+
+	extracted := make([]string, 0, len(names))
+	for _, name := range names {
+		rel, relErr := filepath.Rel(moviePath, filepath.Join(dir, name))
+		if relErr != nil || strings.HasPrefix(rel, "..") {
+			return nil, fmt.Errorf("archive entry %q points outside the movie folder", name)
+		}
+		extracted = append(extracted, rel)
+	}
+	return extracted, nil
+}
+
+// listArchive returns the files (not folders) in the archive from the technical listing of "7z l -slt".
+func listArchive(ctx context.Context, sevenZipPath, archivePath string) ([]string, error) {
+	// #nosec G204 -- see extractSet.
+	cmd := exec.CommandContext(ctx, sevenZipPath, "l", "-slt", archivePath)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, commandError(err, &stderr)
+	}
+	return parseListing(out), nil
+}
+
+// parseListing reads the entries after the "----------" separator; each entry starts with "Path = ".
+func parseListing(out []byte) []string {
+	var names []string
+	var path string
+	var folder, entries bool
+	flush := func() {
+		if path != "" && !folder {
+			names = append(names, filepath.FromSlash(strings.ReplaceAll(path, "\\", "/")))
+		}
+		path, folder = "", false
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "----------" {
+			entries = true
+			continue
+		}
+		if !entries {
+			continue
+		}
+		key, value, ok := strings.Cut(line, " = ")
+		switch {
+		case !ok:
+			continue
+		case key == "Path":
+			flush()
+			path = value
+		case key == "Folder":
+			folder = value == "+"
+		case key == "Attributes":
+			folder = folder || strings.HasPrefix(value, "D")
+		}
+	}
+	flush()
+	return names
+}
+
+func commandError(err error, stderr *bytes.Buffer) error {
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return fmt.Errorf("%w: %s", err, msg)
+	}
+	return err
+}
+
+// readProgress reports the percentages 7z prints with -bsp1; it redraws the line with backspaces or \r.
+func readProgress(r io.Reader, progress func(int)) {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(splitProgress)
+	last := -1
+	for scanner.Scan() {
+		m := progressRe.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		if pct, err := strconv.Atoi(m[1]); err == nil && pct != last && pct <= 100 {
+			last = pct
+			progress(pct)
+		}
+	}
+}
+
+// splitProgress splits on newlines, carriage returns and backspaces.
+func splitProgress(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexAny(data, "\n\r\b"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
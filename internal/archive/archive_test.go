@@ -0,0 +1,237 @@
+package archive
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/testutils"
+)
+
+func TestFindSets(t *testing.T) {
+	paths := []string{
+		"Movie/movie.part02.rar",
+		"Movie/movie.part01.rar",
+		"Movie/movie.nfo",
+		"Old/old.r00",
+		"Old/old.rar",
+		"Old/old.r01",
+		"Split/video.7z.002",
+		"Split/video.7z.001",
+		"Zip/clip.zip",
+		"Zip/clip.z01",
+		"Orphan/orphan.part02.rar",
+		"Video/video.mkv",
+	}
+	want := []Set{
+		{First: "Movie/movie.part01.rar", Volumes: []string{"Movie/movie.part01.rar", "Movie/movie.part02.rar"}},
+		{First: "Old/old.rar", Volumes: []string{"Old/old.r00", "Old/old.r01", "Old/old.rar"}},
+		{First: "Split/video.7z.001", Volumes: []string{"Split/video.7z.001", "Split/video.7z.002"}},
+		{First: "Zip/clip.zip", Volumes: []string{"Zip/clip.z01", "Zip/clip.zip"}},
+	}
+	if got := FindSets(paths); !reflect.DeepEqual(got, want) {
+		t.Fatalf("FindSets() = %+v\nwant %+v", got, want)
+	}
+	if got := FindSets([]string{"Video/video.mkv", "Video/video.srt"}); len(got) != 0 {
+		t.Errorf("FindSets() without archives = %+v, want none", got)
+	}
+}
+
+func TestParseListing(t *testing.T) {
+	out := `7-Zip 23.01 (x64)
+
+Listing archive: /media/Show/show.part01.rar
+
+--
+Path = /media/Show/show.part01.rar
+Type = Rar5
+
+----------
+Path = Show
+Folder = +
+Size = 0
+
+Path = Show\E01.mkv
+Folder = -
+Size = 1024
+
+Path = Show/E02.mkv
+Attributes = A
+Size = 2048
+`
+	want := []string{filepath.Join("Show", "E01.mkv"), filepath.Join("Show", "E02.mkv")}
+	if got := parseListing([]byte(out)); !slices.Equal(got, want) {
+		t.Errorf("parseListing() = %v, want %v", got, want)
+	}
+}
+
+func TestReadProgress(t *testing.T) {
+	var got []int
+	readProgress(strings.NewReader("  0%\b\b\b\b  5% 1 - E01.mkv\r 42% 1 - E01.mkv\r 42%\n100%\n"), func(p int) {
+		got = append(got, p)
+	})
+	if want := []int{0, 5, 42, 100}; !slices.Equal(got, want) {
+		t.Errorf("progress = %v, want %v", got, want)
+	}
+}
+
+func TestExtractMovieArchives(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping fake 7z script test on Windows")
+	}
+	logutils.InitLogger("error")
+	moviePath := testutils.TempDir(t)
+	db := testutils.TestDatabase(t)
+	ctx := context.Background()
+
+	volumes := []string{"Show/show.part1.rar", "Show/show.part2.rar"}
+	for _, v := range volumes {
+		if err := os.MkdirAll(filepath.Join(moviePath, filepath.Dir(v)), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(moviePath, v), []byte("rar"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	movieID, err := db.AddMovie(ctx, "Show", 6, append([]string{"Show/show.nfo"}, volumes...), nil, 0)
+	if err != nil {
+		t.Fatalf("AddMovie: %v", err)
+	}
+
+	// Fake 7z: lists two episodes and writes them into the -o folder.
+	script := `#!/bin/sh
+if [ "$1" = "l" ]; then
+  printf -- '----------\nPath = E01.mkv\nFolder = -\n\nPath = E02.mkv\nFolder = -\n'
+  exit 0
+fi
+for a in "$@"; do
+  case "$a" in -o*) out="${a#-o}";; esac
+done
+printf ' 50%%\r100%%\n'
+echo data > "$out/E01.mkv"
+echo data > "$out/E02.mkv"
+`
+	bin := filepath.Join(t.TempDir(), "7z")
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil { // #nosec G306 -- test executable
+		t.Fatalf("write fake 7z: %v", err)
+	}
+
+	var progress []int
+	if err := ExtractMovieArchives(ctx, movieID, moviePath, db, bin, false, func(p int) { progress = append(progress, p) }); err != nil {
+		t.Fatalf("ExtractMovieArchives: %v", err)
+	}
+
+	got, err := MainFilePaths(ctx, movieID, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(got)
+	want := []string{filepath.Join("Show", "E01.mkv"), filepath.Join("Show", "E02.mkv"), "Show/show.nfo"}
+	if !slices.Equal(got, want) {
+		t.Errorf("main files = %v, want %v", got, want)
+	}
+	for _, v := range volumes {
+		if _, statErr := os.Stat(filepath.Join(moviePath, v)); !os.IsNotExist(statErr) {
+			t.Errorf("archive volume %s should be deleted", v)
+		}
+	}
+	if len(progress) == 0 || progress[len(progress)-1] != 100 {
+		t.Errorf("progress = %v, want it to end at 100", progress)
+	}
+}
+
+func TestExtractMovieArchivesKeepsArchivesOnFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping fake 7z script test on Windows")
+	}
+	logutils.InitLogger("error")
+	moviePath := testutils.TempDir(t)
+	db := testutils.TestDatabase(t)
+	ctx := context.Background()
+
+	archivePath := filepath.Join(moviePath, "movie.zip")
+	if err := os.WriteFile(archivePath, []byte("zip"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	movieID, err := db.AddMovie(ctx, "Movie", 3, []string{"movie.zip"}, nil, 0)
+	if err != nil {
+		t.Fatalf("AddMovie: %v", err)
+	}
+	bin := filepath.Join(t.TempDir(), "7z")
+	script := "#!/bin/sh\necho 'ERROR: Data Error' >&2\nexit 2\n"
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil { // #nosec G306 -- test executable
+		t.Fatalf("write fake 7z: %v", err)
+	}
+
+	err = ExtractMovieArchives(ctx, movieID, moviePath, db, bin, false, func(int) {})
+	if err == nil || !strings.Contains(err.Error(), "Data Error") {
+		t.Fatalf("ExtractMovieArchives error = %v, want the 7z error", err)
+	}
+	if _, statErr := os.Stat(archivePath); statErr != nil {
+		t.Errorf("archive should be kept after a failed extraction: %v", statErr)
+	}
+	if got, _ := MainFilePaths(ctx, movieID, db); !slices.Equal(got, []string{"movie.zip"}) {
+		t.Errorf("main files = %v, want the archive only", got)
+	}
+}
+
+func TestExtractMovieArchivesKeepsArchivesWhileSeeding(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping fake 7z script test on Windows")
+	}
+	logutils.InitLogger("error")
+	moviePath := testutils.TempDir(t)
+	db := testutils.TestDatabase(t)
+	ctx := context.Background()
+
+	archivePath := filepath.Join(moviePath, "movie.rar")
+	if err := os.WriteFile(archivePath, []byte("rar"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	movieID, err := db.AddMovie(ctx, "Movie", 3, []string{"movie.rar"}, nil, 0)
+	if err != nil {
+		t.Fatalf("AddMovie: %v", err)
+	}
+	script := `#!/bin/sh
+if [ "$1" = "l" ]; then
+  printf -- '----------\nPath = movie.mkv\nFolder = -\n'
+  exit 0
+fi
+for a in "$@"; do
+  case "$a" in -o*) out="${a#-o}";; esac
+done
+echo data > "$out/movie.mkv"
+`
+	bin := filepath.Join(t.TempDir(), "7z")
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil { // #nosec G306 -- test executable
+		t.Fatalf("write fake 7z: %v", err)
+	}
+
+	if err := ExtractMovieArchives(ctx, movieID, moviePath, db, bin, true, func(int) {}); err != nil {
+		t.Fatalf("ExtractMovieArchives: %v", err)
+	}
+	got, _ := MainFilePaths(ctx, movieID, db)
+	slices.Sort(got)
+	if want := []string{"movie.mkv", "movie.rar"}; !slices.Equal(got, want) {
+		t.Errorf("main files while seeding = %v, want %v", got, want)
+	}
+	if _, statErr := os.Stat(archivePath); statErr != nil {
+		t.Errorf("archive should be kept while seeding: %v", statErr)
+	}
+
+	if err := RemoveArchives(ctx, movieID, moviePath, db); err != nil {
+		t.Fatalf("RemoveArchives: %v", err)
+	}
+	if got, _ := MainFilePaths(ctx, movieID, db); !slices.Equal(got, []string{"movie.mkv"}) {
+		t.Errorf("main files after seeding = %v, want the extracted file only", got)
+	}
+	if _, statErr := os.Stat(archivePath); !os.IsNotExist(statErr) {
+		t.Error("archive should be deleted after seeding")
+	}
+}
@@ -0,0 +1,52 @@
+package bandwidth
+
+import (
+	"context"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/qbittorrent"
+	aria2 "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/torrent"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/transmission"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+)
+
+// limiterFunc adapts a function to Limiter.
+type limiterFunc func(ctx context.Context, download, upload int64) error
+
+func (f limiterFunc) SetSpeedLimits(ctx context.Context, download, upload int64) error {
+	return f(ctx, download, upload)
+}
+
+// newLimiter returns the torrent daemon from QBITTORRENT_URL, TRANSMISSION_URL or ARIA2_RPC_URL, or nil when
+// torrents run as one aria2c process per download (those take the limits at start, see SetSpeedLimitSource
+// of the download manager).
+func newLimiter(cfg *config.Config) Limiter {
+	switch {
+	case cfg.QBittorrentURL != "":
+		return limiterFunc(func(ctx context.Context, download, upload int64) error {
+			client, err := qbittorrent.NewClient(cfg.QBittorrentURL, cfg.QBittorrentUsername, cfg.QBittorrentPassword)
+			if err != nil {
+				return err
+			}
+			if err := client.Login(ctx); err != nil {
+				return err
+			}
+			return client.SetSpeedLimits(ctx, download, upload)
+		})
+	case cfg.TransmissionURL != "":
+		client, err := transmission.NewClient(cfg.TransmissionURL, cfg.TransmissionUsername, cfg.TransmissionPassword)
+		if err != nil {
+			logutils.Log.WithError(err).Warn("Bandwidth scheduler: invalid TRANSMISSION_URL")
+			return nil
+		}
+		return client
+	case cfg.Aria2Settings.RPCURL != "":
+		client, err := aria2.NewRPCClient(cfg.Aria2Settings.RPCURL, cfg.Aria2Settings.RPCSecret)
+		if err != nil {
+			logutils.Log.WithError(err).Warn("Bandwidth scheduler: invalid ARIA2_RPC_URL")
+			return nil
+		}
+		return client
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package bandwidth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+)
+
+const (
+	// DefaultOverrideDuration is how long a /speed override lasts when no duration is given.
+	DefaultOverrideDuration = time.Hour
+	checkInterval           = time.Minute
+)
+
+// Limits are global speed limits in bytes per second; 0 is unlimited.
+type Limits struct {
+	Download int64
+	Upload   int64
+}
+
+// Limiter is a download client whose global speed limits can be changed at runtime.
+type Limiter interface {
+	SetSpeedLimits(ctx context.Context, download, upload int64) error
+}
+
+// Scheduler applies the SPEED_SCHEDULE profile of the current time of day, or a temporary override set
+// with /speed, to the configured torrent daemon. Without a schedule and an override the limits set in
+// the daemon itself are left alone.
+type Scheduler struct {
+	mu            sync.Mutex
+	profiles      []config.SpeedProfile
+	override      *Limits
+	overrideUntil time.Time
+	applied       *Limits
+	limiter       Limiter // nil with per-download aria2c processes
+	wake          chan struct{}
+	now           func() time.Time
+}
+
+// NewScheduler builds the scheduler for the speed schedule and the torrent daemon in the config.
+// An invalid schedule is rejected by config validation and treated as empty here.
+func NewScheduler(cfg *config.Config) *Scheduler {
+	profiles, _ := cfg.GetBandwidthSettings().ParseSchedule()
+	return &Scheduler{
+		profiles: profiles,
+		limiter:  newLimiter(cfg),
+		wake:     make(chan struct{}, 1),
+		now:      time.Now,
+	}
+}
+
+// Current returns the limits in effect and, when they come from an override, when it expires
+// (zero time when they come from the schedule).
+func (s *Scheduler) Current() (Limits, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.currentLocked()
+}
+
+func (s *Scheduler) currentLocked() (Limits, time.Time) {
+	now := s.now()
+	if s.override != nil {
+		if now.Before(s.overrideUntil) {
+			return *s.override, s.overrideUntil
+		}
+		s.override = nil
+	}
+	return LimitsAt(s.profiles, now), time.Time{}
+}
+
+// Live reports whether limit changes reach running downloads, i.e. a torrent daemon is configured.
+// Per-download aria2c processes only get the limits in effect when they start.
+func (s *Scheduler) Live() bool {
+	return s.limiter != nil
+}
+
+// LimitsAt returns the limits of the first profile active at t, unlimited when none is.
+func LimitsAt(profiles []config.SpeedProfile, t time.Time) Limits {
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	for _, p := range profiles {
+		if p.Active(clock) {
+			return Limits{Download: p.Download, Upload: p.Upload}
+		}
+	}
+	return Limits{}
+}
+
+// SetOverride replaces the scheduled limits for d and applies them right away.
+func (s *Scheduler) SetOverride(l Limits, d time.Duration) time.Time {
+	s.mu.Lock()
+	s.override = &l
+	s.overrideUntil = s.now().Add(d)
+	until := s.overrideUntil
+	s.mu.Unlock()
+	s.kick()
+	return until
+}
+
+// ClearOverride returns to the schedule and applies it right away.
+func (s *Scheduler) ClearOverride() {
+	s.mu.Lock()
+	s.override = nil
+	s.mu.Unlock()
+	s.kick()
+}
+
+func (s *Scheduler) kick() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run applies the limits at start, every minute and whenever the override changes. Blocks until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	if len(s.profiles) > 0 {
+		logutils.Log.WithField("profiles", len(s.profiles)).Info("Starting bandwidth scheduler")
+	}
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	s.Apply(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+		s.Apply(ctx)
+	}
+}
+
+// Apply pushes the current limits to the torrent daemon when they changed since the last successful apply.
+// A failure is retried on the next pass.
+func (s *Scheduler) Apply(ctx context.Context) {
+	if s.limiter == nil {
+		return
+	}
+	s.mu.Lock()
+	limits, _ := s.currentLocked()
+	if s.applied == nil && len(s.profiles) == 0 && s.override == nil {
+		// Nothing was ever set: keep the limits configured in the clients.
+		s.mu.Unlock()
+		return
+	}
+	if s.applied != nil && *s.applied == limits {
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+
+	if err := s.limiter.SetSpeedLimits(ctx, limits.Download, limits.Upload); err != nil {
+		logutils.Log.WithError(err).Warn("Bandwidth scheduler: failed to set speed limits")
+		return
+	}
+	logutils.Log.WithFields(map[string]any{
+		"download": limits.Download,
+		"upload":   limits.Upload,
+	}).Info("Bandwidth scheduler: speed limits applied")
+	s.mu.Lock()
+	s.applied = &limits
+	s.mu.Unlock()
+}
@@ -0,0 +1,143 @@
+package bandwidth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+)
+
+func TestMain(m *testing.M) {
+	logutils.InitLogger("error")
+	os.Exit(m.Run())
+}
+
+type fakeLimiter struct {
+	calls []Limits
+	err   error
+}
+
+func (f *fakeLimiter) SetSpeedLimits(_ context.Context, download, upload int64) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.calls = append(f.calls, Limits{Download: download, Upload: upload})
+	return nil
+}
+
+func newTestScheduler(schedule string, limiter Limiter, now *time.Time) *Scheduler {
+	profiles, err := config.BandwidthConfig{Schedule: schedule}.ParseSchedule()
+	if err != nil {
+		panic(err)
+	}
+	return &Scheduler{
+		profiles: profiles,
+		limiter:  limiter,
+		wake:     make(chan struct{}, 1),
+		now:      func() time.Time { return *now },
+	}
+}
+
+func at(hour, minute int) time.Time {
+	return time.Date(2026, 10, 18, hour, minute, 0, 0, time.Local)
+}
+
+func TestLimitsAt(t *testing.T) {
+	profiles, err := config.BandwidthConfig{Schedule: "18:00-23:00=2M/512K,23:00-07:00=10M,20:00-21:00=1M"}.ParseSchedule()
+	if err != nil {
+		t.Fatalf("ParseSchedule: %v", err)
+	}
+	tests := []struct {
+		at   time.Time
+		want Limits
+	}{
+		{at(12, 0), Limits{}},
+		{at(18, 0), Limits{Download: 2 << 20, Upload: 512 << 10}},
+		{at(20, 30), Limits{Download: 2 << 20, Upload: 512 << 10}}, // first matching profile wins
+		{at(23, 0), Limits{Download: 10 << 20}},
+		{at(6, 59), Limits{Download: 10 << 20}},
+		{at(7, 0), Limits{}},
+	}
+	for _, tt := range tests {
+		if got := LimitsAt(profiles, tt.at); got != tt.want {
+			t.Errorf("LimitsAt(%s) = %+v, want %+v", tt.at.Format("15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestSchedulerApply(t *testing.T) {
+	now := at(12, 0)
+	limiter := &fakeLimiter{}
+	s := newTestScheduler("18:00-23:00=2M/512K", limiter, &now)
+
+	s.Apply(context.Background())
+	s.Apply(context.Background())
+	if len(limiter.calls) != 1 || limiter.calls[0] != (Limits{}) {
+		t.Fatalf("calls = %+v, want one unlimited apply", limiter.calls)
+	}
+
+	now = at(18, 30)
+	s.Apply(context.Background())
+	if len(limiter.calls) != 2 || limiter.calls[1] != (Limits{Download: 2 << 20, Upload: 512 << 10}) {
+		t.Fatalf("calls = %+v, want the evening profile applied", limiter.calls)
+	}
+}
+
+func TestSchedulerLeavesClientLimitsWithoutSchedule(t *testing.T) {
+	now := at(12, 0)
+	limiter := &fakeLimiter{}
+	s := newTestScheduler("", limiter, &now)
+
+	s.Apply(context.Background())
+	if len(limiter.calls) != 0 {
+		t.Fatalf("calls = %+v, want none without schedule and override", limiter.calls)
+	}
+
+	until := s.SetOverride(Limits{Download: 1 << 20}, time.Hour)
+	if !until.Equal(at(13, 0)) {
+		t.Errorf("override until = %v, want 13:00", until)
+	}
+	s.Apply(context.Background())
+	if got, exp := s.Current(); got != (Limits{Download: 1 << 20}) || !exp.Equal(until) {
+		t.Errorf("Current() = %+v, %v", got, exp)
+	}
+
+	// Once the override expires, the limits it set are lifted.
+	now = at(13, 0)
+	s.Apply(context.Background())
+	if len(limiter.calls) != 2 || limiter.calls[1] != (Limits{}) {
+		t.Fatalf("calls = %+v, want override then unlimited", limiter.calls)
+	}
+}
+
+func TestSchedulerOverrideAndClear(t *testing.T) {
+	now := at(19, 0)
+	limiter := &fakeLimiter{}
+	s := newTestScheduler("18:00-23:00=2M", limiter, &now)
+
+	s.SetOverride(Limits{}, 30*time.Minute)
+	if got, until := s.Current(); got != (Limits{}) || until.IsZero() {
+		t.Errorf("Current() = %+v, %v; want unlimited override", got, until)
+	}
+	s.ClearOverride()
+	if got, until := s.Current(); got != (Limits{Download: 2 << 20}) || !until.IsZero() {
+		t.Errorf("Current() = %+v, %v; want the scheduled limits", got, until)
+	}
+}
+
+func TestSchedulerRetriesFailedApply(t *testing.T) {
+	now := at(19, 0)
+	limiter := &fakeLimiter{err: errors.New("connection refused")}
+	s := newTestScheduler("18:00-23:00=2M", limiter, &now)
+
+	s.Apply(context.Background())
+	limiter.err = nil
+	s.Apply(context.Background())
+	if len(limiter.calls) != 1 {
+		t.Fatalf("calls = %+v, want the failed apply retried", limiter.calls)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 
 	tmsconfig "github.com/NikitaDmitryuk/telegram-media-server/internal/config"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
@@ -21,17 +22,25 @@ type Service interface {
 	SendMessage(chatID int64, text string, keyboard any)
 	SendMessageReturningID(chatID int64, text string, keyboard any) (int, error)
 	SendDocument(chatID int64, fileName string, data []byte) error
-	DownloadFile(fileID, fileName string) error
+	// DownloadFile streams a Telegram file into MOVIE_PATH/fileName; progress (optional) gets the bytes written so far.
+	DownloadFile(ctx context.Context, fileID, fileName string, progress func(written int64)) error
+	// ReadFile returns the content of a Telegram file of at most maxBytes, keeping it off the disk.
+	ReadFile(ctx context.Context, fileID string, maxBytes int64) ([]byte, error)
 	AnswerCallbackQuery(callbackConfig tgbotapi.CallbackConfig)
 	DeleteMessage(chatID int64, messageID int) error
 	SaveFile(fileName string, data []byte) error
 	EditMessageTextAndMarkup(chatID int64, messageID int, text string, markup tgbotapi.InlineKeyboardMarkup) error
 }
 
+// CloudFileSizeLimit is the largest file bots can download through the cloud Bot API.
+// A local Bot API server (TELEGRAM_API_URL) started with --local has no such limit.
+const CloudFileSizeLimit = 20 * 1024 * 1024
+
 type Bot struct {
-	Api        *tgbotapi.BotAPI
-	Config     *tmsconfig.Config
-	httpClient *http.Client
+	Api          *tgbotapi.BotAPI
+	Config       *tmsconfig.Config
+	httpClient   *http.Client
+	fileEndpoint string
 }
 
 func InitBot(config *tmsconfig.Config) (*Bot, error) {
@@ -40,13 +49,20 @@ func InitBot(config *tmsconfig.Config) (*Bot, error) {
 		return nil, fmt.Errorf("invalid TELEGRAM_PROXY: %w", err)
 	}
 
-	api, err := tgbotapi.NewBotAPIWithClient(config.BotToken, tgbotapi.APIEndpoint, httpClient)
+	apiEndpoint, fileEndpoint := tgbotapi.APIEndpoint, tgbotapi.FileEndpoint
+	if config.TelegramAPIURL != "" {
+		base := strings.TrimRight(config.TelegramAPIURL, "/")
+		apiEndpoint, fileEndpoint = base+"/bot%s/%s", base+"/file/bot%s/%s"
+		logutils.Log.Infof("Using local Telegram Bot API server: %s", base)
+	}
+
+	api, err := tgbotapi.NewBotAPIWithClient(config.BotToken, apiEndpoint, httpClient)
 	if err != nil {
 		logutils.Log.WithError(err).Error("Error creating bot")
 		return nil, fmt.Errorf("error creating bot: %w", err)
 	}
 	logutils.Log.Infof("Authorized on account %s", api.Self.UserName)
-	return &Bot{Api: api, Config: config, httpClient: httpClient}, nil
+	return &Bot{Api: api, Config: config, httpClient: httpClient, fileEndpoint: fileEndpoint}, nil
 }
 
 func buildHTTPClient(proxyAddr string) (*http.Client, error) {
@@ -98,27 +114,12 @@ func (b *Bot) SendDocument(chatID int64, fileName string, data []byte) error {
 	return nil
 }
 
-func (b *Bot) DownloadFile(fileID, fileName string) error {
-	file, err := b.Api.GetFile(tgbotapi.FileConfig{FileID: fileID})
-	if err != nil {
-		logutils.Log.WithError(err).Error("Failed to get file")
-		return err
-	}
-
-	fileURL := file.Link(b.Api.Token)
-	ctx := context.Background()
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, http.NoBody)
-	if err != nil {
-		logutils.Log.WithError(err).Error("Failed to create HTTP request")
-		return err
-	}
-
-	resp, err := b.httpClient.Do(req)
+func (b *Bot) DownloadFile(ctx context.Context, fileID, fileName string, progress func(written int64)) error {
+	src, err := b.openFile(ctx, fileID)
 	if err != nil {
-		logutils.Log.WithError(err).Error("Failed to download file")
 		return err
 	}
-	defer resp.Body.Close()
+	defer src.Close()
 
 	out, err := os.Create(filepath.Join(b.Config.MoviePath, fileName))
 	if err != nil {
@@ -127,7 +128,7 @@ func (b *Bot) DownloadFile(fileID, fileName string) error {
 	}
 	defer out.Close()
 
-	if _, err = io.Copy(out, resp.Body); err != nil {
+	if _, err = io.Copy(out, &progressReader{ctx: ctx, r: src, progress: progress}); err != nil {
 		logutils.Log.WithError(err).Error("Failed to save file")
 		return err
 	}
@@ -136,6 +137,83 @@ func (b *Bot) DownloadFile(fileID, fileName string) error {
 	return nil
 }
 
+// ReadFile returns the content of a small Telegram file without writing it to disk; larger files fail.
+func (b *Bot) ReadFile(ctx context.Context, fileID string, maxBytes int64) ([]byte, error) {
+	src, err := b.openFile(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(&progressReader{ctx: ctx, r: src}, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("file is larger than %d bytes", maxBytes)
+	}
+	return data, nil
+}
+
+func (b *Bot) openFile(ctx context.Context, fileID string) (io.ReadCloser, error) {
+	file, err := b.Api.GetFile(tgbotapi.FileConfig{FileID: fileID})
+	if err != nil {
+		logutils.Log.WithError(err).Error("Failed to get file")
+		return nil, err
+	}
+	if filepath.IsAbs(file.FilePath) {
+		// A local Bot API server started with --local returns the path of the file on its own disk.
+		src, err := os.Open(file.FilePath)
+		if err != nil {
+			logutils.Log.WithError(err).Error("Failed to open file of the local Bot API server")
+			return nil, err
+		}
+		return src, nil
+	}
+	src, err := b.openFileURL(ctx, fmt.Sprintf(b.fileEndpoint, b.Api.Token, file.FilePath))
+	if err != nil {
+		logutils.Log.WithError(err).Error("Failed to download file")
+		return nil, err
+	}
+	return src, nil
+}
+
+func (b *Bot) openFileURL(ctx context.Context, fileURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("telegram file download returned %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// progressReader reports the bytes read so far and stops reading once ctx is canceled.
+type progressReader struct {
+	ctx      context.Context
+	r        io.Reader
+	read     int64
+	progress func(written int64)
+}
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	if err := p.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := p.r.Read(buf)
+	p.read += int64(n)
+	if n > 0 && p.progress != nil {
+		p.progress(p.read)
+	}
+	return n, err
+}
+
 func (b *Bot) AnswerCallbackQuery(callbackConfig tgbotapi.CallbackConfig) {
 	if _, err := b.Api.Request(callbackConfig); err != nil {
 		logutils.Log.WithError(err).Error("Failed to answer callback query")
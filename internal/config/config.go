@@ -1,6 +1,9 @@
 package config
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
@@ -20,9 +23,20 @@ const (
 	DefaultPasswordMinLength            = 8
 	DefaultMaxConcurrentDownloads       = 3
 	DefaultProgressUpdateInterval       = 3 * time.Second
-	DefaultVideoMaxHeight               = 0             // Default: no max height limit (0 = disabled)
+	DefaultHTTPSegments                 = 4
+	DefaultVideoMaxHeight               = 0  // Default: no max height limit (0 = disabled)
+	DefaultTranscodeCRF                 = 20 // libx264 CRF used when transcoding incompatible videos
+	MaxTranscodeCRF                     = 51
 	DefaultYtdlpUpdateInterval          = 3 * time.Hour // Periodic yt-dlp update interval; 0 = disabled
 	DefaultTMSAPIListen                 = "127.0.0.1:8080"
+	DefaultTrashRetention               = 72 * time.Hour   // Trashed movies are purged after this period
+	DefaultTrashMinFreeSpaceGB          = 10.0             // Purge trash (oldest first) while free space is below this
+	DefaultRetentionCheckInterval       = 24 * time.Hour   // Retention policy is enforced once a day
+	DefaultSubscriptionCheckInterval    = time.Hour        // Channel/playlist subscriptions are checked hourly; 0 = disabled
+	DefaultFeedCheckInterval            = 15 * time.Minute // RSS/Torznab feed rules are polled every 15 minutes; 0 = disabled
+	DefaultSeedCheckInterval            = 5 * time.Minute  // Seeding torrents are checked against the seeding rules every 5 minutes
+	DefaultTonightStart                 = "01:00"          // "⏰ Tonight" downloads start at 01:00 local time
+	DefaultDropFolderInterval           = 10 * time.Second // DROP_FOLDER is scanned for new files every 10 seconds
 )
 
 func NewConfig() (*Config, error) {
@@ -33,8 +47,10 @@ func NewConfig() (*Config, error) {
 		RegularPassword:        getEnv("REGULAR_PASSWORD", ""),
 		Lang:                   getEnv("LANG", "en"),
 		TelegramProxy:          getEnv("TELEGRAM_PROXY", ""),
+		TelegramAPIURL:         getEnv("TELEGRAM_API_URL", ""),
 		Proxy:                  getEnv("CONTENT_PROXY", getEnv("PROXY", "")),
 		ProxyDomains:           getEnv("CONTENT_PROXY_DOMAINS", getEnv("PROXY_DOMAINS", "")),
+		ProxyRules:             getEnv("PROXY_RULES", ""),
 		LogLevel:               getEnv("LOG_LEVEL", "info"),
 		LangPath:               getEnv("LANG_PATH", "/usr/local/share/telegram-media-server/locales"),
 		ProwlarrURL:            getEnv("PROWLARR_URL", ""),
@@ -48,21 +64,65 @@ func NewConfig() (*Config, error) {
 		YtdlpPath:              getEnv("YTDLP_PATH", "/usr/bin/yt-dlp"),
 		YtdlpUpdateOnStart:     getEnvBool("YTDLP_UPDATE_ON_START", true),
 		YtdlpUpdateInterval:    getEnvDuration("YTDLP_UPDATE_INTERVAL", DefaultYtdlpUpdateInterval),
+		CookiesDir:             getEnv("COOKIES_DIR", ""),
 		QBittorrentURL:         getEnv("QBITTORRENT_URL", ""),
 		QBittorrentUsername:    getEnv("QBITTORRENT_USERNAME", "admin"),
 		QBittorrentPassword:    getEnv("QBITTORRENT_PASSWORD", "adminadmin"),
+		TransmissionURL:        getEnv("TRANSMISSION_URL", ""),
+		TransmissionUsername:   getEnv("TRANSMISSION_USERNAME", ""),
+		TransmissionPassword:   getEnv("TRANSMISSION_PASSWORD", ""),
 		TorrentFallbackToAria2: getEnvBool("TORRENT_FALLBACK_TO_ARIA2", false),
+		TorrentFilePreview:     getEnvBool("TORRENT_FILE_PREVIEW", true),
+		ArchiveExtraction:      getEnvBool("ARCHIVE_EXTRACTION", true),
+		SevenZipPath:           getEnv("SEVENZIP_PATH", "7z"),
+		SABnzbdURL:             getEnv("SABNZBD_URL", ""),
+		SABnzbdAPIKey:          getEnv("SABNZBD_API_KEY", ""),
+		NZBGetURL:              getEnv("NZBGET_URL", ""),
+		NZBGetUsername:         getEnv("NZBGET_USERNAME", ""),
+		NZBGetPassword:         getEnv("NZBGET_PASSWORD", ""),
+		UsenetCategory:         getEnv("USENET_CATEGORY", ""),
+
+		SubscriptionCheckInterval: getEnvDuration("SUBSCRIPTION_CHECK_INTERVAL", DefaultSubscriptionCheckInterval),
+		FeedCheckInterval:         getEnvDuration("FEED_CHECK_INTERVAL", DefaultFeedCheckInterval),
+		DropFolder:                getEnv("DROP_FOLDER", ""),
+		DropFolderInterval:        getEnvDuration("DROP_FOLDER_INTERVAL", DefaultDropFolderInterval),
 
 		DownloadSettings: DownloadConfig{
 			MaxConcurrentDownloads: getEnvInt("MAX_CONCURRENT_DOWNLOADS", DefaultMaxConcurrentDownloads),
 			DownloadTimeout:        getEnvDuration("DOWNLOAD_TIMEOUT", 0),
 			ProgressUpdateInterval: getEnvDuration("PROGRESS_UPDATE_INTERVAL", DefaultProgressUpdateInterval),
+			HTTPSegments:           getEnvInt("HTTP_DOWNLOAD_SEGMENTS", DefaultHTTPSegments),
+			TonightStart:           getEnv("TONIGHT_START", DefaultTonightStart),
 		},
 
 		SecuritySettings: SecurityConfig{
 			PasswordMinLength: DefaultPasswordMinLength,
 		},
 
+		TrashSettings: TrashConfig{
+			Enabled:        getEnvBool("TRASH_ENABLED", true),
+			Retention:      getEnvDuration("TRASH_RETENTION", DefaultTrashRetention),
+			MinFreeSpaceGB: getEnvFloat("TRASH_MIN_FREE_SPACE_GB", DefaultTrashMinFreeSpaceGB),
+		},
+
+		RetentionSettings: RetentionConfig{
+			MaxAge:           getEnvDuration("RETENTION_MAX_AGE", 0),
+			MaxLibrarySizeGB: getEnvFloat("RETENTION_MAX_LIBRARY_SIZE_GB", 0),
+			MinFreeSpaceGB:   getEnvFloat("RETENTION_MIN_FREE_SPACE_GB", 0),
+			CheckInterval:    getEnvDuration("RETENTION_CHECK_INTERVAL", DefaultRetentionCheckInterval),
+		},
+
+		SeedingSettings: SeedingConfig{
+			Ratio:         getEnvFloat("SEED_RATIO", 0),
+			Time:          getEnvDuration("SEED_TIME", 0),
+			TrackerRules:  getEnv("SEED_TRACKER_RULES", ""),
+			CheckInterval: getEnvDuration("SEED_CHECK_INTERVAL", DefaultSeedCheckInterval),
+		},
+
+		BandwidthSettings: BandwidthConfig{
+			Schedule: getEnv("SPEED_SCHEDULE", ""),
+		},
+
 		Aria2Settings: Aria2Config{
 			MaxPeers:                 getEnvInt("ARIA2_MAX_PEERS", DefaultAria2MaxPeers),
 			MaxConnectionsPerServer:  getEnvInt("ARIA2_MAX_CONNECTIONS_PER_SERVER", DefaultAria2MaxConnectionsPerServer),
@@ -97,23 +157,29 @@ func NewConfig() (*Config, error) {
 			Timeout:                  getEnvInt("ARIA2_TIMEOUT", DefaultAria2Timeout),
 			MaxTries:                 getEnvInt("ARIA2_MAX_TRIES", DefaultAria2MaxTries),
 			RetryWait:                getEnvInt("ARIA2_RETRY_WAIT", 0),
+			RPCURL:                   getEnv("ARIA2_RPC_URL", ""),
+			RPCSecret:                getEnv("ARIA2_RPC_SECRET", ""),
 		},
 
 		VideoSettings: VideoConfig{
-			EnableReencoding:   getEnvBool("VIDEO_ENABLE_REENCODING", false),
-			ForceReencoding:    getEnvBool("VIDEO_FORCE_REENCODING", false),
-			VideoCodec:         getEnv("VIDEO_CODEC", "h264"),
-			AudioCodec:         getEnv("AUDIO_CODEC", "mp3"),
-			OutputFormat:       getEnv("VIDEO_OUTPUT_FORMAT", "mp4"),
-			FFmpegExtraArgs:    getEnv("FFMPEG_EXTRA_ARGS", "-pix_fmt yuv420p"),
-			QualitySelector:    getEnv("VIDEO_QUALITY_SELECTOR", "bv*+ba/b"),
-			MaxHeight:          getEnvInt("VIDEO_MAX_HEIGHT", DefaultVideoMaxHeight),
-			CompatibilityMode:  getEnvBool("VIDEO_COMPATIBILITY_MODE", false),
-			RejectIncompatible: getEnvBool("VIDEO_REJECT_INCOMPATIBLE", false),
-			SubtitleLang:       getEnv("VIDEO_SUBTITLE_LANG", ""),
-			AudioLang:          getEnv("VIDEO_AUDIO_LANG", ""),
-			WriteSubs:          getEnvBool("VIDEO_WRITE_SUBS", false),
-			TvH264Level:        getEnv("VIDEO_TV_H264_LEVEL", "4.1"),
+			EnableReencoding:      getEnvBool("VIDEO_ENABLE_REENCODING", false),
+			ForceReencoding:       getEnvBool("VIDEO_FORCE_REENCODING", false),
+			VideoCodec:            getEnv("VIDEO_CODEC", "h264"),
+			AudioCodec:            getEnv("AUDIO_CODEC", "mp3"),
+			OutputFormat:          getEnv("VIDEO_OUTPUT_FORMAT", "mp4"),
+			FFmpegExtraArgs:       getEnv("FFMPEG_EXTRA_ARGS", "-pix_fmt yuv420p"),
+			QualitySelector:       getEnv("VIDEO_QUALITY_SELECTOR", "bv*+ba/b"),
+			MaxHeight:             getEnvInt("VIDEO_MAX_HEIGHT", DefaultVideoMaxHeight),
+			CompatibilityMode:     getEnvBool("VIDEO_COMPATIBILITY_MODE", false),
+			RejectIncompatible:    getEnvBool("VIDEO_REJECT_INCOMPATIBLE", false),
+			SubtitleLang:          getEnv("VIDEO_SUBTITLE_LANG", ""),
+			AudioLang:             getEnv("VIDEO_AUDIO_LANG", ""),
+			WriteSubs:             getEnvBool("VIDEO_WRITE_SUBS", false),
+			FormatPicker:          getEnvBool("VIDEO_FORMAT_PICKER", true),
+			TvH264Level:           getEnv("VIDEO_TV_H264_LEVEL", "4.1"),
+			TranscodeIncompatible: getEnvBool("VIDEO_TRANSCODE_INCOMPATIBLE", true),
+			TranscodePreset:       getEnv("VIDEO_TRANSCODE_PRESET", "veryfast"),
+			TranscodeCRF:          getEnvInt("VIDEO_TRANSCODE_CRF", DefaultTranscodeCRF),
 		},
 	}
 
@@ -147,8 +213,10 @@ type Config struct {
 	RegularPassword string
 	Lang            string
 	TelegramProxy   string
+	TelegramAPIURL  string // optional local Bot API server, e.g. "http://localhost:8081"; lifts the 20 MB file limit
 	Proxy           string
 	ProxyDomains    string
+	ProxyRules      string // per-domain "domain=proxy|direct" routes, see ParseProxyRules
 	LogLevel        string
 	LangPath        string
 	ProwlarrURL     string
@@ -163,21 +231,59 @@ type Config struct {
 	YtdlpPath              string // Path to yt-dlp binary; use standalone from GitHub for auto-update via -U (pacman/pip builds refuse -U)
 	YtdlpUpdateOnStart     bool
 	YtdlpUpdateInterval    time.Duration
+	CookiesDir             string // per-domain cookies.txt profiles for yt-dlp; empty = MOVIE_PATH/.cookies
 	QBittorrentURL         string // When set, torrents are handled by qBittorrent Web API instead of aria2 (e.g. http://localhost:8080)
 	QBittorrentUsername    string
 	QBittorrentPassword    string
-	TorrentFallbackToAria2 bool // If true, qBittorrent setup/start errors can fall back to aria2.
+	TransmissionURL        string // When set, torrents are handled by Transmission RPC (e.g. http://nas:9091/transmission/rpc)
+	TransmissionUsername   string
+	TransmissionPassword   string
+	TorrentFallbackToAria2 bool   // If true, qBittorrent/Transmission setup/start errors can fall back to aria2.
+	TorrentFilePreview     bool   // If true, the bot lists the files of a multi-file .torrent to deselect before downloading.
+	ArchiveExtraction      bool   // If true, RAR/ZIP/7z sets in completed downloads are extracted and the archives deleted.
+	SevenZipPath           string // 7z binary used for extraction (needs RAR support, e.g. 7zip or p7zip-full with p7zip-rar)
+	SABnzbdURL             string // When set, NZB releases are sent to SABnzbd (e.g. http://localhost:8085)
+	SABnzbdAPIKey          string
+	NZBGetURL              string // When set, NZB releases are sent to NZBGet JSON-RPC (e.g. http://localhost:6789)
+	NZBGetUsername         string
+	NZBGetPassword         string
+	UsenetCategory         string // optional SABnzbd/NZBGet category for jobs added by the bot
 
-	DownloadSettings DownloadConfig
-	SecuritySettings SecurityConfig
-	Aria2Settings    Aria2Config
-	VideoSettings    VideoConfig
+	SubscriptionCheckInterval time.Duration // how often /subscribe channels and playlists are checked; 0 = disabled
+	FeedCheckInterval         time.Duration // how often RSS/Torznab feed rules (/feedadd) are polled; 0 = disabled
+	DropFolder                string        // watched for .torrent, .magnet and .url files to download; empty = disabled
+	DropFolderInterval        time.Duration // how often DropFolder is scanned
+
+	DownloadSettings  DownloadConfig
+	SecuritySettings  SecurityConfig
+	TrashSettings     TrashConfig
+	RetentionSettings RetentionConfig
+	SeedingSettings   SeedingConfig
+	BandwidthSettings BandwidthConfig
+	Aria2Settings     Aria2Config
+	VideoSettings     VideoConfig
 }
 
 type DownloadConfig struct {
 	MaxConcurrentDownloads int
 	DownloadTimeout        time.Duration
 	ProgressUpdateInterval time.Duration
+	HTTPSegments           int    // parallel Range requests for direct HTTP(S) file downloads; 1 = single stream
+	TonightStart           string // "HH:MM" local time the "⏰ Tonight" option schedules downloads for
+}
+
+// NextTonightStart returns the next TonightStart after now (today or tomorrow).
+func (d DownloadConfig) NextTonightStart(now time.Time) (time.Time, error) {
+	clock, err := parseClock(d.TonightStart)
+	if err != nil {
+		return time.Time{}, err
+	}
+	year, month, day := now.Date()
+	start := time.Date(year, month, day, 0, 0, 0, 0, now.Location()).Add(clock)
+	if !start.After(now) {
+		start = start.AddDate(0, 0, 1)
+	}
+	return start, nil
 }
 
 type Aria2Config struct {
@@ -214,12 +320,246 @@ type Aria2Config struct {
 	Timeout                  int
 	MaxTries                 int
 	RetryWait                int
+	RPCURL                   string // When set, aria2 runs as one daemon driven over JSON-RPC (e.g. http://localhost:6800/jsonrpc)
+	RPCSecret                string // --rpc-secret of the aria2 daemon
 }
 
 type SecurityConfig struct {
 	PasswordMinLength int
 }
 
+// TrashConfig controls the .trash area under MOVIE_PATH used by bot deletions (undo support).
+type TrashConfig struct {
+	Enabled        bool          // if false, deletions remove files immediately
+	Retention      time.Duration // trashed movies older than this are purged
+	MinFreeSpaceGB float64       // when free space drops below this, trash is purged oldest-first (0 = disabled)
+}
+
+// RetentionConfig is the automatic eviction policy for finished movies. Each limit is disabled when 0;
+// movies pinned with /keep are never evicted.
+type RetentionConfig struct {
+	MaxAge           time.Duration // movies added longer ago than this are evicted
+	MaxLibrarySizeGB float64       // oldest movies are evicted while the library is larger than this
+	MinFreeSpaceGB   float64       // oldest movies are evicted while free space is below this
+	CheckInterval    time.Duration // how often the policy is enforced
+}
+
+// Enabled reports whether any retention limit is set.
+func (r RetentionConfig) Enabled() bool {
+	return r.MaxAge > 0 || r.MaxLibrarySizeGB > 0 || r.MinFreeSpaceGB > 0
+}
+
+// SeedingConfig is the seeding policy for completed qBittorrent torrents. Torrents are kept in the client
+// until the ratio or the seeding time is reached and then removed (files are kept); 0 disables a limit.
+// TrackerRules overrides the limits per tracker, e.g. "tracker.example.org=2/72h,other.net=1.5,third.net=/24h".
+type SeedingConfig struct {
+	Ratio         float64       // seed until upload/download ratio reaches this
+	Time          time.Duration // seed for at most this long
+	TrackerRules  string        // per-tracker "host=ratio/time" overrides, comma-separated
+	CheckInterval time.Duration // how often seeding torrents are checked against the rules
+}
+
+// Enabled reports whether completed torrents are seeded by default.
+func (s SeedingConfig) Enabled() bool {
+	return s.Ratio > 0 || s.Time > 0 || s.TrackerRules != ""
+}
+
+// SeedRule is a seeding limit for the trackers whose host is Tracker or one of its subdomains.
+type SeedRule struct {
+	Tracker string
+	Ratio   float64
+	Time    time.Duration
+}
+
+// ParseTrackerRules parses TrackerRules ("host=ratio/time" entries; either part may be omitted).
+func (s SeedingConfig) ParseTrackerRules() ([]SeedRule, error) {
+	var rules []SeedRule
+	for entry := range strings.SplitSeq(s.TrackerRules, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, limits, ok := strings.Cut(entry, "=")
+		host = strings.ToLower(strings.TrimSpace(host))
+		if !ok || host == "" {
+			return nil, fmt.Errorf("invalid seeding rule %q: want host=ratio/time", entry)
+		}
+		rule := SeedRule{Tracker: host}
+		ratio, duration, _ := strings.Cut(strings.TrimSpace(limits), "/")
+		if ratio = strings.TrimSpace(ratio); ratio != "" {
+			v, err := strconv.ParseFloat(ratio, 64)
+			if err != nil || v < 0 {
+				return nil, fmt.Errorf("invalid ratio in seeding rule %q", entry)
+			}
+			rule.Ratio = v
+		}
+		if duration = strings.TrimSpace(duration); duration != "" {
+			v, err := time.ParseDuration(duration)
+			if err != nil || v < 0 {
+				return nil, fmt.Errorf("invalid time in seeding rule %q", entry)
+			}
+			rule.Time = v
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// ProxyDirect is the PROXY_RULES target that connects without a proxy.
+const ProxyDirect = "direct"
+
+// ProxyRule routes the hosts Domain and its subdomains through Proxy; an empty Proxy connects directly.
+// Domain "*" matches every host that no other rule covers, except local and private addresses.
+type ProxyRule struct {
+	Domain string
+	Proxy  string
+	// Legacy rules come from CONTENT_PROXY / CONTENT_PROXY_DOMAINS, which only ever covered yt-dlp and
+	// direct file downloads; torrent, tracker and indexer traffic ignores them.
+	Legacy bool
+}
+
+// ParseProxyRules parses ProxyRules, e.g. "youtube.com=socks5://127.0.0.1:1080,example.org=direct,*=http://proxy:3128",
+// followed by the rules implied by the older CONTENT_PROXY / CONTENT_PROXY_DOMAINS settings.
+func (c *Config) ParseProxyRules() ([]ProxyRule, error) {
+	var rules []ProxyRule
+	for entry := range strings.SplitSeq(c.ProxyRules, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		domain, target, ok := strings.Cut(entry, "=")
+		domain = strings.Trim(strings.ToLower(strings.TrimSpace(domain)), ".")
+		target = strings.TrimSpace(target)
+		if !ok || domain == "" || target == "" {
+			return nil, fmt.Errorf("invalid PROXY_RULES entry %q: want domain=proxy or domain=direct", entry)
+		}
+		rule := ProxyRule{Domain: strings.TrimPrefix(domain, "*.")}
+		if !strings.EqualFold(target, ProxyDirect) {
+			if err := validateProxyURL(target); err != nil {
+				return nil, fmt.Errorf("invalid PROXY_RULES entry %q: %w", entry, err)
+			}
+			rule.Proxy = target
+		}
+		rules = append(rules, rule)
+	}
+	legacy := strings.TrimSpace(c.Proxy)
+	if legacy == "" {
+		return rules, nil
+	}
+	if !strings.Contains(legacy, "://") {
+		legacy = "http://" + legacy
+	}
+	if err := validateProxyURL(legacy); err != nil {
+		return nil, fmt.Errorf("invalid CONTENT_PROXY: %w", err)
+	}
+	if strings.TrimSpace(c.ProxyDomains) == "" {
+		return append(rules, ProxyRule{Domain: "*", Proxy: legacy, Legacy: true}), nil
+	}
+	for domain := range strings.SplitSeq(c.ProxyDomains, ",") {
+		if domain = strings.Trim(strings.ToLower(strings.TrimSpace(domain)), "."); domain != "" {
+			rules = append(rules, ProxyRule{Domain: domain, Proxy: legacy, Legacy: true})
+		}
+	}
+	return rules, nil
+}
+
+// BandwidthConfig holds the time-of-day speed profiles applied to qBittorrent, Transmission and aria2 as
+// global limits, e.g. "18:00-23:00=2M/512K,23:00-07:00=0". Outside every profile the speed is unlimited.
+type BandwidthConfig struct {
+	Schedule string // comma-separated "HH:MM-HH:MM=download[/upload]" profiles; the first matching one wins
+}
+
+// Enabled reports whether a speed schedule is set.
+func (b BandwidthConfig) Enabled() bool {
+	return strings.TrimSpace(b.Schedule) != ""
+}
+
+// SpeedProfile limits the speed (bytes per second, 0 = unlimited) between Start and End, counted from
+// midnight. A profile whose End is before its Start runs over midnight.
+type SpeedProfile struct {
+	Start    time.Duration
+	End      time.Duration
+	Download int64
+	Upload   int64
+}
+
+// Active reports whether the profile covers the given time of day.
+func (p SpeedProfile) Active(clock time.Duration) bool {
+	if p.Start <= p.End {
+		return clock >= p.Start && clock < p.End
+	}
+	return clock >= p.Start || clock < p.End
+}
+
+// ParseSchedule parses Schedule ("HH:MM-HH:MM=download[/upload]" entries; a missing upload is unlimited).
+func (b BandwidthConfig) ParseSchedule() ([]SpeedProfile, error) {
+	var profiles []SpeedProfile
+	for entry := range strings.SplitSeq(b.Schedule, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		window, limits, ok := strings.Cut(entry, "=")
+		from, to, okWindow := strings.Cut(window, "-")
+		if !ok || !okWindow {
+			return nil, fmt.Errorf("invalid speed profile %q: want HH:MM-HH:MM=download/upload", entry)
+		}
+		start, err := parseClock(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start in speed profile %q", entry)
+		}
+		end, err := parseClock(to)
+		if err != nil || end == start {
+			return nil, fmt.Errorf("invalid end in speed profile %q", entry)
+		}
+		profile := SpeedProfile{Start: start, End: end}
+		down, up, _ := strings.Cut(limits, "/")
+		if profile.Download, err = ParseRate(down); err != nil {
+			return nil, fmt.Errorf("invalid download limit in speed profile %q", entry)
+		}
+		if profile.Upload, err = ParseRate(up); err != nil {
+			return nil, fmt.Errorf("invalid upload limit in speed profile %q", entry)
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+// ParseRate parses a speed such as "2M", "512K", "1.5MB" or "0" (1024-based) into bytes per second.
+// An empty string or 0 means unlimited.
+func ParseRate(raw string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(raw))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "/S"), "B")
+	if s == "" {
+		return 0, nil
+	}
+	multiplier := 1.0
+	switch s[len(s)-1] {
+	case 'K':
+		multiplier = 1 << 10
+	case 'M':
+		multiplier = 1 << 20
+	case 'G':
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		s = s[:len(s)-1]
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid speed %q", raw)
+	}
+	return int64(v * multiplier), nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
 type VideoConfig struct {
 	EnableReencoding   bool
 	ForceReencoding    bool
@@ -234,7 +574,12 @@ type VideoConfig struct {
 	SubtitleLang       string
 	AudioLang          string
 	WriteSubs          bool
+	FormatPicker       bool   // if true, the bot offers the formats of a video link before downloading it
 	TvH264Level        string // H.264 level cap for compatibility mode (e.g. "4.0", "4.1")
+	// TranscodeIncompatible re-encodes red videos (HEVC, VP9, AV1...) to H.264 in compatibility mode.
+	TranscodeIncompatible bool
+	TranscodePreset       string // libx264 preset used for the transcode (e.g. "veryfast", "medium")
+	TranscodeCRF          int    // libx264 constant rate factor, 0-51 (lower is better quality)
 }
 
 func (c *Config) GetDownloadSettings() DownloadConfig {
@@ -245,10 +590,48 @@ func (c *Config) GetSecuritySettings() SecurityConfig {
 	return c.SecuritySettings
 }
 
+func (c *Config) GetTrashSettings() TrashConfig {
+	return c.TrashSettings
+}
+
+func (c *Config) GetRetentionSettings() RetentionConfig {
+	return c.RetentionSettings
+}
+
+func (c *Config) GetSeedingSettings() SeedingConfig {
+	return c.SeedingSettings
+}
+
+func (c *Config) GetBandwidthSettings() BandwidthConfig {
+	return c.BandwidthSettings
+}
+
 func (c *Config) GetAria2Settings() Aria2Config {
 	return c.Aria2Settings
 }
 
+// Torrent daemons whose downloads outlive the bot; a movie stores the one that holds its torrent.
+const (
+	TorrentClientQBittorrent  = "qbittorrent"
+	TorrentClientTransmission = "transmission"
+	TorrentClientAria2        = "aria2"
+)
+
+// TorrentClient returns the daemon new torrents are sent to: QBITTORRENT_URL, then TRANSMISSION_URL, then
+// ARIA2_RPC_URL; "" when torrents run as one aria2c process per download.
+func (c *Config) TorrentClient() string {
+	switch {
+	case c.QBittorrentURL != "":
+		return TorrentClientQBittorrent
+	case c.TransmissionURL != "":
+		return TorrentClientTransmission
+	case c.Aria2Settings.RPCURL != "":
+		return TorrentClientAria2
+	default:
+		return ""
+	}
+}
+
 func (c *Config) GetVideoSettings() VideoConfig {
 	return c.VideoSettings
 }
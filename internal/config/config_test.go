@@ -258,6 +258,226 @@ func TestConfigValidation(t *testing.T) {
 			expectError:   true,
 			errorContains: "configuration validation failed",
 		},
+		{
+			name: "Trash retention must be positive",
+			setupEnv: func() {
+				tempDir := os.TempDir()
+				os.Setenv("BOT_TOKEN", "test-token")
+				os.Setenv("MOVIE_PATH", tempDir)
+				os.Setenv("ADMIN_PASSWORD", "admin123")
+				os.Setenv("REGULAR_PASSWORD", "regular123")
+				os.Setenv("TRASH_RETENTION", "0s")
+			},
+			cleanupEnv: func() {
+				os.Unsetenv("BOT_TOKEN")
+				os.Unsetenv("MOVIE_PATH")
+				os.Unsetenv("ADMIN_PASSWORD")
+				os.Unsetenv("REGULAR_PASSWORD")
+				os.Unsetenv("TRASH_RETENTION")
+			},
+			expectError:   true,
+			errorContains: "configuration validation failed",
+		},
+		{
+			name: "qBittorrent and Transmission are mutually exclusive",
+			setupEnv: func() {
+				tempDir := os.TempDir()
+				os.Setenv("BOT_TOKEN", "test-token")
+				os.Setenv("MOVIE_PATH", tempDir)
+				os.Setenv("ADMIN_PASSWORD", "admin123")
+				os.Setenv("REGULAR_PASSWORD", "regular123")
+				os.Setenv("QBITTORRENT_URL", "http://localhost:8080")
+				os.Setenv("TRANSMISSION_URL", "http://localhost:9091/transmission/rpc")
+			},
+			cleanupEnv: func() {
+				os.Unsetenv("BOT_TOKEN")
+				os.Unsetenv("MOVIE_PATH")
+				os.Unsetenv("ADMIN_PASSWORD")
+				os.Unsetenv("REGULAR_PASSWORD")
+				os.Unsetenv("QBITTORRENT_URL")
+				os.Unsetenv("TRANSMISSION_URL")
+			},
+			expectError:   true,
+			errorContains: "configuration validation failed",
+		},
+		{
+			name: "Invalid aria2 RPC URL scheme",
+			setupEnv: func() {
+				tempDir := os.TempDir()
+				os.Setenv("BOT_TOKEN", "test-token")
+				os.Setenv("MOVIE_PATH", tempDir)
+				os.Setenv("ADMIN_PASSWORD", "admin123")
+				os.Setenv("REGULAR_PASSWORD", "regular123")
+				os.Setenv("ARIA2_RPC_URL", "tcp://localhost:6800/jsonrpc")
+			},
+			cleanupEnv: func() {
+				os.Unsetenv("BOT_TOKEN")
+				os.Unsetenv("MOVIE_PATH")
+				os.Unsetenv("ADMIN_PASSWORD")
+				os.Unsetenv("REGULAR_PASSWORD")
+				os.Unsetenv("ARIA2_RPC_URL")
+			},
+			expectError:   true,
+			errorContains: "configuration validation failed",
+		},
+		{
+			name: "SABnzbd without API key",
+			setupEnv: func() {
+				tempDir := os.TempDir()
+				os.Setenv("BOT_TOKEN", "test-token")
+				os.Setenv("MOVIE_PATH", tempDir)
+				os.Setenv("ADMIN_PASSWORD", "admin123")
+				os.Setenv("REGULAR_PASSWORD", "regular123")
+				os.Setenv("SABNZBD_URL", "http://localhost:8085")
+			},
+			cleanupEnv: func() {
+				os.Unsetenv("BOT_TOKEN")
+				os.Unsetenv("MOVIE_PATH")
+				os.Unsetenv("ADMIN_PASSWORD")
+				os.Unsetenv("REGULAR_PASSWORD")
+				os.Unsetenv("SABNZBD_URL")
+			},
+			expectError:   true,
+			errorContains: "configuration validation failed",
+		},
+		{
+			name: "Negative retention library size",
+			setupEnv: func() {
+				tempDir := os.TempDir()
+				os.Setenv("BOT_TOKEN", "test-token")
+				os.Setenv("MOVIE_PATH", tempDir)
+				os.Setenv("ADMIN_PASSWORD", "admin123")
+				os.Setenv("REGULAR_PASSWORD", "regular123")
+				os.Setenv("RETENTION_MAX_LIBRARY_SIZE_GB", "-1")
+			},
+			cleanupEnv: func() {
+				os.Unsetenv("BOT_TOKEN")
+				os.Unsetenv("MOVIE_PATH")
+				os.Unsetenv("ADMIN_PASSWORD")
+				os.Unsetenv("REGULAR_PASSWORD")
+				os.Unsetenv("RETENTION_MAX_LIBRARY_SIZE_GB")
+			},
+			expectError:   true,
+			errorContains: "configuration validation failed",
+		},
+		{
+			name: "Invalid seeding tracker rule",
+			setupEnv: func() {
+				tempDir := os.TempDir()
+				os.Setenv("BOT_TOKEN", "test-token")
+				os.Setenv("MOVIE_PATH", tempDir)
+				os.Setenv("ADMIN_PASSWORD", "admin123")
+				os.Setenv("REGULAR_PASSWORD", "regular123")
+				os.Setenv("SEED_TRACKER_RULES", "tracker.example.org=two")
+			},
+			cleanupEnv: func() {
+				os.Unsetenv("BOT_TOKEN")
+				os.Unsetenv("MOVIE_PATH")
+				os.Unsetenv("ADMIN_PASSWORD")
+				os.Unsetenv("REGULAR_PASSWORD")
+				os.Unsetenv("SEED_TRACKER_RULES")
+			},
+			expectError:   true,
+			errorContains: "configuration validation failed",
+		},
+		{
+			name: "Invalid speed schedule",
+			setupEnv: func() {
+				tempDir := os.TempDir()
+				os.Setenv("BOT_TOKEN", "test-token")
+				os.Setenv("MOVIE_PATH", tempDir)
+				os.Setenv("ADMIN_PASSWORD", "admin123")
+				os.Setenv("REGULAR_PASSWORD", "regular123")
+				os.Setenv("SPEED_SCHEDULE", "18:00-23:00=fast")
+			},
+			cleanupEnv: func() {
+				os.Unsetenv("BOT_TOKEN")
+				os.Unsetenv("MOVIE_PATH")
+				os.Unsetenv("ADMIN_PASSWORD")
+				os.Unsetenv("REGULAR_PASSWORD")
+				os.Unsetenv("SPEED_SCHEDULE")
+			},
+			expectError:   true,
+			errorContains: "configuration validation failed",
+		},
+		{
+			name: "Invalid Telegram API URL",
+			setupEnv: func() {
+				tempDir := os.TempDir()
+				os.Setenv("BOT_TOKEN", "test-token")
+				os.Setenv("MOVIE_PATH", tempDir)
+				os.Setenv("ADMIN_PASSWORD", "admin123")
+				os.Setenv("REGULAR_PASSWORD", "regular123")
+				os.Setenv("TELEGRAM_API_URL", "localhost:8081")
+			},
+			cleanupEnv: func() {
+				os.Unsetenv("BOT_TOKEN")
+				os.Unsetenv("MOVIE_PATH")
+				os.Unsetenv("ADMIN_PASSWORD")
+				os.Unsetenv("REGULAR_PASSWORD")
+				os.Unsetenv("TELEGRAM_API_URL")
+			},
+			expectError:   true,
+			errorContains: "configuration validation failed",
+		},
+		{
+			name: "Drop folder same as movie path",
+			setupEnv: func() {
+				tempDir := os.TempDir()
+				os.Setenv("BOT_TOKEN", "test-token")
+				os.Setenv("MOVIE_PATH", tempDir)
+				os.Setenv("ADMIN_PASSWORD", "admin123")
+				os.Setenv("REGULAR_PASSWORD", "regular123")
+				os.Setenv("DROP_FOLDER", tempDir)
+			},
+			cleanupEnv: func() {
+				os.Unsetenv("BOT_TOKEN")
+				os.Unsetenv("MOVIE_PATH")
+				os.Unsetenv("ADMIN_PASSWORD")
+				os.Unsetenv("REGULAR_PASSWORD")
+				os.Unsetenv("DROP_FOLDER")
+			},
+			expectError:   true,
+			errorContains: "configuration validation failed",
+		},
+		{
+			name: "Invalid proxy rule",
+			setupEnv: func() {
+				os.Setenv("BOT_TOKEN", "test-token")
+				os.Setenv("MOVIE_PATH", os.TempDir())
+				os.Setenv("ADMIN_PASSWORD", "admin123")
+				os.Setenv("REGULAR_PASSWORD", "regular123")
+				os.Setenv("PROXY_RULES", "youtube.com=ftp://proxy:21")
+			},
+			cleanupEnv: func() {
+				os.Unsetenv("BOT_TOKEN")
+				os.Unsetenv("MOVIE_PATH")
+				os.Unsetenv("ADMIN_PASSWORD")
+				os.Unsetenv("REGULAR_PASSWORD")
+				os.Unsetenv("PROXY_RULES")
+			},
+			expectError:   true,
+			errorContains: "configuration validation failed",
+		},
+		{
+			name: "Invalid transcode CRF",
+			setupEnv: func() {
+				os.Setenv("BOT_TOKEN", "test-token")
+				os.Setenv("MOVIE_PATH", os.TempDir())
+				os.Setenv("ADMIN_PASSWORD", "admin123")
+				os.Setenv("REGULAR_PASSWORD", "regular123")
+				os.Setenv("VIDEO_TRANSCODE_CRF", "60")
+			},
+			cleanupEnv: func() {
+				os.Unsetenv("BOT_TOKEN")
+				os.Unsetenv("MOVIE_PATH")
+				os.Unsetenv("ADMIN_PASSWORD")
+				os.Unsetenv("REGULAR_PASSWORD")
+				os.Unsetenv("VIDEO_TRANSCODE_CRF")
+			},
+			expectError:   true,
+			errorContains: "configuration validation failed",
+		},
 	}
 
 	for _, tt := range tests {
@@ -523,3 +743,92 @@ func containsError(actual, expected string) bool {
 			len(actual) >= len(expected) &&
 				actual[:len(expected)] == expected))
 }
+
+func TestSeedingConfigParseTrackerRules(t *testing.T) {
+	s := SeedingConfig{TrackerRules: " Tracker.Example.org=2/72h, other.net=1.5 ,third.net=/24h"}
+	rules, err := s.ParseTrackerRules()
+	if err != nil {
+		t.Fatalf("ParseTrackerRules: %v", err)
+	}
+	want := []SeedRule{
+		{Tracker: "tracker.example.org", Ratio: 2, Time: 72 * time.Hour},
+		{Tracker: "other.net", Ratio: 1.5},
+		{Tracker: "third.net", Time: 24 * time.Hour},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("rules = %+v, want %+v", rules, want)
+	}
+	for i := range want {
+		if rules[i] != want[i] {
+			t.Errorf("rule %d = %+v, want %+v", i, rules[i], want[i])
+		}
+	}
+	for _, bad := range []string{"tracker.org", "=2", "tracker.org=-1", "tracker.org=1/soon"} {
+		if _, err := (SeedingConfig{TrackerRules: bad}).ParseTrackerRules(); err == nil {
+			t.Errorf("ParseTrackerRules(%q) should fail", bad)
+		}
+	}
+}
+
+func TestBandwidthConfigParseSchedule(t *testing.T) {
+	b := BandwidthConfig{Schedule: "18:00-23:00=2M/512K, 23:00-07:00=0"}
+	profiles, err := b.ParseSchedule()
+	if err != nil {
+		t.Fatalf("ParseSchedule: %v", err)
+	}
+	want := []SpeedProfile{
+		{Start: 18 * time.Hour, End: 23 * time.Hour, Download: 2 << 20, Upload: 512 << 10},
+		{Start: 23 * time.Hour, End: 7 * time.Hour},
+	}
+	if len(profiles) != len(want) {
+		t.Fatalf("profiles = %+v, want %+v", profiles, want)
+	}
+	for i := range want {
+		if profiles[i] != want[i] {
+			t.Errorf("profile %d = %+v, want %+v", i, profiles[i], want[i])
+		}
+	}
+	if !profiles[1].Active(2*time.Hour) || !profiles[1].Active(23*time.Hour) || profiles[1].Active(12*time.Hour) {
+		t.Error("overnight profile should cover 23:00-07:00 only")
+	}
+	if profiles[0].Active(23 * time.Hour) {
+		t.Error("profile end should be exclusive")
+	}
+	for _, bad := range []string{"18:00=2M", "18:00-23:00", "25:00-23:00=1M", "18:00-18:00=1M", "18:00-23:00=1M/-2K"} {
+		if _, err := (BandwidthConfig{Schedule: bad}).ParseSchedule(); err == nil {
+			t.Errorf("ParseSchedule(%q) should fail", bad)
+		}
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := map[string]int64{"": 0, "0": 0, "1024": 1024, "512K": 512 << 10, "2M": 2 << 20, "1.5MB": 3 << 19, "1g": 1 << 30, "2MB/s": 2 << 20}
+	for in, want := range tests {
+		got, err := ParseRate(in)
+		if err != nil || got != want {
+			t.Errorf("ParseRate(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	if _, err := ParseRate("fast"); err == nil {
+		t.Error("ParseRate(\"fast\") should fail")
+	}
+}
+
+func TestDownloadConfigNextTonightStart(t *testing.T) {
+	d := DownloadConfig{TonightStart: "01:30"}
+	evening := time.Date(2026, 10, 18, 22, 0, 0, 0, time.UTC)
+	got, err := d.NextTonightStart(evening)
+	if err != nil {
+		t.Fatalf("NextTonightStart: %v", err)
+	}
+	if want := time.Date(2026, 10, 19, 1, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("NextTonightStart(22:00) = %v, want %v", got, want)
+	}
+	night := time.Date(2026, 10, 19, 0, 15, 0, 0, time.UTC)
+	if got, _ = d.NextTonightStart(night); !got.Equal(time.Date(2026, 10, 19, 1, 30, 0, 0, time.UTC)) {
+		t.Errorf("NextTonightStart(00:15) = %v, want the same night", got)
+	}
+	if _, err := (DownloadConfig{TonightStart: "late"}).NextTonightStart(night); err == nil {
+		t.Error("NextTonightStart should fail on an invalid TONIGHT_START")
+	}
+}
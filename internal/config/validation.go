@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 )
 
@@ -20,15 +22,45 @@ func (c *Config) validate() error {
 	if err := c.validatePasswords(); err != nil {
 		errs = append(errs, err)
 	}
+	if err := c.validateTelegramAPI(); err != nil {
+		errs = append(errs, err)
+	}
 	if err := c.validateProwlarr(); err != nil {
 		errs = append(errs, err)
 	}
+	if _, err := c.ParseProxyRules(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.validateTorrentClient(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.validateUsenetClient(); err != nil {
+		errs = append(errs, err)
+	}
 	if err := c.validateTMSAPI(); err != nil {
 		errs = append(errs, err)
 	}
 	if err := c.validateDownloadSettings(); err != nil {
 		errs = append(errs, err)
 	}
+	if err := c.validateTrashSettings(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.validateRetentionSettings(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.validateSeedingSettings(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.validateBandwidthSettings(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.validateDropFolder(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.validateVideoSettings(); err != nil {
+		errs = append(errs, err)
+	}
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
@@ -106,6 +138,59 @@ func (c *Config) validateProwlarr() error {
 	return nil
 }
 
+func (c *Config) validateTelegramAPI() error {
+	if c.TelegramAPIURL == "" {
+		return nil
+	}
+	u, err := url.Parse(c.TelegramAPIURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("TELEGRAM_API_URL must be an http or https URL, got %q", c.TelegramAPIURL)
+	}
+	return nil
+}
+
+// validateProxyURL accepts the proxy schemes understood by net/http and yt-dlp.
+func validateProxyURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid proxy URL %q", raw)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return nil
+	default:
+		return fmt.Errorf("unsupported proxy scheme %q (want http, https, socks5 or socks5h)", u.Scheme)
+	}
+}
+
+func (c *Config) validateTorrentClient() error {
+	if c.QBittorrentURL != "" && c.TransmissionURL != "" {
+		return errors.New("set only one of QBITTORRENT_URL and TRANSMISSION_URL")
+	}
+	if rpcURL := c.Aria2Settings.RPCURL; rpcURL != "" {
+		u, err := url.Parse(rpcURL)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("ARIA2_RPC_URL is not a valid URL: %q", rpcURL)
+		}
+		switch u.Scheme {
+		case "http", "https", "ws", "wss":
+		default:
+			return errors.New("ARIA2_RPC_URL must use http, https, ws or wss")
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateUsenetClient() error {
+	if c.SABnzbdURL != "" && c.NZBGetURL != "" {
+		return errors.New("set only one of SABNZBD_URL and NZBGET_URL")
+	}
+	if c.SABnzbdURL != "" && c.SABnzbdAPIKey == "" {
+		return errors.New("SABNZBD_API_KEY is required when SABNZBD_URL is set")
+	}
+	return nil
+}
+
 func (c *Config) validateTMSAPI() error {
 	if !c.TMSAPIEnabled {
 		return nil
@@ -134,5 +219,104 @@ func (c *Config) validateDownloadSettings() error {
 		return errors.New("DOWNLOAD_TIMEOUT cannot be negative")
 	}
 
+	if c.DownloadSettings.HTTPSegments <= 0 {
+		return errors.New("HTTP_DOWNLOAD_SEGMENTS must be greater than 0")
+	}
+	if _, err := parseClock(c.DownloadSettings.TonightStart); err != nil {
+		return fmt.Errorf("TONIGHT_START must be HH:MM, got %q", c.DownloadSettings.TonightStart)
+	}
+
+	return nil
+}
+
+func (c *Config) validateTrashSettings() error {
+	if !c.TrashSettings.Enabled {
+		return nil
+	}
+	if c.TrashSettings.Retention <= 0 {
+		return errors.New("TRASH_RETENTION must be greater than 0 when TRASH_ENABLED=true")
+	}
+	if c.TrashSettings.MinFreeSpaceGB < 0 {
+		return errors.New("TRASH_MIN_FREE_SPACE_GB cannot be negative")
+	}
+	return nil
+}
+
+func (c *Config) validateRetentionSettings() error {
+	r := c.RetentionSettings
+	if r.MaxAge < 0 {
+		return errors.New("RETENTION_MAX_AGE cannot be negative")
+	}
+	if r.MaxLibrarySizeGB < 0 {
+		return errors.New("RETENTION_MAX_LIBRARY_SIZE_GB cannot be negative")
+	}
+	if r.MinFreeSpaceGB < 0 {
+		return errors.New("RETENTION_MIN_FREE_SPACE_GB cannot be negative")
+	}
+	if r.Enabled() && r.CheckInterval <= 0 {
+		return errors.New("RETENTION_CHECK_INTERVAL must be greater than 0 when a retention limit is set")
+	}
+	return nil
+}
+
+func (c *Config) validateSeedingSettings() error {
+	s := c.SeedingSettings
+	if s.Ratio < 0 {
+		return errors.New("SEED_RATIO cannot be negative")
+	}
+	if s.Time < 0 {
+		return errors.New("SEED_TIME cannot be negative")
+	}
+	if _, err := s.ParseTrackerRules(); err != nil {
+		return fmt.Errorf("SEED_TRACKER_RULES: %w", err)
+	}
+	if s.CheckInterval <= 0 {
+		return errors.New("SEED_CHECK_INTERVAL must be greater than 0")
+	}
+	return nil
+}
+
+func (c *Config) validateBandwidthSettings() error {
+	if _, err := c.BandwidthSettings.ParseSchedule(); err != nil {
+		return fmt.Errorf("SPEED_SCHEDULE: %w", err)
+	}
+	return nil
+}
+
+// validateDropFolder keeps DROP_FOLDER apart from MOVIE_PATH: the bot writes its own .torrent and .magnet
+// files there, which the watcher would pick up again.
+// x264Presets lists the presets accepted by libx264.
+var x264Presets = map[string]struct{}{
+	"ultrafast": {}, "superfast": {}, "veryfast": {}, "faster": {}, "fast": {},
+	"medium": {}, "slow": {}, "slower": {}, "veryslow": {}, "placebo": {},
+}
+
+func (c *Config) validateVideoSettings() error {
+	vs := &c.VideoSettings
+	if _, ok := x264Presets[vs.TranscodePreset]; !ok {
+		return fmt.Errorf("VIDEO_TRANSCODE_PRESET must be a libx264 preset, got %q", vs.TranscodePreset)
+	}
+	if vs.TranscodeCRF < 0 || vs.TranscodeCRF > MaxTranscodeCRF {
+		return fmt.Errorf("VIDEO_TRANSCODE_CRF must be between 0 and %d, got %d", MaxTranscodeCRF, vs.TranscodeCRF)
+	}
+	return nil
+}
+
+func (c *Config) validateDropFolder() error {
+	if c.DropFolder == "" {
+		return nil
+	}
+	if info, err := os.Stat(c.DropFolder); err != nil || !info.IsDir() {
+		return fmt.Errorf("DROP_FOLDER directory does not exist: %s", c.DropFolder)
+	}
+	if err := checkDirWritable(c.DropFolder); err != nil {
+		return fmt.Errorf("DROP_FOLDER is not writable: %w", err)
+	}
+	if filepath.Clean(c.DropFolder) == filepath.Clean(c.MoviePath) {
+		return fmt.Errorf("DROP_FOLDER must differ from MOVIE_PATH")
+	}
+	if c.DropFolderInterval <= 0 {
+		return fmt.Errorf("DROP_FOLDER_INTERVAL must be positive, got %s", c.DropFolderInterval)
+	}
 	return nil
 }
@@ -0,0 +1,226 @@
+package cookies
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tmsconfig "github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+)
+
+const (
+	// DirName is the default profile folder under MOVIE_PATH, next to movie.db.
+	DirName = ".cookies"
+	// MaxFileBytes caps an uploaded cookies.txt.
+	MaxFileBytes = 1 << 20
+
+	fileExt = ".txt"
+	dirMode = 0o700
+
+	// netscapeFields is the column count of a Netscape cookies.txt line:
+	// domain, include subdomains, path, secure, expiry, name, value.
+	netscapeFields = 7
+	httpOnlyPrefix = "#HttpOnly_"
+)
+
+var (
+	ErrInvalidDomain = errors.New("invalid domain")
+	ErrNotFound      = errors.New("no cookies for this domain")
+	ErrInvalidFile   = errors.New("not a Netscape cookies.txt file")
+)
+
+var domainRE = regexp.MustCompile(`^[a-z0-9-]+(\.[a-z0-9-]+)+$`)
+
+// Profile describes the cookies.txt stored for one domain.
+type Profile struct {
+	Domain  string
+	Cookies int
+	Expired int
+	// Expires is the latest expiry among the cookies; zero when all of them are session cookies.
+	Expires   time.Time
+	UpdatedAt time.Time
+}
+
+// Dir is the folder holding the profiles: COOKIES_DIR, or .cookies under MOVIE_PATH.
+func Dir(cfg *tmsconfig.Config) string {
+	if cfg.CookiesDir != "" {
+		return cfg.CookiesDir
+	}
+	return filepath.Join(cfg.MoviePath, DirName)
+}
+
+// NormalizeDomain turns "www.YouTube.com" or "https://www.youtube.com/watch" into "youtube.com".
+func NormalizeDomain(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if strings.Contains(s, "://") {
+		u, err := url.Parse(s)
+		if err != nil {
+			return "", ErrInvalidDomain
+		}
+		s = u.Hostname()
+	}
+	s = strings.TrimPrefix(strings.Trim(s, "."), "www.")
+	if !domainRE.MatchString(s) {
+		return "", ErrInvalidDomain
+	}
+	return s, nil
+}
+
+// Parse checks that data is a Netscape cookies.txt file and describes it; the domain is not set.
+func Parse(data []byte, now time.Time) (Profile, error) {
+	var p Profile
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxFileBytes)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		line = strings.TrimPrefix(line, httpOnlyPrefix)
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != netscapeFields {
+			return Profile{}, fmt.Errorf("%w: line %q has no %d tab-separated fields", ErrInvalidFile, truncate(line), netscapeFields)
+		}
+		expiry, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return Profile{}, fmt.Errorf("%w: line %q has an invalid expiry", ErrInvalidFile, truncate(line))
+		}
+		p.Cookies++
+		if expiry == 0 {
+			continue
+		}
+		expires := time.Unix(expiry, 0)
+		if expires.Before(now) {
+			p.Expired++
+		}
+		if expires.After(p.Expires) {
+			p.Expires = expires
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Profile{}, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+	if p.Cookies == 0 {
+		return Profile{}, fmt.Errorf("%w: no cookies found", ErrInvalidFile)
+	}
+	return p, nil
+}
+
+func truncate(line string) string {
+	const maxRunes = 40
+	if r := []rune(line); len(r) > maxRunes {
+		return string(r[:maxRunes]) + "…"
+	}
+	return line
+}
+
+// Save validates data and stores it as the profile of domain, replacing the previous one.
+func Save(dir, domain string, data []byte) (Profile, error) {
+	domain, err := NormalizeDomain(domain)
+	if err != nil {
+		return Profile{}, err
+	}
+	if len(data) > MaxFileBytes {
+		return Profile{}, fmt.Errorf("%w: %d bytes is too big", ErrInvalidFile, len(data))
+	}
+	p, err := Parse(data, time.Now())
+	if err != nil {
+		return Profile{}, err
+	}
+	if err := os.MkdirAll(dir, dirMode); err != nil {
+		return Profile{}, fmt.Errorf("create cookies dir: %w", err)
+	}
+	// CreateTemp makes the file 0600, so the cookies are never readable by others, not even briefly.
+	tmp, err := os.CreateTemp(dir, ".upload_")
+	if err != nil {
+		return Profile{}, fmt.Errorf("save cookies: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return Profile{}, fmt.Errorf("save cookies: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return Profile{}, fmt.Errorf("save cookies: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, domain+fileExt)); err != nil {
+		return Profile{}, fmt.Errorf("save cookies: %w", err)
+	}
+	p.Domain = domain
+	p.UpdatedAt = time.Now()
+	return p, nil
+}
+
+// List returns the stored profiles sorted by domain; a missing folder means no profiles.
+func List(dir string) ([]Profile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	now := time.Now()
+	var profiles []Profile
+	for _, entry := range entries {
+		domain, ok := strings.CutSuffix(entry.Name(), fileExt)
+		if !ok || !entry.Type().IsRegular() || !domainRE.MatchString(domain) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		// yt-dlp rewrites the file after each run, so a profile that stopped parsing is still listed.
+		p, _ := Parse(data, now)
+		p.Domain = domain
+		if info, err := entry.Info(); err == nil {
+			p.UpdatedAt = info.ModTime()
+		}
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Domain < profiles[j].Domain })
+	return profiles, nil
+}
+
+// Delete removes the profile of domain.
+func Delete(dir, domain string) error {
+	domain, err := NormalizeDomain(domain)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, domain+fileExt)); err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// FileFor returns the cookies.txt for the host of rawURL: the profile of the host itself or of the closest
+// parent domain (music.youtube.com falls back to youtube.com). Empty when there is none.
+func FileFor(dir, rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	for host != "" && strings.Contains(host, ".") {
+		path := filepath.Join(dir, host+fileExt)
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			return path
+		}
+		_, host, _ = strings.Cut(host, ".")
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package cookies
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func cookieLine(domain string, expires int64) string {
+	return domain + "\tTRUE\t/\tTRUE\t" + strconv.FormatInt(expires, 10) + "\tSID\tsecret\n"
+}
+
+func TestParse(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	later := now.Add(30 * 24 * time.Hour).Unix()
+	data := "# Netscape HTTP Cookie File\n\n" +
+		cookieLine(".youtube.com", later) +
+		"#HttpOnly_" + cookieLine(".youtube.com", now.Add(-time.Hour).Unix()) +
+		cookieLine(".youtube.com", 0)
+
+	p, err := Parse([]byte(data), now)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if p.Cookies != 3 || p.Expired != 1 || p.Expires.Unix() != later {
+		t.Errorf("Parse() = %+v, want 3 cookies, 1 expired, expiring at %d", p, later)
+	}
+
+	for _, bad := range []string{"", "# only a comment\n", "not a cookie line\n", ".a.com\tTRUE\t/\tTRUE\tsoon\tSID\tx\n"} {
+		if _, err := Parse([]byte(bad), now); !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidFile", bad, err)
+		}
+	}
+}
+
+func TestNormalizeDomain(t *testing.T) {
+	for in, want := range map[string]string{
+		"YouTube.com":                     "youtube.com",
+		"www.youtube.com":                 "youtube.com",
+		"https://www.youtube.com/watch?v": "youtube.com",
+		".vk.com":                         "vk.com",
+	} {
+		if got, err := NormalizeDomain(in); err != nil || got != want {
+			t.Errorf("NormalizeDomain(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "localhost", "../etc/passwd", "a b.com"} {
+		if _, err := NormalizeDomain(bad); !errors.Is(err, ErrInvalidDomain) {
+			t.Errorf("NormalizeDomain(%q) error = %v, want ErrInvalidDomain", bad, err)
+		}
+	}
+}
+
+func TestSaveListFileForDelete(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), DirName)
+	data := []byte(cookieLine(".youtube.com", time.Now().Add(time.Hour).Unix()))
+
+	if _, err := Save(dir, "www.youtube.com", data); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dir, "youtube.com.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("cookies file mode = %o, want 600", perm)
+	}
+	if _, err := Save(dir, "vk.com", []byte("garbage")); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("Save(garbage) error = %v, want ErrInvalidFile", err)
+	}
+
+	profiles, err := List(dir)
+	if err != nil || len(profiles) != 1 || profiles[0].Domain != "youtube.com" || profiles[0].Cookies != 1 {
+		t.Fatalf("List() = %+v, %v", profiles, err)
+	}
+
+	want := filepath.Join(dir, "youtube.com.txt")
+	for rawURL, file := range map[string]string{
+		"https://www.youtube.com/watch?v=1": want,
+		"https://music.youtube.com/x":       want,
+		"https://notyoutube.com/x":          "",
+		"https://vimeo.com/1":               "",
+	} {
+		if got := FileFor(dir, rawURL); got != file {
+			t.Errorf("FileFor(%q) = %q, want %q", rawURL, got, file)
+		}
+	}
+
+	if err := Delete(dir, "youtube.com"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := Delete(dir, "youtube.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete error = %v, want ErrNotFound", err)
+	}
+	if got := FileFor(dir, "https://www.youtube.com/watch?v=1"); got != "" {
+		t.Errorf("FileFor after Delete = %q, want none", got)
+	}
+}
@@ -206,3 +206,13 @@ func (s *SQLiteDatabase) GetUserByChatID(ctx context.Context, chatID int64) (Use
 	}
 	return user, nil
 }
+
+func (s *SQLiteDatabase) GetUsersByRole(ctx context.Context, role UserRole) ([]User, error) {
+	var users []User
+	if err := s.withRetry(ctx, "GetUsersByRole", func() error {
+		return s.db.WithContext(ctx).Where("role = ?", role).Find(&users).Error
+	}); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
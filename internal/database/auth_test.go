@@ -640,3 +640,25 @@ func TestIsTemporaryPasswordValid(t *testing.T) {
 		})
 	}
 }
+
+func TestSQLiteDatabase_GetUsersByRole(t *testing.T) {
+	db := setupTestDB(t)
+	defer closeTestDB(db)
+
+	db.db.Create(&User{ChatID: 1, Name: "admin1", Role: AdminRole})
+	db.db.Create(&User{ChatID: 2, Name: "regular", Role: RegularRole})
+	db.db.Create(&User{ChatID: 3, Name: "admin2", Role: AdminRole})
+
+	admins, err := db.GetUsersByRole(context.Background(), AdminRole)
+	if err != nil {
+		t.Fatalf("GetUsersByRole: %v", err)
+	}
+	if len(admins) != 2 {
+		t.Fatalf("len(admins) = %d, want 2", len(admins))
+	}
+	for i := range admins {
+		if admins[i].Role != AdminRole {
+			t.Errorf("user %d has role %s, want admin", admins[i].ChatID, admins[i].Role)
+		}
+	}
+}
@@ -16,8 +16,12 @@ type MovieReader interface {
 	GetTempFilesByMovieID(ctx context.Context, movieID uint) ([]MovieFile, error)
 	MovieExistsId(ctx context.Context, movieID uint) (bool, error)
 	MovieExistsFiles(ctx context.Context, files []string) (bool, error)
+	// FindMovieByInfoHash returns the library (non-trashed) movie with the torrent info hash, nil when there is none.
+	FindMovieByInfoHash(ctx context.Context, infoHash string) (*Movie, error)
 	MovieExistsUploadedFile(ctx context.Context, fileName string) (bool, error)
 	GetIncompleteQBittorrentDownloads(ctx context.Context) ([]Movie, error)
+	// GetTrashedMovies returns movies moved to the trash, oldest first.
+	GetTrashedMovies(ctx context.Context) ([]Movie, error)
 }
 
 // MovieWriter is the write subset for movies and files. Use together with MovieReader where both are needed.
@@ -31,15 +35,32 @@ type MovieWriter interface {
 	RefreshMovieFileSizeFromDisk(ctx context.Context, movieID uint, movieRoot string) (int64, error)
 	RemoveMovie(ctx context.Context, movieID uint) error
 	UpdateConversionStatus(ctx context.Context, movieID uint, status string) error
-	UpdateConversionPercentage(ctx context.Context, movieID uint, percentage int) error
+	UpdateConversionPercentage(ctx context.Context, movieID uint, progress ConversionProgress) error
+	UpdateExtractionStatus(ctx context.Context, movieID uint, status string) error
+	UpdateExtractionPercentage(ctx context.Context, movieID uint, percentage int) error
 	SetTvCompatibility(ctx context.Context, movieID uint, compat string) error
-	SetQBittorrentHash(ctx context.Context, movieID uint, hash string) error
+	// SetTorrentClientHash stores the id of the download in a torrent daemon (config.TorrentClient*).
+	SetTorrentClientHash(ctx context.Context, movieID uint, client, hash string) error
+	SetMovieInfoHash(ctx context.Context, movieID uint, infoHash string) error
+	// SetMovieSource stores the link a download is fetched from again after a failure or restart (direct files).
+	SetMovieSource(ctx context.Context, movieID uint, source string) error
 	RemoveFilesByMovieID(ctx context.Context, movieID uint) error
 	// ReplaceMainMovieFiles removes non-temp file rows and inserts new paths (e.g. after magnet metadata from qBittorrent).
 	ReplaceMainMovieFiles(ctx context.Context, movieID uint, paths []string) error
 	UpdateMovieFileSize(ctx context.Context, movieID uint, size int64) error
 	UpdateMovieTotalEpisodes(ctx context.Context, movieID uint, total int) error
 	RemoveTempFilesByMovieID(ctx context.Context, movieID uint) error
+	// SetMovieTrashed marks the movie as trashed at the given time; nil restores it to the library.
+	SetMovieTrashed(ctx context.Context, movieID uint, trashedAt *time.Time) error
+	// SetMoviePinned marks the movie as "keep" so the retention policy never evicts it.
+	SetMoviePinned(ctx context.Context, movieID uint, pinned bool) error
+	// SetMovieSeedMode stores the per-movie seeding toggle: "" (policy), "on" or "off".
+	SetMovieSeedMode(ctx context.Context, movieID uint, mode string) error
+	// UpdateSeedingState records whether the completed torrent is still seeding and its upload ratio.
+	UpdateSeedingState(ctx context.Context, movieID uint, seeding bool, ratio float64) error
+	// SetMovieSchedule stores the not-before time, source link and encoded start options of a scheduled download;
+	// nil startAt clears the time and the options and keeps the source.
+	SetMovieSchedule(ctx context.Context, movieID uint, startAt *time.Time, source, options string) error
 }
 
 // AuthStore is the subset for authentication and user management. Use in auth handlers and middleware.
@@ -51,6 +72,35 @@ type AuthStore interface {
 	ExtendTemporaryUser(ctx context.Context, chatID int64, newExpiration time.Time) error
 	GenerateTemporaryPassword(ctx context.Context, duration time.Duration) (string, error)
 	GetUserByChatID(ctx context.Context, chatID int64) (User, error)
+	GetUsersByRole(ctx context.Context, role UserRole) ([]User, error)
+}
+
+// SubscriptionStore keeps channel/playlist subscriptions and their download archive.
+type SubscriptionStore interface {
+	AddSubscription(ctx context.Context, sub *Subscription) error
+	GetSubscriptions(ctx context.Context) ([]Subscription, error)
+	GetSubscriptionByID(ctx context.Context, id uint) (Subscription, error)
+	RemoveSubscription(ctx context.Context, id uint) error
+	// MarkSubscriptionChecked stores the check time and, when known, the channel/playlist title.
+	MarkSubscriptionChecked(ctx context.Context, id uint, title string, checkedAt time.Time) error
+	// GetSubscriptionVideoIDs returns the archive: ids of entries already seen for the subscription.
+	GetSubscriptionVideoIDs(ctx context.Context, id uint) (map[string]struct{}, error)
+	AddSubscriptionVideoIDs(ctx context.Context, id uint, videoIDs []string) error
+}
+
+// FeedRuleStore keeps RSS/Torznab auto-download rules and the releases they already handled.
+type FeedRuleStore interface {
+	AddFeedRule(ctx context.Context, rule *FeedRule) error
+	GetFeedRules(ctx context.Context) ([]FeedRule, error)
+	GetFeedRuleByID(ctx context.Context, id uint) (FeedRule, error)
+	// RemoveFeedRule deletes the rule; its downloaded releases stay recorded so they are not downloaded again.
+	RemoveFeedRule(ctx context.Context, id uint) error
+	SetFeedRulePaused(ctx context.Context, id uint, paused bool) error
+	// MarkFeedRuleChecked stores the check time and, when known, the feed title.
+	MarkFeedRuleChecked(ctx context.Context, id uint, title string, checkedAt time.Time) error
+	// GetKnownFeedReleases returns which of keys the rule already handled or any rule downloaded.
+	GetKnownFeedReleases(ctx context.Context, ruleID uint, keys []string) (map[string]struct{}, error)
+	AddFeedReleases(ctx context.Context, releases []FeedRelease) error
 }
 
 // Database is the full storage interface. Embed MovieReader, MovieWriter, AuthStore and Init for backward compatibility.
@@ -59,6 +109,8 @@ type Database interface {
 	MovieReader
 	MovieWriter
 	AuthStore
+	SubscriptionStore
+	FeedRuleStore
 }
 
 func NewDatabase(config *tmsconfig.Config) (Database, error) {
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (s *SQLiteDatabase) AddFeedRule(ctx context.Context, rule *FeedRule) error {
+	return s.withRetry(ctx, "AddFeedRule", func() error {
+		return s.db.WithContext(ctx).Create(rule).Error
+	})
+}
+
+func (s *SQLiteDatabase) GetFeedRules(ctx context.Context) ([]FeedRule, error) {
+	var rules []FeedRule
+	if err := s.withRetry(ctx, "GetFeedRules", func() error {
+		return s.db.WithContext(ctx).Order("id ASC").Find(&rules).Error
+	}); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (s *SQLiteDatabase) GetFeedRuleByID(ctx context.Context, id uint) (FeedRule, error) {
+	var rule FeedRule
+	if err := s.withRetry(ctx, "GetFeedRuleByID", func() error {
+		return s.db.WithContext(ctx).First(&rule, id).Error
+	}); err != nil {
+		return FeedRule{}, err
+	}
+	return rule, nil
+}
+
+func (s *SQLiteDatabase) RemoveFeedRule(ctx context.Context, id uint) error {
+	return s.withRetry(ctx, "RemoveFeedRule", func() error {
+		return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("rule_id = ? AND downloaded = ?", id, false).Delete(&FeedRelease{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&FeedRule{}, id).Error
+		})
+	})
+}
+
+func (s *SQLiteDatabase) SetFeedRulePaused(ctx context.Context, id uint, paused bool) error {
+	return s.withRetry(ctx, "SetFeedRulePaused", func() error {
+		return s.db.WithContext(ctx).Model(&FeedRule{}).Where("id = ?", id).Update("paused", paused).Error
+	})
+}
+
+func (s *SQLiteDatabase) MarkFeedRuleChecked(ctx context.Context, id uint, title string, checkedAt time.Time) error {
+	updates := map[string]any{"last_checked_at": checkedAt}
+	if title != "" {
+		updates["title"] = title
+	}
+	return s.withRetry(ctx, "MarkFeedRuleChecked", func() error {
+		return s.db.WithContext(ctx).Model(&FeedRule{}).Where("id = ?", id).Updates(updates).Error
+	})
+}
+
+func (s *SQLiteDatabase) GetKnownFeedReleases(ctx context.Context, ruleID uint, keys []string) (map[string]struct{}, error) {
+	known := make(map[string]struct{})
+	if len(keys) == 0 {
+		return known, nil
+	}
+	var found []string
+	if err := s.withRetry(ctx, "GetKnownFeedReleases", func() error {
+		return s.db.WithContext(ctx).Model(&FeedRelease{}).
+			Where("key IN ? AND (rule_id = ? OR downloaded = ?)", keys, ruleID, true).Pluck("key", &found).Error
+	}); err != nil {
+		return nil, err
+	}
+	for _, k := range found {
+		known[k] = struct{}{}
+	}
+	return known, nil
+}
+
+func (s *SQLiteDatabase) AddFeedReleases(ctx context.Context, releases []FeedRelease) error {
+	if len(releases) == 0 {
+		return nil
+	}
+	return s.withRetry(ctx, "AddFeedReleases", func() error {
+		return s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&releases).Error
+	})
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestFeedReleases_KnownPerRuleOrDownloaded(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	if migErr := db.AutoMigrate(&FeedRule{}, &FeedRelease{}); migErr != nil {
+		t.Fatalf("Failed to migrate: %v", migErr)
+	}
+	s := &SQLiteDatabase{db: db}
+	ctx := context.Background()
+
+	ruleA := &FeedRule{FeedURL: "https://tracker.example/rss"}
+	ruleB := &FeedRule{FeedURL: "https://tracker.example/rss"}
+	for _, r := range []*FeedRule{ruleA, ruleB} {
+		if err := s.AddFeedRule(ctx, r); err != nil {
+			t.Fatalf("AddFeedRule: %v", err)
+		}
+	}
+
+	// "seen" is only recorded by the baseline of rule A, "got" was downloaded by rule A.
+	releases := []FeedRelease{
+		{Key: "seen", RuleID: ruleA.ID},
+		{Key: "got", RuleID: ruleA.ID, Downloaded: true},
+	}
+	if err := s.AddFeedReleases(ctx, releases); err != nil {
+		t.Fatalf("AddFeedReleases: %v", err)
+	}
+	if err := s.AddFeedReleases(ctx, releases[:1]); err != nil {
+		t.Fatalf("AddFeedReleases must ignore duplicates: %v", err)
+	}
+
+	keys := []string{"seen", "got", "new"}
+	knownA, err := s.GetKnownFeedReleases(ctx, ruleA.ID, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := knownA["seen"]; !ok || len(knownA) != 2 {
+		t.Errorf("rule A known = %v, want seen and got", knownA)
+	}
+	knownB, err := s.GetKnownFeedReleases(ctx, ruleB.ID, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := knownB["got"]; !ok || len(knownB) != 1 {
+		t.Errorf("rule B known = %v, want only the downloaded release", knownB)
+	}
+
+	if err := s.RemoveFeedRule(ctx, ruleA.ID); err != nil {
+		t.Fatalf("RemoveFeedRule: %v", err)
+	}
+	knownB, err = s.GetKnownFeedReleases(ctx, ruleB.ID, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := knownB["got"]; !ok {
+		t.Error("downloaded release must stay known after its rule is removed")
+	}
+	var left int64
+	db.Model(&FeedRelease{}).Count(&left)
+	if left != 1 {
+		t.Errorf("%d feed releases left, want only the downloaded one", left)
+	}
+}
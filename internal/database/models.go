@@ -4,9 +4,14 @@ import "github.com/NikitaDmitryuk/telegram-media-server/internal/models"
 
 type Movie = models.Movie
 type MovieFile = models.MovieFile
+type ConversionProgress = models.ConversionProgress
 type UserRole = models.UserRole
 type TemporaryPassword = models.TemporaryPassword
 type User = models.User
+type Subscription = models.Subscription
+type SubscriptionItem = models.SubscriptionItem
+type FeedRule = models.FeedRule
+type FeedRelease = models.FeedRelease
 
 const (
 	AdminRole     = models.AdminRole
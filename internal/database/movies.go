@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -77,16 +78,63 @@ func (s *SQLiteDatabase) RemoveMovie(ctx context.Context, movieID uint) error {
 	})
 }
 
+// GetMovieList returns library movies; trashed movies are excluded (see GetTrashedMovies).
 func (s *SQLiteDatabase) GetMovieList(ctx context.Context) ([]Movie, error) {
 	var movies []Movie
 	if err := s.withRetry(ctx, "GetMovieList", func() error {
-		return s.db.WithContext(ctx).Find(&movies).Error
+		return s.db.WithContext(ctx).Where("trashed_at IS NULL").Find(&movies).Error
 	}); err != nil {
 		return nil, err
 	}
 	return movies, nil
 }
 
+func (s *SQLiteDatabase) GetTrashedMovies(ctx context.Context) ([]Movie, error) {
+	var movies []Movie
+	if err := s.withRetry(ctx, "GetTrashedMovies", func() error {
+		return s.db.WithContext(ctx).Where("trashed_at IS NOT NULL").Order("trashed_at ASC").Find(&movies).Error
+	}); err != nil {
+		return nil, err
+	}
+	return movies, nil
+}
+
+func (s *SQLiteDatabase) SetMovieTrashed(ctx context.Context, movieID uint, trashedAt *time.Time) error {
+	return s.withRetry(ctx, "SetMovieTrashed", func() error {
+		return s.db.WithContext(ctx).Model(&Movie{}).Where("id = ?", movieID).Update("trashed_at", trashedAt).Error
+	})
+}
+
+func (s *SQLiteDatabase) SetMoviePinned(ctx context.Context, movieID uint, pinned bool) error {
+	return s.withRetry(ctx, "SetMoviePinned", func() error {
+		return s.db.WithContext(ctx).Model(&Movie{}).Where("id = ?", movieID).Update("pinned", pinned).Error
+	})
+}
+
+func (s *SQLiteDatabase) SetMovieSeedMode(ctx context.Context, movieID uint, mode string) error {
+	return s.withRetry(ctx, "SetMovieSeedMode", func() error {
+		return s.db.WithContext(ctx).Model(&Movie{}).Where("id = ?", movieID).Update("seed_mode", mode).Error
+	})
+}
+
+func (s *SQLiteDatabase) UpdateSeedingState(ctx context.Context, movieID uint, seeding bool, ratio float64) error {
+	return s.withRetry(ctx, "UpdateSeedingState", func() error {
+		return s.db.WithContext(ctx).Model(&Movie{}).Where("id = ?", movieID).
+			Updates(map[string]any{"seeding": seeding, "seed_ratio": ratio}).Error
+	})
+}
+
+func (s *SQLiteDatabase) SetMovieSchedule(ctx context.Context, movieID uint, startAt *time.Time, source, options string) error {
+	updates := map[string]any{"start_at": startAt, "start_options": ""}
+	if startAt != nil {
+		updates["source"] = source
+		updates["start_options"] = options
+	}
+	return s.withRetry(ctx, "SetMovieSchedule", func() error {
+		return s.db.WithContext(ctx).Model(&Movie{}).Where("id = ?", movieID).Updates(updates).Error
+	})
+}
+
 func (s *SQLiteDatabase) UpdateDownloadedPercentage(ctx context.Context, movieID uint, percentage int) error {
 	return s.withRetry(ctx, "UpdateDownloadedPercentage", func() error {
 		return s.db.WithContext(ctx).Model(&Movie{}).
@@ -105,7 +153,11 @@ func (s *SQLiteDatabase) UpdateEpisodesProgress(ctx context.Context, movieID uin
 
 func (s *SQLiteDatabase) SetLoaded(ctx context.Context, movieID uint, movieRoot string) error {
 	const completePercentage = 100
-	updates := map[string]any{"downloaded_percentage": completePercentage}
+	updates := map[string]any{
+		"downloaded_percentage": completePercentage,
+		// Keep the first completion time when a finished movie is loaded again (e.g. resumed after a restart).
+		"completed_at": gorm.Expr("COALESCE(completed_at, ?)", time.Now()),
+	}
 	if sum, err := s.sumMainFilesSizeOnDisk(ctx, movieID, movieRoot); err == nil && sum > 0 {
 		updates["file_size"] = sum
 	}
@@ -156,11 +208,37 @@ func (s *SQLiteDatabase) UpdateConversionStatus(ctx context.Context, movieID uin
 	})
 }
 
-func (s *SQLiteDatabase) UpdateConversionPercentage(ctx context.Context, movieID uint, percentage int) error {
+// UpdateConversionPercentage stores the conversion percentage together with the current file, speed and ETA.
+func (s *SQLiteDatabase) UpdateConversionPercentage(ctx context.Context, movieID uint, progress ConversionProgress) error {
 	return s.withRetry(ctx, "UpdateConversionPercentage", func() error {
 		return s.db.WithContext(ctx).Model(&Movie{}).
-			Where("id = ? AND conversion_percentage <> ?", movieID, percentage).
-			Update("conversion_percentage", percentage).Error
+			Where("id = ?", movieID).
+			Updates(conversionProgressColumns(progress)).Error
+	})
+}
+
+func conversionProgressColumns(progress ConversionProgress) map[string]any {
+	return map[string]any{
+		"conversion_percentage": progress.Percentage,
+		"conversion_file":       progress.File,
+		"conversion_speed":      progress.Speed,
+		"conversion_eta":        int(progress.ETA.Seconds()),
+	}
+}
+
+func (s *SQLiteDatabase) UpdateExtractionStatus(ctx context.Context, movieID uint, status string) error {
+	return s.withRetry(ctx, "UpdateExtractionStatus", func() error {
+		return s.db.WithContext(ctx).Model(&Movie{}).
+			Where("id = ? AND extraction_status <> ?", movieID, status).
+			Update("extraction_status", status).Error
+	})
+}
+
+func (s *SQLiteDatabase) UpdateExtractionPercentage(ctx context.Context, movieID uint, percentage int) error {
+	return s.withRetry(ctx, "UpdateExtractionPercentage", func() error {
+		return s.db.WithContext(ctx).Model(&Movie{}).
+			Where("id = ? AND extraction_percentage <> ?", movieID, percentage).
+			Update("extraction_percentage", percentage).Error
 	})
 }
 
@@ -172,14 +250,45 @@ func (s *SQLiteDatabase) SetTvCompatibility(ctx context.Context, movieID uint, c
 	})
 }
 
-func (s *SQLiteDatabase) SetQBittorrentHash(ctx context.Context, movieID uint, hash string) error {
-	return s.withRetry(ctx, "SetQBittorrentHash", func() error {
+func (s *SQLiteDatabase) SetTorrentClientHash(ctx context.Context, movieID uint, client, hash string) error {
+	return s.withRetry(ctx, "SetTorrentClientHash", func() error {
 		return s.db.WithContext(ctx).Model(&Movie{}).
-			Where("id = ? AND qbittorrent_hash <> ?", movieID, hash).
-			Update("qbittorrent_hash", hash).Error
+			Where("id = ? AND (qbittorrent_hash <> ? OR torrent_client <> ?)", movieID, hash, client).
+			Updates(map[string]any{"qbittorrent_hash": hash, "torrent_client": client}).Error
+	})
+}
+
+func (s *SQLiteDatabase) SetMovieInfoHash(ctx context.Context, movieID uint, infoHash string) error {
+	return s.withRetry(ctx, "SetMovieInfoHash", func() error {
+		return s.db.WithContext(ctx).Model(&Movie{}).Where("id = ?", movieID).Update("info_hash", infoHash).Error
 	})
 }
 
+func (s *SQLiteDatabase) SetMovieSource(ctx context.Context, movieID uint, source string) error {
+	return s.withRetry(ctx, "SetMovieSource", func() error {
+		return s.db.WithContext(ctx).Model(&Movie{}).Where("id = ?", movieID).Update("source", source).Error
+	})
+}
+
+func (s *SQLiteDatabase) FindMovieByInfoHash(ctx context.Context, infoHash string) (*Movie, error) {
+	if infoHash == "" {
+		return nil, nil
+	}
+	var movies []Movie
+	if err := s.withRetry(ctx, "FindMovieByInfoHash", func() error {
+		return s.db.WithContext(ctx).
+			Where("info_hash = ? AND trashed_at IS NULL", infoHash).
+			Order("id").Limit(1).
+			Find(&movies).Error
+	}); err != nil {
+		return nil, err
+	}
+	if len(movies) == 0 {
+		return nil, nil
+	}
+	return &movies[0], nil
+}
+
 func (s *SQLiteDatabase) GetMovieByID(ctx context.Context, movieID uint) (Movie, error) {
 	var movie Movie
 	if err := s.withRetry(ctx, "GetMovieByID", func() error {
@@ -202,11 +311,16 @@ func (s *SQLiteDatabase) GetIncompleteQBittorrentDownloads(ctx context.Context)
 	return movies, nil
 }
 
+// MovieExistsFiles reports whether any library movie owns one of the paths. Trashed movies do not count:
+// their files live under .trash, so the same release can be downloaded again.
 func (s *SQLiteDatabase) MovieExistsFiles(ctx context.Context, files []string) (bool, error) {
 	for _, file := range files {
 		var count int64
 		if err := s.withRetry(ctx, "MovieExistsFiles", func() error {
-			return s.db.WithContext(ctx).Model(&MovieFile{}).Where("file_path = ?", file).Count(&count).Error
+			return s.db.WithContext(ctx).Model(&MovieFile{}).
+				Joins("JOIN movies ON movies.id = movie_files.movie_id").
+				Where("movie_files.file_path = ? AND movies.trashed_at IS NULL", file).
+				Count(&count).Error
 		}); err != nil {
 			return false, err
 		}
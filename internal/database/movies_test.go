@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -139,15 +140,26 @@ func TestUpdateConversionPercentage(t *testing.T) {
 		t.Fatalf("AddMovie: %v", err)
 	}
 
-	if updateErr := s.UpdateConversionPercentage(ctx, movieID, 50); updateErr != nil {
+	progress := ConversionProgress{Percentage: 50, File: "S01E02.mkv", Speed: 2.5, ETA: 90 * time.Second}
+	if updateErr := s.UpdateConversionPercentage(ctx, movieID, progress); updateErr != nil {
 		t.Fatalf("UpdateConversionPercentage: %v", updateErr)
 	}
 	m, getErr := s.GetMovieByID(ctx, movieID)
 	if getErr != nil {
 		t.Fatalf("GetMovieByID: %v", getErr)
 	}
-	if m.ConversionPercentage != 50 {
-		t.Errorf("ConversionPercentage: want 50, got %d", m.ConversionPercentage)
+	if m.ConversionPercentage != 50 || m.ConversionFile != "S01E02.mkv" || m.ConversionSpeed != 2.5 || m.ConversionETA != 90 {
+		t.Errorf("conversion progress = %d%% %q %.1fx %ds, want 50%% S01E02.mkv 2.5x 90s",
+			m.ConversionPercentage, m.ConversionFile, m.ConversionSpeed, m.ConversionETA)
+	}
+
+	if updateErr := s.UpdateConversionPercentage(ctx, movieID, ConversionProgress{Percentage: 100}); updateErr != nil {
+		t.Fatalf("UpdateConversionPercentage: %v", updateErr)
+	}
+	m, _ = s.GetMovieByID(ctx, movieID)
+	if m.ConversionPercentage != 100 || m.ConversionFile != "" || m.ConversionETA != 0 {
+		t.Errorf("percentage-only update should clear the details, got %d%% %q %ds",
+			m.ConversionPercentage, m.ConversionFile, m.ConversionETA)
 	}
 }
 
@@ -250,3 +262,140 @@ func TestRemoveMovieCascadesMovieFiles(t *testing.T) {
 		t.Fatalf("fileCount = %d, want 0 after cascade delete", fileCount)
 	}
 }
+
+func TestTrashedMoviesHiddenFromLibrary(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	if migErr := db.AutoMigrate(&Movie{}, &MovieFile{}); migErr != nil {
+		t.Fatalf("Failed to migrate: %v", migErr)
+	}
+
+	s := &SQLiteDatabase{db: db}
+	ctx := context.Background()
+	trashedID, err := s.AddMovie(ctx, "Old", 1024, []string{"old.mkv"}, nil, 0)
+	if err != nil {
+		t.Fatalf("AddMovie: %v", err)
+	}
+	keptID, err := s.AddMovie(ctx, "Kept", 1024, []string{"kept.mkv"}, nil, 0)
+	if err != nil {
+		t.Fatalf("AddMovie: %v", err)
+	}
+	now := time.Now()
+	if setErr := s.SetMovieTrashed(ctx, trashedID, &now); setErr != nil {
+		t.Fatalf("SetMovieTrashed: %v", setErr)
+	}
+
+	list, err := s.GetMovieList(ctx)
+	if err != nil {
+		t.Fatalf("GetMovieList: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != keptID {
+		t.Fatalf("GetMovieList = %+v, want only movie %d", list, keptID)
+	}
+	trashed, err := s.GetTrashedMovies(ctx)
+	if err != nil {
+		t.Fatalf("GetTrashedMovies: %v", err)
+	}
+	if len(trashed) != 1 || trashed[0].ID != trashedID || !trashed[0].IsTrashed() {
+		t.Fatalf("GetTrashedMovies = %+v, want only movie %d", trashed, trashedID)
+	}
+	exists, err := s.MovieExistsFiles(ctx, []string{"old.mkv"})
+	if err != nil {
+		t.Fatalf("MovieExistsFiles: %v", err)
+	}
+	if exists {
+		t.Fatal("MovieExistsFiles should ignore trashed movies so the release can be downloaded again")
+	}
+
+	if setErr := s.SetMovieTrashed(ctx, trashedID, nil); setErr != nil {
+		t.Fatalf("SetMovieTrashed(nil): %v", setErr)
+	}
+	list, err = s.GetMovieList(ctx)
+	if err != nil {
+		t.Fatalf("GetMovieList: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("len(GetMovieList) = %d after restore, want 2", len(list))
+	}
+}
+
+func TestFindMovieByInfoHash(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	if migErr := db.AutoMigrate(&Movie{}, &MovieFile{}); migErr != nil {
+		t.Fatalf("Failed to migrate: %v", migErr)
+	}
+
+	s := &SQLiteDatabase{db: db}
+	ctx := context.Background()
+	const hash = "1234567890abcdef1234567890abcdef12345678"
+	id, err := s.AddMovie(ctx, "Movie", 1024, []string{"movie.mkv"}, nil, 0)
+	if err != nil {
+		t.Fatalf("AddMovie: %v", err)
+	}
+	if setErr := s.SetMovieInfoHash(ctx, id, hash); setErr != nil {
+		t.Fatalf("SetMovieInfoHash: %v", setErr)
+	}
+
+	found, err := s.FindMovieByInfoHash(ctx, hash)
+	if err != nil {
+		t.Fatalf("FindMovieByInfoHash: %v", err)
+	}
+	if found == nil || found.ID != id {
+		t.Fatalf("FindMovieByInfoHash = %+v, want movie %d", found, id)
+	}
+	if found, _ = s.FindMovieByInfoHash(ctx, ""); found != nil {
+		t.Errorf("FindMovieByInfoHash(\"\") = %+v, want nil", found)
+	}
+
+	now := time.Now()
+	if setErr := s.SetMovieTrashed(ctx, id, &now); setErr != nil {
+		t.Fatalf("SetMovieTrashed: %v", setErr)
+	}
+	if found, _ = s.FindMovieByInfoHash(ctx, hash); found != nil {
+		t.Error("FindMovieByInfoHash should ignore trashed movies so the release can be downloaded again")
+	}
+}
+
+func TestSetLoadedKeepsFirstCompletionTime(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	if migErr := db.AutoMigrate(&Movie{}, &MovieFile{}); migErr != nil {
+		t.Fatalf("Failed to migrate: %v", migErr)
+	}
+
+	s := &SQLiteDatabase{db: db}
+	ctx := context.Background()
+	movieID, err := s.AddMovie(ctx, "Movie", 1024, []string{"movie.mkv"}, nil, 0)
+	if err != nil {
+		t.Fatalf("AddMovie: %v", err)
+	}
+
+	if loadErr := s.SetLoaded(ctx, movieID, t.TempDir()); loadErr != nil {
+		t.Fatalf("SetLoaded: %v", loadErr)
+	}
+	first, _ := s.GetMovieByID(ctx, movieID)
+	if first.CompletedAt == nil {
+		t.Fatal("SetLoaded should record the completion time")
+	}
+
+	if loadErr := s.SetLoaded(ctx, movieID, t.TempDir()); loadErr != nil {
+		t.Fatalf("SetLoaded: %v", loadErr)
+	}
+	again, _ := s.GetMovieByID(ctx, movieID)
+	if again.CompletedAt == nil || !again.CompletedAt.Equal(*first.CompletedAt) {
+		t.Errorf("second SetLoaded changed CompletedAt from %v to %v", first.CompletedAt, again.CompletedAt)
+	}
+}
@@ -63,7 +63,10 @@ func (s *SQLiteDatabase) configureConnectionPool() error {
 }
 
 func (s *SQLiteDatabase) runMigrations() error {
-	if err := s.db.AutoMigrate(&Movie{}, &MovieFile{}, &User{}, &TemporaryPassword{}); err != nil {
+	if err := s.db.AutoMigrate(
+		&Movie{}, &MovieFile{}, &User{}, &TemporaryPassword{},
+		&Subscription{}, &SubscriptionItem{}, &FeedRule{}, &FeedRelease{},
+	); err != nil {
 		return fmt.Errorf("auto migration failed: %w", err)
 	}
 
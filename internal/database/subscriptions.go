@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (s *SQLiteDatabase) AddSubscription(ctx context.Context, sub *Subscription) error {
+	return s.withRetry(ctx, "AddSubscription", func() error {
+		return s.db.WithContext(ctx).Create(sub).Error
+	})
+}
+
+func (s *SQLiteDatabase) GetSubscriptions(ctx context.Context) ([]Subscription, error) {
+	var subs []Subscription
+	if err := s.withRetry(ctx, "GetSubscriptions", func() error {
+		return s.db.WithContext(ctx).Order("id ASC").Find(&subs).Error
+	}); err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+func (s *SQLiteDatabase) GetSubscriptionByID(ctx context.Context, id uint) (Subscription, error) {
+	var sub Subscription
+	if err := s.withRetry(ctx, "GetSubscriptionByID", func() error {
+		return s.db.WithContext(ctx).First(&sub, id).Error
+	}); err != nil {
+		return Subscription{}, err
+	}
+	return sub, nil
+}
+
+func (s *SQLiteDatabase) RemoveSubscription(ctx context.Context, id uint) error {
+	return s.withRetry(ctx, "RemoveSubscription", func() error {
+		return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("subscription_id = ?", id).Delete(&SubscriptionItem{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&Subscription{}, id).Error
+		})
+	})
+}
+
+func (s *SQLiteDatabase) MarkSubscriptionChecked(ctx context.Context, id uint, title string, checkedAt time.Time) error {
+	updates := map[string]any{"last_checked_at": checkedAt}
+	if title != "" {
+		updates["title"] = title
+	}
+	return s.withRetry(ctx, "MarkSubscriptionChecked", func() error {
+		return s.db.WithContext(ctx).Model(&Subscription{}).Where("id = ?", id).Updates(updates).Error
+	})
+}
+
+func (s *SQLiteDatabase) GetSubscriptionVideoIDs(ctx context.Context, id uint) (map[string]struct{}, error) {
+	var videoIDs []string
+	if err := s.withRetry(ctx, "GetSubscriptionVideoIDs", func() error {
+		return s.db.WithContext(ctx).Model(&SubscriptionItem{}).Where("subscription_id = ?", id).Pluck("video_id", &videoIDs).Error
+	}); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{}, len(videoIDs))
+	for _, v := range videoIDs {
+		seen[v] = struct{}{}
+	}
+	return seen, nil
+}
+
+func (s *SQLiteDatabase) AddSubscriptionVideoIDs(ctx context.Context, id uint, videoIDs []string) error {
+	if len(videoIDs) == 0 {
+		return nil
+	}
+	items := make([]SubscriptionItem, 0, len(videoIDs))
+	for _, v := range videoIDs {
+		items = append(items, SubscriptionItem{SubscriptionID: id, VideoID: v})
+	}
+	return s.withRetry(ctx, "AddSubscriptionVideoIDs", func() error {
+		return s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&items).Error
+	})
+}
@@ -1,8 +1,11 @@
 package deletion
 
 import (
+	"context"
+	"errors"
 	"sync"
 
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
 	tmsdmanager "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/manager"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/filemanager"
@@ -11,6 +14,11 @@ import (
 
 const queueBufferSize = 100
 
+const completePercentage = 100
+
+// ErrQueueFull is passed to EnqueuePermanent's onDeleted when the request could not be queued.
+var ErrQueueFull = errors.New("deletion queue full")
+
 // Queue enqueues movies for background stop and delete. Only logging is performed; no user notifications.
 // IsPendingDeletion is true from Enqueue until the worker finishes (success or failure), so the movie can be hidden from the delete menu.
 type Queue interface {
@@ -18,10 +26,34 @@ type Queue interface {
 	IsPendingDeletion(movieID uint) bool
 }
 
+// UndoableQueue: optional; queues that move completed movies to the trash instead of deleting them.
+// onTrashed is called from the worker once the files are in .trash, so the caller can offer an "Undo" button.
+// It is not called when the movie is deleted permanently (trash disabled, or download not finished).
+type UndoableQueue interface {
+	Queue
+	EnqueueWithUndo(movieID uint, onTrashed func(movieID uint, title string))
+}
+
+// PermanentQueue: optional; queues that can skip the trash. Used by automatic eviction, where the point
+// is to free disk space right away. onDeleted is called from the worker once the files are gone (err nil)
+// or the deletion failed, and right away with ErrQueueFull when the request could not be queued.
+type PermanentQueue interface {
+	Queue
+	EnqueuePermanent(movieID uint, onDeleted func(movieID uint, err error))
+}
+
+type deleteRequest struct {
+	movieID   uint
+	onTrashed func(movieID uint, title string)
+	onDeleted func(movieID uint, err error)
+	permanent bool
+}
+
 // queueImpl processes stop+delete in a single worker; success and failures are only logged.
 type queueImpl struct {
-	ch              chan uint
+	ch              chan deleteRequest
 	moviePath       string
+	trashEnabled    bool
 	db              database.Database
 	downloadManager tmsdmanager.Service
 	mu              sync.RWMutex
@@ -29,10 +61,12 @@ type queueImpl struct {
 }
 
 // NewQueue starts a background worker and returns a Queue that enqueues by movie ID.
-func NewQueue(moviePath string, db database.Database, downloadManager tmsdmanager.Service) Queue {
+// With TRASH_ENABLED, completed movies are moved to MOVIE_PATH/.trash; unfinished downloads are always deleted.
+func NewQueue(cfg *config.Config, db database.Database, downloadManager tmsdmanager.Service) UndoableQueue {
 	q := &queueImpl{
-		ch:              make(chan uint, queueBufferSize),
-		moviePath:       moviePath,
+		ch:              make(chan deleteRequest, queueBufferSize),
+		moviePath:       cfg.MoviePath,
+		trashEnabled:    cfg.TrashSettings.Enabled,
 		db:              db,
 		downloadManager: downloadManager,
 		pending:         make(map[uint]struct{}),
@@ -42,18 +76,34 @@ func NewQueue(moviePath string, db database.Database, downloadManager tmsdmanage
 }
 
 func (q *queueImpl) Enqueue(movieID uint) {
+	q.enqueue(deleteRequest{movieID: movieID})
+}
+
+func (q *queueImpl) EnqueueWithUndo(movieID uint, onTrashed func(movieID uint, title string)) {
+	q.enqueue(deleteRequest{movieID: movieID, onTrashed: onTrashed})
+}
+
+func (q *queueImpl) EnqueuePermanent(movieID uint, onDeleted func(movieID uint, err error)) {
+	q.enqueue(deleteRequest{movieID: movieID, onDeleted: onDeleted, permanent: true})
+}
+
+func (q *queueImpl) enqueue(req deleteRequest) {
+	movieID := req.movieID
 	q.mu.Lock()
 	q.pending[movieID] = struct{}{}
 	q.mu.Unlock()
 
 	select {
-	case q.ch <- movieID:
+	case q.ch <- req:
 		logutils.Log.WithField("movie_id", movieID).Info("Movie enqueued for deletion")
 	default:
 		q.mu.Lock()
 		delete(q.pending, movieID)
 		q.mu.Unlock()
 		logutils.Log.WithField("movie_id", movieID).Warn("Deletion queue full, movie not enqueued")
+		if req.onDeleted != nil {
+			req.onDeleted(movieID, ErrQueueFull)
+		}
 	}
 }
 
@@ -65,14 +115,28 @@ func (q *queueImpl) IsPendingDeletion(movieID uint) bool {
 }
 
 func (q *queueImpl) worker() {
-	for movieID := range q.ch {
+	for req := range q.ch {
+		movieID := req.movieID
 		if err := q.downloadManager.StopDownloadSilent(movieID); err != nil {
 			logutils.Log.WithError(err).WithField("movie_id", movieID).Debug("Stop download for deletion (may already be completed)")
 		}
-		if err := filemanager.DeleteMovie(movieID, q.moviePath, q.db, q.downloadManager); err != nil {
-			logutils.Log.WithError(err).WithField("movie_id", movieID).Error("Failed to delete movie in background")
+		if movie, ok := q.trashCandidate(req); ok {
+			if err := filemanager.TrashMovie(movieID, q.moviePath, q.db, q.downloadManager); err != nil {
+				// Do not fall back to permanent deletion: the user expects to be able to undo.
+				logutils.Log.WithError(err).WithField("movie_id", movieID).Error("Failed to move movie to trash in background")
+			} else if req.onTrashed != nil {
+				req.onTrashed(movieID, movie.Name)
+			}
 		} else {
-			logutils.Log.WithField("movie_id", movieID).Info("Movie deleted successfully in background")
+			err := filemanager.DeleteMovie(movieID, q.moviePath, q.db, q.downloadManager)
+			if err != nil {
+				logutils.Log.WithError(err).WithField("movie_id", movieID).Error("Failed to delete movie in background")
+			} else {
+				logutils.Log.WithField("movie_id", movieID).Info("Movie deleted successfully in background")
+			}
+			if req.onDeleted != nil {
+				req.onDeleted(movieID, err)
+			}
 		}
 		q.mu.Lock()
 		delete(q.pending, movieID)
@@ -80,6 +144,18 @@ func (q *queueImpl) worker() {
 	}
 }
 
+// trashCandidate reports whether the movie should go to the trash: only finished downloads are worth keeping.
+func (q *queueImpl) trashCandidate(req deleteRequest) (database.Movie, bool) {
+	if !q.trashEnabled || req.permanent {
+		return database.Movie{}, false
+	}
+	movie, err := q.db.GetMovieByID(context.Background(), req.movieID)
+	if err != nil {
+		return database.Movie{}, false
+	}
+	return movie, movie.DownloadedPercentage >= completePercentage
+}
+
 // NoopQueue is a Queue that does nothing. Use in tests when deletion is not under test.
 type NoopQueue struct{}
 
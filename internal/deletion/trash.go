@@ -0,0 +1,82 @@
+package deletion
+
+import (
+	"context"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/filemanager"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+)
+
+const trashPurgeInterval = 10 * time.Minute
+
+// StartTrashPurger periodically empties the trash: movies trashed longer than TRASH_RETENTION ago are purged,
+// then the oldest remaining ones while free space is below TRASH_MIN_FREE_SPACE_GB. Blocks until ctx is done.
+func StartTrashPurger(ctx context.Context, cfg *config.Config, db database.Database) {
+	if !cfg.TrashSettings.Enabled {
+		return
+	}
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	logutils.Log.WithFields(map[string]any{
+		"retention":         cfg.TrashSettings.Retention,
+		"min_free_space_gb": cfg.TrashSettings.MinFreeSpaceGB,
+	}).Info("Starting trash purger")
+
+	PurgeTrash(ctx, cfg, db, time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			logutils.Log.Info("Stopping trash purger")
+			return
+		case now := <-ticker.C:
+			PurgeTrash(ctx, cfg, db, now)
+		}
+	}
+}
+
+// PurgeTrash runs one purge pass and returns the number of movies removed for good.
+func PurgeTrash(ctx context.Context, cfg *config.Config, db database.Database, now time.Time) int {
+	trashed, err := db.GetTrashedMovies(ctx)
+	if err != nil {
+		logutils.Log.WithError(err).Warn("PurgeTrash: GetTrashedMovies failed")
+		return 0
+	}
+	purged := 0
+	remaining := trashed[:0]
+	for i := range trashed {
+		if now.Sub(*trashed[i].TrashedAt) < cfg.TrashSettings.Retention {
+			remaining = append(remaining, trashed[i])
+			continue
+		}
+		if purgeErr := filemanager.PurgeTrashedMovie(trashed[i].ID, cfg.MoviePath, db); purgeErr != nil {
+			logutils.Log.WithError(purgeErr).WithField("movie_id", trashed[i].ID).Warn("PurgeTrash: failed to purge expired movie")
+			continue
+		}
+		purged++
+	}
+
+	if cfg.TrashSettings.MinFreeSpaceGB > 0 {
+		// GetTrashedMovies is ordered oldest first.
+		for i := range remaining {
+			freeGB, spaceErr := filemanager.GetAvailableSpaceGB(cfg.MoviePath)
+			if spaceErr != nil || freeGB >= cfg.TrashSettings.MinFreeSpaceGB {
+				break
+			}
+			if purgeErr := filemanager.PurgeTrashedMovie(remaining[i].ID, cfg.MoviePath, db); purgeErr != nil {
+				logutils.Log.WithError(purgeErr).WithField("movie_id", remaining[i].ID).
+					Warn("PurgeTrash: failed to purge movie for free space")
+				continue
+			}
+			purged++
+		}
+	}
+
+	if purged > 0 {
+		logutils.Log.WithField("count", purged).Info("Trash purged")
+	}
+	return purged
+}
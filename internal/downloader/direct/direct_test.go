@@ -0,0 +1,278 @@
+package direct
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tmsconfig "github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+)
+
+func TestMain(m *testing.M) {
+	logutils.InitLogger("error")
+	os.Exit(m.Run())
+}
+
+func testContent(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+// fileServer serves content with Range support via http.ServeContent and counts ranged GETs.
+func fileServer(t *testing.T, content []byte, contentType, disposition string, rangedGets *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.Header.Get("Range") != "" && rangedGets != nil {
+			rangedGets.Add(1)
+		}
+		w.Header().Set("Content-Type", contentType)
+		if disposition != "" {
+			w.Header().Set("Content-Disposition", disposition)
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testConfig(dir string, segments int) *tmsconfig.Config {
+	return &tmsconfig.Config{
+		MoviePath:        dir,
+		DownloadSettings: tmsconfig.DownloadConfig{HTTPSegments: segments},
+	}
+}
+
+// runDownload starts dl and drains both channels, returning the final error.
+func runDownload(t *testing.T, dl interface {
+	StartDownload(ctx context.Context) (chan float64, chan error, <-chan int, error)
+}) error {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	progressChan, errChan, _, err := dl.StartDownload(ctx)
+	if err != nil {
+		t.Fatalf("StartDownload: %v", err)
+	}
+	for range progressChan {
+	}
+	return <-errChan
+}
+
+func TestProbe_ContentDispositionAndSize(t *testing.T) {
+	content := testContent(4096)
+	srv := fileServer(t, content, "application/octet-stream", `attachment; filename="The Movie (2024).mkv"`, nil)
+
+	client := NewHTTPClient(nil)
+	info, err := Probe(context.Background(), client, srv.URL+"/download?id=1")
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if info.FileName != "The Movie (2024).mkv" || info.Size != int64(len(content)) || !info.AcceptRanges || !info.Attachment {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+	if !info.IsDirectFile() {
+		t.Error("octet-stream attachment should be a direct file")
+	}
+
+	dl := NewHTTPDownloader(info, nil, client, testConfig(t.TempDir(), 1))
+	if title, _ := dl.GetTitle(); title != "The Movie (2024)" {
+		t.Errorf("title = %q", title)
+	}
+	if mainFiles, _, _ := dl.GetFiles(); mainFiles[0] != "The_Movie_2024_.mkv" {
+		t.Errorf("file name = %q", mainFiles[0])
+	}
+}
+
+func TestProbe_FallsBackToRangedGet(t *testing.T) {
+	content := testContent(1000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "video/mp4")
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	client := NewHTTPClient(nil)
+	info, err := Probe(context.Background(), client, srv.URL+"/films/clip%20one.mp4")
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if info.Size != int64(len(content)) || !info.AcceptRanges || info.FileName != "clip one.mp4" {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+}
+
+func TestIsDirectFile(t *testing.T) {
+	tests := []struct {
+		info Info
+		want bool
+	}{
+		{Info{ContentType: "video/x-matroska", FileName: "a"}, true},
+		{Info{ContentType: "application/octet-stream", FileName: "movie.mkv"}, true},
+		{Info{ContentType: "application/octet-stream", FileName: "download"}, false},
+		{Info{ContentType: "application/octet-stream", FileName: "download", Attachment: true}, true},
+		{Info{ContentType: "text/html; charset=utf-8", FileName: "watch"}, false},
+		{Info{ContentType: "application/vnd.apple.mpegurl", FileName: "index.m3u8"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.info.IsDirectFile(); got != tt.want {
+			t.Errorf("IsDirectFile(%+v) = %v, want %v", tt.info, got, tt.want)
+		}
+	}
+}
+
+func TestPlanSegments(t *testing.T) {
+	if got := planSegments(0, true, 4); len(got) != 1 || got[0].end != -1 {
+		t.Errorf("unknown size: want one open-ended segment, got %d", len(got))
+	}
+	if got := planSegments(100*minSegmentSize, false, 4); len(got) != 1 {
+		t.Errorf("no Range support: want 1 segment, got %d", len(got))
+	}
+	if got := planSegments(minSegmentSize, true, 4); len(got) != 1 {
+		t.Errorf("small file: want 1 segment, got %d", len(got))
+	}
+	size := int64(10*minSegmentSize + 3)
+	got := planSegments(size, true, 4)
+	if len(got) != 4 {
+		t.Fatalf("want 4 segments, got %d", len(got))
+	}
+	var covered int64
+	for i, s := range got {
+		if i > 0 && s.start != got[i-1].end+1 {
+			t.Errorf("segment %d does not follow the previous one", i)
+		}
+		covered += s.end - s.start + 1
+	}
+	if covered != size {
+		t.Errorf("segments cover %d bytes, want %d", covered, size)
+	}
+}
+
+func TestHTTPDownloader_SingleStream(t *testing.T) {
+	content := testContent(64 * 1024)
+	srv := fileServer(t, content, "video/mp4", "", nil)
+	dir := t.TempDir()
+
+	client := NewHTTPClient(nil)
+	info, err := Probe(context.Background(), client, srv.URL+"/movie.mp4")
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	dl := NewHTTPDownloader(info, nil, client, testConfig(dir, 4))
+	if err := runDownload(t, dl); err != nil {
+		t.Fatalf("download: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "movie.mp4"))
+	if err != nil || !bytes.Equal(got, content) {
+		t.Fatalf("downloaded file mismatch (err=%v, len=%d)", err, len(got))
+	}
+	for _, tmp := range []string{"movie.mp4.part", "movie.mp4.part.json"} {
+		if _, err := os.Stat(filepath.Join(dir, tmp)); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed after completion", tmp)
+		}
+	}
+}
+
+func TestHTTPDownloader_ResumesSegmentsFromState(t *testing.T) {
+	content := testContent(3 * 10000)
+	var rangedGets atomic.Int32
+	srv := fileServer(t, content, "video/mp4", "", &rangedGets)
+	dir := t.TempDir()
+
+	// Simulate an interrupted 3-segment download: first segment done, second half done, third untouched.
+	partial := make([]byte, len(content))
+	copy(partial[:10000], content[:10000])
+	copy(partial[10000:15000], content[10000:15000])
+	if err := os.WriteFile(filepath.Join(dir, "movie.mp4.part"), partial, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	segments := []*segment{{start: 0, end: 9999}, {start: 10000, end: 19999}, {start: 20000, end: 29999}}
+	segments[0].done.Store(10000)
+	segments[1].done.Store(5000)
+	saveState(filepath.Join(dir, "movie.mp4.part.json"), int64(len(content)), segments)
+
+	client := NewHTTPClient(nil)
+	info, err := Probe(context.Background(), client, srv.URL+"/movie.mp4")
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	dl := NewHTTPDownloader(info, nil, client, testConfig(dir, 3))
+	if err := runDownload(t, dl); err != nil {
+		t.Fatalf("download: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "movie.mp4"))
+	if err != nil || !bytes.Equal(got, content) {
+		t.Fatalf("resumed file mismatch (err=%v)", err)
+	}
+	if n := rangedGets.Load(); n != 2 {
+		t.Errorf("want 2 ranged requests for the unfinished segments, got %d", n)
+	}
+}
+
+func TestHTTPDownloader_ChecksumMismatch(t *testing.T) {
+	content := testContent(2048)
+	srv := fileServer(t, content, "video/mp4", "", nil)
+	dir := t.TempDir()
+
+	wrong := sha256.Sum256([]byte("something else"))
+	cleanURL, checksum, err := ParseChecksum(srv.URL + "/movie.mp4#sha256=" + hex.EncodeToString(wrong[:]))
+	if err != nil || checksum == nil {
+		t.Fatalf("ParseChecksum: %v", err)
+	}
+	if cleanURL != srv.URL+"/movie.mp4" {
+		t.Fatalf("fragment not stripped: %s", cleanURL)
+	}
+
+	client := NewHTTPClient(nil)
+	info, err := Probe(context.Background(), client, cleanURL)
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	dl := NewHTTPDownloader(info, checksum, client, testConfig(dir, 1))
+	err = runDownload(t, dl)
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("want ErrChecksumMismatch, got %v", err)
+	}
+	if httpDL := dl.(*HTTPDownloader); httpDL.CanResume(err) {
+		t.Error("a download with a wrong checksum must not be resumed")
+	} else if want := srv.URL + "/movie.mp4#sha256=" + hex.EncodeToString(wrong[:]); httpDL.SourceURL() != want {
+		t.Errorf("SourceURL() = %q, want %q with the checksum", httpDL.SourceURL(), want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "movie.mp4")); !os.IsNotExist(err) {
+		t.Error("file with a wrong checksum must not be moved into the library")
+	}
+
+	right := sha256.Sum256(content)
+	_, checksum, _ = ParseChecksum(srv.URL + "/movie.mp4#sha256=" + hex.EncodeToString(right[:]))
+	dl = NewHTTPDownloader(info, checksum, client, testConfig(dir, 1))
+	if err := runDownload(t, dl); err != nil {
+		t.Fatalf("download with matching checksum: %v", err)
+	}
+}
+
+func TestParseChecksum(t *testing.T) {
+	if clean, c, err := ParseChecksum("https://host/a.mkv#t=10"); err != nil || c != nil || clean != "https://host/a.mkv#t=10" {
+		t.Errorf("unrelated fragment should be kept: %q %v %v", clean, c, err)
+	}
+	if _, _, err := ParseChecksum("https://host/a.mkv#md5=abc"); err == nil {
+		t.Error("short digest should be rejected")
+	}
+}
@@ -0,0 +1,277 @@
+package direct
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	tmsconfig "github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	tmsutils "github.com/NikitaDmitryuk/telegram-media-server/internal/utils"
+)
+
+// StateSuffix names the temp file with the progress of each segment an interrupted download resumes from.
+const StateSuffix = ".part.json"
+
+const (
+	partSuffix       = ".part"
+	progressInterval = time.Second
+	fileMode         = 0o644
+	fullPercentage   = 100
+)
+
+// HTTPDownloader downloads a single file over HTTP(S). When the server supports Range requests the
+// file is fetched in parallel segments and an interrupted download resumes from the saved state.
+type HTTPDownloader struct {
+	info            Info
+	checksum        *Checksum
+	client          *http.Client
+	moviePath       string
+	fileName        string
+	title           string
+	maxSegments     int
+	mu              sync.Mutex
+	cancel          context.CancelFunc
+	stoppedManually bool
+	finished        bool
+}
+
+func NewHTTPDownloader(info Info, checksum *Checksum, client *http.Client, cfg *tmsconfig.Config) downloader.Downloader {
+	ext := filepath.Ext(info.FileName)
+	title := strings.TrimSuffix(info.FileName, ext)
+	if title == "" {
+		title = "download"
+	}
+	segments := cfg.GetDownloadSettings().HTTPSegments
+	if segments < 1 {
+		segments = 1
+	}
+	return &HTTPDownloader{
+		info:        info,
+		checksum:    checksum,
+		client:      client,
+		moviePath:   cfg.MoviePath,
+		fileName:    tmsutils.SanitizeFileName(title) + strings.ToLower(ext),
+		title:       title,
+		maxSegments: segments,
+	}
+}
+
+func (d *HTTPDownloader) GetTitle() (string, error) {
+	return d.title, nil
+}
+
+func (d *HTTPDownloader) GetFiles() (mainFiles, tempFiles []string, err error) {
+	return []string{d.fileName}, []string{d.fileName + partSuffix, d.fileName + StateSuffix}, nil
+}
+
+func (d *HTTPDownloader) GetFileSize() (int64, error) {
+	return d.info.Size, nil
+}
+
+func (*HTTPDownloader) TotalEpisodes() int { return 0 }
+
+func (d *HTTPDownloader) StoppedManually() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stoppedManually
+}
+
+// SourceURL returns the link the file is downloaded from, with its checksum fragment.
+func (d *HTTPDownloader) SourceURL() string {
+	if d.checksum == nil {
+		return d.info.URL
+	}
+	return d.info.URL + "#" + d.checksum.Algorithm + "=" + d.checksum.Expected
+}
+
+// CanResume reports whether the failed download can continue from its partial file: it is no longer running,
+// the failure is not permanent (4xx, ignored Range, checksum mismatch) and the saved state is still there.
+func (d *HTTPDownloader) CanResume(err error) bool {
+	d.mu.Lock()
+	finished := d.finished
+	d.mu.Unlock()
+	var permanent *permanentError
+	if !finished || errors.As(err, &permanent) || errors.Is(err, ErrChecksumMismatch) {
+		return false
+	}
+	_, statErr := os.Stat(filepath.Join(d.moviePath, d.fileName+StateSuffix))
+	return statErr == nil
+}
+
+func (d *HTTPDownloader) StopDownload() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stoppedManually = true
+	if d.cancel != nil {
+		logutils.Log.WithField("file", d.fileName).Info("Canceling HTTP download")
+		d.cancel()
+	}
+	return nil
+}
+
+func (d *HTTPDownloader) StartDownload(
+	ctx context.Context,
+) (progressChan chan float64, errChan chan error, episodesChan <-chan int, err error) {
+	partPath := filepath.Join(d.moviePath, d.fileName+partSuffix)
+	statePath := filepath.Join(d.moviePath, d.fileName+StateSuffix)
+
+	segments := loadState(statePath, d.info.Size)
+	if segments == nil || !d.info.AcceptRanges {
+		segments = planSegments(d.info.Size, d.info.AcceptRanges, d.maxSegments)
+		if removeErr := os.Remove(partPath); removeErr != nil && !os.IsNotExist(removeErr) {
+			return nil, nil, nil, fmt.Errorf("remove stale partial file: %w", removeErr)
+		}
+	} else {
+		logutils.Log.WithFields(map[string]any{
+			"file":       d.fileName,
+			"downloaded": downloadedBytes(segments),
+		}).Info("Resuming HTTP download")
+	}
+
+	f, err := os.OpenFile(partPath, os.O_CREATE|os.O_WRONLY, fileMode) // #nosec G304 -- path under MOVIE_PATH
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("open partial file: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	d.mu.Lock()
+	d.cancel = cancel
+	d.mu.Unlock()
+
+	progressChan = make(chan float64)
+	errChan = make(chan error, 1)
+	go d.run(ctx, cancel, f, segments, progressChan, errChan)
+	return progressChan, errChan, nil, nil
+}
+
+func (d *HTTPDownloader) run(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	f *os.File,
+	segments []*segment,
+	progressChan chan float64,
+	errChan chan error,
+) {
+	defer cancel()
+	defer close(progressChan)
+	defer close(errChan)
+	defer func() {
+		d.mu.Lock()
+		d.finished = true
+		d.mu.Unlock()
+	}()
+
+	partPath := f.Name()
+	statePath := filepath.Join(d.moviePath, d.fileName+StateSuffix)
+
+	done := make(chan error, 1)
+	go func() { done <- d.downloadSegments(ctx, f, segments) }()
+
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+
+	var downloadErr error
+wait:
+	for {
+		select {
+		case downloadErr = <-done:
+			break wait
+		case <-ticker.C:
+			saveState(statePath, d.info.Size, segments)
+			if d.info.Size > 0 {
+				select {
+				case progressChan <- float64(downloadedBytes(segments)) * fullPercentage / float64(d.info.Size):
+				case <-ctx.Done():
+				}
+			}
+		}
+	}
+
+	if closeErr := f.Close(); downloadErr == nil && closeErr != nil {
+		downloadErr = closeErr
+	}
+
+	if downloadErr != nil {
+		saveState(statePath, d.info.Size, segments)
+		if d.StoppedManually() {
+			logutils.Log.WithField("file", d.fileName).Info("HTTP download stopped manually")
+			errChan <- downloader.ErrStoppedByUser
+			return
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			errChan <- ctxErr
+			return
+		}
+		logutils.Log.WithError(downloadErr).WithField("url", d.info.URL).Error("HTTP download failed")
+		errChan <- fmt.Errorf("http download failed: %w", downloadErr)
+		return
+	}
+
+	if err := d.finalize(partPath, statePath); err != nil {
+		errChan <- err
+		return
+	}
+	select {
+	case progressChan <- fullPercentage:
+	case <-ctx.Done():
+	}
+	errChan <- nil
+}
+
+// finalize verifies the checksum (when given) and moves the partial file into place.
+func (d *HTTPDownloader) finalize(partPath, statePath string) error {
+	if d.checksum != nil {
+		if err := d.checksum.Verify(partPath); err != nil {
+			logutils.Log.WithError(err).WithField("file", d.fileName).Error("Downloaded file failed checksum verification")
+			_ = os.Remove(partPath)
+			_ = os.Remove(statePath)
+			return err
+		}
+	}
+	if err := os.Rename(partPath, filepath.Join(d.moviePath, d.fileName)); err != nil {
+		return fmt.Errorf("move downloaded file: %w", err)
+	}
+	_ = os.Remove(statePath)
+	return nil
+}
+
+func (d *HTTPDownloader) downloadSegments(ctx context.Context, f *os.File, segments []*segment) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for _, seg := range segments {
+		if seg.complete() {
+			continue
+		}
+		wg.Add(1)
+		go func(seg *segment) {
+			defer wg.Done()
+			if err := d.fetchSegment(ctx, f, seg, len(segments) > 1); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(seg)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+var (
+	_ downloader.Downloader          = (*HTTPDownloader)(nil)
+	_ downloader.ResumableDownloader = (*HTTPDownloader)(nil)
+)
@@ -0,0 +1,145 @@
+package direct
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	tmsconfig "github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/proxy"
+)
+
+const (
+	probeTimeout          = 15 * time.Second
+	responseHeaderTimeout = 30 * time.Second
+)
+
+// Info is what the server tells about a file before downloading it.
+type Info struct {
+	URL          string // request URL (without checksum fragment)
+	FileName     string // from Content-Disposition, else the last path segment of the final URL
+	Size         int64  // 0 when the server does not report it
+	ContentType  string
+	AcceptRanges bool // server honors Range requests (resume and parallel segments)
+	Attachment   bool // Content-Disposition: attachment
+}
+
+// NewHTTPClient returns the client used for probing and downloading files. Each request, redirects
+// included, is routed by PROXY_RULES and the older CONTENT_PROXY settings. There is no overall timeout
+// (files can be tens of GB); only waiting for response headers is bounded.
+func NewHTTPClient(cfg *tmsconfig.Config) *http.Client {
+	transport := proxy.ContentTransport(cfg)
+	transport.ResponseHeaderTimeout = responseHeaderTimeout
+	return &http.Client{Transport: transport}
+}
+
+// Probe sends HEAD (or a one-byte ranged GET when HEAD is not allowed) and returns file metadata.
+func Probe(ctx context.Context, client *http.Client, rawURL string) (Info, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	resp, err := doProbe(ctx, client, http.MethodHead, rawURL)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented ||
+		resp.StatusCode == http.StatusForbidden) {
+		resp.Body.Close()
+		resp, err = doProbe(ctx, client, http.MethodGet, rawURL)
+	}
+	if err != nil {
+		return Info{}, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return Info{}, fmt.Errorf("probe %s: status %d", rawURL, resp.StatusCode)
+	}
+	info := Info{
+		URL:          rawURL,
+		ContentType:  resp.Header.Get("Content-Type"),
+		AcceptRanges: strings.EqualFold(resp.Header.Get("Accept-Ranges"), "bytes"),
+	}
+	if resp.StatusCode == http.StatusPartialContent {
+		info.AcceptRanges = true
+		info.Size = sizeFromContentRange(resp.Header.Get("Content-Range"))
+	} else if resp.ContentLength > 0 {
+		info.Size = resp.ContentLength
+	}
+	info.FileName, info.Attachment = fileNameFromDisposition(resp.Header.Get("Content-Disposition"))
+	if info.FileName == "" {
+		info.FileName = fileNameFromURL(resp.Request.URL)
+	}
+	return info, nil
+}
+
+func doProbe(ctx context.Context, client *http.Client, method, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+	return client.Do(req)
+}
+
+// IsDirectFile reports whether the URL serves a media file rather than a web page. Pages (text/html)
+// stay with yt-dlp; streaming manifests (HLS, DASH) too.
+func (i Info) IsDirectFile() bool {
+	mediaType, _, err := mime.ParseMediaType(i.ContentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(i.ContentType))
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "video/"):
+		return true
+	case mediaType == "application/x-matroska", mediaType == "application/mp4":
+		return true
+	case mediaType == "application/octet-stream", mediaType == "binary/octet-stream", mediaType == "application/force-download":
+		return i.Attachment || path.Ext(i.FileName) != ""
+	default:
+		return false
+	}
+}
+
+func fileNameFromDisposition(header string) (name string, attachment bool) {
+	if header == "" {
+		return "", false
+	}
+	disposition, params, err := mime.ParseMediaType(header)
+	if err != nil {
+		return "", false
+	}
+	// mime.ParseMediaType decodes RFC 2231 filename* into "filename".
+	return path.Base(strings.ReplaceAll(params["filename"], "\\", "/")), strings.EqualFold(disposition, "attachment")
+}
+
+func fileNameFromURL(u *url.URL) string {
+	name := path.Base(u.Path)
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	if name == "/" || name == "." {
+		return ""
+	}
+	return name
+}
+
+// sizeFromContentRange parses "bytes 0-0/12345"; returns 0 when the total is unknown ("*").
+func sizeFromContentRange(header string) int64 {
+	_, total, ok := strings.Cut(header, "/")
+	if !ok {
+		return 0
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(total), 10, 64)
+	if err != nil || size < 0 {
+		return 0
+	}
+	return size
+}
@@ -0,0 +1,230 @@
+package direct
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+)
+
+const (
+	// minSegmentSize keeps small files in a single stream; splitting them only adds request overhead.
+	minSegmentSize     = 16 * 1024 * 1024
+	maxSegmentAttempts = 5
+	retryBackoff       = 2 * time.Second
+	stateFileMode      = 0o600
+)
+
+var errRangeNotHonored = errors.New("server ignored the Range request")
+
+// segment is a byte range of the file; end is inclusive and -1 when the size is unknown.
+type segment struct {
+	start int64
+	end   int64
+	done  atomic.Int64
+}
+
+func (s *segment) complete() bool {
+	return s.end >= 0 && s.start+s.done.Load() > s.end
+}
+
+type segmentState struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+	Done  int64 `json:"done"`
+}
+
+type downloadState struct {
+	Size     int64          `json:"size"`
+	Segments []segmentState `json:"segments"`
+}
+
+// planSegments splits the file into up to maxSegments ranges of at least minSegmentSize.
+func planSegments(size int64, acceptRanges bool, maxSegments int) []*segment {
+	count := int64(1)
+	if size > 0 && acceptRanges {
+		count = min(int64(maxSegments), size/minSegmentSize)
+		count = max(count, 1)
+	}
+	if size <= 0 {
+		return []*segment{{start: 0, end: -1}}
+	}
+	segments := make([]*segment, 0, count)
+	chunk := size / count
+	for i := range count {
+		start := i * chunk
+		end := start + chunk - 1
+		if i == count-1 {
+			end = size - 1
+		}
+		segments = append(segments, &segment{start: start, end: end})
+	}
+	return segments
+}
+
+func downloadedBytes(segments []*segment) int64 {
+	var total int64
+	for _, s := range segments {
+		total += s.done.Load()
+	}
+	return total
+}
+
+// loadState returns the saved segments for a partially downloaded file, or nil when there is no
+// usable state (missing, unreadable, or saved for a different file size).
+func loadState(path string, size int64) []*segment {
+	data, err := os.ReadFile(path) // #nosec G304 -- path under MOVIE_PATH
+	if err != nil {
+		return nil
+	}
+	var state downloadState
+	if err := json.Unmarshal(data, &state); err != nil || size <= 0 || state.Size != size || len(state.Segments) == 0 {
+		return nil
+	}
+	segments := make([]*segment, 0, len(state.Segments))
+	for _, s := range state.Segments {
+		seg := &segment{start: s.Start, end: s.End}
+		seg.done.Store(s.Done)
+		segments = append(segments, seg)
+	}
+	return segments
+}
+
+func saveState(path string, size int64, segments []*segment) {
+	if size <= 0 {
+		return
+	}
+	state := downloadState{Size: size}
+	for _, s := range segments {
+		state.Segments = append(state.Segments, segmentState{Start: s.start, End: s.end, Done: s.done.Load()})
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(path, data, stateFileMode); err != nil {
+		logutils.Log.WithError(err).Debug("Failed to save HTTP download state")
+	}
+}
+
+// fetchSegment downloads the rest of seg, retrying from the current offset on transient errors.
+func (d *HTTPDownloader) fetchSegment(ctx context.Context, f *os.File, seg *segment, ranged bool) error {
+	failures := 0
+	for !seg.complete() {
+		progressed, err := d.fetchSegmentOnce(ctx, f, seg, ranged)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent
+		}
+		// the budget is for consecutive failures; a long download may be interrupted many times
+		if progressed {
+			failures = 0
+		}
+		failures++
+		if failures >= maxSegmentAttempts {
+			return err
+		}
+		logutils.Log.WithError(err).WithFields(map[string]any{
+			"file":    d.fileName,
+			"offset":  seg.start + seg.done.Load(),
+			"failure": failures,
+		}).Warn("HTTP segment interrupted, retrying")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryBackoff):
+		}
+	}
+	return nil
+}
+
+// fetchSegmentOnce issues one request for the remaining bytes of seg. progressed reports whether any
+// bytes were written.
+func (d *HTTPDownloader) fetchSegmentOnce(ctx context.Context, f *os.File, seg *segment, ranged bool) (progressed bool, err error) {
+	offset := seg.start + seg.done.Load()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.info.URL, http.NoBody)
+	if err != nil {
+		return false, &permanentError{err}
+	}
+	useRange := ranged || offset > 0
+	if useRange {
+		rangeHeader := "bytes=" + strconv.FormatInt(offset, 10) + "-"
+		if seg.end >= 0 {
+			rangeHeader += strconv.FormatInt(seg.end, 10)
+		}
+		req.Header.Set("Range", rangeHeader)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case useRange && resp.StatusCode == http.StatusPartialContent:
+	case !useRange && resp.StatusCode == http.StatusOK:
+	case useRange && resp.StatusCode == http.StatusOK && !ranged:
+		// single stream from a server without Range support: start over
+		seg.done.Store(0)
+		offset = seg.start
+	case useRange && resp.StatusCode == http.StatusOK:
+		return false, &permanentError{errRangeNotHonored}
+	case resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests:
+		return false, fmt.Errorf("status %d", resp.StatusCode)
+	default:
+		return false, &permanentError{fmt.Errorf("status %d", resp.StatusCode)}
+	}
+
+	var body io.Reader = resp.Body
+	if seg.end >= 0 {
+		body = io.LimitReader(resp.Body, seg.end-offset+1)
+	}
+	w := &segmentWriter{f: f, seg: seg}
+	_, err = io.Copy(w, body)
+	progressed = w.written > 0
+	if err != nil {
+		return progressed, err
+	}
+	if seg.end >= 0 && !seg.complete() {
+		return progressed, io.ErrUnexpectedEOF
+	}
+	return progressed, nil
+}
+
+// segmentWriter writes at the segment's current offset and advances it.
+type segmentWriter struct {
+	f       *os.File
+	seg     *segment
+	written int64
+}
+
+func (w *segmentWriter) Write(p []byte) (int, error) {
+	n, err := w.f.WriteAt(p, w.seg.start+w.seg.done.Load())
+	w.seg.done.Add(int64(n))
+	w.written += int64(n)
+	return n, err
+}
+
+// permanentError marks failures that retrying cannot fix (4xx, ignored Range).
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
@@ -29,8 +29,11 @@ type QBittorrentHashDownloader interface {
 
 // OnHashKnownSetter: optional; manager sets callback to persist hash synchronously
 // so it survives process restart (channel-based persist can be lost between send and DB write).
+// TorrentClient names the daemon the hash belongs to (config.TorrentClient*), so the torrent is later
+// removed and resumed through that daemon even if the configuration changed in between.
 type OnHashKnownSetter interface {
 	SetOnHashKnown(cb func(hash string))
+	TorrentClient() string
 }
 
 // MagnetMetadataSyncSetter: optional; qBittorrent magnet downloads register placeholder paths until
@@ -39,6 +42,49 @@ type MagnetMetadataSyncSetter interface {
 	SetOnMagnetMetadataReady(cb func(relativePaths []string, totalBytes int64, videoFileCount int))
 }
 
+// SeedingDownloader: optional; torrent downloaders that can leave a completed torrent in the client to seed.
+// keep gets the torrent's tracker URL at completion; when it returns false the torrent is removed as usual.
+type SeedingDownloader interface {
+	SetKeepSeeding(keep func(tracker string) bool)
+}
+
+// SpeedLimitedDownloader: optional; downloaders running their own process (aria2c) that take the global
+// speed limits in bytes per second (0 = unlimited) at start instead of from a shared torrent client.
+type SpeedLimitedDownloader interface {
+	SetSpeedLimits(download, upload int64)
+}
+
+// ResumableDownloader: optional; downloaders that continue from their partial file (direct HTTP) give the link
+// stored with the movie, so a failed or interrupted download is recreated and resumed instead of deleted.
+type ResumableDownloader interface {
+	SourceURL() string
+	// CanResume reports whether the download, failed with err, can continue from what it already fetched.
+	CanResume(err error) bool
+}
+
 type Updater interface {
 	RunUpdate(ctx context.Context)
 }
+
+// TorrentFile is one file of a multi-file torrent as shown in the file preview.
+type TorrentFile struct {
+	Index int    // 1-based position in the torrent's file list
+	Path  string // relative to the download directory, including the torrent's root folder
+	Size  int64
+}
+
+// FileSelector: optional; torrent downloaders that can download a subset of a multi-file torrent.
+// GetFiles, GetFileSize and TotalEpisodes only count the selected files.
+type FileSelector interface {
+	// TorrentFiles lists the files of a multi-file .torrent; nil for single-file torrents and magnets.
+	TorrentFiles() []TorrentFile
+	// SelectFiles limits the download to the files with the given 1-based indices; call it before StartDownload.
+	SelectFiles(indices []int) error
+}
+
+// InfoHashDownloader: optional; torrent downloaders report the BitTorrent info hash so the same release
+// added twice (magnet and .torrent, different dn) is recognised as a duplicate.
+type InfoHashDownloader interface {
+	// InfoHash returns the lowercase hex v1 info hash (v2 SHA-256 for v2-only torrents), "" when unknown.
+	InfoHash() string
+}
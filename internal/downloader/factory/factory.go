@@ -2,6 +2,8 @@ package factory
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,9 +15,15 @@ import (
 
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/direct"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/qbittorrent"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/telegram"
 	aria2 "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/torrent"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/transmission"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/usenet"
 	ytdlp "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/video"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/proxy"
 	"github.com/google/uuid"
 )
 
@@ -26,24 +34,109 @@ const (
 )
 
 func NewTorrentDownloader(torrentFileName, moviePath string, cfg *config.Config) (downloader.Downloader, error) {
-	if cfg.QBittorrentURL != "" {
-		dl, err := qbittorrent.NewQBittorrentDownloader(torrentFileName, moviePath, cfg)
-		if err == nil {
-			return dl, nil
-		}
-		if !cfg.TorrentFallbackToAria2 {
-			return nil, fmt.Errorf("qBittorrent is configured but unavailable: %w", err)
-		}
+	return newTorrentDownloaderOrAria2(torrentFileName, moviePath, cfg)
+}
+
+// NewNZBDownloader returns the Usenet downloader (SABnzbd or NZBGet) for an .nzb file under moviePath.
+func NewNZBDownloader(nzbFileName, moviePath string, cfg *config.Config) (downloader.Downloader, error) {
+	return usenet.NewUsenetDownloader(nzbFileName, moviePath, cfg)
+}
+
+// NewDownloaderFromFile picks the NZB or torrent downloader by the extension of fileName under moviePath.
+func NewDownloaderFromFile(fileName, moviePath string, cfg *config.Config) (downloader.Downloader, error) {
+	if strings.HasSuffix(strings.ToLower(fileName), ".nzb") {
+		return NewNZBDownloader(fileName, moviePath, cfg)
 	}
-	return aria2.NewAria2Downloader(torrentFileName, moviePath, cfg), nil
+	return NewTorrentDownloader(fileName, moviePath, cfg)
 }
 
 func NewVideoDownloader(videoURL string, cfg *config.Config) downloader.Downloader {
 	return ytdlp.NewYTDLPDownloader(videoURL, cfg)
 }
 
-// CreateDownloaderFromURL creates a downloader from a URL string: magnet link, .torrent URL, or video URL (yt-dlp).
+// TelegramFile is a video or document sent to the bot.
+type TelegramFile = telegram.File
+
+// NewTelegramFileDownloader saves a video or document sent to the bot into MOVIE_PATH through fetcher (the bot).
+func NewTelegramFileDownloader(fetcher telegram.FileFetcher, file TelegramFile, cfg *config.Config) downloader.Downloader {
+	return telegram.NewFileDownloader(fetcher, file, cfg.MoviePath)
+}
+
+// VideoOptions are per-download quality, audio and subtitle overrides for yt-dlp downloads.
+type VideoOptions = ytdlp.Options
+
+// QualityAudioOnly is the VideoOptions.Quality that downloads only the audio stream.
+const QualityAudioOnly = ytdlp.QualityAudioOnly
+
+// VideoFormat is one resolution/codec combination offered by a FormatPicker.
+type VideoFormat = ytdlp.Format
+
+// FormatPicker is implemented by yt-dlp downloads: ListFormats returns the formats of a single video
+// (nil for playlists) and SetFormat makes the download use the chosen VideoFormat.Selector.
+type FormatPicker interface {
+	ListFormats(ctx context.Context) ([]VideoFormat, error)
+	SetFormat(selector string)
+}
+
+// StartOptions are the choices a download was started with that its source does not carry: the video options
+// (including a format picked with FormatPicker) and the selected torrent files. Scheduled downloads store them
+// so the downloader is recreated the same way after a restart.
+type StartOptions struct {
+	Video VideoOptions `json:"video"`
+	// Files are the indices passed to downloader.FileSelector.SelectFiles; empty downloads every file.
+	Files []int `json:"files,omitempty"`
+}
+
+// Encode returns o as stored with the movie, "" when o chooses nothing.
+func (o StartOptions) Encode() string {
+	if o.Video.IsZero() && len(o.Files) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// DecodeStartOptions parses a value returned by StartOptions.Encode; "" gives the zero options.
+func DecodeStartOptions(s string) (StartOptions, error) {
+	var o StartOptions
+	if s == "" {
+		return o, nil
+	}
+	if err := json.Unmarshal([]byte(s), &o); err != nil {
+		return o, fmt.Errorf("invalid start options: %w", err)
+	}
+	return o, nil
+}
+
+// SelectFiles limits dl to the stored files; a no-op when o selects none.
+func (o StartOptions) SelectFiles(dl downloader.Downloader) error {
+	if len(o.Files) == 0 {
+		return nil
+	}
+	selector, ok := dl.(downloader.FileSelector)
+	if !ok {
+		return errors.New("the download has no files to select")
+	}
+	return selector.SelectFiles(o.Files)
+}
+
+// CreateDownloaderFromURL creates a downloader from a URL string: magnet link, .torrent URL, direct file URL,
+// or video URL (yt-dlp).
 func CreateDownloaderFromURL(ctx context.Context, rawURL, moviePath string, cfg *config.Config) (downloader.Downloader, error) {
+	return CreateDownloaderFromURLWithOptions(ctx, rawURL, moviePath, cfg, VideoOptions{})
+}
+
+// CreateDownloaderFromURLWithOptions is CreateDownloaderFromURL with video options; they only apply when the URL
+// ends up with yt-dlp, torrents and direct files ignore them.
+func CreateDownloaderFromURLWithOptions(
+	ctx context.Context,
+	rawURL, moviePath string,
+	cfg *config.Config,
+	opts VideoOptions,
+) (downloader.Downloader, error) {
 	rawURL = strings.TrimSpace(rawURL)
 	if rawURL == "" {
 		return nil, fmt.Errorf("empty URL")
@@ -65,7 +158,7 @@ func CreateDownloaderFromURL(ctx context.Context, rawURL, moviePath string, cfg
 	// HTTP(S) URL ending with .torrent or with torrent content: download to temp file
 	if strings.HasPrefix(rawURL, "http://") || strings.HasPrefix(rawURL, "https://") {
 		if strings.HasSuffix(strings.ToLower(rawURL), ".torrent") {
-			localPath, err := downloadTorrentFile(ctx, rawURL, moviePath)
+			localPath, err := downloadTorrentFile(ctx, rawURL, moviePath, cfg)
 			if err != nil {
 				return nil, err
 			}
@@ -76,11 +169,53 @@ func CreateDownloaderFromURL(ctx context.Context, rawURL, moviePath string, cfg
 		if dl, err := resolveProwlarrDownload(ctx, rawURL, moviePath, cfg); err == nil {
 			return dl, nil
 		}
+		// Direct file link (video/* or octet-stream): native HTTP downloader with Range resume
+		if dl, ok, err := tryDirectDownload(ctx, rawURL, cfg); ok || err != nil {
+			return dl, err
+		}
 		// fall through to video
 	}
 
 	// Otherwise treat as video URL (yt-dlp)
-	return ytdlp.NewYTDLPDownloader(rawURL, cfg), nil
+	return ytdlp.NewYTDLPDownloaderWithOptions(rawURL, cfg, opts), nil
+}
+
+// tryDirectDownload probes rawURL and returns the native HTTP downloader when it serves a media file.
+// ok is false for web pages and unreachable URLs so yt-dlp can try them; err is set only for a
+// malformed checksum fragment.
+func tryDirectDownload(ctx context.Context, rawURL string, cfg *config.Config) (dl downloader.Downloader, ok bool, err error) {
+	cleanURL, checksum, err := direct.ParseChecksum(rawURL)
+	if err != nil {
+		return nil, false, err
+	}
+	client := direct.NewHTTPClient(cfg)
+	info, err := direct.Probe(ctx, client, cleanURL)
+	if err != nil {
+		logutils.Log.WithError(err).WithField("url", cleanURL).Debug("Direct download probe failed, falling back to yt-dlp")
+		return nil, false, nil
+	}
+	if !info.IsDirectFile() {
+		return nil, false, nil
+	}
+	return direct.NewHTTPDownloader(info, checksum, client, cfg), true, nil
+}
+
+// NewDirectDownloader probes rawURL (with an optional checksum fragment) and returns the native HTTP downloader,
+// e.g. to resume a direct download from its partial file. Fails when the URL no longer serves a file.
+func NewDirectDownloader(ctx context.Context, rawURL string, cfg *config.Config) (downloader.Downloader, error) {
+	cleanURL, checksum, err := direct.ParseChecksum(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	client := direct.NewHTTPClient(cfg)
+	info, err := direct.Probe(ctx, client, cleanURL)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDirectFile() {
+		return nil, fmt.Errorf("%s no longer serves a file (content type %q)", cleanURL, info.ContentType)
+	}
+	return direct.NewHTTPDownloader(info, checksum, client, cfg), nil
 }
 
 // CreateDownloaderFromTorrentData writes bencoded .torrent bytes into moviePath and returns a torrent downloader (qBittorrent or aria2).
@@ -112,7 +247,8 @@ func resolveProwlarrDownload(ctx context.Context, rawURL, moviePath string, cfg
 		req.Header.Set("X-Api-Key", cfg.ProwlarrAPIKey)
 	}
 	client := &http.Client{
-		Timeout: time.Duration(torrentDownloadTimeoutSec) * time.Second,
+		Timeout:   time.Duration(torrentDownloadTimeoutSec) * time.Second,
+		Transport: proxy.Transport(cfg),
 		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
 			return http.ErrUseLastResponse
 		},
@@ -129,7 +265,51 @@ func resolveProwlarrDownload(ctx context.Context, rawURL, moviePath string, cfg
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("prowlarr download returned status %d", resp.StatusCode)
 	}
-	return handleProwlarrBody(resp, moviePath, cfg)
+	return handleReleaseBody(resp, moviePath, cfg)
+}
+
+// CreateDownloaderFromReleaseURL returns a downloader for an indexer release link (RSS/Torznab enclosure):
+// a magnet, or an HTTP(S) URL that serves a .torrent, an NZB or redirects to a magnet. Unlike
+// CreateDownloaderFromURL it never falls back to yt-dlp or the direct downloader.
+func CreateDownloaderFromReleaseURL(ctx context.Context, rawURL, moviePath string, cfg *config.Config) (downloader.Downloader, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if strings.HasPrefix(strings.ToLower(rawURL), "magnet:") {
+		return CreateDownloaderFromURL(ctx, rawURL, moviePath, cfg)
+	}
+	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
+		return nil, fmt.Errorf("unsupported release link: %q", rawURL)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	if ok, _ := isProwlarrDownloadURL(cfg, rawURL); ok && cfg.ProwlarrAPIKey != "" {
+		req.Header.Set("X-Api-Key", cfg.ProwlarrAPIKey)
+	}
+	client := &http.Client{
+		Timeout:   time.Duration(torrentDownloadTimeoutSec) * time.Second,
+		Transport: proxy.Transport(cfg),
+		// Indexers often redirect to a magnet: stop there and read it from Location.
+		CheckRedirect: func(req *http.Request, _ []*http.Request) error {
+			if req.URL.Scheme == "magnet" {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if dl, ok, resolveErr := tryRedirectMagnet(resp, moviePath, cfg); ok {
+		return dl, resolveErr
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("release download returned status %d", resp.StatusCode)
+	}
+	return handleReleaseBody(resp, moviePath, cfg)
 }
 
 // tryRedirectMagnet handles 3xx with Location: magnet. Returns (downloader, true, nil) on success,
@@ -149,17 +329,17 @@ func tryRedirectMagnet(resp *http.Response, moviePath string, cfg *config.Config
 	return dl, true, err
 }
 
-// handleProwlarrBody reads the response body and returns a downloader for .torrent or magnet content.
-func handleProwlarrBody(resp *http.Response, moviePath string, cfg *config.Config) (downloader.Downloader, error) {
+// handleReleaseBody reads a Prowlarr or indexer download response and returns a downloader for .torrent, magnet or NZB content.
+func handleReleaseBody(resp *http.Response, moviePath string, cfg *config.Config) (downloader.Downloader, error) {
 	if resp.ContentLength > torrentMaxSizeBytes {
-		return nil, fmt.Errorf("prowlarr download response too large")
+		return nil, fmt.Errorf("release download response too large")
 	}
 	data, err := io.ReadAll(io.LimitReader(resp.Body, torrentMaxSizeBytes+1))
 	if err != nil {
 		return nil, err
 	}
 	if int64(len(data)) > torrentMaxSizeBytes {
-		return nil, fmt.Errorf("prowlarr download response too large")
+		return nil, fmt.Errorf("release download response too large")
 	}
 	contentType := resp.Header.Get("Content-Type")
 	if isTorrentResponse(data, contentType) {
@@ -172,7 +352,10 @@ func handleProwlarrBody(resp *http.Response, moviePath string, cfg *config.Confi
 		}
 		return writeMagnetAndReturnDownloader(moviePath, bodyStr, cfg)
 	}
-	return nil, fmt.Errorf("prowlarr download URL did not return torrent or magnet")
+	if usenet.IsNZB(data) {
+		return writeNZBAndReturnDownloader(moviePath, data, cfg)
+	}
+	return nil, fmt.Errorf("download URL did not return torrent, magnet or NZB")
 }
 
 func isProwlarrDownloadURL(cfg *config.Config, rawURL string) (bool, error) {
@@ -206,6 +389,22 @@ func safeJoinPath(base, name string) (string, error) {
 	return path, nil
 }
 
+func writeNZBAndReturnDownloader(moviePath string, data []byte, cfg *config.Config) (downloader.Downloader, error) {
+	if !usenet.Configured(cfg) {
+		return nil, fmt.Errorf("release is an NZB but no Usenet client is configured (SABNZBD_URL or NZBGET_URL)")
+	}
+	name := "nzb_" + uuid.New().String()[:8] + ".nzb"
+	path, err := safeJoinPath(moviePath, name)
+	if err != nil {
+		return nil, err
+	}
+	// #nosec G703 -- path validated against traversal
+	if err := os.WriteFile(path, data, ownerOnlyFileMode); err != nil {
+		return nil, err
+	}
+	return NewNZBDownloader(name, moviePath, cfg)
+}
+
 func writeMagnetAndReturnDownloader(moviePath, magnetURI string, cfg *config.Config) (downloader.Downloader, error) {
 	name := "magnet_" + uuid.New().String()[:8] + ".magnet"
 	path, err := safeJoinPath(moviePath, name)
@@ -220,6 +419,9 @@ func writeMagnetAndReturnDownloader(moviePath, magnetURI string, cfg *config.Con
 	return newTorrentDownloaderOrAria2(name, moviePath, cfg)
 }
 
+// newTorrentDownloaderOrAria2 picks the configured torrent client (qBittorrent, then Transmission),
+// falling back to aria2 only when TORRENT_FALLBACK_TO_ARIA2 is set. aria2 runs through the
+// ARIA2_RPC_URL daemon when configured, otherwise as one aria2c process per download.
 func newTorrentDownloaderOrAria2(torrentFileName, moviePath string, cfg *config.Config) (downloader.Downloader, error) {
 	if cfg.QBittorrentURL != "" {
 		dl, err := qbittorrent.NewQBittorrentDownloader(torrentFileName, moviePath, cfg)
@@ -229,6 +431,17 @@ func newTorrentDownloaderOrAria2(torrentFileName, moviePath string, cfg *config.
 		if !cfg.TorrentFallbackToAria2 {
 			return nil, fmt.Errorf("qBittorrent is configured but unavailable: %w", err)
 		}
+	} else if cfg.TransmissionURL != "" {
+		dl, err := transmission.NewTransmissionDownloader(torrentFileName, moviePath, cfg)
+		if err == nil {
+			return dl, nil
+		}
+		if !cfg.TorrentFallbackToAria2 {
+			return nil, fmt.Errorf("transmission is configured but unavailable: %w", err)
+		}
+	}
+	if cfg.Aria2Settings.RPCURL != "" {
+		return aria2.NewAria2RPCDownloader(torrentFileName, moviePath, cfg)
 	}
 	return aria2.NewAria2Downloader(torrentFileName, moviePath, cfg), nil
 }
@@ -253,12 +466,15 @@ func writeTorrentAndReturnDownloader(moviePath string, data []byte, cfg *config.
 	return newTorrentDownloaderOrAria2(fname, moviePath, cfg)
 }
 
-func downloadTorrentFile(ctx context.Context, fileURL, moviePath string) (string, error) {
+func downloadTorrentFile(ctx context.Context, fileURL, moviePath string, cfg *config.Config) (string, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, http.NoBody)
 	if err != nil {
 		return "", fmt.Errorf("create request: %w", err)
 	}
-	client := &http.Client{Timeout: time.Duration(torrentDownloadTimeoutSec) * time.Second}
+	client := &http.Client{
+		Timeout:   time.Duration(torrentDownloadTimeoutSec) * time.Second,
+		Transport: proxy.Transport(cfg),
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("download .torrent: %w", err)
@@ -0,0 +1,32 @@
+package factory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStartOptionsRoundTrip(t *testing.T) {
+	off := false
+	tests := []StartOptions{
+		{},
+		{Files: []int{0, 2}},
+		{Video: VideoOptions{Quality: "720p", AudioLang: "en", WriteSubs: &off}},
+		{Video: VideoOptions{Format: "137+ba"}, Files: []int{1}},
+	}
+	for _, want := range tests {
+		encoded := want.Encode()
+		if (encoded == "") != (want.Video.IsZero() && len(want.Files) == 0) {
+			t.Errorf("Encode(%+v) = %q", want, encoded)
+		}
+		got, err := DecodeStartOptions(encoded)
+		if err != nil {
+			t.Fatalf("DecodeStartOptions(%q): %v", encoded, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("DecodeStartOptions(%q) = %+v, want %+v", encoded, got, want)
+		}
+	}
+	if _, err := DecodeStartOptions("{"); err == nil {
+		t.Error("DecodeStartOptions accepted invalid JSON")
+	}
+}
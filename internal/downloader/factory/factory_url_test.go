@@ -2,12 +2,17 @@ package factory
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/direct"
 	aria2 "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/torrent"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/transmission"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/usenet"
 	ytdlp "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/video"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
 )
@@ -67,6 +72,39 @@ func TestCreateDownloaderFromURL_VideoURL(t *testing.T) {
 	_ = dl.(*ytdlp.YTDLPDownloader)
 }
 
+func TestCreateDownloaderFromURL_DirectFileByContentType(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/page" {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte("<html></html>"))
+			return
+		}
+		w.Header().Set("Content-Type", "video/x-matroska")
+		w.Header().Set("Content-Length", "1024")
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	cfg := &config.Config{MoviePath: dir}
+	ctx := context.Background()
+
+	dl, err := CreateDownloaderFromURL(ctx, srv.URL+"/files/movie.mkv", dir, cfg)
+	if err != nil {
+		t.Fatalf("CreateDownloaderFromURL(direct): %v", err)
+	}
+	if _, ok := dl.(*direct.HTTPDownloader); !ok {
+		t.Fatalf("downloader type = %T, want *direct.HTTPDownloader", dl)
+	}
+
+	dl, err = CreateDownloaderFromURL(ctx, srv.URL+"/page", dir, cfg)
+	if err != nil {
+		t.Fatalf("CreateDownloaderFromURL(page): %v", err)
+	}
+	if _, ok := dl.(*ytdlp.YTDLPDownloader); !ok {
+		t.Fatalf("downloader type = %T, want *ytdlp.YTDLPDownloader for an HTML page", dl)
+	}
+}
+
 func TestCreateDownloaderFromURL_TorrentURLEnding(t *testing.T) {
 	// We don't actually download; just check that the function recognizes .torrent URL
 	// and would call downloadTorrentFile (which would fail for invalid URL in test).
@@ -136,3 +174,89 @@ func TestCreateDownloaderFromURL_QBittorrentFallbackToAria2WhenEnabled(t *testin
 		t.Fatalf("downloader type = %T, want *aria2.Aria2Downloader", dl)
 	}
 }
+
+func TestCreateDownloaderFromURL_TransmissionConfigured(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{TransmissionURL: "http://localhost:9091/transmission/rpc"}
+	ctx := context.Background()
+
+	dl, err := CreateDownloaderFromURL(ctx, testMagnetURI, dir, cfg)
+	if err != nil {
+		t.Fatalf("CreateDownloaderFromURL: %v", err)
+	}
+	if _, ok := dl.(*transmission.TransmissionDownloader); !ok {
+		t.Fatalf("downloader type = %T, want *transmission.TransmissionDownloader", dl)
+	}
+}
+
+func TestCreateDownloaderFromURL_Aria2RPCConfigured(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{Aria2Settings: config.Aria2Config{RPCURL: "http://localhost:6800/jsonrpc"}}
+	ctx := context.Background()
+
+	dl, err := CreateDownloaderFromURL(ctx, testMagnetURI, dir, cfg)
+	if err != nil {
+		t.Fatalf("CreateDownloaderFromURL: %v", err)
+	}
+	if _, ok := dl.(*aria2.Aria2RPCDownloader); !ok {
+		t.Fatalf("downloader type = %T, want *aria2.Aria2RPCDownloader", dl)
+	}
+}
+
+func TestNewDownloaderFromFile_NZB(t *testing.T) {
+	dir := t.TempDir()
+	nzb := `<?xml version="1.0"?><nzb><head><meta type="name">Show.S01</meta></head>` +
+		`<file subject="a"><segments><segment bytes="10">x@y</segment></segments></file></nzb>`
+	if err := os.WriteFile(filepath.Join(dir, "nzb_test.nzb"), []byte(nzb), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewDownloaderFromFile("nzb_test.nzb", dir, &config.Config{}); err == nil {
+		t.Fatal("expected error without a Usenet client")
+	}
+	cfg := &config.Config{NZBGetURL: "http://localhost:6789"}
+	dl, err := NewDownloaderFromFile("nzb_test.nzb", dir, cfg)
+	if err != nil {
+		t.Fatalf("NewDownloaderFromFile: %v", err)
+	}
+	if _, ok := dl.(*usenet.UsenetDownloader); !ok {
+		t.Fatalf("expected *usenet.UsenetDownloader, got %T", dl)
+	}
+	if title, _ := dl.GetTitle(); title != "Show.S01" {
+		t.Errorf("title = %q, want Show.S01", title)
+	}
+}
+
+func TestCreateDownloaderFromReleaseURL(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/magnet", http.StatusFound)
+	})
+	mux.HandleFunc("/magnet", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Location", testMagnetURI)
+		w.WriteHeader(http.StatusFound)
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html>login required</html>"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	ctx := context.Background()
+	dl, err := CreateDownloaderFromReleaseURL(ctx, srv.URL+"/redirect", dir, &config.Config{})
+	if err != nil {
+		t.Fatalf("CreateDownloaderFromReleaseURL(redirect to magnet): %v", err)
+	}
+	if _, ok := dl.(*aria2.Aria2Downloader); !ok {
+		t.Fatalf("expected *aria2.Aria2Downloader, got %T", dl)
+	}
+
+	if _, err := CreateDownloaderFromReleaseURL(ctx, srv.URL+"/page", dir, &config.Config{}); err == nil {
+		t.Error("expected error for a web page, release links must not fall back to yt-dlp")
+	}
+	if _, err := CreateDownloaderFromReleaseURL(ctx, "ftp://example.com/a.torrent", dir, &config.Config{}); err == nil {
+		t.Error("expected error for unsupported scheme")
+	}
+}
@@ -286,6 +286,34 @@ func TestResumeDownloadQueuesWhenSlotsAreBusy(t *testing.T) {
 	}
 }
 
+// speedLimitedDownloader records the limits the manager passes to an aria2c-style downloader.
+type speedLimitedDownloader struct {
+	testutils.MockDownloader
+	download, upload int64
+}
+
+func (d *speedLimitedDownloader) SetSpeedLimits(download, upload int64) {
+	d.download, d.upload = download, upload
+}
+
+func TestResumeDownloadAppliesSpeedLimits(t *testing.T) {
+	cfg := testutils.TestConfig("/tmp")
+	cfg.DownloadSettings.MaxConcurrentDownloads = 2
+	cfg.VideoSettings.CompatibilityMode = false
+	dm := NewDownloadManager(cfg, testutils.TestDatabase(t))
+	dm.SetSpeedLimitSource(func() (download, upload int64) { return 4 << 20, 1 << 20 })
+
+	resumed := &speedLimitedDownloader{MockDownloader: testutils.MockDownloader{Title: "Resumed Movie"}}
+	errChan, err := dm.ResumeDownload(778, resumed, "Resumed Movie", 1, notifier.Noop)
+	if err != nil {
+		t.Fatalf("ResumeDownload: %v", err)
+	}
+	<-errChan
+	if resumed.download != 2<<20 || resumed.upload != 512<<10 {
+		t.Errorf("speed limits = %d/%d, want an equal share of 4 MiB/s and 1 MiB/s for 2 slots", resumed.download, resumed.upload)
+	}
+}
+
 // TestQueuedDownloadDoesNotCompleteImmediately verifies that adding a download
 // to the queue does NOT immediately signal completion (the bug we fixed).
 func TestQueuedDownloadDoesNotCompleteImmediately(t *testing.T) {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/testutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/tvcompat"
 )
 
 // conversionMockDB embeds DatabaseStub and records conversion-related DB calls.
@@ -56,13 +57,13 @@ func (m *conversionMockDB) UpdateConversionStatus(_ context.Context, movieID uin
 	return nil
 }
 
-func (m *conversionMockDB) UpdateConversionPercentage(_ context.Context, movieID uint, pct int) error {
+func (m *conversionMockDB) UpdateConversionPercentage(_ context.Context, movieID uint, progress database.ConversionProgress) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.UpdateConversionPctCalls = append(m.UpdateConversionPctCalls, struct {
 		MovieID uint
 		Pct     int
-	}{movieID, pct})
+	}{movieID, progress.Percentage})
 	return nil
 }
 
@@ -149,3 +150,23 @@ func TestConfigurationInheritance_CompatibilityMode(t *testing.T) {
 		t.Error("expected conversionQueue to be initialized when CompatibilityMode is true")
 	}
 }
+
+func TestConversionProgress_Throttled(t *testing.T) {
+	cfg := testutils.TestConfig("/tmp")
+	mockDB := &conversionMockDB{}
+	dm := NewDownloadManager(cfg, mockDB)
+	report := dm.conversionProgress(context.Background(), 7)
+
+	report(tvcompat.Progress{Percent: 0, File: "S01E01.mkv"})
+	report(tvcompat.Progress{Percent: 10, File: "S01E01.mkv", Speed: 2})
+	report(tvcompat.Progress{Percent: 10, File: "S01E01.mkv", Speed: 2.1})
+	report(tvcompat.Progress{Percent: 11, File: "S01E01.mkv", Speed: 2.1})
+
+	got := make([]int, 0, len(mockDB.UpdateConversionPctCalls))
+	for _, c := range mockDB.UpdateConversionPctCalls {
+		got = append(got, c.Pct)
+	}
+	if len(got) != 2 || got[0] != 10 || got[1] != 11 {
+		t.Errorf("persisted percentages = %v, want [10 11]", got)
+	}
+}
@@ -6,12 +6,16 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/archive"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/qbittorrent"
+	aria2 "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/torrent"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/transmission"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/notifier"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/seeding"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/tvcompat"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/utils"
 )
@@ -46,7 +50,7 @@ func (dm *DownloadManager) ResumeDownload(
 	case dm.semaphore <- struct{}{}:
 		return dm.resumeDownloadImmediately(movieID, dl, title, totalEpisodes, queueNotifier)
 	default:
-		_, _, outerErrChan := dm.addToQueue(movieID, dl, title, queueNotifier)
+		_, _, outerErrChan := dm.addToQueue(movieID, dl, title, time.Time{}, queueNotifier)
 		logutils.Log.WithFields(map[string]any{
 			"movie_id": movieID,
 			"title":    title,
@@ -64,6 +68,9 @@ func (dm *DownloadManager) resumeDownloadImmediately(
 ) (chan error, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	dm.attachSeedingPolicy(dl, movieID)
+	dm.attachSpeedLimits(dl)
+
 	progressChan, errChan, episodesChan, err := dl.StartDownload(ctx)
 	if err != nil {
 		cancel()
@@ -107,14 +114,75 @@ func (dm *DownloadManager) StartDownload(
 	outerErrChan chan error,
 	err error,
 ) {
-	movieTitle, err := dl.GetTitle()
+	movieID, movieTitle, err := dm.addMovie(dl)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	select {
+	case dm.semaphore <- struct{}{}:
+		return dm.startDownloadImmediately(movieID, dl, movieTitle, queueNotifier)
+	default:
+		queuedMovieID, progressChan, outerErrChan := dm.addToQueue(movieID, dl, movieTitle, time.Time{}, queueNotifier)
+		return queuedMovieID, progressChan, outerErrChan, nil
+	}
+}
+
+// ScheduleDownload stores the download and queues it to start no earlier than startAt.
+// source and options (encoded factory.StartOptions) are kept so the download can be queued again after a restart.
+func (dm *DownloadManager) ScheduleDownload(
+	dl downloader.Downloader,
+	startAt time.Time,
+	source, options string,
+	queueNotifier notifier.QueueNotifier,
+) (
+	movieID uint,
+	progressChan chan float64,
+	outerErrChan chan error,
+	err error,
+) {
+	movieID, movieTitle, err := dm.addMovie(dl)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	if err = dm.db.SetMovieSchedule(context.Background(), movieID, &startAt, source, options); err != nil {
+		dm.removeMovieRollback(context.Background(), movieID)
+		return 0, nil, nil, utils.WrapError(err, "Failed to save download start time", map[string]any{
+			"movie_id": movieID,
+			"title":    movieTitle,
+		})
+	}
+	logutils.Log.WithFields(map[string]any{
+		"movie_id": movieID,
+		"title":    movieTitle,
+		"start_at": startAt,
+	}).Info("Scheduled download")
+	movieID, progressChan, outerErrChan = dm.addToQueue(movieID, dl, movieTitle, startAt, queueNotifier)
+	return movieID, progressChan, outerErrChan, nil
+}
+
+// ResumeScheduledDownload queues an already stored scheduled download again (after a restart).
+func (dm *DownloadManager) ResumeScheduledDownload(
+	movieID uint,
+	dl downloader.Downloader,
+	title string,
+	startAt time.Time,
+	queueNotifier notifier.QueueNotifier,
+) chan error {
+	_, _, outerErrChan := dm.addToQueue(movieID, dl, title, startAt, queueNotifier)
+	return outerErrChan
+}
+
+// addMovie stores the movie and its files for a new download and returns its ID and title.
+func (dm *DownloadManager) addMovie(dl downloader.Downloader) (movieID uint, movieTitle string, err error) {
+	movieTitle, err = dl.GetTitle()
 	if err != nil {
-		return 0, nil, nil, utils.WrapError(err, "Failed to get download title", nil)
+		return 0, "", utils.WrapError(err, "Failed to get download title", nil)
 	}
 
 	movieFiles, tempFiles, err := dl.GetFiles()
 	if err != nil {
-		return 0, nil, nil, utils.WrapError(err, "Failed to get download files", nil)
+		return 0, "", utils.WrapError(err, "Failed to get download files", nil)
 	}
 
 	fileSize, err := dl.GetFileSize()
@@ -126,24 +194,30 @@ func (dm *DownloadManager) StartDownload(
 	totalEpisodes := dl.TotalEpisodes()
 	movieID, err = dm.db.AddMovie(context.Background(), movieTitle, fileSize, movieFiles, tempFiles, totalEpisodes)
 	if err != nil {
-		return 0, nil, nil, utils.WrapError(err, "Failed to add movie to database", map[string]any{
+		return 0, "", utils.WrapError(err, "Failed to add movie to database", map[string]any{
 			"title": movieTitle,
 		})
 	}
 
+	if hashed, ok := dl.(downloader.InfoHashDownloader); ok {
+		if infoHash := hashed.InfoHash(); infoHash != "" {
+			if hashErr := dm.db.SetMovieInfoHash(context.Background(), movieID, infoHash); hashErr != nil {
+				logutils.Log.WithError(hashErr).WithField("movie_id", movieID).Warn("Failed to save torrent info hash")
+			}
+		}
+	}
+	if resumable, ok := dl.(downloader.ResumableDownloader); ok {
+		if sourceErr := dm.db.SetMovieSource(context.Background(), movieID, resumable.SourceURL()); sourceErr != nil {
+			logutils.Log.WithError(sourceErr).WithField("movie_id", movieID).Warn("Failed to save download source")
+		}
+	}
+
 	logutils.Log.WithFields(map[string]any{
 		"movie_id":  movieID,
 		"title":     movieTitle,
 		"file_size": fileSize,
-	}).Info("Starting download")
-
-	select {
-	case dm.semaphore <- struct{}{}:
-		return dm.startDownloadImmediately(movieID, dl, movieTitle, queueNotifier)
-	default:
-		queuedMovieID, progressChan, outerErrChan := dm.addToQueue(movieID, dl, movieTitle, queueNotifier)
-		return queuedMovieID, progressChan, outerErrChan, nil
-	}
+	}).Info("Added download")
+	return movieID, movieTitle, nil
 }
 
 func (dm *DownloadManager) startDownloadImmediately(
@@ -157,13 +231,15 @@ func (dm *DownloadManager) startDownloadImmediately(
 	// Set hash callback BEFORE starting the download goroutine to avoid a data race
 	// where run() checks d.onHashKnown before the main goroutine sets it.
 	if setter, ok := dl.(downloader.OnHashKnownSetter); ok {
+		client := setter.TorrentClient()
 		setter.SetOnHashKnown(func(hash string) {
 			logutils.Log.WithFields(map[string]any{
 				"movie_id": movieID,
+				"client":   client,
 				"hash":     hash,
-			}).Info("Persisting qBittorrent hash to DB via onHashKnown callback")
-			if dbErr := dm.db.SetQBittorrentHash(context.Background(), movieID, hash); dbErr != nil {
-				logutils.Log.WithError(dbErr).WithField("movie_id", movieID).Warn("Failed to persist qBittorrent hash")
+			}).Info("Persisting torrent client hash to DB via onHashKnown callback")
+			if dbErr := dm.db.SetTorrentClientHash(context.Background(), movieID, client, hash); dbErr != nil {
+				logutils.Log.WithError(dbErr).WithField("movie_id", movieID).Warn("Failed to persist torrent client hash")
 			}
 		})
 	} else {
@@ -171,6 +247,8 @@ func (dm *DownloadManager) startDownloadImmediately(
 	}
 
 	dm.attachMagnetMetadataSync(dl, movieID)
+	dm.attachSeedingPolicy(dl, movieID)
+	dm.attachSpeedLimits(dl)
 
 	progressChan, errChan, episodesChan, err := dl.StartDownload(ctx)
 	if err != nil {
@@ -192,11 +270,13 @@ func (dm *DownloadManager) startDownloadImmediately(
 		}
 	}
 	// Fallback: also persist hash via channel (idempotent second persist).
-	if hd, ok := dl.(downloader.QBittorrentHashDownloader); ok {
+	hd, hasHashChan := dl.(downloader.QBittorrentHashDownloader)
+	setter, hasClient := dl.(downloader.OnHashKnownSetter)
+	if hasHashChan && hasClient {
 		if ch := hd.QBittorrentHashChan(); ch != nil {
 			go func() {
 				if hash, ok := <-ch; ok && hash != "" {
-					_ = dm.db.SetQBittorrentHash(context.Background(), movieID, hash)
+					_ = dm.db.SetTorrentClientHash(context.Background(), movieID, setter.TorrentClient(), hash)
 				}
 			}()
 		}
@@ -298,7 +378,7 @@ func (dm *DownloadManager) ResumePendingTVConversions(ctx context.Context) {
 		default:
 			continue
 		}
-		if m.TvCompatibility == tvcompat.TvCompatRed {
+		if m.TvCompatibility == tvcompat.TvCompatRed && !dm.transcodeIncompatible() {
 			if err := dm.db.UpdateConversionStatus(ctx, m.ID, "skipped"); err != nil {
 				logutils.Log.WithError(err).WithField("movie_id", m.ID).Warn("ResumePendingTVConversions: set skipped failed")
 			}
@@ -311,10 +391,9 @@ func (dm *DownloadManager) ResumePendingTVConversions(ctx context.Context) {
 	}
 }
 
+// RemoveQBittorrentTorrent removes the movie's torrent from the daemon that downloaded it (movie.TorrentClient).
+// Rows stored before the daemon was recorded go to the configured one.
 func (dm *DownloadManager) RemoveQBittorrentTorrent(ctx context.Context, movieID uint) error {
-	if dm.cfg.QBittorrentURL == "" {
-		return nil
-	}
 	movie, err := dm.db.GetMovieByID(ctx, movieID)
 	if err != nil {
 		logutils.Log.WithError(err).WithField("movie_id", movieID).Warn("RemoveQBittorrentTorrent: failed to get movie from DB")
@@ -322,30 +401,96 @@ func (dm *DownloadManager) RemoveQBittorrentTorrent(ctx context.Context, movieID
 	}
 	if movie.QBittorrentHash == "" {
 		logutils.Log.WithField("movie_id", movieID).
-			Warn("RemoveQBittorrentTorrent: no qBittorrent hash stored in DB, cannot remove torrent")
+			Debug("RemoveQBittorrentTorrent: no torrent client hash stored in DB, nothing to remove")
+		return nil
+	}
+	client := movie.TorrentClient
+	if client == "" {
+		client = dm.cfg.TorrentClient()
+	}
+	switch client {
+	case config.TorrentClientQBittorrent:
+		return dm.removeQBittorrentTorrent(ctx, &movie)
+	case config.TorrentClientTransmission:
+		return dm.removeTransmissionTorrent(ctx, &movie)
+	case config.TorrentClientAria2:
+		return dm.removeAria2RPCDownload(ctx, &movie)
+	default:
+		return nil
+	}
+}
+
+// removeQBittorrentTorrent deletes the torrent with the stored info hash from qBittorrent Web UI.
+func (dm *DownloadManager) removeQBittorrentTorrent(ctx context.Context, movie *database.Movie) error {
+	if dm.cfg.QBittorrentURL == "" {
+		logutils.Log.WithField("movie_id", movie.ID).Warn("Torrent is in qBittorrent, but QBITTORRENT_URL is no longer set")
 		return nil
 	}
 	logutils.Log.WithFields(map[string]any{
-		"movie_id": movieID,
+		"movie_id": movie.ID,
 		"hash":     movie.QBittorrentHash,
 	}).Info("RemoveQBittorrentTorrent: attempting to delete torrent")
 	client, err := qbittorrent.NewClient(dm.cfg.QBittorrentURL, dm.cfg.QBittorrentUsername, dm.cfg.QBittorrentPassword)
 	if err != nil {
-		logutils.Log.WithError(err).WithField("movie_id", movieID).Warn("Failed to create qBittorrent client for removal")
+		logutils.Log.WithError(err).WithField("movie_id", movie.ID).Warn("Failed to create qBittorrent client for removal")
 		return err
 	}
 	if err := client.Login(ctx); err != nil {
-		logutils.Log.WithError(err).WithField("movie_id", movieID).Warn("qBittorrent login failed for removal")
+		logutils.Log.WithError(err).WithField("movie_id", movie.ID).Warn("qBittorrent login failed for removal")
 		return err
 	}
 	if err := client.DeleteTorrent(ctx, movie.QBittorrentHash, false); err != nil {
 		logutils.Log.WithError(err).
-			WithField("movie_id", movieID).
+			WithField("movie_id", movie.ID).
 			WithField("hash", movie.QBittorrentHash).
 			Warn("Failed to delete torrent from qBittorrent")
 		return err
 	}
-	logutils.Log.WithField("movie_id", movieID).Info("Removed torrent from qBittorrent Web UI")
+	logutils.Log.WithField("movie_id", movie.ID).Info("Removed torrent from qBittorrent Web UI")
+	return nil
+}
+
+// removeTransmissionTorrent is the Transmission counterpart of removeQBittorrentTorrent; the stored
+// hash is the torrent info hash.
+func (dm *DownloadManager) removeTransmissionTorrent(ctx context.Context, movie *database.Movie) error {
+	if dm.cfg.TransmissionURL == "" {
+		logutils.Log.WithField("movie_id", movie.ID).Warn("Torrent is in Transmission, but TRANSMISSION_URL is no longer set")
+		return nil
+	}
+	client, err := transmission.NewClient(dm.cfg.TransmissionURL, dm.cfg.TransmissionUsername, dm.cfg.TransmissionPassword)
+	if err != nil {
+		return err
+	}
+	if err := client.RemoveTorrent(ctx, movie.QBittorrentHash, false); err != nil {
+		logutils.Log.WithError(err).
+			WithField("movie_id", movie.ID).
+			WithField("hash", movie.QBittorrentHash).
+			Warn("Failed to remove torrent from Transmission")
+		return err
+	}
+	logutils.Log.WithField("movie_id", movie.ID).Info("Removed torrent from Transmission")
+	return nil
+}
+
+// removeAria2RPCDownload removes the download from the ARIA2_RPC_URL daemon; the stored hash is the aria2 GID.
+func (dm *DownloadManager) removeAria2RPCDownload(ctx context.Context, movie *database.Movie) error {
+	aria2Cfg := dm.cfg.GetAria2Settings()
+	if aria2Cfg.RPCURL == "" {
+		logutils.Log.WithField("movie_id", movie.ID).Warn("Download is in aria2, but ARIA2_RPC_URL is no longer set")
+		return nil
+	}
+	client, err := aria2.NewRPCClient(aria2Cfg.RPCURL, aria2Cfg.RPCSecret)
+	if err != nil {
+		return err
+	}
+	if err := client.Remove(ctx, movie.QBittorrentHash); err != nil {
+		logutils.Log.WithError(err).
+			WithField("movie_id", movie.ID).
+			WithField("gid", movie.QBittorrentHash).
+			Warn("Failed to remove download from aria2")
+		return err
+	}
+	logutils.Log.WithField("movie_id", movie.ID).Info("Removed download from aria2")
 	return nil
 }
 
@@ -419,6 +564,66 @@ func (dm *DownloadManager) attachMagnetMetadataSync(dl downloader.Downloader, mo
 	})
 }
 
+// attachSeedingPolicy lets the qBittorrent downloader keep a completed torrent seeding when the movie's
+// seed toggle or the seeding policy asks for it; the seeding monitor removes it once the rule is met.
+func (dm *DownloadManager) attachSeedingPolicy(dl downloader.Downloader, movieID uint) {
+	sd, ok := dl.(downloader.SeedingDownloader)
+	if !ok {
+		return
+	}
+	sd.SetKeepSeeding(func(tracker string) bool {
+		ctx := context.Background()
+		movie, err := dm.db.GetMovieByID(ctx, movieID)
+		if err != nil {
+			logutils.Log.WithError(err).WithField("movie_id", movieID).Warn("Seeding: failed to get movie, not seeding")
+			return false
+		}
+		if !seeding.NewPolicy(dm.cfg.GetSeedingSettings()).Keep(movie.SeedMode, tracker) {
+			return false
+		}
+		if err := dm.db.UpdateSeedingState(ctx, movieID, true, 0); err != nil {
+			logutils.Log.WithError(err).WithField("movie_id", movieID).Warn("Seeding: failed to mark movie as seeding")
+			return false
+		}
+		return true
+	})
+}
+
+// SetSpeedLimitSource sets where downloads running their own aria2c process get the global speed limits
+// (bytes per second, 0 = unlimited) from. Each process gets an equal share of MAX_CONCURRENT_DOWNLOADS.
+func (dm *DownloadManager) SetSpeedLimitSource(limits func() (download, upload int64)) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.speedLimits = limits
+}
+
+func (dm *DownloadManager) attachSpeedLimits(dl downloader.Downloader) {
+	sl, ok := dl.(downloader.SpeedLimitedDownloader)
+	if !ok {
+		return
+	}
+	dm.mu.RLock()
+	limits := dm.speedLimits
+	dm.mu.RUnlock()
+	if limits == nil {
+		return
+	}
+	download, upload := limits()
+	if slots := int64(dm.downloadSettings.MaxConcurrentDownloads); slots > 1 {
+		download = shareOf(download, slots)
+		upload = shareOf(upload, slots)
+	}
+	sl.SetSpeedLimits(download, upload)
+}
+
+// shareOf splits a limit between slots, keeping at least 1 byte/s so a limit never turns into unlimited.
+func shareOf(limit, slots int64) int64 {
+	if limit <= 0 {
+		return limit
+	}
+	return max(limit/slots, 1)
+}
+
 // removeMovieRollback removes the movie and its file records from DB and deletes associated files from disk.
 // Used when StartDownload fails so the movie does not stay in the list.
 func (dm *DownloadManager) removeMovieRollback(ctx context.Context, movieID uint) {
@@ -454,8 +659,65 @@ func (dm *DownloadManager) removeMovieRollback(ctx context.Context, movieID uint
 	}
 }
 
+// extractArchivesIfNeeded runs after download completes, before the TV compatibility probe: releases shipped
+// as RAR/ZIP/7z sets are extracted so the probe, conversion and DLNA see the videos instead of the archives.
+// Progress is stored like conversion progress; a failed extraction keeps the archives and the download still completes.
+func (dm *DownloadManager) extractArchivesIfNeeded(ctx context.Context, movieID uint) {
+	if !dm.cfg.ArchiveExtraction {
+		return
+	}
+	paths, err := archive.MainFilePaths(ctx, movieID, dm.db)
+	if err != nil {
+		logutils.Log.WithError(err).WithField("movie_id", movieID).Warn("Archive extraction: failed to get files")
+		return
+	}
+	if len(archive.FindSets(paths)) == 0 {
+		return
+	}
+	logutils.Log.WithField("movie_id", movieID).Info("Extracting archives of completed download")
+	_ = dm.db.UpdateExtractionStatus(ctx, movieID, "in_progress")
+	_ = dm.db.UpdateExtractionPercentage(ctx, movieID, 0)
+
+	// A seeding torrent still needs its archives; the seeding monitor deletes them when seeding ends.
+	keepArchives := false
+	if movie, movieErr := dm.db.GetMovieByID(ctx, movieID); movieErr == nil {
+		keepArchives = movie.Seeding
+	}
+	extractCtx, cancel := context.WithTimeout(ctx, extractionTimeout)
+	defer cancel()
+	err = archive.ExtractMovieArchives(extractCtx, movieID, dm.cfg.MoviePath, dm.db, dm.cfg.SevenZipPath, keepArchives, func(percent int) {
+		if updateErr := dm.db.UpdateExtractionPercentage(ctx, movieID, percent); updateErr != nil {
+			logutils.Log.WithError(updateErr).WithField("movie_id", movieID).Debug("Failed to update extraction percentage")
+		}
+	})
+	if err != nil {
+		logutils.Log.WithError(err).WithField("movie_id", movieID).Error("Archive extraction failed, keeping the archives")
+		_ = dm.db.UpdateExtractionStatus(ctx, movieID, "failed")
+		return
+	}
+	_ = dm.db.UpdateExtractionStatus(ctx, movieID, "done")
+	if files, filesErr := archive.MainFilePaths(ctx, movieID, dm.db); filesErr == nil {
+		if videos := countVideoFiles(files); videos > 1 {
+			_ = dm.db.UpdateMovieTotalEpisodes(ctx, movieID, videos)
+			_ = dm.db.UpdateEpisodesProgress(ctx, movieID, videos)
+		}
+	}
+	logutils.Log.WithField("movie_id", movieID).Info("Archive extraction completed")
+}
+
+func countVideoFiles(paths []string) int {
+	var n int
+	for _, p := range paths {
+		if tvcompat.IsVideoFilePath(p) {
+			n++
+		}
+	}
+	return n
+}
+
 // enqueueConversionIfNeeded runs after download completes: probes TV compatibility, sets tv_compatibility,
-// and either marks as skipped (red) or enqueues for light conversion (green/yellow).
+// and enqueues a light conversion (green/yellow) or a full transcode (red, see transcodeIncompatible);
+// red videos that are not transcoded are marked as skipped.
 // Returns needWait, done channel, and compatRed (true if video is red / not playable on TV).
 func (dm *DownloadManager) enqueueConversionIfNeeded(
 	ctx context.Context,
@@ -479,14 +741,22 @@ func (dm *DownloadManager) enqueueConversionIfNeeded(
 		return false, nil, false
 	}
 	_ = dm.db.SetTvCompatibility(ctx, movieID, compat)
-	if compat == tvcompat.TvCompatRed {
+	compatRed = compat == tvcompat.TvCompatRed
+	if compatRed && !dm.transcodeIncompatible() {
 		_ = dm.db.UpdateConversionStatus(ctx, movieID, "skipped")
 		return false, nil, true
 	}
 	_ = dm.db.UpdateConversionStatus(ctx, movieID, "pending")
-	_ = dm.db.UpdateConversionPercentage(ctx, movieID, 0)
+	_ = dm.db.UpdateConversionPercentage(ctx, movieID, database.ConversionProgress{})
 	needWait, ch := dm.EnqueueConversion(movieID, title)
-	return needWait, ch, false
+	return needWait, ch, compatRed
+}
+
+// transcodeIncompatible reports whether red videos are re-encoded to H.264 by the conversion worker.
+// VIDEO_REJECT_INCOMPATIBLE wins: rejected downloads are never transcoded.
+func (dm *DownloadManager) transcodeIncompatible() bool {
+	vs := &dm.cfg.VideoSettings
+	return vs.CompatibilityMode && vs.TranscodeIncompatible && !vs.RejectIncompatible
 }
 
 // EnqueueConversion adds movieID to the conversion queue (no-op if compatibility mode is off).
@@ -507,6 +777,31 @@ func (dm *DownloadManager) EnqueueConversion(movieID uint, title string) (enqueu
 	}
 }
 
+// conversionProgress returns the progress callback of a conversion job: it stores the overall percentage
+// with the current file, speed and ETA, throttled like download progress.
+func (dm *DownloadManager) conversionProgress(ctx context.Context, movieID uint) func(tvcompat.Progress) {
+	lastPersisted := -1
+	var lastFlush time.Time
+	return func(p tvcompat.Progress) {
+		now := time.Now()
+		if !shouldPersistProgress(p.Percent, lastPersisted, lastFlush, now) {
+			return
+		}
+		err := dm.db.UpdateConversionPercentage(ctx, movieID, database.ConversionProgress{
+			Percentage: p.Percent,
+			File:       p.File,
+			Speed:      p.Speed,
+			ETA:        p.ETA,
+		})
+		if err != nil {
+			logutils.Log.WithError(err).WithField("movie_id", movieID).Debug("Failed to update conversion progress")
+			return
+		}
+		lastPersisted = p.Percent
+		lastFlush = now
+	}
+}
+
 func (dm *DownloadManager) runConversionWorker() {
 	vs := &dm.cfg.VideoSettings
 	for job := range dm.conversionQueue {
@@ -522,16 +817,38 @@ func (dm *DownloadManager) runConversionWorker() {
 				}
 			}()
 
-			jobCtx, cancelJob := context.WithTimeout(context.Background(), conversionJobTimeout)
+			movieID := j.MovieID
+			movie, _ := dm.db.GetMovieByID(context.Background(), movieID)
+			transcode := movie.TvCompatibility == tvcompat.TvCompatRed && dm.transcodeIncompatible()
+			timeout := conversionJobTimeout
+			if transcode {
+				timeout = transcodeJobTimeout
+			}
+			jobCtx, cancelJob := context.WithTimeout(context.Background(), timeout)
 			defer cancelJob()
 
-			movieID := j.MovieID
 			if err := dm.db.UpdateConversionStatus(jobCtx, movieID, "in_progress"); err != nil {
 				logutils.Log.WithError(err).WithField("movie_id", movieID).Warn("Failed to set conversion status")
 			}
-			movie, _ := dm.db.GetMovieByID(jobCtx, movieID)
-			if movie.TvCompatibility != tvcompat.TvCompatGreen {
-				tvcompat.RunTvCompatibility(jobCtx, movieID, dm.cfg.MoviePath, dm.db, vs)
+			progress := dm.conversionProgress(jobCtx, movieID)
+			switch {
+			case transcode:
+				// The original stays on disk while its torrent seeds; the seeding monitor deletes it afterwards.
+				err := tvcompat.TranscodeIncompatible(jobCtx, movieID, dm.cfg.MoviePath, dm.db, vs, movie.Seeding, progress)
+				if err != nil && jobCtx.Err() == nil {
+					logutils.Log.WithError(err).WithField("movie_id", movieID).Error("TV compatibility transcode failed")
+					if statusErr := dm.db.UpdateConversionStatus(jobCtx, movieID, "failed"); statusErr != nil {
+						logutils.Log.WithError(statusErr).WithField("movie_id", movieID).Warn("Failed to set conversion status failed")
+					}
+					return
+				}
+				if err == nil {
+					if compatErr := dm.db.SetTvCompatibility(jobCtx, movieID, tvcompat.TvCompatGreen); compatErr != nil {
+						logutils.Log.WithError(compatErr).WithField("movie_id", movieID).Warn("Failed to set TV compatibility")
+					}
+				}
+			case movie.TvCompatibility != tvcompat.TvCompatGreen:
+				tvcompat.RunTvCompatibility(jobCtx, movieID, dm.cfg.MoviePath, dm.db, vs, progress)
 			}
 			const completeConversionPct = 100
 			if jobCtx.Err() != nil {
@@ -541,7 +858,8 @@ func (dm *DownloadManager) runConversionWorker() {
 				logutils.Log.WithField("movie_id", movieID).Warn("TV compatibility conversion timed out or canceled")
 				return
 			}
-			if err := dm.db.UpdateConversionPercentage(jobCtx, movieID, completeConversionPct); err != nil {
+			complete := database.ConversionProgress{Percentage: completeConversionPct}
+			if err := dm.db.UpdateConversionPercentage(jobCtx, movieID, complete); err != nil {
 				logutils.Log.WithError(err).WithField("movie_id", movieID).Warn("Failed to set conversion percentage")
 			}
 			if err := dm.db.UpdateConversionStatus(jobCtx, movieID, "done"); err != nil {
@@ -125,6 +125,7 @@ func (dm *DownloadManager) monitorDownload(
 					"duration": time.Since(downloadStartTime),
 				}).Info("Download completed successfully")
 
+				dm.extractArchivesIfNeeded(context.Background(), movieID)
 				needWait, done, compatRed := dm.enqueueConversionIfNeeded(context.Background(), movieID, job.title)
 				if compatRed && dm.cfg.VideoSettings.RejectIncompatible {
 					job.queueNotifier.OnVideoNotSupported(movieID, job.title)
@@ -208,6 +209,7 @@ func (dm *DownloadManager) monitorDownload(
 			} else {
 				logutils.Log.WithField("movie_id", movieID).Info("Download completed successfully")
 
+				dm.extractArchivesIfNeeded(context.Background(), movieID)
 				needWait, done, compatRed := dm.enqueueConversionIfNeeded(context.Background(), movieID, job.title)
 				if compatRed && dm.cfg.VideoSettings.RejectIncompatible {
 					job.queueNotifier.OnVideoNotSupported(movieID, job.title)
@@ -1,6 +1,7 @@
 package manager
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -15,15 +16,16 @@ func (dm *DownloadManager) processQueue() {
 
 	for range ticker.C {
 		dm.queueMutex.Lock()
-		if len(dm.queue) == 0 {
+		next := dm.nextDueLocked(dm.getCurrentTime())
+		if next < 0 {
 			dm.queueMutex.Unlock()
 			continue
 		}
 
 		select {
 		case dm.semaphore <- struct{}{}:
-			queued := dm.queue[0]
-			dm.queue = dm.queue[1:]
+			queued := dm.queue[next]
+			dm.queue = append(dm.queue[:next], dm.queue[next+1:]...)
 			dm.queueMutex.Unlock()
 
 			dm.startQueuedDownload(&queued)
@@ -33,12 +35,28 @@ func (dm *DownloadManager) processQueue() {
 	}
 }
 
+// nextDueLocked returns the index of the first queued download whose start time has come, or -1.
+// Scheduled downloads wait without blocking the ones queued after them.
+func (dm *DownloadManager) nextDueLocked(now time.Time) int {
+	for i := range dm.queue {
+		if !now.Before(dm.queue[i].startAt) {
+			return i
+		}
+	}
+	return -1
+}
+
 func (dm *DownloadManager) startQueuedDownload(queued *queuedDownload) {
 	logutils.Log.WithFields(map[string]any{
 		"movie_id": queued.movieID,
 		"title":    queued.title,
 	}).Info("Starting queued download")
 
+	if !queued.startAt.IsZero() {
+		if err := dm.db.SetMovieSchedule(context.Background(), queued.movieID, nil, "", ""); err != nil {
+			logutils.Log.WithError(err).WithField("movie_id", queued.movieID).Warn("Failed to clear download start time")
+		}
+	}
 	queued.queueNotifier.OnStarted(queued.movieID, queued.title)
 
 	_, _, innerErrChan, err := dm.startDownloadImmediately(
@@ -90,6 +108,7 @@ func (dm *DownloadManager) addToQueue(
 	movieID uint,
 	dl downloader.Downloader,
 	movieTitle string,
+	startAt time.Time,
 	queueNotifier notifier.QueueNotifier,
 ) (movieIDOut uint, progressChan chan float64, outerErrChan chan error) {
 	// Create channels that will be used by the caller to track progress
@@ -103,6 +122,7 @@ func (dm *DownloadManager) addToQueue(
 		movieID:       movieID,
 		title:         movieTitle,
 		addedAt:       time.Now(),
+		startAt:       startAt,
 		queueNotifier: queueNotifier,
 		progressChan:  progressChan,
 		errChan:       outerErrChan,
@@ -116,7 +136,10 @@ func (dm *DownloadManager) addToQueue(
 		"position": queuePosition,
 	}).Info("Added download to queue")
 
-	queueNotifier.OnQueued(movieID, movieTitle, queuePosition, dm.downloadSettings.MaxConcurrentDownloads)
+	// Scheduled downloads are announced by the caller with their start time instead.
+	if startAt.IsZero() {
+		queueNotifier.OnQueued(movieID, movieTitle, queuePosition, dm.downloadSettings.MaxConcurrentDownloads)
+	}
 
 	// Start a goroutine to monitor queue timeout only
 	// Note: We do NOT close channels here - they will be closed by startQueuedDownload
@@ -126,7 +149,8 @@ func (dm *DownloadManager) addToQueue(
 			return
 		}
 
-		timeoutTimer := time.NewTimer(dm.downloadSettings.DownloadTimeout)
+		// A scheduled download starts counting at its start time.
+		timeoutTimer := time.NewTimer(dm.downloadSettings.DownloadTimeout + max(time.Until(startAt), 0))
 		defer timeoutTimer.Stop()
 
 		<-timeoutTimer.C
@@ -197,6 +221,9 @@ func (dm *DownloadManager) GetQueueItems() []map[string]any {
 			"position": i + 1,
 			"added_at": item.addedAt,
 		}
+		if !item.startAt.IsZero() {
+			items[i]["start_at"] = item.startAt
+		}
 	}
 	return items
 }
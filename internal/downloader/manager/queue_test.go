@@ -272,3 +272,34 @@ func TestGetTotalDownloads_Mixed(t *testing.T) {
 		t.Errorf("GetTotalDownloads() = %d, want 5 (2 active + 3 queued)", total)
 	}
 }
+
+func TestNextDueSkipsScheduledDownloads(t *testing.T) {
+	dm := newQueueTestManager(t)
+
+	now := time.Now()
+	dm.queueMutex.Lock()
+	dm.queue = append(dm.queue,
+		queuedDownload{movieID: 1, title: "Tonight", startAt: now.Add(time.Hour), queueNotifier: notifier.Noop},
+		queuedDownload{movieID: 2, title: "Now", queueNotifier: notifier.Noop},
+	)
+	next := dm.nextDueLocked(now)
+	dm.queueMutex.Unlock()
+	if next != 1 {
+		t.Fatalf("nextDueLocked() = %d, want 1 (the scheduled item must not block the queue)", next)
+	}
+
+	dm.queueMutex.Lock()
+	dm.queue = dm.queue[:1]
+	if next = dm.nextDueLocked(now); next != -1 {
+		t.Errorf("nextDueLocked() = %d, want -1 before the start time", next)
+	}
+	if next = dm.nextDueLocked(now.Add(time.Hour)); next != 0 {
+		t.Errorf("nextDueLocked() = %d, want 0 at the start time", next)
+	}
+	dm.queueMutex.Unlock()
+
+	items := dm.GetQueueItems()
+	if startAt, ok := items[0]["start_at"].(time.Time); !ok || !startAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("queue item start_at = %v, want the scheduled time", items[0]["start_at"])
+	}
+}
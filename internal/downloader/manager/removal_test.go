@@ -0,0 +1,86 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/testutils"
+)
+
+// daemonServer emulates qBittorrent (/api/v2/...) and the aria2 JSON-RPC endpoint (/jsonrpc) and records
+// the qBittorrent paths and aria2 methods it receives.
+func daemonServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := r.URL.Path
+		if r.URL.Path == "/jsonrpc" {
+			var req struct {
+				ID     string `json:"id"`
+				Method string `json:"method"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			call = req.Method
+			defer func() { _ = json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "result": "OK"}) }()
+		}
+		mu.Lock()
+		calls = append(calls, call)
+		mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(calls)
+	}
+}
+
+func TestRemoveTorrentUsesStoredClient(t *testing.T) {
+	logutils.InitLogger("error")
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		client string
+		want   []string
+	}{
+		{"aria2 fallback while qBittorrent is configured", config.TorrentClientAria2,
+			[]string{"aria2.forceRemove", "aria2.removeDownloadResult"}},
+		{"qBittorrent", config.TorrentClientQBittorrent, []string{"/api/v2/auth/login", "/api/v2/torrents/delete"}},
+		{"row without a stored client", "", []string{"/api/v2/auth/login", "/api/v2/torrents/delete"}},
+		{"Transmission is no longer configured", config.TorrentClientTransmission, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls := daemonServer(t)
+			cfg := testutils.TestConfig(testutils.TempDir(t))
+			cfg.QBittorrentURL = srv.URL
+			cfg.Aria2Settings.RPCURL = srv.URL + "/jsonrpc"
+			db := testutils.TestDatabase(t)
+			movieID, err := db.AddMovie(ctx, "Movie", 1024, []string{"movie.mkv"}, nil, 0)
+			if err != nil {
+				t.Fatalf("AddMovie: %v", err)
+			}
+			if err := db.SetTorrentClientHash(ctx, movieID, tt.client, "0123456789abcdef"); err != nil {
+				t.Fatalf("SetTorrentClientHash: %v", err)
+			}
+
+			if err := NewDownloadManager(cfg, db).RemoveQBittorrentTorrent(ctx, movieID); err != nil {
+				t.Fatalf("RemoveQBittorrentTorrent: %v", err)
+			}
+			if got := calls(); !slices.Equal(got, tt.want) {
+				t.Errorf("daemon calls = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package manager
+
+import (
+	"context"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+)
+
+const fullPercentage = 100
+
+// ReservedBytes returns the disk space still needed by active and queued downloads: for each item,
+// the declared size minus the part already written (estimated from the stored download progress).
+// Items with unknown size (e.g. magnet before metadata) reserve nothing.
+func (dm *DownloadManager) ReservedBytes(ctx context.Context) int64 {
+	ids := dm.GetActiveDownloads()
+	dm.queueMutex.Lock()
+	for i := range dm.queue {
+		ids = append(ids, dm.queue[i].movieID)
+	}
+	dm.queueMutex.Unlock()
+
+	var reserved int64
+	seen := make(map[uint]struct{}, len(ids))
+	for _, movieID := range ids {
+		if _, ok := seen[movieID]; ok {
+			continue
+		}
+		seen[movieID] = struct{}{}
+		movie, err := dm.db.GetMovieByID(ctx, movieID)
+		if err != nil {
+			logutils.Log.WithError(err).WithField("movie_id", movieID).Debug("ReservedBytes: movie not found")
+			continue
+		}
+		reserved += remainingBytes(&movie)
+	}
+	return reserved
+}
+
+func remainingBytes(movie *database.Movie) int64 {
+	if movie.FileSize <= 0 {
+		return 0
+	}
+	percentage := min(max(movie.DownloadedPercentage, 0), fullPercentage)
+	return movie.FileSize - movie.FileSize*int64(percentage)/fullPercentage
+}
@@ -0,0 +1,53 @@
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/notifier"
+)
+
+func TestReservedBytes_ActiveAndQueued(t *testing.T) {
+	dm := newQueueTestManager(t)
+	ctx := context.Background()
+
+	const size = 1000
+	activeID, err := dm.db.AddMovie(ctx, "Active", size, []string{"active.mkv"}, nil, 0)
+	if err != nil {
+		t.Fatalf("AddMovie: %v", err)
+	}
+	if err := dm.db.UpdateDownloadedPercentage(ctx, activeID, 40); err != nil {
+		t.Fatalf("UpdateDownloadedPercentage: %v", err)
+	}
+	queuedID, err := dm.db.AddMovie(ctx, "Queued", size, []string{"queued.mkv"}, nil, 0)
+	if err != nil {
+		t.Fatalf("AddMovie: %v", err)
+	}
+	unknownID, err := dm.db.AddMovie(ctx, "Magnet", 0, []string{"magnet.mkv"}, nil, 0)
+	if err != nil {
+		t.Fatalf("AddMovie: %v", err)
+	}
+
+	dm.mu.Lock()
+	dm.jobs[activeID] = &downloadJob{}
+	dm.mu.Unlock()
+	dm.queueMutex.Lock()
+	dm.queue = append(dm.queue,
+		queuedDownload{movieID: queuedID, title: "Queued", addedAt: time.Now(), queueNotifier: notifier.Noop},
+		queuedDownload{movieID: unknownID, title: "Magnet", addedAt: time.Now(), queueNotifier: notifier.Noop},
+	)
+	dm.queueMutex.Unlock()
+
+	// 60% of the active item is still to be written, the queued one is reserved in full, unknown size reserves nothing.
+	if got, want := dm.ReservedBytes(ctx), int64(600+size); got != want {
+		t.Errorf("ReservedBytes() = %d, want %d", got, want)
+	}
+}
+
+func TestReservedBytes_Empty(t *testing.T) {
+	dm := newQueueTestManager(t)
+	if got := dm.ReservedBytes(context.Background()); got != 0 {
+		t.Errorf("ReservedBytes() = %d, want 0", got)
+	}
+}
@@ -21,8 +21,12 @@ const (
 	ProgressChannelBuffSize = 100
 	// conversionJobTimeout bounds ffprobe/ffmpeg work so the download monitor cannot block on <-done forever.
 	conversionJobTimeout = 2 * time.Hour
+	// transcodeJobTimeout bounds the full H.264 transcode of red videos, which is far slower than a remux.
+	transcodeJobTimeout = 12 * time.Hour
 	// compatibilityProbeTimeout limits ffprobe in enqueueConversionIfNeeded (runs inside the download monitor).
 	compatibilityProbeTimeout = 5 * time.Minute
+	// extractionTimeout bounds archive extraction in extractArchivesIfNeeded (runs inside the download monitor).
+	extractionTimeout = 2 * time.Hour
 )
 
 // Service defines the external interface for the download manager.
@@ -38,17 +42,36 @@ type Service interface {
 		totalEpisodes int,
 		queueNotifier notifier.QueueNotifier,
 	) (chan error, error)
+	// ScheduleDownload adds the movie like StartDownload but keeps it in the queue until startAt.
+	// source is the link to recreate the downloader after a restart ("" for .torrent/.nzb files), options the
+	// encoded factory.StartOptions to apply to it.
+	ScheduleDownload(
+		dl downloader.Downloader,
+		startAt time.Time,
+		source, options string,
+		queueNotifier notifier.QueueNotifier,
+	) (uint, chan float64, chan error, error)
+	// ResumeScheduledDownload puts a scheduled movie from the DB back in the queue after a restart.
+	ResumeScheduledDownload(
+		movieID uint,
+		dl downloader.Downloader,
+		title string,
+		startAt time.Time,
+		queueNotifier notifier.QueueNotifier,
+	) chan error
 	StopDownload(movieID uint) error
 	// StopDownloadSilent stops the download without triggering "download stopped" user notification (e.g. when stopping from deletion queue).
 	StopDownloadSilent(movieID uint) error
 	StopAllDownloads()
 	GetActiveDownloads() []uint
 	GetQueueItems() []map[string]any
-	// RemoveQBittorrentTorrent removes the torrent from qBittorrent Web UI by movie ID (looks up hash in DB).
-	// No-op if not qBittorrent or hash missing.
+	// RemoveQBittorrentTorrent removes the torrent from the daemon that downloaded it (qBittorrent Web UI, Transmission
+	// or aria2 RPC) by movie ID (looks up hash and client in DB). No-op if that daemon is not configured or hash missing.
 	RemoveQBittorrentTorrent(ctx context.Context, movieID uint) error
 	// ResumePendingTVConversions re-enqueues TV compatibility jobs left pending after a crash or stuck pipeline.
 	ResumePendingTVConversions(ctx context.Context)
+	// ReservedBytes is the space still to be written by active and queued downloads.
+	ReservedBytes(ctx context.Context) int64
 }
 
 // conversionJob is sent to the conversion worker; Done is closed when conversion (or skip) is finished.
@@ -68,6 +91,7 @@ type DownloadManager struct {
 	db               database.Database
 	cfg              *config.Config
 	conversionQueue  chan conversionJob
+	speedLimits      func() (download, upload int64) // current global limits for per-download aria2c processes
 }
 
 type downloadJob struct {
@@ -90,6 +114,7 @@ type queuedDownload struct {
 	movieID       uint
 	title         string
 	addedAt       time.Time
+	startAt       time.Time // not before this time; zero = as soon as a slot is free
 	queueNotifier notifier.QueueNotifier
 	progressChan  chan float64 // Channel to forward progress to the caller
 	errChan       chan error   // Channel to forward errors to the caller
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -219,6 +220,9 @@ type TorrentInfo struct {
 	AddedOn     int64   `json:"added_on"`
 	SavePath    string  `json:"save_path"`
 	ContentPath string  `json:"content_path"`
+	Ratio       float64 `json:"ratio"`        // uploaded / downloaded
+	SeedingTime int64   `json:"seeding_time"` // seconds spent seeding after completion
+	Tracker     string  `json:"tracker"`      // current working tracker URL; empty when none is working
 }
 
 // TorrentsInfo returns torrent list. sortOrder: "asc" or "desc". sortBy: e.g. "added_on".
@@ -354,3 +358,34 @@ func (c *Client) DeleteTorrent(ctx context.Context, hash string, deleteFiles boo
 	}
 	return nil
 }
+
+// SetSpeedLimits sets the global download and upload limits in bytes per second; 0 removes a limit.
+func (c *Client) SetSpeedLimits(ctx context.Context, download, upload int64) error {
+	if err := c.setTransferLimit(ctx, "/transfer/setDownloadLimit", download); err != nil {
+		return err
+	}
+	return c.setTransferLimit(ctx, "/transfer/setUploadLimit", upload)
+}
+
+func (c *Client) setTransferLimit(ctx context.Context, endpoint string, limit int64) error {
+	u := c.baseURL + apiPrefix + endpoint
+	form := url.Values{}
+	form.Set("limit", strconv.FormatInt(limit, 10))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Referer", c.baseURL+"/")
+	// #nosec G704 -- baseURL from config
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("qBittorrent: %s failed status=%d body=%s", endpoint, resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -123,3 +123,35 @@ func TestClientLoginConnectionRefused(t *testing.T) {
 		t.Fatal("Login succeeded, want connection error")
 	}
 }
+
+func TestClientSetSpeedLimits(t *testing.T) {
+	t.Parallel()
+	limits := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/transfer/setDownloadLimit", "/api/v2/transfer/setUploadLimit":
+			if err := r.ParseForm(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			limits[r.URL.Path] = r.PostForm.Get("limit")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "admin", "adminadmin")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := client.SetSpeedLimits(context.Background(), 2<<20, 0); err != nil {
+		t.Fatalf("SetSpeedLimits: %v", err)
+	}
+	if got := limits["/api/v2/transfer/setDownloadLimit"]; got != "2097152" {
+		t.Errorf("download limit = %q, want 2097152", got)
+	}
+	if got := limits["/api/v2/transfer/setUploadLimit"]; got != "0" {
+		t.Errorf("upload limit = %q, want 0", got)
+	}
+}
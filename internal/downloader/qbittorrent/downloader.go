@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -54,6 +55,8 @@ type QBittorrentDownloader struct {
 	totalEpisodesStored      int    // for resume: total episode count when no torrent file (from DB)
 	onMagnetMetadata         func(paths []string, totalBytes int64, videoFileCount int)
 	magnetDBSynced           bool // true after first successful torrents/files sync to DB (magnet only)
+	selection                aria2pkg.FileSelection
+	keepSeeding              func(tracker string) bool // optional; true keeps the completed torrent in qBittorrent to seed
 }
 
 // NewQBittorrentDownloader creates a downloader that uses qBittorrent.
@@ -147,20 +150,25 @@ func (d *QBittorrentDownloader) GetFiles() (mainFiles, tempFiles []string, err e
 	if err != nil {
 		return nil, nil, err
 	}
+	tempFiles = []string{d.torrentFileName}
 	if len(meta.Info.Files) > 0 {
-		for _, file := range meta.Info.Files {
+		for i, file := range meta.Info.Files {
 			if meta.Info.Name == "" {
 				return nil, nil, fmt.Errorf("torrent meta does not contain a root directory name")
 			}
 			if len(file.Path) == 0 {
 				return nil, nil, fmt.Errorf("file path is empty in torrent meta")
 			}
-			mainFiles = append(mainFiles, filepath.Join(meta.Info.Name, filepath.Join(file.Path...)))
+			// Pieces shared with a skipped file can still create it on disk; it is removed as a temp file.
+			if d.selection.Includes(i + 1) {
+				mainFiles = append(mainFiles, meta.FilePath(i))
+			} else {
+				tempFiles = append(tempFiles, meta.FilePath(i))
+			}
 		}
 	} else {
 		mainFiles = append(mainFiles, meta.Info.Name)
 	}
-	tempFiles = []string{d.torrentFileName}
 	return mainFiles, tempFiles, nil
 }
 
@@ -175,8 +183,10 @@ func (d *QBittorrentDownloader) GetFileSize() (int64, error) {
 	}
 	if len(meta.Info.Files) > 0 {
 		var total int64
-		for _, f := range meta.Info.Files {
-			total += f.Length
+		for i, f := range meta.Info.Files {
+			if d.selection.Includes(i + 1) {
+				total += f.Length
+			}
 		}
 		return total, nil
 	}
@@ -193,8 +203,7 @@ func (d *QBittorrentDownloader) TotalEpisodes() int {
 	}
 	var n int
 	for i := range meta.Info.Files {
-		path := filepath.Join(meta.Info.Name, filepath.Join(meta.Info.Files[i].Path...))
-		if tvcompat.IsVideoFilePath(path) {
+		if d.selection.Includes(i+1) && tvcompat.IsVideoFilePath(meta.FilePath(i)) {
 			n++
 		}
 	}
@@ -257,6 +266,16 @@ func (d *QBittorrentDownloader) SetOnHashKnown(cb func(hash string)) {
 	d.onHashKnown = cb
 }
 
+// TorrentClient implements downloader.OnHashKnownSetter.
+func (*QBittorrentDownloader) TorrentClient() string {
+	return config.TorrentClientQBittorrent
+}
+
+// SetKeepSeeding implements downloader.SeedingDownloader.
+func (d *QBittorrentDownloader) SetKeepSeeding(keep func(tracker string) bool) {
+	d.keepSeeding = keep
+}
+
 // SetOnMagnetMetadataReady implements downloader.MagnetMetadataSyncSetter.
 func (d *QBittorrentDownloader) SetOnMagnetMetadataReady(cb func(paths []string, totalBytes int64, videoFileCount int)) {
 	d.onMagnetMetadata = cb
@@ -360,6 +379,9 @@ func (d *QBittorrentDownloader) run(
 			d.hashChan = nil
 		}
 
+		if d.selection != nil {
+			d.skipDeselectedFiles(ctx, our.Hash)
+		}
 		if totalVideo > 1 {
 			d.applyLexicographicPriorities(ctx, our.Hash)
 		}
@@ -505,7 +527,11 @@ func (d *QBittorrentDownloader) run(
 					return
 				}
 			}
-			d.removeTorrentOnCompletion(our.Hash)
+			if d.keepSeeding != nil && d.keepSeeding(t.Tracker) {
+				logutils.Log.WithField("hash", our.Hash).Info("Keeping completed torrent in qBittorrent for seeding")
+			} else {
+				d.removeTorrentOnCompletion(our.Hash)
+			}
 			errChan <- nil
 			return
 		}
@@ -562,7 +588,7 @@ func countVideoPathsInTorrentFiles(files []TorrentFileInfo) int {
 func countCompletedVideoFiles(files []TorrentFileInfo) int {
 	n := 0
 	for _, f := range files {
-		if tvcompat.IsVideoFilePath(f.Name) && f.Progress >= 1.0 {
+		if f.Priority != priorityDoNotDownload && tvcompat.IsVideoFilePath(f.Name) && f.Progress >= 1.0 {
 			n++
 		}
 	}
@@ -570,9 +596,10 @@ func countCompletedVideoFiles(files []TorrentFileInfo) int {
 }
 
 const (
-	priorityNormal  = 1
-	priorityHigh    = 6
-	priorityMaximal = 7
+	priorityDoNotDownload = 0
+	priorityNormal        = 1
+	priorityHigh          = 6
+	priorityMaximal       = 7
 )
 
 // applyLexicographicPriorities sets file download priorities so that earlier
@@ -593,7 +620,7 @@ func (d *QBittorrentDownloader) applyLexicographicPriorities(ctx context.Context
 	}
 	var videos []videoEntry
 	for _, f := range files {
-		if tvcompat.IsVideoFilePath(f.Name) {
+		if d.selection.Includes(f.Index+1) && tvcompat.IsVideoFilePath(f.Name) {
 			videos = append(videos, videoEntry{index: f.Index, name: f.Name})
 		}
 	}
@@ -622,6 +649,66 @@ func (d *QBittorrentDownloader) applyLexicographicPriorities(ctx context.Context
 	}).Info("Applied lexicographic file priorities for series")
 }
 
+// skipDeselectedFiles gives the files left out by SelectFiles priority 0 so qBittorrent does not download them.
+func (d *QBittorrentDownloader) skipDeselectedFiles(ctx context.Context, hash string) {
+	files, err := d.client.TorrentFiles(ctx, hash)
+	if err != nil {
+		logutils.Log.WithError(err).Warn("Failed to get torrent files for file selection")
+		return
+	}
+	var ids []string
+	for _, f := range files {
+		if !d.selection.Includes(f.Index + 1) {
+			ids = append(ids, strconv.Itoa(f.Index))
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	if err := d.client.SetFilePriority(ctx, hash, strings.Join(ids, "|"), priorityDoNotDownload); err != nil {
+		logutils.Log.WithError(err).WithField("hash", hash).Warn("Failed to skip deselected files")
+		return
+	}
+	logutils.Log.WithFields(map[string]any{"hash": hash, "skipped_files": len(ids)}).Info("Skipped deselected torrent files")
+}
+
+// TorrentFiles implements downloader.FileSelector.
+func (d *QBittorrentDownloader) TorrentFiles() []downloader.TorrentFile {
+	if d.resumeHash != "" || d.magnetURI != "" {
+		return nil
+	}
+	meta, err := d.parseMeta()
+	if err != nil {
+		return nil
+	}
+	return meta.TorrentFiles()
+}
+
+// InfoHash implements downloader.InfoHashDownloader.
+func (d *QBittorrentDownloader) InfoHash() string {
+	if d.resumeHash != "" {
+		return ""
+	}
+	return aria2pkg.SourceInfoHash(filepath.Join(d.downloadDir, d.torrentFileName), d.magnetURI)
+}
+
+// SelectFiles implements downloader.FileSelector; deselected files get priority 0 once the torrent is added.
+func (d *QBittorrentDownloader) SelectFiles(indices []int) error {
+	if d.resumeHash != "" {
+		return fmt.Errorf("resume downloader has no torrent meta")
+	}
+	meta, err := d.parseMeta()
+	if err != nil {
+		return err
+	}
+	sel, err := aria2pkg.NewFileSelection(meta, indices)
+	if err != nil {
+		return err
+	}
+	d.selection = sel
+	return nil
+}
+
 func (d *QBittorrentDownloader) StopDownload() error {
 	d.mu.Lock()
 	d.stoppedManually = true
@@ -663,4 +750,7 @@ var (
 	_ downloader.QBittorrentHashDownloader = (*QBittorrentDownloader)(nil)
 	_ downloader.OnHashKnownSetter         = (*QBittorrentDownloader)(nil)
 	_ downloader.MagnetMetadataSyncSetter  = (*QBittorrentDownloader)(nil)
+	_ downloader.FileSelector              = (*QBittorrentDownloader)(nil)
+	_ downloader.InfoHashDownloader        = (*QBittorrentDownloader)(nil)
+	_ downloader.SeedingDownloader         = (*QBittorrentDownloader)(nil)
 )
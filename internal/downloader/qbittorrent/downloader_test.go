@@ -77,3 +77,15 @@ func TestEpisodesChanCapacity(t *testing.T) {
 		t.Fatalf("episodesChanCapacity(10) = %d, want 10", got)
 	}
 }
+
+func TestCountCompletedVideoFilesSkipsDeselected(t *testing.T) {
+	t.Parallel()
+	n := countCompletedVideoFiles([]TorrentFileInfo{
+		{Name: "S01E01.mkv", Progress: 1, Priority: priorityNormal},
+		{Name: "Sample.mkv", Progress: 1, Priority: priorityDoNotDownload},
+		{Name: "S01E02.mkv", Progress: 0.5, Priority: priorityNormal},
+	})
+	if n != 1 {
+		t.Fatalf("count = %d, want 1", n)
+	}
+}
@@ -0,0 +1,165 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	tmsutils "github.com/NikitaDmitryuk/telegram-media-server/internal/utils"
+)
+
+const (
+	partSuffix       = ".part"
+	progressInterval = time.Second
+	fullPercentage   = 100
+)
+
+// FileFetcher streams a Telegram file into MOVIE_PATH; bot.Service implements it.
+type FileFetcher interface {
+	DownloadFile(ctx context.Context, fileID, fileName string, progress func(written int64)) error
+}
+
+// File is a video or document sent (or forwarded) to the bot.
+type File struct {
+	ID   string
+	Name string // original file name, including the extension
+	Size int64
+}
+
+// FileDownloader saves a Telegram video or document into MOVIE_PATH.
+type FileDownloader struct {
+	fetcher         FileFetcher
+	file            File
+	moviePath       string
+	fileName        string
+	title           string
+	mu              sync.Mutex
+	cancel          context.CancelFunc
+	stoppedManually bool
+}
+
+func NewFileDownloader(fetcher FileFetcher, file File, moviePath string) downloader.Downloader {
+	ext := filepath.Ext(file.Name)
+	title := strings.TrimSuffix(file.Name, ext)
+	if title == "" {
+		title = "telegram_video"
+	}
+	return &FileDownloader{
+		fetcher:   fetcher,
+		file:      file,
+		moviePath: moviePath,
+		fileName:  tmsutils.SanitizeFileName(title) + strings.ToLower(ext),
+		title:     title,
+	}
+}
+
+func (d *FileDownloader) GetTitle() (string, error) {
+	return d.title, nil
+}
+
+func (d *FileDownloader) GetFiles() (mainFiles, tempFiles []string, err error) {
+	return []string{d.fileName}, []string{d.fileName + partSuffix}, nil
+}
+
+func (d *FileDownloader) GetFileSize() (int64, error) {
+	return d.file.Size, nil
+}
+
+func (*FileDownloader) TotalEpisodes() int { return 0 }
+
+func (d *FileDownloader) StoppedManually() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stoppedManually
+}
+
+func (d *FileDownloader) StopDownload() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stoppedManually = true
+	if d.cancel != nil {
+		logutils.Log.WithField("file", d.fileName).Info("Canceling Telegram file download")
+		d.cancel()
+	}
+	return nil
+}
+
+func (d *FileDownloader) StartDownload(
+	ctx context.Context,
+) (progressChan chan float64, errChan chan error, episodesChan <-chan int, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	d.mu.Lock()
+	d.cancel = cancel
+	d.mu.Unlock()
+
+	progressChan = make(chan float64)
+	errChan = make(chan error, 1)
+	go d.run(ctx, cancel, progressChan, errChan)
+	return progressChan, errChan, nil, nil
+}
+
+func (d *FileDownloader) run(ctx context.Context, cancel context.CancelFunc, progressChan chan float64, errChan chan error) {
+	defer cancel()
+	defer close(progressChan)
+	defer close(errChan)
+
+	partName := d.fileName + partSuffix
+	var written atomic.Int64
+	done := make(chan error, 1)
+	go func() {
+		done <- d.fetcher.DownloadFile(ctx, d.file.ID, partName, func(n int64) { written.Store(n) })
+	}()
+
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+
+	var downloadErr error
+wait:
+	for {
+		select {
+		case downloadErr = <-done:
+			break wait
+		case <-ticker.C:
+			if d.file.Size > 0 {
+				select {
+				case progressChan <- float64(written.Load()) * fullPercentage / float64(d.file.Size):
+				case <-ctx.Done():
+				}
+			}
+		}
+	}
+
+	if downloadErr != nil {
+		if d.StoppedManually() {
+			logutils.Log.WithField("file", d.fileName).Info("Telegram file download stopped manually")
+			errChan <- downloader.ErrStoppedByUser
+			return
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			errChan <- ctxErr
+			return
+		}
+		logutils.Log.WithError(downloadErr).WithField("file", d.fileName).Error("Telegram file download failed")
+		errChan <- fmt.Errorf("telegram file download failed: %w", downloadErr)
+		return
+	}
+
+	if err := os.Rename(filepath.Join(d.moviePath, partName), filepath.Join(d.moviePath, d.fileName)); err != nil {
+		errChan <- fmt.Errorf("move downloaded file: %w", err)
+		return
+	}
+	select {
+	case progressChan <- fullPercentage:
+	case <-ctx.Done():
+	}
+	errChan <- nil
+}
+
+var _ downloader.Downloader = (*FileDownloader)(nil)
@@ -0,0 +1,114 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+)
+
+func TestMain(m *testing.M) {
+	logutils.InitLogger("error")
+	os.Exit(m.Run())
+}
+
+// fakeFetcher writes content into moviePath, or blocks until the context is canceled when block is set.
+type fakeFetcher struct {
+	moviePath string
+	content   []byte
+	block     bool
+	fileID    string
+}
+
+func (f *fakeFetcher) DownloadFile(ctx context.Context, fileID, fileName string, progress func(written int64)) error {
+	f.fileID = fileID
+	if f.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if err := os.WriteFile(filepath.Join(f.moviePath, fileName), f.content, 0o600); err != nil {
+		return err
+	}
+	progress(int64(len(f.content)))
+	return nil
+}
+
+// drain reads both channels until they are closed and returns the final error.
+func drain(t *testing.T, progressChan chan float64, errChan chan error) error {
+	t.Helper()
+	var err error
+	timeout := time.After(5 * time.Second)
+	for progressChan != nil || errChan != nil {
+		select {
+		case _, ok := <-progressChan:
+			if !ok {
+				progressChan = nil
+			}
+		case e, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			err = e
+		case <-timeout:
+			t.Fatal("download did not finish")
+		}
+	}
+	return err
+}
+
+func TestFileDownloader_SavesFile(t *testing.T) {
+	dir := t.TempDir()
+	fetcher := &fakeFetcher{moviePath: dir, content: []byte("video data")}
+	dl := NewFileDownloader(fetcher, File{ID: "file-1", Name: "My Clip.MP4", Size: 10}, dir)
+
+	if title, _ := dl.GetTitle(); title != "My Clip" {
+		t.Errorf("GetTitle() = %q, want %q", title, "My Clip")
+	}
+	mainFiles, tempFiles, _ := dl.GetFiles()
+	if len(mainFiles) != 1 || filepath.Ext(mainFiles[0]) != ".mp4" || len(tempFiles) != 1 {
+		t.Fatalf("GetFiles() = %v, %v", mainFiles, tempFiles)
+	}
+
+	progressChan, errChan, _, err := dl.StartDownload(context.Background())
+	if err != nil {
+		t.Fatalf("StartDownload: %v", err)
+	}
+	if err := drain(t, progressChan, errChan); err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+	if fetcher.fileID != "file-1" {
+		t.Errorf("fetched file %q, want file-1", fetcher.fileID)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, mainFiles[0]))
+	if err != nil || string(data) != "video data" {
+		t.Fatalf("saved file = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, tempFiles[0])); !os.IsNotExist(err) {
+		t.Errorf("partial file should be gone, stat err = %v", err)
+	}
+}
+
+func TestFileDownloader_Stop(t *testing.T) {
+	dir := t.TempDir()
+	dl := NewFileDownloader(&fakeFetcher{moviePath: dir, block: true}, File{ID: "file-1", Name: "clip.mkv", Size: 10}, dir)
+
+	progressChan, errChan, _, err := dl.StartDownload(context.Background())
+	if err != nil {
+		t.Fatalf("StartDownload: %v", err)
+	}
+	if err := dl.StopDownload(); err != nil {
+		t.Fatalf("StopDownload: %v", err)
+	}
+	if err := drain(t, progressChan, errChan); !errors.Is(err, downloader.ErrStoppedByUser) {
+		t.Fatalf("download error = %v, want ErrStoppedByUser", err)
+	}
+	if !dl.StoppedManually() {
+		t.Error("StoppedManually() = false after StopDownload")
+	}
+}
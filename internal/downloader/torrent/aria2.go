@@ -10,6 +10,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -19,6 +20,7 @@ import (
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/proxy"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/tvcompat"
 	"github.com/go-bittorrent/magneturi"
 )
@@ -33,6 +35,21 @@ const (
 	maxFailureSummaryLength = 300 // max chars for aria2 error summary in user-facing message
 )
 
+// fallbackTrackers are added to every torrent. HTTP(S) trackers help when UDP is blocked
+// (e.g. some Docker/restrictive networks).
+var fallbackTrackers = []string{
+	"udp://tracker.opentrackr.org:1337/announce",
+	"http://tracker.opentrackr.org:1337/announce",
+	"udp://open.stealth.si:80/announce",
+	"udp://tracker.torrent.eu.org:451/announce",
+	"udp://exodus.desync.com:6969/announce",
+	"udp://tracker.coppersurfer.tk:6969/announce",
+	"udp://tracker.leechers-paradise.org:6969/announce",
+	"udp://zer0day.ch:1337/announce",
+	"udp://open.demonii.si:1337/announce",
+	"https://tracker.nanoha.org:443/announce",
+}
+
 type Aria2Downloader struct {
 	torrentFileName string
 	downloadDir     string
@@ -40,6 +57,9 @@ type Aria2Downloader struct {
 	stoppedManually bool
 	config          *config.Config
 	magnetURI       string // when non-empty, download is from magnet link (torrentFileName is .magnet file path)
+	selection       FileSelection
+	downloadLimit   int64 // --max-overall-download-limit in bytes per second; 0 = unlimited
+	uploadLimit     int64 // --max-overall-upload-limit in bytes per second; 0 = ARIA2_MAX_OVERALL_UPLOAD_LIMIT
 }
 
 func NewAria2Downloader(torrentFileName, moviePath string, cfg *config.Config) downloader.Downloader {
@@ -228,7 +248,7 @@ func (d *Aria2Downloader) runMultiFileDownload(
 		defer close(episodesChan)
 	}
 
-	_, _, isVideo, totalSize := sortedFileIndicesByPath(meta)
+	indices, _, isVideo, totalSize := sortedFileIndicesByPath(meta)
 	if totalSize == 0 {
 		close(progressChan)
 		errChan <- nil
@@ -236,13 +256,13 @@ func (d *Aria2Downloader) runMultiFileDownload(
 	}
 
 	totalVideo := 0
-	for _, v := range isVideo {
-		if v {
+	for i, v := range isVideo {
+		if v && d.selection.Includes(indices[i]) {
 			totalVideo++
 		}
 	}
 
-	// Single run: all files, no --select-file — one swarm connection, best throughput
+	// Single run: all selected files in one --select-file — one swarm connection, best throughput
 	logutils.Log.WithFields(map[string]any{
 		"torrent_file":   d.torrentFileName,
 		"download_dir":   d.downloadDir,
@@ -402,8 +422,7 @@ func (d *Aria2Downloader) TotalEpisodes() int {
 	// Only video files count as episodes (posters/subs are not "episodes")
 	var n int
 	for i := range meta.Info.Files {
-		path := filepath.Join(meta.Info.Name, filepath.Join(meta.Info.Files[i].Path...))
-		if tvcompat.IsVideoFilePath(path) {
+		if d.selection.Includes(i+1) && tvcompat.IsVideoFilePath(meta.FilePath(i)) {
 			n++
 		}
 	}
@@ -484,25 +503,30 @@ func (d *Aria2Downloader) GetFiles() (mainFiles, tempFiles []string, err error)
 		return nil, nil, err
 	}
 
+	tempFiles = []string{
+		meta.Info.Name + ".aria2",
+		d.torrentFileName,
+	}
+
 	if len(meta.Info.Files) > 0 {
-		for _, file := range meta.Info.Files {
+		for i, file := range meta.Info.Files {
 			if meta.Info.Name == "" {
 				return nil, nil, fmt.Errorf("torrent meta does not contain a root directory name")
 			}
 			if len(file.Path) == 0 {
 				return nil, nil, fmt.Errorf("file path is empty in torrent meta")
 			}
-			mainFiles = append(mainFiles, filepath.Join(meta.Info.Name, filepath.Join(file.Path...)))
+			// aria2 may still write pieces shared with a deselected file; they are removed as temp files.
+			if d.selection.Includes(i + 1) {
+				mainFiles = append(mainFiles, meta.FilePath(i))
+			} else {
+				tempFiles = append(tempFiles, meta.FilePath(i))
+			}
 		}
 	} else {
 		mainFiles = append(mainFiles, meta.Info.Name)
 	}
 
-	tempFiles = []string{
-		meta.Info.Name + ".aria2",
-		d.torrentFileName,
-	}
-
 	return mainFiles, tempFiles, nil
 }
 
@@ -515,8 +539,10 @@ func (d *Aria2Downloader) GetFileSize() (int64, error) {
 
 	if len(meta.Info.Files) > 0 {
 		var totalSize int64
-		for _, file := range meta.Info.Files {
-			totalSize += file.Length
+		for i, file := range meta.Info.Files {
+			if d.selection.Includes(i + 1) {
+				totalSize += file.Length
+			}
 		}
 		if logutils.Log != nil {
 			logutils.Log.Debugf("Calculated total size for multi-file torrent: %d bytes", totalSize)
@@ -536,6 +562,37 @@ func (d *Aria2Downloader) GetFileSize() (int64, error) {
 	return meta.Info.Length, nil
 }
 
+// TorrentFiles implements downloader.FileSelector.
+func (d *Aria2Downloader) TorrentFiles() []downloader.TorrentFile {
+	if d.magnetURI != "" {
+		return nil
+	}
+	meta, err := d.parseTorrentMeta()
+	if err != nil {
+		return nil
+	}
+	return meta.TorrentFiles()
+}
+
+// InfoHash implements downloader.InfoHashDownloader.
+func (d *Aria2Downloader) InfoHash() string {
+	return SourceInfoHash(filepath.Join(d.downloadDir, d.torrentFileName), d.magnetURI)
+}
+
+// SelectFiles implements downloader.FileSelector; the selection is passed to aria2c as --select-file.
+func (d *Aria2Downloader) SelectFiles(indices []int) error {
+	meta, err := d.parseTorrentMeta()
+	if err != nil {
+		return err
+	}
+	sel, err := NewFileSelection(meta, indices)
+	if err != nil {
+		return err
+	}
+	d.selection = sel
+	return nil
+}
+
 // GetEarlyTvCompatibility returns a preliminary TV compatibility from torrent metadata (file names)
 // so the circle can be shown immediately when the torrent is added.
 // For magnet links, file names are unknown until metadata is received, so we return yellow (unknown).
@@ -631,7 +688,61 @@ func (*Aria2Downloader) validateTorrentFile(filePath string) error {
 	return ValidateContent(header, n)
 }
 
+// SetSpeedLimits sets the overall limits of the aria2c process; call it before StartDownload.
+// A running process keeps the limits it was started with.
+func (d *Aria2Downloader) SetSpeedLimits(download, upload int64) {
+	d.downloadLimit = download
+	d.uploadLimit = upload
+}
+
+func (d *Aria2Downloader) overallUploadLimit(cfg *config.Aria2Config) string {
+	if d.uploadLimit > 0 {
+		return strconv.FormatInt(d.uploadLimit, 10)
+	}
+	return cfg.MaxOverallUploadLimit
+}
+
 // buildAria2Args builds aria2c arguments. torrentPathOrMagnet is either a path to .torrent file or a magnet URI.
+// trackers returns the announce URLs of the torrent file or the tr= trackers of the magnet link.
+func (d *Aria2Downloader) trackers() []string {
+	if d.magnetURI != "" {
+		if parsed, err := magneturi.Parse(d.magnetURI); err == nil {
+			return parsed.Trackers
+		}
+		return nil
+	}
+	meta, err := ParseMeta(filepath.Join(d.downloadDir, d.torrentFileName))
+	if err != nil {
+		return nil
+	}
+	return meta.Trackers()
+}
+
+// proxyOptions returns the aria2 http-proxy/all-proxy options for a torrent: the most specific PROXY_RULES
+// route among its trackers and the fallback ones, else ARIA2_HTTP_PROXY / ARIA2_ALL_PROXY. aria2 proxies
+// tracker announces and web seeds, not peer connections, and only through HTTP proxies, so a SOCKS route
+// falls back to the static settings.
+func proxyOptions(router proxy.Router, cfg *config.Aria2Config, trackers []string) map[string]string {
+	opts := make(map[string]string)
+	rule, ok := router.RouteAny(append(slices.Clone(trackers), fallbackTrackers...))
+	switch {
+	case ok && rule.Proxy == "":
+		return opts
+	case ok && strings.HasPrefix(rule.Proxy, "http://"):
+		opts["all-proxy"] = rule.Proxy
+		return opts
+	case ok:
+		logutils.Log.WithField("domain", rule.Domain).Warn("aria2 only supports HTTP proxies, ignoring the proxy rule for its trackers")
+	}
+	if cfg.HTTPProxy != "" {
+		opts["http-proxy"] = cfg.HTTPProxy
+	}
+	if cfg.AllProxy != "" {
+		opts["all-proxy"] = cfg.AllProxy
+	}
+	return opts
+}
+
 func (d *Aria2Downloader) buildAria2Args(torrentPathOrMagnet string, cfg *config.Aria2Config) []string {
 	args := []string{
 		"--dir", d.downloadDir,
@@ -648,11 +759,11 @@ func (d *Aria2Downloader) buildAria2Args(torrentPathOrMagnet string, cfg *config
 		fmt.Sprintf("--split=%d", cfg.Split),
 		fmt.Sprintf("--min-split-size=%s", cfg.MinSplitSize),
 		fmt.Sprintf("--max-concurrent-downloads=%d", 1), // Per torrent
-		"--max-overall-download-limit=0",                // No global limit
+		fmt.Sprintf("--max-overall-download-limit=%d", d.downloadLimit),
 		fmt.Sprintf("--bt-max-peers=%d", cfg.BTMaxPeers),
 		fmt.Sprintf("--bt-request-peer-speed-limit=%s", cfg.BTRequestPeerSpeedLimit),
 		fmt.Sprintf("--bt-max-open-files=%d", cfg.BTMaxOpenFiles),
-		fmt.Sprintf("--max-overall-upload-limit=%s", cfg.MaxOverallUploadLimit),
+		fmt.Sprintf("--max-overall-upload-limit=%s", d.overallUploadLimit(cfg)),
 		fmt.Sprintf("--max-upload-limit=%s", cfg.MaxUploadLimit),
 		fmt.Sprintf("--seed-ratio=%.1f", cfg.SeedRatio),
 		fmt.Sprintf("--seed-time=%d", cfg.SeedTime),
@@ -717,12 +828,11 @@ func (d *Aria2Downloader) buildAria2Args(torrentPathOrMagnet string, cfg *config
 	}
 
 	// Network and proxy settings
-	if cfg.HTTPProxy != "" {
-		args = append(args, fmt.Sprintf("--http-proxy=%s", cfg.HTTPProxy))
-	}
-
-	if cfg.AllProxy != "" {
-		args = append(args, fmt.Sprintf("--all-proxy=%s", cfg.AllProxy))
+	proxies := proxyOptions(proxy.New(d.config), cfg, d.trackers())
+	for _, key := range []string{"http-proxy", "all-proxy"} {
+		if v := proxies[key]; v != "" {
+			args = append(args, fmt.Sprintf("--%s=%s", key, v))
+		}
 	}
 
 	if cfg.UserAgent != "" {
@@ -730,28 +840,24 @@ func (d *Aria2Downloader) buildAria2Args(torrentPathOrMagnet string, cfg *config
 	}
 
 	// Timeout and retry settings, plus fallback trackers for better connectivity.
-	// HTTP(S) trackers help when UDP is blocked (e.g. some Docker/restrictive networks).
 	args = append(args,
 		fmt.Sprintf("--timeout=%d", cfg.Timeout),
 		fmt.Sprintf("--max-tries=%d", cfg.MaxTries),
 		fmt.Sprintf("--retry-wait=%d", cfg.RetryWait),
-		"--bt-tracker=udp://tracker.opentrackr.org:1337/announce",
-		"--bt-tracker=http://tracker.opentrackr.org:1337/announce",
-		"--bt-tracker=udp://open.stealth.si:80/announce",
-		"--bt-tracker=udp://tracker.torrent.eu.org:451/announce",
-		"--bt-tracker=udp://exodus.desync.com:6969/announce",
-		"--bt-tracker=udp://tracker.coppersurfer.tk:6969/announce",
-		"--bt-tracker=udp://tracker.leechers-paradise.org:6969/announce",
-		"--bt-tracker=udp://zer0day.ch:1337/announce",
-		"--bt-tracker=udp://open.demonii.si:1337/announce",
-		"--bt-tracker=https://tracker.nanoha.org:443/announce",
 	)
+	for _, tracker := range fallbackTrackers {
+		args = append(args, "--bt-tracker="+tracker)
+	}
 
 	// Follow torrent setting
 	if cfg.FollowTorrent {
 		args = append(args, "--follow-torrent=true")
 	}
 
+	if d.selection != nil {
+		args = append(args, "--select-file="+joinIndices(d.selection.Indices()))
+	}
+
 	// Add the torrent file path, or --input-file for .magnet (aria2 reads URI from file)
 	if strings.HasSuffix(strings.ToLower(torrentPathOrMagnet), ".magnet") {
 		args = append(args, "--input-file", torrentPathOrMagnet)
@@ -0,0 +1,188 @@
+package aria2
+
+import (
+	"bytes"
+	"crypto/sha1" // #nosec G505 -- BitTorrent v1 info hashes are SHA-1 by definition
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// btmhSHA256Prefix is the multihash prefix (sha2-256, 32 bytes) of BitTorrent v2 "urn:btmh:" magnet hashes.
+const btmhSHA256Prefix = "1220"
+
+var errTruncatedBencode = errors.New("truncated bencode data")
+
+// InfoHash returns the info hash of .torrent content as lowercase hex: the v1 SHA-1 (BTIH) when the torrent
+// has v1 pieces (v1 and hybrid torrents), otherwise the v2 SHA-256 of the info dictionary.
+func InfoHash(data []byte) (string, error) {
+	info, err := rawInfoDict(data)
+	if err != nil {
+		return "", err
+	}
+	v1, err := dictHasKey(info, "pieces")
+	if err != nil {
+		return "", err
+	}
+	if v1 {
+		sum := sha1.Sum(info) // #nosec G401 -- protocol-defined hash, not used for security
+		return hex.EncodeToString(sum[:]), nil
+	}
+	sum := sha256.Sum256(info)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// SourceInfoHash returns the info hash of magnetURI, or of the .torrent at torrentPath when magnetURI is
+// empty; "" when it cannot be determined.
+func SourceInfoHash(torrentPath, magnetURI string) string {
+	if magnetURI != "" {
+		return MagnetInfoHash(magnetURI)
+	}
+	data, err := os.ReadFile(torrentPath)
+	if err != nil {
+		return ""
+	}
+	hash, err := InfoHash(data)
+	if err != nil {
+		return ""
+	}
+	return hash
+}
+
+// MagnetInfoHash extracts the info hash of a magnet link as lowercase hex: the btih when present,
+// otherwise the SHA-256 of a v2 btmh; "" when the link has neither.
+func MagnetInfoHash(magnet string) string {
+	u, err := url.Parse(magnet)
+	if err != nil {
+		return ""
+	}
+	var v2 string
+	for _, xt := range u.Query()["xt"] {
+		xt = strings.ToLower(xt)
+		if hash, ok := strings.CutPrefix(xt, "urn:btih:"); ok {
+			return NormalizeInfoHash(hash)
+		}
+		if hash, ok := strings.CutPrefix(xt, "urn:btmh:"+btmhSHA256Prefix); ok && len(hash) == sha256.Size*2 {
+			if _, err := hex.DecodeString(hash); err == nil {
+				v2 = hash
+			}
+		}
+	}
+	return v2
+}
+
+// NormalizeInfoHash returns a 40-char hex or 32-char base32 info hash as lowercase hex, "" otherwise.
+func NormalizeInfoHash(hash string) string {
+	hash = strings.TrimSpace(hash)
+	switch len(hash) {
+	case btihHexLen:
+		if _, err := hex.DecodeString(hash); err == nil {
+			return strings.ToLower(hash)
+		}
+	case btihBase32Len:
+		if raw, err := base32.StdEncoding.DecodeString(strings.ToUpper(hash)); err == nil {
+			return hex.EncodeToString(raw)
+		}
+	}
+	return ""
+}
+
+// rawInfoDict returns the "info" value of a torrent exactly as encoded in the file; the info hash is
+// computed over these bytes, so the dictionary must not be decoded and re-encoded.
+func rawInfoDict(data []byte) ([]byte, error) {
+	if len(data) == 0 || data[0] != 'd' {
+		return nil, errors.New("torrent is not a bencoded dictionary")
+	}
+	info, err := dictValue(data, "info")
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, errors.New("torrent has no info dictionary")
+	}
+	if info[0] != 'd' {
+		return nil, errors.New("torrent info is not a dictionary")
+	}
+	return info, nil
+}
+
+// dictHasKey reports whether the bencoded dictionary dict has the key.
+func dictHasKey(dict []byte, key string) (bool, error) {
+	value, err := dictValue(dict, key)
+	return value != nil, err
+}
+
+// dictValue returns the raw bencoded value of key in the dictionary dict, nil when the key is missing.
+func dictValue(dict []byte, key string) ([]byte, error) {
+	encodedKey := strconv.Itoa(len(key)) + ":" + key
+	pos := 1
+	for pos < len(dict) && dict[pos] != 'e' {
+		keyEnd, err := skipBencode(dict, pos)
+		if err != nil {
+			return nil, err
+		}
+		valueEnd, err := skipBencode(dict, keyEnd)
+		if err != nil {
+			return nil, err
+		}
+		if string(dict[pos:keyEnd]) == encodedKey {
+			return dict[keyEnd:valueEnd], nil
+		}
+		pos = valueEnd
+	}
+	if pos >= len(dict) {
+		return nil, errTruncatedBencode
+	}
+	return nil, nil
+}
+
+// skipBencode returns the position right after the bencoded value starting at pos.
+func skipBencode(data []byte, pos int) (int, error) {
+	if pos >= len(data) {
+		return 0, errTruncatedBencode
+	}
+	switch c := data[pos]; {
+	case c == 'i':
+		end := bytes.IndexByte(data[pos:], 'e')
+		if end < 0 {
+			return 0, errTruncatedBencode
+		}
+		return pos + end + 1, nil
+	case c == 'l' || c == 'd':
+		pos++
+		for pos < len(data) && data[pos] != 'e' {
+			next, err := skipBencode(data, pos)
+			if err != nil {
+				return 0, err
+			}
+			pos = next
+		}
+		if pos >= len(data) {
+			return 0, errTruncatedBencode
+		}
+		return pos + 1, nil
+	case c >= '0' && c <= '9':
+		colon := bytes.IndexByte(data[pos:], ':')
+		if colon < 0 {
+			return 0, errTruncatedBencode
+		}
+		n, err := strconv.Atoi(string(data[pos : pos+colon]))
+		if err != nil {
+			return 0, fmt.Errorf("invalid bencode string length at %d", pos)
+		}
+		start := pos + colon + 1
+		// Checked before adding so a huge declared length cannot overflow into a negative end.
+		if n < 0 || n > len(data)-start {
+			return 0, errTruncatedBencode
+		}
+		return start + n, nil
+	default:
+		return 0, fmt.Errorf("invalid bencode value at %d", pos)
+	}
+}
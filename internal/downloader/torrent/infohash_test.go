@@ -0,0 +1,87 @@
+package aria2
+
+import (
+	"bytes"
+	"crypto/sha1" // #nosec G505 -- reference v1 info hash
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"testing"
+
+	"github.com/jackpal/bencode-go"
+)
+
+func bencodeBytes(t *testing.T, v any) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := bencode.Marshal(&buf, v); err != nil {
+		t.Fatalf("bencode marshal: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestInfoHashMatchesMagnet(t *testing.T) {
+	info := map[string]any{"name": "Movie.mkv", "length": 1024, "piece length": 16384, "pieces": "01234567890123456789"}
+	infoBytes := bencodeBytes(t, info)
+	sum := sha1.Sum(infoBytes) // #nosec G401 -- reference v1 info hash
+	want := hex.EncodeToString(sum[:])
+
+	// Keys around "info" must not change the hash: only the raw info dictionary is hashed.
+	data := bencodeBytes(t, map[string]any{
+		"announce":      "http://tracker.example/announce",
+		"info":          info,
+		"url-list":      []any{"http://mirror.example/"},
+		"creation date": 1700000000,
+	})
+	got, err := InfoHash(data)
+	if err != nil {
+		t.Fatalf("InfoHash: %v", err)
+	}
+	if got != want {
+		t.Fatalf("InfoHash = %s, want %s", got, want)
+	}
+
+	base32Hash := base32.StdEncoding.EncodeToString(sum[:])
+	for _, magnet := range []string{
+		"magnet:?xt=urn:btih:" + want + "&dn=Another+Name",
+		"magnet:?dn=Movie&xt=urn:btih:" + base32Hash,
+	} {
+		if got := MagnetInfoHash(magnet); got != want {
+			t.Errorf("MagnetInfoHash(%q) = %q, want %q", magnet, got, want)
+		}
+	}
+}
+
+func TestInfoHashV2(t *testing.T) {
+	info := map[string]any{"name": "Movie", "meta version": 2, "piece length": 16384}
+	sum := sha256.Sum256(bencodeBytes(t, info))
+	want := hex.EncodeToString(sum[:])
+
+	got, err := InfoHash(bencodeBytes(t, map[string]any{"info": info}))
+	if err != nil {
+		t.Fatalf("InfoHash: %v", err)
+	}
+	if got != want {
+		t.Fatalf("InfoHash = %s, want %s", got, want)
+	}
+	if got := MagnetInfoHash("magnet:?xt=urn:btmh:" + btmhSHA256Prefix + want); got != want {
+		t.Errorf("MagnetInfoHash(btmh) = %q, want %q", got, want)
+	}
+}
+
+func TestInfoHashErrors(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"not dict":  []byte("li1ee"),
+		"no info":   []byte("d8:announce3:urle"),
+		"truncated": []byte("d4:infod4:name5:Mo"),
+		"overflow":  []byte("d4:infod4:name9223372036854775807:xee"),
+	} {
+		if _, err := InfoHash(data); err == nil {
+			t.Errorf("InfoHash(%s) should fail", name)
+		}
+	}
+	if got := MagnetInfoHash("magnet:?dn=NoHash"); got != "" {
+		t.Errorf("MagnetInfoHash without xt = %q, want empty", got)
+	}
+}
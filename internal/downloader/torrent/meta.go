@@ -3,13 +3,18 @@ package aria2
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
 	"github.com/jackpal/bencode-go"
 )
 
 type Meta struct {
-	Announce string `bencode:"announce"`
-	Info     struct {
+	Announce     string     `bencode:"announce"`
+	AnnounceList [][]string `bencode:"announce-list"`
+	Info         struct {
 		Name   string `bencode:"name"`
 		Length int64  `bencode:"length"`
 		Files  []struct {
@@ -39,3 +44,79 @@ func ParseMeta(filePath string) (*Meta, error) {
 	}
 	return &meta, nil
 }
+
+// Trackers returns the announce URL followed by the announce-list tiers, without duplicates.
+func (m *Meta) Trackers() []string {
+	var trackers []string
+	seen := make(map[string]bool)
+	add := func(tracker string) {
+		if tracker = strings.TrimSpace(tracker); tracker != "" && !seen[tracker] {
+			seen[tracker] = true
+			trackers = append(trackers, tracker)
+		}
+	}
+	add(m.Announce)
+	for _, tier := range m.AnnounceList {
+		for _, tracker := range tier {
+			add(tracker)
+		}
+	}
+	return trackers
+}
+
+// FilePath returns the path of the i-th (0-based) file of a multi-file torrent relative to the download directory.
+func (m *Meta) FilePath(i int) string {
+	return filepath.Join(m.Info.Name, filepath.Join(m.Info.Files[i].Path...))
+}
+
+// TorrentFiles lists the files of a multi-file torrent for the file preview, skipping BEP 47 padding files.
+func (m *Meta) TorrentFiles() []downloader.TorrentFile {
+	var files []downloader.TorrentFile
+	for i := range m.Info.Files {
+		path := m.Info.Files[i].Path
+		if len(path) == 0 || strings.HasPrefix(path[len(path)-1], paddingFileMarker) {
+			continue
+		}
+		files = append(files, downloader.TorrentFile{Index: i + 1, Path: m.FilePath(i), Size: m.Info.Files[i].Length})
+	}
+	return files
+}
+
+// FileSelection holds the 1-based indices of the torrent files to download; nil downloads every file.
+type FileSelection map[int]bool
+
+// NewFileSelection checks indices against the files of meta. Selecting every file returns nil.
+func NewFileSelection(meta *Meta, indices []int) (FileSelection, error) {
+	if len(meta.Info.Files) == 0 {
+		return nil, fmt.Errorf("file selection needs a multi-file torrent")
+	}
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("no files selected")
+	}
+	sel := make(FileSelection, len(indices))
+	for _, idx := range indices {
+		if idx < 1 || idx > len(meta.Info.Files) {
+			return nil, fmt.Errorf("file index %d is out of range 1-%d", idx, len(meta.Info.Files))
+		}
+		sel[idx] = true
+	}
+	if len(sel) == len(meta.Info.Files) {
+		return nil, nil
+	}
+	return sel, nil
+}
+
+// Includes reports whether the file with the 1-based index is downloaded.
+func (s FileSelection) Includes(index int) bool {
+	return s == nil || s[index]
+}
+
+// Indices returns the selected 1-based indices in ascending order.
+func (s FileSelection) Indices() []int {
+	indices := make([]int, 0, len(s))
+	for idx := range s {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+	return indices
+}
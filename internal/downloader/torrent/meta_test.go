@@ -3,6 +3,7 @@ package aria2
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/jackpal/bencode-go"
@@ -201,6 +202,34 @@ func TestMagnetDummyMetaExactLength(t *testing.T) {
 }
 
 //nolint:gocyclo // Test function validating torrent structures
+func TestMetaTrackers(t *testing.T) {
+	filePath := writeBencode(t, testutils.TempDir(t), "trackers", map[string]any{
+		"announce": "http://tracker.example.com/announce",
+		"announce-list": [][]string{
+			{"http://tracker.example.com/announce", "udp://backup.example.net:6969/announce"},
+			{"https://third.example.org/announce"},
+		},
+		"info": map[string]any{
+			"name":         "test.mp4",
+			"length":       int64(2048),
+			"piece length": int64(32768),
+			"pieces":       "01234567890123456789",
+		},
+	})
+	meta, err := ParseMeta(filePath)
+	if err != nil {
+		t.Fatalf("Failed to parse meta: %v", err)
+	}
+	want := []string{
+		"http://tracker.example.com/announce",
+		"udp://backup.example.net:6969/announce",
+		"https://third.example.org/announce",
+	}
+	if got := meta.Trackers(); !slices.Equal(got, want) {
+		t.Errorf("Trackers() = %v, want %v", got, want)
+	}
+}
+
 func TestMetaStructure(t *testing.T) {
 	tempDir := testutils.TempDir(t)
 
@@ -319,3 +348,75 @@ func TestSortedFileIndicesByPath(t *testing.T) {
 		t.Errorf("sortedFileIndicesByPath totalSize = %d, want 600", totalSize)
 	}
 }
+
+func TestAria2DownloaderFileSelection(t *testing.T) {
+	tempDir := testutils.TempDir(t)
+	torrentPath := writeBencode(t, tempDir, "season", map[string]any{
+		"announce": "http://tracker.example.com/announce",
+		"info": map[string]any{
+			"name":         "Season 1",
+			"piece length": int64(32768),
+			"pieces":       "01234567890123456789",
+			"files": []map[string]any{
+				{"length": int64(100), "path": []string{"E01.mkv"}},
+				{"length": int64(7), "path": []string{".pad", "_____padding_file_0_"}},
+				{"length": int64(200), "path": []string{"E02.mkv"}},
+				{"length": int64(50), "path": []string{"Extras", "Sample.mkv"}},
+				{"length": int64(1), "path": []string{"info.nfo"}},
+			},
+		},
+	})
+	d := &Aria2Downloader{downloadDir: tempDir, torrentFileName: filepath.Base(torrentPath)}
+
+	files := d.TorrentFiles()
+	if len(files) != 4 || files[1].Index != 3 || files[1].Path != filepath.Join("Season 1", "E02.mkv") || files[1].Size != 200 {
+		t.Fatalf("TorrentFiles() = %+v, want 4 files without padding", files)
+	}
+
+	for _, bad := range [][]int{nil, {0}, {6}} {
+		if err := d.SelectFiles(bad); err == nil {
+			t.Errorf("SelectFiles(%v) error = nil, want an error", bad)
+		}
+	}
+	if err := d.SelectFiles([]int{1, 3, 5}); err != nil {
+		t.Fatalf("SelectFiles: %v", err)
+	}
+
+	if size, _ := d.GetFileSize(); size != 301 {
+		t.Errorf("GetFileSize() = %d, want 301", size)
+	}
+	if n := d.TotalEpisodes(); n != 2 {
+		t.Errorf("TotalEpisodes() = %d, want 2", n)
+	}
+	mainFiles, tempFiles, err := d.GetFiles()
+	if err != nil {
+		t.Fatalf("GetFiles: %v", err)
+	}
+	if len(mainFiles) != 3 {
+		t.Errorf("GetFiles() main = %v, want the 3 selected files", mainFiles)
+	}
+	sample := filepath.Join("Season 1", "Extras", "Sample.mkv")
+	if !slices.Contains(tempFiles, sample) || slices.Contains(mainFiles, sample) {
+		t.Errorf("GetFiles() temp = %v, want the deselected %q among temp files", tempFiles, sample)
+	}
+}
+
+func TestNewFileSelectionAllFiles(t *testing.T) {
+	meta := &Meta{}
+	meta.Info.Files = make([]struct {
+		Length int64    `bencode:"length"`
+		Path   []string `bencode:"path"`
+	}, 2)
+	sel, err := NewFileSelection(meta, []int{2, 1, 2})
+	if err != nil || sel != nil {
+		t.Fatalf("NewFileSelection(all files) = %v, %v, want nil selection", sel, err)
+	}
+	if !sel.Includes(2) {
+		t.Error("nil selection should include every file")
+	}
+
+	meta.Info.Files = nil
+	if _, err := NewFileSelection(meta, []int{1}); err == nil {
+		t.Error("NewFileSelection on a single-file torrent: error = nil")
+	}
+}
@@ -223,6 +223,40 @@ func TestBuildAria2Args(t *testing.T) {
 		assertContainsArg(t, args, "--all-proxy=socks5://proxy:1080")
 	})
 
+	t.Run("Proxy rules for trackers", func(t *testing.T) {
+		magnetDir := t.TempDir()
+		magnet := "magnet:?xt=urn:btih:ABCDEFGHIJKLMNOPQRSTUVWXYZ234567&tr=http%3A%2F%2Fbt.rutracker.org%2Fann"
+		routed := &Aria2Downloader{
+			downloadDir: magnetDir,
+			magnetURI:   magnet,
+			config:      &tmsconfig.Config{ProxyRules: "rutracker.org=http://proxy:3128,*=socks5://default:1080"},
+		}
+		cfgProxy := *baseCfg
+		cfgProxy.AllProxy = "http://static:8080"
+		args := routed.buildAria2Args(magnetDir+"/x.magnet", &cfgProxy)
+		assertContainsArg(t, args, "--all-proxy=http://proxy:3128")
+
+		// SOCKS routes are not supported by aria2: the static setting is kept.
+		routed.magnetURI = "magnet:?xt=urn:btih:ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+		args = routed.buildAria2Args(magnetDir+"/x.magnet", &cfgProxy)
+		assertContainsArg(t, args, "--all-proxy=http://static:8080")
+
+		routed.config = &tmsconfig.Config{ProxyRules: "*=direct"}
+		for _, arg := range routed.buildAria2Args(magnetDir+"/x.magnet", &cfgProxy) {
+			if strings.HasPrefix(arg, "--all-proxy") {
+				t.Errorf("direct rule should drop the static proxy, got %s", arg)
+			}
+		}
+
+		// CONTENT_PROXY is for yt-dlp and direct downloads only: the static aria2 proxy stays.
+		routed.config = &tmsconfig.Config{Proxy: "socks5://legacy:1080"}
+		args = routed.buildAria2Args(magnetDir+"/x.magnet", &cfgProxy)
+		assertContainsArg(t, args, "--all-proxy=http://static:8080")
+		routed.config = &tmsconfig.Config{Proxy: "http://legacy:3128"}
+		args = routed.buildAria2Args(magnetDir+"/x.magnet", &cfgProxy)
+		assertContainsArg(t, args, "--all-proxy=http://static:8080")
+	})
+
 	t.Run("User agent", func(t *testing.T) {
 		cfgUA := *baseCfg
 		cfgUA.UserAgent = "TestAgent/1.0"
@@ -253,6 +287,21 @@ func TestBuildAria2Args(t *testing.T) {
 		assertContainsArg(t, args, "--bt-min-crypto-level=arc4")
 	})
 
+	t.Run("Selected files", func(t *testing.T) {
+		sel := &Aria2Downloader{downloadDir: "/tmp/downloads", selection: FileSelection{3: true, 1: true}}
+		args := sel.buildAria2Args("/tmp/test.torrent", baseCfg)
+		assertContainsArg(t, args, "--select-file=1,3")
+	})
+
+	t.Run("Speed limits", func(t *testing.T) {
+		assertContainsArg(t, d.buildAria2Args("/tmp/test.torrent", baseCfg), "--max-overall-upload-limit=10K")
+		limited := &Aria2Downloader{downloadDir: "/tmp/downloads"}
+		limited.SetSpeedLimits(2<<20, 512<<10)
+		args := limited.buildAria2Args("/tmp/test.torrent", baseCfg)
+		assertContainsArg(t, args, "--max-overall-download-limit=2097152")
+		assertContainsArg(t, args, "--max-overall-upload-limit=524288")
+	})
+
 	t.Run("Continue download", func(t *testing.T) {
 		cfgCont := *baseCfg
 		cfgCont.ContinueDownload = true
@@ -0,0 +1,283 @@
+package aria2
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	rpcDefaultPath     = "/jsonrpc"
+	rpcRequestTimeout  = 30 * time.Second
+	rpcMaxResponseBody = 4 << 20
+)
+
+// Download states reported by aria2.tellStatus.
+const (
+	StatusActive   = "active"
+	StatusWaiting  = "waiting"
+	StatusPaused   = "paused"
+	StatusError    = "error"
+	StatusComplete = "complete"
+	StatusRemoved  = "removed"
+)
+
+// ErrGIDNotFound is returned when the daemon no longer knows the requested GID (e.g. it was restarted
+// without --save-session, or the result was purged).
+var ErrGIDNotFound = errors.New("aria2: GID not found")
+
+// RPCClient talks to a long-lived `aria2c --enable-rpc` daemon over JSON-RPC.
+// ws:// and wss:// URLs are accepted and mapped to the HTTP endpoint of the same server.
+type RPCClient struct {
+	endpoint   string
+	secret     string
+	httpClient *http.Client
+	nextID     atomic.Uint64
+}
+
+// NewRPCClient creates a client for rawURL (e.g. http://localhost:6800/jsonrpc). secret is the
+// --rpc-secret of the daemon; empty when the daemon runs without one.
+func NewRPCClient(rawURL, secret string) (*RPCClient, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("invalid aria2 RPC URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	default:
+		return nil, fmt.Errorf("aria2 RPC URL must use http, https, ws or wss: %q", rawURL)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("aria2 RPC URL has no host: %q", rawURL)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = rpcDefaultPath
+	}
+	return &RPCClient{
+		endpoint:   u.String(),
+		secret:     secret,
+		httpClient: &http.Client{Timeout: rpcRequestTimeout},
+	}, nil
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("aria2 RPC error %d: %s", e.Code, e.Message)
+}
+
+// call invokes method with params and decodes the result into out (when non-nil).
+func (c *RPCClient) call(ctx context.Context, method string, out any, params ...any) error {
+	if c.secret != "" {
+		params = append([]any{"token:" + c.secret}, params...)
+	}
+	if params == nil {
+		params = []any{}
+	}
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      strconv.FormatUint(c.nextID.Add(1), 10),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, rpcMaxResponseBody))
+	if err != nil {
+		return err
+	}
+
+	// aria2 answers RPC errors with HTTP 400 and a JSON error object, so decode before checking the status.
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	if jsonErr := json.Unmarshal(raw, &envelope); jsonErr != nil {
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("aria2 RPC %s: HTTP %d", method, resp.StatusCode)
+		}
+		return fmt.Errorf("aria2 RPC %s: decode response: %w", method, jsonErr)
+	}
+	if envelope.Error != nil {
+		if strings.Contains(envelope.Error.Message, "is not found") {
+			return fmt.Errorf("%w: %s", ErrGIDNotFound, envelope.Error.Message)
+		}
+		return fmt.Errorf("aria2 RPC %s: %w", method, envelope.Error)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(envelope.Result, out)
+}
+
+// GetVersion returns the daemon version; used as a readiness check.
+func (c *RPCClient) GetVersion(ctx context.Context) (string, error) {
+	var res struct {
+		Version string `json:"version"`
+	}
+	if err := c.call(ctx, "aria2.getVersion", &res); err != nil {
+		return "", err
+	}
+	return res.Version, nil
+}
+
+// AddTorrent adds .torrent contents and returns the GID of the new download.
+func (c *RPCClient) AddTorrent(ctx context.Context, torrent []byte, options map[string]string) (string, error) {
+	var gid string
+	err := c.call(ctx, "aria2.addTorrent", &gid, base64.StdEncoding.EncodeToString(torrent), []string{}, options)
+	return gid, err
+}
+
+// AddURI adds a URI (e.g. a magnet link) and returns the GID of the new download.
+func (c *RPCClient) AddURI(ctx context.Context, uri string, options map[string]string) (string, error) {
+	var gid string
+	err := c.call(ctx, "aria2.addUri", &gid, []string{uri}, options)
+	return gid, err
+}
+
+// TellStatus returns the current state of gid.
+func (c *RPCClient) TellStatus(ctx context.Context, gid string) (*Status, error) {
+	var st Status
+	if err := c.call(ctx, "aria2.tellStatus", &st, gid); err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// Pause pauses gid; the download keeps its data and can be unpaused later.
+func (c *RPCClient) Pause(ctx context.Context, gid string) error {
+	return c.call(ctx, "aria2.pause", nil, gid)
+}
+
+// Unpause resumes a paused gid.
+func (c *RPCClient) Unpause(ctx context.Context, gid string) error {
+	return c.call(ctx, "aria2.unpause", nil, gid)
+}
+
+// Remove stops gid (data on disk is kept) and drops its result from the stopped list.
+// A GID that is already stopped only has its result removed.
+func (c *RPCClient) Remove(ctx context.Context, gid string) error {
+	if err := c.call(ctx, "aria2.forceRemove", nil, gid); err != nil {
+		// Already stopped downloads reject forceRemove; their result is still purged below.
+		var rpcErr *rpcError
+		if !errors.Is(err, ErrGIDNotFound) && !errors.As(err, &rpcErr) {
+			return err
+		}
+	}
+	if err := c.call(ctx, "aria2.removeDownloadResult", nil, gid); err != nil && !errors.Is(err, ErrGIDNotFound) {
+		return err
+	}
+	return nil
+}
+
+// SelectFiles restricts gid to the given 1-based file indices.
+func (c *RPCClient) SelectFiles(ctx context.Context, gid string, indices []int) error {
+	return c.call(ctx, "aria2.changeOption", nil, gid, map[string]string{"select-file": joinIndices(indices)})
+}
+
+// SetSpeedLimits sets the global download and upload limits of the daemon in bytes per second; 0 removes a limit.
+func (c *RPCClient) SetSpeedLimits(ctx context.Context, download, upload int64) error {
+	return c.call(ctx, "aria2.changeGlobalOption", nil, map[string]string{
+		"max-overall-download-limit": strconv.FormatInt(download, 10),
+		"max-overall-upload-limit":   strconv.FormatInt(upload, 10),
+	})
+}
+
+// Number is an integer that aria2 encodes as a JSON string.
+type Number int64
+
+func (n *Number) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		*n = 0
+		return nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("aria2 number %q: %w", s, err)
+	}
+	*n = Number(v)
+	return nil
+}
+
+// Status is the subset of aria2.tellStatus the bot uses.
+type Status struct {
+	GID             string   `json:"gid"`
+	Status          string   `json:"status"`
+	TotalLength     Number   `json:"totalLength"`
+	CompletedLength Number   `json:"completedLength"`
+	DownloadSpeed   Number   `json:"downloadSpeed"`
+	UploadSpeed     Number   `json:"uploadSpeed"`
+	Connections     Number   `json:"connections"`
+	NumSeeders      Number   `json:"numSeeders"`
+	Seeder          string   `json:"seeder"`
+	InfoHash        string   `json:"infoHash"`
+	ErrorCode       string   `json:"errorCode"`
+	ErrorMessage    string   `json:"errorMessage"`
+	FollowedBy      []string `json:"followedBy"`
+	Dir             string   `json:"dir"`
+	Files           []File   `json:"files"`
+	Bittorrent      struct {
+		Info struct {
+			Name string `json:"name"`
+		} `json:"info"`
+	} `json:"bittorrent"`
+}
+
+// File is one entry of Status.Files; Path is absolute on the daemon's filesystem.
+type File struct {
+	Index           string `json:"index"`
+	Path            string `json:"path"`
+	Length          Number `json:"length"`
+	CompletedLength Number `json:"completedLength"`
+	Selected        string `json:"selected"`
+}
+
+// IsMetadata reports whether s is the magnet metadata download that aria2 replaces with the real one.
+func (s *Status) IsMetadata() bool {
+	return s.Bittorrent.Info.Name == "" && len(s.Files) == 1 && strings.HasPrefix(s.Files[0].Path, "[METADATA]")
+}
+
+// Progress returns the completed percentage of the selected files.
+func (s *Status) Progress() float64 {
+	if s.TotalLength <= 0 {
+		return 0
+	}
+	return float64(s.CompletedLength) / float64(s.TotalLength) * 100
+}
+
+// DataComplete is true when every selected byte is on disk (aria2 may still be seeding).
+func (s *Status) DataComplete() bool {
+	if s.IsMetadata() || s.TotalLength <= 0 || s.CompletedLength < s.TotalLength {
+		return false
+	}
+	return s.Status == StatusComplete || s.Seeder == "true"
+}
@@ -0,0 +1,564 @@
+package aria2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/proxy"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/tvcompat"
+)
+
+const (
+	rpcPollInterval = 3 * time.Second
+	rpcStopTimeout  = 15 * time.Second
+	// paddingFileMarker prefixes BEP 47 padding files; aria2 would otherwise write them to disk.
+	paddingFileMarker = "_____padding_file_"
+)
+
+// Aria2RPCDownloader drives a torrent through a long-lived aria2 daemon (ARIA2_RPC_URL) instead of
+// spawning aria2c per download. The download GID is persisted in the torrent hash column so a
+// restarted bot can reattach to it.
+type Aria2RPCDownloader struct {
+	local       *Aria2Downloader // torrent/magnet metadata; nil when resuming by GID
+	downloadDir string
+	cfg         *config.Config
+	client      *RPCClient
+
+	mu                       sync.Mutex
+	gid                      string
+	stoppedManually          bool
+	onHashKnown              func(gid string) // optional; called whenever the tracked GID changes
+	onMagnetMetadata         func(paths []string, totalBytes int64, videoFileCount int)
+	magnetDBSynced           bool   // true after the first metadata sync to DB (magnet only)
+	resumeGID                string // when set, skip add and poll this GID (resume after restart)
+	initialCompletedEpisodes int    // for resume: episodes already completed before restart (from DB)
+	totalEpisodesStored      int    // for resume: total episode count when no torrent file (from DB)
+}
+
+// NewAria2RPCDownloader creates a downloader that hands torrentFileName (.torrent or .magnet under
+// moviePath) to the aria2 daemon.
+func NewAria2RPCDownloader(torrentFileName, moviePath string, cfg *config.Config) (downloader.Downloader, error) {
+	client, err := newRPCClientFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	local, _ := NewAria2Downloader(torrentFileName, moviePath, cfg).(*Aria2Downloader)
+	return &Aria2RPCDownloader{
+		local:       local,
+		downloadDir: moviePath,
+		cfg:         cfg,
+		client:      client,
+	}, nil
+}
+
+// NewAria2RPCResumeDownloader creates a downloader that monitors an existing daemon download by GID
+// (e.g. after bot restart). completedEpisodes comes from the DB so OnFirstEpisodeReady is not re-sent.
+func NewAria2RPCResumeDownloader(
+	gid, downloadDir string,
+	totalEpisodes, completedEpisodes int,
+	cfg *config.Config,
+) (downloader.Downloader, error) {
+	client, err := newRPCClientFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Aria2RPCDownloader{
+		downloadDir:              downloadDir,
+		cfg:                      cfg,
+		client:                   client,
+		resumeGID:                gid,
+		initialCompletedEpisodes: completedEpisodes,
+		totalEpisodesStored:      totalEpisodes,
+	}, nil
+}
+
+func newRPCClientFromConfig(cfg *config.Config) (*RPCClient, error) {
+	aria2Cfg := cfg.GetAria2Settings()
+	if aria2Cfg.RPCURL == "" {
+		return nil, fmt.Errorf("ARIA2_RPC_URL is not set")
+	}
+	return NewRPCClient(aria2Cfg.RPCURL, aria2Cfg.RPCSecret)
+}
+
+func (d *Aria2RPCDownloader) GetTitle() (string, error) {
+	if d.local == nil {
+		return "", nil
+	}
+	return d.local.GetTitle()
+}
+
+func (d *Aria2RPCDownloader) GetFiles() (mainFiles, tempFiles []string, err error) {
+	if d.local == nil {
+		return nil, nil, fmt.Errorf("resume downloader has no torrent meta")
+	}
+	return d.local.GetFiles()
+}
+
+func (d *Aria2RPCDownloader) GetFileSize() (int64, error) {
+	if d.local == nil {
+		return 0, nil
+	}
+	return d.local.GetFileSize()
+}
+
+func (d *Aria2RPCDownloader) TotalEpisodes() int {
+	if d.local == nil {
+		return d.totalEpisodesStored
+	}
+	return d.local.TotalEpisodes()
+}
+
+// TorrentFiles implements downloader.FileSelector.
+func (d *Aria2RPCDownloader) TorrentFiles() []downloader.TorrentFile {
+	if d.local == nil {
+		return nil
+	}
+	return d.local.TorrentFiles()
+}
+
+// InfoHash implements downloader.InfoHashDownloader.
+func (d *Aria2RPCDownloader) InfoHash() string {
+	if d.local == nil {
+		return ""
+	}
+	return d.local.InfoHash()
+}
+
+// SelectFiles implements downloader.FileSelector; the selection is sent as the select-file option.
+func (d *Aria2RPCDownloader) SelectFiles(indices []int) error {
+	if d.local == nil {
+		return fmt.Errorf("resume downloader has no torrent meta")
+	}
+	return d.local.SelectFiles(indices)
+}
+
+// GetEarlyTvCompatibility returns preliminary TV compatibility from torrent file names, or yellow for magnet.
+func (d *Aria2RPCDownloader) GetEarlyTvCompatibility(ctx context.Context) (string, error) {
+	if d.local == nil {
+		return "", nil
+	}
+	return d.local.GetEarlyTvCompatibility(ctx)
+}
+
+func (d *Aria2RPCDownloader) StoppedManually() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stoppedManually
+}
+
+// SetOnHashKnown implements downloader.OnHashKnownSetter; the "hash" is the aria2 GID. For magnets it is
+// called twice: for the metadata download and for the real download that follows it.
+func (d *Aria2RPCDownloader) SetOnHashKnown(cb func(hash string)) {
+	d.onHashKnown = cb
+}
+
+// TorrentClient implements downloader.OnHashKnownSetter.
+func (*Aria2RPCDownloader) TorrentClient() string {
+	return config.TorrentClientAria2
+}
+
+// SetOnMagnetMetadataReady implements downloader.MagnetMetadataSyncSetter.
+func (d *Aria2RPCDownloader) SetOnMagnetMetadataReady(cb func(paths []string, totalBytes int64, videoFileCount int)) {
+	d.onMagnetMetadata = cb
+}
+
+func (d *Aria2RPCDownloader) setGID(gid string) {
+	d.mu.Lock()
+	d.gid = gid
+	d.mu.Unlock()
+	if d.onHashKnown != nil {
+		d.onHashKnown(gid)
+	}
+}
+
+func (d *Aria2RPCDownloader) StartDownload(
+	ctx context.Context,
+) (progressChan chan float64, errChan chan error, episodesChan <-chan int, err error) {
+	totalVideo := d.totalEpisodesStored
+	if d.local != nil {
+		if _, metaErr := d.local.parseTorrentMeta(); metaErr != nil {
+			return nil, nil, nil, fmt.Errorf("failed to parse torrent meta: %w", metaErr)
+		}
+		totalVideo = d.local.TotalEpisodes()
+	}
+	progressChan = make(chan float64)
+	errChan = make(chan error, 1)
+	var epCh chan int
+	if totalVideo > 1 {
+		epCh = make(chan int, totalVideo)
+		episodesChan = epCh
+	}
+	go d.run(ctx, totalVideo, progressChan, errChan, epCh)
+	return progressChan, errChan, episodesChan, nil
+}
+
+// stopErr is the result to report when ctx is done. A download the bot stops tracking without a
+// manual stop (e.g. DOWNLOAD_TIMEOUT) is paused so the daemon keeps it resumable without using bandwidth.
+func (d *Aria2RPCDownloader) stopErr(ctx context.Context) error {
+	if d.StoppedManually() {
+		return downloader.ErrStoppedByUser
+	}
+	d.mu.Lock()
+	gid := d.gid
+	d.mu.Unlock()
+	if gid != "" {
+		pauseCtx, cancel := context.WithTimeout(context.Background(), rpcStopTimeout)
+		defer cancel()
+		if err := d.client.Pause(pauseCtx, gid); err != nil {
+			logutils.Log.WithError(err).WithField("gid", gid).Debug("aria2 pause after cancel failed")
+		}
+	}
+	return ctx.Err()
+}
+
+// addOrAttach adds the torrent (or, on resume, checks the GID is still known and unpauses it) and
+// returns the GID to poll.
+func (d *Aria2RPCDownloader) addOrAttach(ctx context.Context) (string, error) {
+	if d.resumeGID != "" {
+		st, err := d.client.TellStatus(ctx, d.resumeGID)
+		if err != nil {
+			return "", fmt.Errorf("aria2 resume tellStatus: %w", err)
+		}
+		if st.Status == StatusPaused {
+			if err := d.client.Unpause(ctx, d.resumeGID); err != nil {
+				return "", fmt.Errorf("aria2 resume unpause: %w", err)
+			}
+		}
+		d.mu.Lock()
+		d.gid = d.resumeGID
+		d.mu.Unlock()
+		return d.resumeGID, nil
+	}
+
+	aria2Cfg := d.cfg.GetAria2Settings()
+	options := rpcOptions(d.downloadDir, aria2Cfg)
+	maps.Copy(options, proxyOptions(proxy.New(d.cfg), &aria2Cfg, d.local.trackers()))
+	var gid string
+	var err error
+	if d.local.magnetURI != "" {
+		gid, err = d.client.AddURI(ctx, d.local.magnetURI, options)
+	} else {
+		var body []byte
+		body, err = os.ReadFile(filepath.Join(d.downloadDir, d.local.torrentFileName))
+		if err != nil {
+			return "", fmt.Errorf("read torrent file: %w", err)
+		}
+		if meta, metaErr := d.local.parseTorrentMeta(); metaErr == nil {
+			if indices := selectedFileIndices(meta, d.local.selection); indices != nil {
+				options["select-file"] = joinIndices(indices)
+			}
+		}
+		gid, err = d.client.AddTorrent(ctx, body, options)
+	}
+	if err != nil {
+		return "", fmt.Errorf("aria2 add: %w", err)
+	}
+	logutils.Log.WithField("gid", gid).Info("aria2 download added via RPC")
+	// Persist GID synchronously so it survives process restart.
+	d.setGID(gid)
+	return gid, nil
+}
+
+func (d *Aria2RPCDownloader) run(
+	ctx context.Context,
+	totalVideo int,
+	progressChan chan float64,
+	errChan chan error,
+	episodesChan chan int,
+) {
+	defer close(errChan)
+	if episodesChan != nil {
+		defer close(episodesChan)
+	}
+	defer close(progressChan)
+
+	gid, err := d.addOrAttach(ctx)
+	if err != nil {
+		errChan <- err
+		return
+	}
+
+	select {
+	case progressChan <- 0:
+	case <-ctx.Done():
+		errChan <- d.stopErr(ctx)
+		return
+	}
+
+	ticker := time.NewTicker(rpcPollInterval)
+	defer ticker.Stop()
+	effectiveVideoCount := totalVideo
+	lastProgress := -1.0
+	lastCompletedEpisodes := d.initialCompletedEpisodes
+	for {
+		select {
+		case <-ctx.Done():
+			errChan <- d.stopErr(ctx)
+			return
+		case <-ticker.C:
+		}
+
+		st, err := d.client.TellStatus(ctx, gid)
+		if err != nil {
+			switch {
+			case ctx.Err() != nil:
+				errChan <- d.stopErr(ctx)
+			case d.StoppedManually():
+				errChan <- downloader.ErrStoppedByUser
+			default:
+				errChan <- fmt.Errorf("aria2 tellStatus: %w", err)
+			}
+			return
+		}
+		switch st.Status {
+		case StatusError:
+			errChan <- fmt.Errorf("aria2: %s (error code %s)", st.ErrorMessage, st.ErrorCode)
+			return
+		case StatusRemoved:
+			if d.StoppedManually() {
+				errChan <- downloader.ErrStoppedByUser
+			} else {
+				errChan <- errors.New("aria2: download was removed from the daemon")
+			}
+			return
+		}
+
+		// Magnet: the metadata download completes and is followed by the real one.
+		if len(st.FollowedBy) > 0 {
+			gid = st.FollowedBy[0]
+			d.setGID(gid)
+			logutils.Log.WithField("gid", gid).Info("aria2 magnet metadata received, following download")
+			continue
+		}
+		if st.IsMetadata() {
+			continue
+		}
+
+		if d.local != nil && d.local.magnetURI != "" && !d.magnetDBSynced && len(st.Files) > 0 {
+			d.magnetDBSynced = true
+			vCount := d.syncMagnetMetadata(ctx, st)
+			if vCount > effectiveVideoCount {
+				effectiveVideoCount = vCount
+			}
+		}
+
+		logutils.Log.WithFields(map[string]any{
+			"gid":            gid,
+			"download_speed": int64(st.DownloadSpeed),
+			"upload_speed":   int64(st.UploadSpeed),
+			"connections":    int64(st.Connections),
+			"seeders":        int64(st.NumSeeders),
+		}).Debug("aria2 download status")
+
+		if progress := min(st.Progress(), 100); progress != lastProgress {
+			lastProgress = progress
+			select {
+			case progressChan <- progress:
+			case <-ctx.Done():
+				errChan <- d.stopErr(ctx)
+				return
+			}
+		}
+
+		completed := countCompletedVideoFiles(st.Files)
+		if episodesChan != nil && effectiveVideoCount > 1 && completed > lastCompletedEpisodes {
+			lastCompletedEpisodes = completed
+			select {
+			case episodesChan <- completed:
+			case <-ctx.Done():
+				errChan <- d.stopErr(ctx)
+				return
+			}
+		}
+
+		if !st.DataComplete() {
+			continue
+		}
+		if episodesChan != nil && effectiveVideoCount > 0 && lastCompletedEpisodes != effectiveVideoCount {
+			select {
+			case episodesChan <- effectiveVideoCount:
+			case <-ctx.Done():
+				errChan <- d.stopErr(ctx)
+				return
+			}
+		}
+		// A seeding download is left to the daemon's seed-ratio/seed-time; a finished one is purged.
+		if st.Status == StatusComplete {
+			d.removeDownloadResult(gid)
+		}
+		errChan <- nil
+		return
+	}
+}
+
+// syncMagnetMetadata reports real relative paths and sizes to the manager, deselects padding files,
+// and returns the video file count.
+func (d *Aria2RPCDownloader) syncMagnetMetadata(ctx context.Context, st *Status) int {
+	relPaths := make([]string, 0, len(st.Files))
+	var totalBytes int64
+	var selected []int
+	hasPadding := false
+	for i := range st.Files {
+		f := &st.Files[i]
+		rel, err := filepath.Rel(st.Dir, f.Path)
+		if err != nil {
+			rel = filepath.Base(f.Path)
+		}
+		if strings.Contains(filepath.Base(f.Path), paddingFileMarker) {
+			hasPadding = true
+			continue
+		}
+		if idx, err := strconv.Atoi(f.Index); err == nil {
+			selected = append(selected, idx)
+		}
+		relPaths = append(relPaths, rel)
+		totalBytes += int64(f.Length)
+	}
+	if hasPadding && len(selected) > 0 {
+		if err := d.client.SelectFiles(ctx, st.GID, selected); err != nil {
+			logutils.Log.WithError(err).WithField("gid", st.GID).Warn("Failed to deselect padding files")
+		}
+	}
+	vCount := countVideoFiles(st.Files)
+	if d.onMagnetMetadata != nil {
+		d.onMagnetMetadata(relPaths, totalBytes, vCount)
+	}
+	return vCount
+}
+
+func (d *Aria2RPCDownloader) removeDownloadResult(gid string) {
+	ctx, cancel := context.WithTimeout(context.Background(), rpcStopTimeout)
+	defer cancel()
+	if err := d.client.Remove(ctx, gid); err != nil {
+		logutils.Log.WithError(err).WithField("gid", gid).Warn("Failed to remove completed aria2 download result")
+	}
+}
+
+func (d *Aria2RPCDownloader) StopDownload() error {
+	d.mu.Lock()
+	d.stoppedManually = true
+	gid := d.gid
+	d.mu.Unlock()
+	if gid == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), rpcStopTimeout)
+	defer cancel()
+	if err := d.client.Remove(ctx, gid); err != nil {
+		logutils.Log.WithError(err).WithField("gid", gid).Warn("aria2 remove failed")
+		return err
+	}
+	return nil
+}
+
+// rpcOptions maps ARIA2_* settings to per-download options. Global options (DHT, listen ports, open
+// file and overall upload limits) belong to the daemon's own configuration.
+func rpcOptions(dir string, cfg config.Aria2Config) map[string]string {
+	opts := map[string]string{
+		"dir":                         dir,
+		"file-allocation":             cfg.FileAllocation,
+		"allow-overwrite":             "false",
+		"auto-file-renaming":          "true",
+		"max-connection-per-server":   strconv.Itoa(cfg.MaxConnectionsPerServer),
+		"split":                       strconv.Itoa(cfg.Split),
+		"min-split-size":              cfg.MinSplitSize,
+		"bt-max-peers":                strconv.Itoa(cfg.BTMaxPeers),
+		"bt-request-peer-speed-limit": cfg.BTRequestPeerSpeedLimit,
+		"max-upload-limit":            cfg.MaxUploadLimit,
+		"seed-ratio":                  strconv.FormatFloat(cfg.SeedRatio, 'f', 1, 64),
+		"seed-time":                   strconv.Itoa(cfg.SeedTime),
+		"bt-tracker-timeout":          strconv.Itoa(cfg.BTTrackerTimeout),
+		"bt-tracker-interval":         strconv.Itoa(cfg.BTTrackerInterval),
+		"enable-peer-exchange":        strconv.FormatBool(cfg.EnablePeerExchange),
+		"bt-enable-lpd":               strconv.FormatBool(cfg.EnableLocalPeerDiscovery),
+		"bt-save-metadata":            strconv.FormatBool(cfg.BTSaveMetadata),
+		"bt-hash-check-seed":          strconv.FormatBool(cfg.BTHashCheckSeed),
+		"bt-require-crypto":           strconv.FormatBool(cfg.BTRequireCrypto),
+		"check-integrity":             strconv.FormatBool(cfg.CheckIntegrity),
+		"continue":                    strconv.FormatBool(cfg.ContinueDownload),
+		"remote-time":                 strconv.FormatBool(cfg.RemoteTime),
+		"follow-torrent":              strconv.FormatBool(cfg.FollowTorrent),
+		"timeout":                     strconv.Itoa(cfg.Timeout),
+		"max-tries":                   strconv.Itoa(cfg.MaxTries),
+		"retry-wait":                  strconv.Itoa(cfg.RetryWait),
+		"bt-tracker":                  strings.Join(fallbackTrackers, ","),
+	}
+	if cfg.BTRequireCrypto {
+		opts["bt-min-crypto-level"] = cfg.BTMinCryptoLevel
+	}
+	if cfg.UserAgent != "" {
+		opts["user-agent"] = cfg.UserAgent
+	}
+	return opts
+}
+
+// selectedFileIndices returns the 1-based indices of the selected real files, or nil when the torrent
+// has no padding files and no selection (aria2 then downloads everything by default).
+func selectedFileIndices(meta *Meta, sel FileSelection) []int {
+	var indices []int
+	skipped := false
+	for i := range meta.Info.Files {
+		path := meta.Info.Files[i].Path
+		if (len(path) > 0 && strings.HasPrefix(path[len(path)-1], paddingFileMarker)) || !sel.Includes(i+1) {
+			skipped = true
+			continue
+		}
+		indices = append(indices, i+1)
+	}
+	if !skipped {
+		return nil
+	}
+	return indices
+}
+
+func joinIndices(indices []int) string {
+	parts := make([]string, len(indices))
+	for i, idx := range indices {
+		parts[i] = strconv.Itoa(idx)
+	}
+	return strings.Join(parts, ",")
+}
+
+func countVideoFiles(files []File) int {
+	n := 0
+	for i := range files {
+		if files[i].Selected != "false" && tvcompat.IsVideoFilePath(files[i].Path) {
+			n++
+		}
+	}
+	return n
+}
+
+func countCompletedVideoFiles(files []File) int {
+	n := 0
+	for i := range files {
+		f := &files[i]
+		if f.Selected != "false" && tvcompat.IsVideoFilePath(f.Path) && f.Length > 0 && f.CompletedLength >= f.Length {
+			n++
+		}
+	}
+	return n
+}
+
+// Ensure Aria2RPCDownloader implements downloader.Downloader and optional interfaces.
+var (
+	_ downloader.Downloader               = (*Aria2RPCDownloader)(nil)
+	_ downloader.EarlyCompatDownloader    = (*Aria2RPCDownloader)(nil)
+	_ downloader.OnHashKnownSetter        = (*Aria2RPCDownloader)(nil)
+	_ downloader.MagnetMetadataSyncSetter = (*Aria2RPCDownloader)(nil)
+	_ downloader.FileSelector             = (*Aria2RPCDownloader)(nil)
+	_ downloader.FileSelector             = (*Aria2Downloader)(nil)
+	_ downloader.InfoHashDownloader       = (*Aria2RPCDownloader)(nil)
+	_ downloader.InfoHashDownloader       = (*Aria2Downloader)(nil)
+)
@@ -0,0 +1,242 @@
+package aria2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/testutils"
+)
+
+type rpcCall struct {
+	Method string
+	Params []json.RawMessage
+}
+
+// rpcServer emulates the aria2 JSON-RPC endpoint; handle returns a result or an RPC error message.
+func rpcServer(t *testing.T, handle func(call rpcCall) (result any, errMsg string)) (*httptest.Server, *[]rpcCall) {
+	t.Helper()
+	var (
+		mu    sync.Mutex
+		calls []rpcCall
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != rpcDefaultPath {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			ID     string            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		call := rpcCall{Method: req.Method, Params: req.Params}
+		mu.Lock()
+		calls = append(calls, call)
+		mu.Unlock()
+		result, errMsg := handle(call)
+		if errMsg != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "error": map[string]any{"code": 1, "message": errMsg}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "result": result})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestRPCClientGetVersionWithSecret(t *testing.T) {
+	t.Parallel()
+	srv, _ := rpcServer(t, func(call rpcCall) (any, string) {
+		if call.Method != "aria2.getVersion" || len(call.Params) != 1 || string(call.Params[0]) != `"token:s3cret"` {
+			return nil, "Unauthorized"
+		}
+		return map[string]any{"version": "1.37.0"}, ""
+	})
+
+	client, err := NewRPCClient(srv.URL, "s3cret")
+	if err != nil {
+		t.Fatalf("NewRPCClient: %v", err)
+	}
+	version, err := client.GetVersion(context.Background())
+	if err != nil {
+		t.Fatalf("GetVersion: %v", err)
+	}
+	if version != "1.37.0" {
+		t.Fatalf("version = %q, want 1.37.0", version)
+	}
+}
+
+func TestRPCClientGIDNotFound(t *testing.T) {
+	t.Parallel()
+	srv, _ := rpcServer(t, func(rpcCall) (any, string) {
+		return nil, "GID 2089b05ecca3d829 is not found"
+	})
+
+	client, _ := NewRPCClient(srv.URL+rpcDefaultPath, "")
+	if _, err := client.TellStatus(context.Background(), "2089b05ecca3d829"); !errors.Is(err, ErrGIDNotFound) {
+		t.Fatalf("TellStatus error = %v, want ErrGIDNotFound", err)
+	}
+}
+
+func TestNewRPCClientURLs(t *testing.T) {
+	t.Parallel()
+	client, err := NewRPCClient("ws://localhost:6800/jsonrpc", "")
+	if err != nil {
+		t.Fatalf("NewRPCClient(ws): %v", err)
+	}
+	if client.endpoint != "http://localhost:6800/jsonrpc" {
+		t.Fatalf("endpoint = %q, want http://localhost:6800/jsonrpc", client.endpoint)
+	}
+	if _, err := NewRPCClient("tcp://localhost:6800", ""); err == nil {
+		t.Fatal("NewRPCClient accepted tcp scheme")
+	}
+}
+
+func TestStatusDecodeAndProgress(t *testing.T) {
+	t.Parallel()
+	raw := `{"gid":"a1","status":"active","totalLength":"200","completedLength":"50","downloadSpeed":"1024",
+		"numSeeders":"3","files":[{"index":"1","path":"/media/Show/E01.mkv","length":"100","completedLength":"100","selected":"true"},
+		{"index":"2","path":"/media/Show/E02.mkv","length":"100","completedLength":"0","selected":"true"}]}`
+	var st Status
+	if err := json.Unmarshal([]byte(raw), &st); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got := st.Progress(); got != 25 {
+		t.Fatalf("Progress = %v, want 25", got)
+	}
+	if st.DataComplete() {
+		t.Fatal("DataComplete = true for partial download")
+	}
+	if got := countCompletedVideoFiles(st.Files); got != 1 {
+		t.Fatalf("countCompletedVideoFiles = %d, want 1", got)
+	}
+
+	seeding := Status{Status: StatusActive, Seeder: "true", TotalLength: 10, CompletedLength: 10}
+	if !seeding.DataComplete() {
+		t.Fatal("DataComplete = false for seeding download")
+	}
+	metadata := Status{Status: StatusComplete, TotalLength: 10, CompletedLength: 10, Files: []File{{Path: "[METADATA]abc"}}}
+	if metadata.DataComplete() {
+		t.Fatal("DataComplete = true for magnet metadata download")
+	}
+}
+
+func TestSelectedFileIndices(t *testing.T) {
+	t.Parallel()
+	meta := &Meta{}
+	meta.Info.Files = []struct {
+		Length int64    `bencode:"length"`
+		Path   []string `bencode:"path"`
+	}{
+		{Length: 10, Path: []string{"E01.mkv"}},
+		{Length: 5, Path: []string{".pad", "_____padding_file_0_"}},
+		{Length: 10, Path: []string{"E02.mkv"}},
+	}
+	got := selectedFileIndices(meta, nil)
+	if len(got) != 2 || got[0] != 1 || got[1] != 3 {
+		t.Fatalf("selectedFileIndices = %v, want [1 3]", got)
+	}
+	got = selectedFileIndices(meta, FileSelection{3: true})
+	if len(got) != 1 || got[0] != 3 {
+		t.Fatalf("selectedFileIndices with selection = %v, want [3]", got)
+	}
+
+	meta.Info.Files = meta.Info.Files[:1]
+	if got := selectedFileIndices(meta, nil); got != nil {
+		t.Fatalf("selectedFileIndices without padding = %v, want nil", got)
+	}
+}
+
+func TestAria2RPCDownloaderAddAndStop(t *testing.T) {
+	logutils.InitLogger("debug")
+	tempDir := testutils.TempDir(t)
+	cfg := testutils.TestConfig(tempDir)
+
+	srv, calls := rpcServer(t, func(call rpcCall) (any, string) {
+		switch call.Method {
+		case "aria2.addTorrent":
+			var opts map[string]string
+			if len(call.Params) != 3 || json.Unmarshal(call.Params[2], &opts) != nil || opts["dir"] != tempDir {
+				return nil, "bad addTorrent params"
+			}
+			return "2089b05ecca3d829", ""
+		case "aria2.forceRemove", "aria2.removeDownloadResult":
+			return "OK", ""
+		default:
+			return nil, "unexpected method " + call.Method
+		}
+	})
+	cfg.Aria2Settings.RPCURL = srv.URL
+
+	torrentPath := testutils.CreateRealTestTorrent(t, tempDir, "rpc-add")
+	dl, err := NewAria2RPCDownloader(filepath.Base(torrentPath), tempDir, cfg)
+	if err != nil {
+		t.Fatalf("NewAria2RPCDownloader: %v", err)
+	}
+	d := dl.(*Aria2RPCDownloader)
+	var persisted string
+	d.SetOnHashKnown(func(gid string) { persisted = gid })
+
+	gid, err := d.addOrAttach(context.Background())
+	if err != nil {
+		t.Fatalf("addOrAttach: %v", err)
+	}
+	if gid != "2089b05ecca3d829" || persisted != gid {
+		t.Fatalf("gid = %q, persisted = %q, want 2089b05ecca3d829", gid, persisted)
+	}
+
+	if err := d.StopDownload(); err != nil {
+		t.Fatalf("StopDownload: %v", err)
+	}
+	if !d.StoppedManually() {
+		t.Fatal("StoppedManually = false after StopDownload")
+	}
+	methods := make([]string, 0, len(*calls))
+	for _, c := range *calls {
+		methods = append(methods, c.Method)
+	}
+	want := []string{"aria2.addTorrent", "aria2.forceRemove", "aria2.removeDownloadResult"}
+	if len(methods) != len(want) {
+		t.Fatalf("RPC calls = %v, want %v", methods, want)
+	}
+	for i := range want {
+		if methods[i] != want[i] {
+			t.Fatalf("RPC calls = %v, want %v", methods, want)
+		}
+	}
+}
+
+func TestRPCClientSetSpeedLimits(t *testing.T) {
+	t.Parallel()
+	srv, calls := rpcServer(t, func(rpcCall) (any, string) { return "OK", "" })
+
+	client, err := NewRPCClient(srv.URL, "")
+	if err != nil {
+		t.Fatalf("NewRPCClient: %v", err)
+	}
+	if err := client.SetSpeedLimits(context.Background(), 2<<20, 512<<10); err != nil {
+		t.Fatalf("SetSpeedLimits: %v", err)
+	}
+	if len(*calls) != 1 || (*calls)[0].Method != "aria2.changeGlobalOption" {
+		t.Fatalf("calls = %+v", *calls)
+	}
+	var options map[string]string
+	if err := json.Unmarshal((*calls)[0].Params[0], &options); err != nil {
+		t.Fatalf("decode options: %v", err)
+	}
+	if options["max-overall-download-limit"] != "2097152" || options["max-overall-upload-limit"] != "524288" {
+		t.Fatalf("options = %v", options)
+	}
+}
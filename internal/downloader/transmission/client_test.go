@@ -0,0 +1,152 @@
+package transmission
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// rpcServer emulates Transmission's CSRF handshake and dispatches methods to handle.
+func rpcServer(t *testing.T, handle func(method string, args map[string]any) (string, any)) *httptest.Server {
+	t.Helper()
+	const sessionID = "test-session"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/transmission/rpc" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get(sessionIDHeader) != sessionID {
+			w.Header().Set(sessionIDHeader, sessionID)
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		var req struct {
+			Method    string         `json:"method"`
+			Arguments map[string]any `json:"arguments"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, args := handle(req.Method, req.Arguments)
+		_ = json.NewEncoder(w).Encode(map[string]any{"result": result, "arguments": args})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClientSessionIDNegotiation(t *testing.T) {
+	t.Parallel()
+	srv := rpcServer(t, func(method string, _ map[string]any) (string, any) {
+		if method != "session-get" {
+			return "method not recognized", nil
+		}
+		return "success", map[string]any{"version": "4.0.5"}
+	})
+
+	client, err := NewClient(srv.URL, "", "")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	version, err := client.SessionVersion(context.Background())
+	if err != nil {
+		t.Fatalf("SessionVersion: %v", err)
+	}
+	if version != "4.0.5" {
+		t.Fatalf("version = %q, want 4.0.5", version)
+	}
+}
+
+func TestClientAddTorrentDuplicate(t *testing.T) {
+	t.Parallel()
+	srv := rpcServer(t, func(method string, args map[string]any) (string, any) {
+		if method != "torrent-add" || args["filename"] == nil || args["download-dir"] != "/media" {
+			return "bad request", nil
+		}
+		return "success", map[string]any{
+			"torrent-duplicate": map[string]any{"id": 7, "name": "Movie", "hashString": "abc123"},
+		}
+	})
+
+	client, _ := NewClient(srv.URL+"/transmission/rpc", "", "")
+	added, err := client.AddTorrent(context.Background(), "magnet:?xt=urn:btih:abc123", nil, "/media")
+	if err != nil {
+		t.Fatalf("AddTorrent: %v", err)
+	}
+	if added.HashString != "abc123" {
+		t.Fatalf("hash = %q, want abc123", added.HashString)
+	}
+}
+
+func TestClientRPCFailureResult(t *testing.T) {
+	t.Parallel()
+	srv := rpcServer(t, func(string, map[string]any) (string, any) {
+		return "invalid or corrupt torrent file", nil
+	})
+
+	client, _ := NewClient(srv.URL, "", "")
+	if _, err := client.AddTorrent(context.Background(), "", []byte("d4:infoe"), "/media"); err == nil {
+		t.Fatal("AddTorrent succeeded, want RPC error")
+	}
+}
+
+func TestClientUnauthorized(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	client, _ := NewClient(srv.URL, "user", "bad")
+	if _, err := client.SessionVersion(context.Background()); err == nil {
+		t.Fatal("SessionVersion succeeded, want unauthorized error")
+	}
+}
+
+func TestClientGetTorrentMissing(t *testing.T) {
+	t.Parallel()
+	srv := rpcServer(t, func(string, map[string]any) (string, any) {
+		return "success", map[string]any{"torrents": []any{}}
+	})
+
+	client, _ := NewClient(srv.URL, "", "")
+	torrent, err := client.GetTorrent(context.Background(), "abc123")
+	if err != nil {
+		t.Fatalf("GetTorrent: %v", err)
+	}
+	if torrent != nil {
+		t.Fatalf("torrent = %+v, want nil", torrent)
+	}
+}
+
+func TestNewClientRejectsInvalidURL(t *testing.T) {
+	t.Parallel()
+	if _, err := NewClient("ftp://nas:9091", "", ""); err == nil {
+		t.Fatal("NewClient accepted ftp scheme")
+	}
+}
+
+func TestClientSetSpeedLimits(t *testing.T) {
+	t.Parallel()
+	var got map[string]any
+	srv := rpcServer(t, func(method string, args map[string]any) (string, any) {
+		if method != "session-set" {
+			return "method not recognized", nil
+		}
+		got = args
+		return "success", nil
+	})
+
+	client, err := NewClient(srv.URL+"/transmission/rpc", "", "")
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := client.SetSpeedLimits(context.Background(), 1500, 0); err != nil {
+		t.Fatalf("SetSpeedLimits: %v", err)
+	}
+	if got["speed-limit-down"] != float64(2) || got["speed-limit-down-enabled"] != true || got["speed-limit-up-enabled"] != false {
+		t.Fatalf("session-set arguments = %v", got)
+	}
+}
@@ -0,0 +1,641 @@
+package transmission
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
+	aria2pkg "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/torrent"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/tvcompat"
+	"github.com/go-bittorrent/magneturi"
+)
+
+const (
+	pollInterval       = 3 * time.Second
+	progressPercentMax = 100
+	stopTimeout        = 15 * time.Second
+	deleteTimeout      = 10 * time.Second
+)
+
+// episodesChanCapacity returns the buffer size for incremental episode notifications (see qbittorrent).
+func episodesChanCapacity(totalVideo int) int {
+	if totalVideo < 2 {
+		return 1
+	}
+	return totalVideo
+}
+
+// TransmissionDownloader implements downloader.Downloader using Transmission JSON-RPC.
+type TransmissionDownloader struct {
+	torrentFileName string
+	downloadDir     string
+	cfg             *config.Config
+	magnetURI       string
+	client          *Client
+
+	mu                       sync.Mutex
+	hash                     string
+	hashChan                 chan string       // sends the torrent info hash once when known (for DB persistence)
+	onHashKnown              func(hash string) // optional; called synchronously when hash is known
+	stoppedManually          bool
+	resumeHash               string // when set, skip add and poll by this hash (resume after restart)
+	initialCompletedEpisodes int    // for resume: episodes already completed before restart (from DB)
+	totalEpisodesStored      int    // for resume: total episode count when no torrent file (from DB)
+	onMagnetMetadata         func(paths []string, totalBytes int64, videoFileCount int)
+	magnetDBSynced           bool // true after the first metadata sync to DB (magnet only)
+	selection                aria2pkg.FileSelection
+}
+
+// NewTransmissionDownloader creates a downloader that uses Transmission.
+// torrentFileName is the .torrent or .magnet file name under moviePath.
+func NewTransmissionDownloader(torrentFileName, moviePath string, cfg *config.Config) (downloader.Downloader, error) {
+	if cfg.TransmissionURL == "" {
+		return nil, fmt.Errorf("TransmissionURL is not set")
+	}
+	client, err := NewClient(cfg.TransmissionURL, cfg.TransmissionUsername, cfg.TransmissionPassword)
+	if err != nil {
+		return nil, err
+	}
+	d := &TransmissionDownloader{
+		torrentFileName: torrentFileName,
+		downloadDir:     moviePath,
+		cfg:             cfg,
+		client:          client,
+		hashChan:        make(chan string, 1),
+	}
+	if strings.HasSuffix(strings.ToLower(torrentFileName), ".magnet") {
+		path := filepath.Join(moviePath, torrentFileName)
+		if b, err := os.ReadFile(path); err == nil {
+			d.magnetURI = strings.TrimSpace(string(b))
+		}
+	}
+	return d, nil
+}
+
+// NewTransmissionResumeDownloader creates a downloader that monitors an already-added torrent by hash (e.g. after bot restart).
+// completedEpisodes is the number of episodes already completed (from DB) so we do not re-send OnFirstEpisodeReady.
+func NewTransmissionResumeDownloader(
+	hash, downloadDir string,
+	totalEpisodes, completedEpisodes int,
+	cfg *config.Config,
+) (downloader.Downloader, error) {
+	if cfg.TransmissionURL == "" {
+		return nil, fmt.Errorf("TransmissionURL is not set")
+	}
+	client, err := NewClient(cfg.TransmissionURL, cfg.TransmissionUsername, cfg.TransmissionPassword)
+	if err != nil {
+		return nil, err
+	}
+	return &TransmissionDownloader{
+		downloadDir:              downloadDir,
+		cfg:                      cfg,
+		client:                   client,
+		resumeHash:               hash,
+		initialCompletedEpisodes: completedEpisodes,
+		totalEpisodesStored:      totalEpisodes,
+	}, nil
+}
+
+func (d *TransmissionDownloader) parseMeta() (*aria2pkg.Meta, error) {
+	if d.magnetURI != "" {
+		m := &aria2pkg.Meta{}
+		m.Info.Name = "Magnet download"
+		if parsed, err := magneturi.Parse(d.magnetURI); err == nil {
+			if parsed.DisplayName != "" {
+				m.Info.Name = parsed.DisplayName
+			}
+			if parsed.ExactLength > 0 {
+				m.Info.Length = parsed.ExactLength
+			}
+		}
+		return m, nil
+	}
+	return aria2pkg.ParseMeta(filepath.Join(d.downloadDir, d.torrentFileName))
+}
+
+func (d *TransmissionDownloader) GetTitle() (string, error) {
+	if d.resumeHash != "" {
+		return "", nil
+	}
+	meta, err := d.parseMeta()
+	if err != nil {
+		return "", err
+	}
+	return meta.Info.Name, nil
+}
+
+func (d *TransmissionDownloader) GetFiles() (mainFiles, tempFiles []string, err error) {
+	if d.resumeHash != "" {
+		return nil, nil, fmt.Errorf("resume downloader has no torrent meta")
+	}
+	meta, err := d.parseMeta()
+	if err != nil {
+		return nil, nil, err
+	}
+	var skipped []string
+	if len(meta.Info.Files) > 0 {
+		if meta.Info.Name == "" {
+			return nil, nil, fmt.Errorf("torrent meta does not contain a root directory name")
+		}
+		for i, file := range meta.Info.Files {
+			if len(file.Path) == 0 {
+				return nil, nil, fmt.Errorf("file path is empty in torrent meta")
+			}
+			if d.selection.Includes(i + 1) {
+				mainFiles = append(mainFiles, meta.FilePath(i))
+			} else {
+				// Pieces shared with an unwanted file can still create it on disk.
+				skipped = append(skipped, meta.FilePath(i))
+			}
+		}
+	} else {
+		mainFiles = append(mainFiles, meta.Info.Name)
+	}
+	// Transmission keeps unfinished files as "<name>.part" when rename-partial-files is on.
+	tempFiles = []string{d.torrentFileName}
+	for _, f := range mainFiles {
+		tempFiles = append(tempFiles, f+".part")
+	}
+	for _, f := range skipped {
+		tempFiles = append(tempFiles, f, f+".part")
+	}
+	return mainFiles, tempFiles, nil
+}
+
+func (d *TransmissionDownloader) GetFileSize() (int64, error) {
+	if d.resumeHash != "" {
+		return 0, nil
+	}
+	meta, err := d.parseMeta()
+	if err != nil {
+		logutils.Log.WithError(err).Warn("Failed to parse torrent metadata for file size, returning 0")
+		return 0, nil
+	}
+	if len(meta.Info.Files) > 0 {
+		var total int64
+		for i, f := range meta.Info.Files {
+			if d.selection.Includes(i + 1) {
+				total += f.Length
+			}
+		}
+		return total, nil
+	}
+	return meta.Info.Length, nil
+}
+
+func (d *TransmissionDownloader) TotalEpisodes() int {
+	if d.resumeHash != "" {
+		return d.totalEpisodesStored
+	}
+	meta, err := d.parseMeta()
+	if err != nil {
+		return 0
+	}
+	var n int
+	for i := range meta.Info.Files {
+		if d.selection.Includes(i+1) && tvcompat.IsVideoFilePath(meta.FilePath(i)) {
+			n++
+		}
+	}
+	if n == 0 && len(meta.Info.Files) == 0 && meta.Info.Name != "" {
+		return 1
+	}
+	return n
+}
+
+func (d *TransmissionDownloader) StoppedManually() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stoppedManually
+}
+
+func (d *TransmissionDownloader) setHash(h string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.hash = h
+}
+
+func (d *TransmissionDownloader) StartDownload(
+	ctx context.Context,
+) (progressChan chan float64, errChan chan error, episodesChan <-chan int, err error) {
+	progressChan = make(chan float64)
+	errChan = make(chan error, 1)
+	totalVideo := d.totalEpisodesStored
+	if d.resumeHash == "" {
+		if _, metaErr := d.parseMeta(); metaErr != nil {
+			return nil, nil, nil, fmt.Errorf("failed to parse torrent meta: %w", metaErr)
+		}
+		d.hashChan = make(chan string, 1)
+		totalVideo = d.TotalEpisodes()
+	}
+	var epCh chan int
+	if totalVideo > 1 {
+		epCh = make(chan int, episodesChanCapacity(totalVideo))
+		episodesChan = epCh
+	}
+	go d.run(ctx, totalVideo, progressChan, errChan, epCh)
+	return progressChan, errChan, episodesChan, nil
+}
+
+// QBittorrentHashChan implements downloader.QBittorrentHashDownloader; the hash is the torrent info hash.
+func (d *TransmissionDownloader) QBittorrentHashChan() <-chan string {
+	return d.hashChan
+}
+
+// SetOnHashKnown implements downloader.OnHashKnownSetter.
+func (d *TransmissionDownloader) SetOnHashKnown(cb func(hash string)) {
+	d.onHashKnown = cb
+}
+
+// TorrentClient implements downloader.OnHashKnownSetter.
+func (*TransmissionDownloader) TorrentClient() string {
+	return config.TorrentClientTransmission
+}
+
+// SetOnMagnetMetadataReady implements downloader.MagnetMetadataSyncSetter.
+func (d *TransmissionDownloader) SetOnMagnetMetadataReady(cb func(paths []string, totalBytes int64, videoFileCount int)) {
+	d.onMagnetMetadata = cb
+}
+
+// stopErr is the result to report when ctx is done.
+func (d *TransmissionDownloader) stopErr(ctx context.Context) error {
+	if d.StoppedManually() {
+		return downloader.ErrStoppedByUser
+	}
+	return ctx.Err()
+}
+
+// addOrAttach adds the torrent (or, on resume, checks it is still in the session) and returns its hash.
+func (d *TransmissionDownloader) addOrAttach(ctx context.Context, totalVideo int) (string, error) {
+	if d.resumeHash != "" {
+		t, err := d.client.GetTorrent(ctx, d.resumeHash)
+		if err != nil {
+			return "", fmt.Errorf("transmission resume torrent-get: %w", err)
+		}
+		if t == nil {
+			return "", fmt.Errorf("transmission: resumed torrent not found (hash=%s)", d.resumeHash)
+		}
+		d.setHash(d.resumeHash)
+		return d.resumeHash, nil
+	}
+
+	var metainfo []byte
+	if d.magnetURI == "" {
+		body, err := os.ReadFile(filepath.Join(d.downloadDir, d.torrentFileName))
+		if err != nil {
+			return "", fmt.Errorf("read torrent file: %w", err)
+		}
+		metainfo = body
+	}
+	added, err := d.client.AddTorrent(ctx, d.magnetURI, metainfo, d.downloadDir)
+	if err != nil {
+		return "", fmt.Errorf("transmission torrent-add: %w", err)
+	}
+	hash := added.HashString
+	d.setHash(hash)
+	logutils.Log.WithField("hash", hash).Info("Transmission torrent hash determined")
+	// Persist hash synchronously so it survives process restart.
+	if d.onHashKnown != nil {
+		d.onHashKnown(hash)
+	}
+	if d.hashChan != nil {
+		select {
+		case d.hashChan <- hash:
+		default:
+		}
+		close(d.hashChan)
+		d.hashChan = nil
+	}
+	if d.selection != nil {
+		d.skipDeselectedFiles(ctx, hash)
+	}
+	if totalVideo > 1 {
+		d.applyLexicographicPriorities(ctx, hash)
+	}
+	return hash, nil
+}
+
+func (d *TransmissionDownloader) run(
+	ctx context.Context,
+	totalVideo int,
+	progressChan chan float64,
+	errChan chan error,
+	episodesChan chan int,
+) {
+	defer close(errChan)
+	if episodesChan != nil {
+		defer close(episodesChan)
+	}
+	defer close(progressChan)
+
+	hash, err := d.addOrAttach(ctx, totalVideo)
+	if err != nil {
+		errChan <- err
+		return
+	}
+
+	select {
+	case progressChan <- 0:
+	case <-ctx.Done():
+		errChan <- d.stopErr(ctx)
+		return
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	effectiveVideoCount := totalVideo
+	lastProgress := -1.0
+	lastCompletedEpisodes := d.initialCompletedEpisodes
+	for {
+		select {
+		case <-ctx.Done():
+			errChan <- d.stopErr(ctx)
+			return
+		case <-ticker.C:
+		}
+
+		t, err := d.client.GetTorrent(ctx, hash)
+		if err != nil {
+			if ctx.Err() != nil {
+				errChan <- d.stopErr(ctx)
+			} else {
+				errChan <- fmt.Errorf("transmission torrent-get: %w", err)
+			}
+			return
+		}
+		if t == nil {
+			if d.StoppedManually() {
+				errChan <- downloader.ErrStoppedByUser
+			} else {
+				errChan <- fmt.Errorf("transmission: torrent no longer in session")
+			}
+			return
+		}
+		if t.Error == errorLocal {
+			errChan <- fmt.Errorf("transmission: %s", t.ErrorString)
+			return
+		}
+
+		if progress := min(t.PercentDone*progressPercentMax, progressPercentMax); progress != lastProgress {
+			lastProgress = progress
+			select {
+			case progressChan <- progress:
+			case <-ctx.Done():
+				errChan <- d.stopErr(ctx)
+				return
+			}
+		}
+
+		// Magnet: replace placeholder DB paths with real ones once metadata is known.
+		if d.magnetURI != "" && !d.magnetDBSynced && t.MetadataPercentComplete >= 1 && len(t.Files) > 0 {
+			d.magnetDBSynced = true
+			vCount := d.syncMagnetMetadata(t.Files)
+			if vCount > effectiveVideoCount {
+				effectiveVideoCount = vCount
+			}
+			if effectiveVideoCount > 1 {
+				d.applyLexicographicPriorities(ctx, hash)
+			}
+		}
+
+		completed := countCompletedVideoFiles(d.selectedFiles(t.Files))
+		if episodesChan != nil && effectiveVideoCount > 1 && completed > lastCompletedEpisodes {
+			lastCompletedEpisodes = completed
+			select {
+			case episodesChan <- completed:
+			case <-ctx.Done():
+				errChan <- d.stopErr(ctx)
+				return
+			}
+		}
+
+		if !torrentReadyToFinalize(t) {
+			continue
+		}
+		if effectiveVideoCount > 1 && completed < effectiveVideoCount {
+			continue
+		}
+		if episodesChan != nil && effectiveVideoCount > 0 && lastCompletedEpisodes != effectiveVideoCount {
+			select {
+			case episodesChan <- effectiveVideoCount:
+			case <-ctx.Done():
+				errChan <- d.stopErr(ctx)
+				return
+			}
+		}
+		d.removeTorrentOnCompletion(hash)
+		errChan <- nil
+		return
+	}
+}
+
+// syncMagnetMetadata reports real relative paths and sizes to the manager; returns the video file count.
+func (d *TransmissionDownloader) syncMagnetMetadata(files []TorrentFile) int {
+	relPaths := make([]string, 0, len(files))
+	var totalBytes int64
+	for i := range files {
+		relPaths = append(relPaths, filepath.FromSlash(files[i].Name))
+		totalBytes += files[i].Length
+	}
+	vCount := countVideoFiles(files)
+	if d.onMagnetMetadata != nil {
+		d.onMagnetMetadata(relPaths, totalBytes, vCount)
+	}
+	return vCount
+}
+
+// removeTorrentOnCompletion removes the finished torrent from Transmission, keeping the data.
+func (d *TransmissionDownloader) removeTorrentOnCompletion(hash string) {
+	ctx, cancel := context.WithTimeout(context.Background(), deleteTimeout)
+	defer cancel()
+	if err := d.client.RemoveTorrent(ctx, hash, false); err != nil {
+		logutils.Log.WithError(err).WithField("hash", hash).Warn("Failed to remove torrent from Transmission on completion")
+		return
+	}
+	logutils.Log.WithField("hash", hash).Info("Removed completed torrent from Transmission")
+}
+
+// torrentReadyToFinalize is true once metadata is known, nothing is left to download and the
+// torrent is not being verified (percentDone is 1.0 during a recheck of complete data too).
+func torrentReadyToFinalize(t *Torrent) bool {
+	if t == nil || t.Error == errorLocal || t.MetadataPercentComplete < 1 {
+		return false
+	}
+	if t.Status == StatusCheckWait || t.Status == StatusCheck {
+		return false
+	}
+	return t.SizeWhenDone > 0 && t.LeftUntilDone == 0
+}
+
+func countVideoFiles(files []TorrentFile) int {
+	n := 0
+	for i := range files {
+		if tvcompat.IsVideoFilePath(files[i].Name) {
+			n++
+		}
+	}
+	return n
+}
+
+func countCompletedVideoFiles(files []TorrentFile) int {
+	n := 0
+	for i := range files {
+		f := &files[i]
+		if tvcompat.IsVideoFilePath(f.Name) && f.Length > 0 && f.BytesCompleted >= f.Length {
+			n++
+		}
+	}
+	return n
+}
+
+// applyLexicographicPriorities gives the first video file (by name) high priority so the first
+// episode is ready early; Transmission has no per-file "maximal" level like qBittorrent.
+func (d *TransmissionDownloader) applyLexicographicPriorities(ctx context.Context, hash string) {
+	t, err := d.client.GetTorrent(ctx, hash)
+	if err != nil || t == nil {
+		logutils.Log.WithError(err).Warn("Failed to get torrent files for priority setup")
+		return
+	}
+	var videos []int
+	for i := range t.Files {
+		if d.selection.Includes(i+1) && tvcompat.IsVideoFilePath(t.Files[i].Name) {
+			videos = append(videos, i)
+		}
+	}
+	if len(videos) <= 1 {
+		return
+	}
+	sort.Slice(videos, func(i, j int) bool { return t.Files[videos[i]].Name < t.Files[videos[j]].Name })
+	if err := d.client.SetFilePriorities(ctx, hash, videos[:1], videos[1:], nil); err != nil {
+		logutils.Log.WithError(err).WithField("hash", hash).Warn("Failed to set file priorities")
+		return
+	}
+	logutils.Log.WithFields(map[string]any{
+		"hash":        hash,
+		"video_files": len(videos),
+		"first_file":  t.Files[videos[0]].Name,
+	}).Info("Applied lexicographic file priorities for series")
+}
+
+// skipDeselectedFiles marks the files left out by SelectFiles as unwanted.
+func (d *TransmissionDownloader) skipDeselectedFiles(ctx context.Context, hash string) {
+	var unwanted []int
+	for idx := 1; idx <= d.fileCount(); idx++ {
+		if !d.selection.Includes(idx) {
+			unwanted = append(unwanted, idx-1)
+		}
+	}
+	if err := d.client.SetFilesUnwanted(ctx, hash, unwanted); err != nil {
+		logutils.Log.WithError(err).WithField("hash", hash).Warn("Failed to skip deselected files")
+		return
+	}
+	logutils.Log.WithFields(map[string]any{"hash": hash, "skipped_files": len(unwanted)}).Info("Skipped deselected torrent files")
+}
+
+func (d *TransmissionDownloader) fileCount() int {
+	meta, err := d.parseMeta()
+	if err != nil {
+		return 0
+	}
+	return len(meta.Info.Files)
+}
+
+// selectedFiles drops the files left out by SelectFiles; Transmission lists unwanted files too.
+func (d *TransmissionDownloader) selectedFiles(files []TorrentFile) []TorrentFile {
+	if d.selection == nil {
+		return files
+	}
+	selected := make([]TorrentFile, 0, len(d.selection))
+	for i := range files {
+		if d.selection.Includes(i + 1) {
+			selected = append(selected, files[i])
+		}
+	}
+	return selected
+}
+
+// TorrentFiles implements downloader.FileSelector.
+func (d *TransmissionDownloader) TorrentFiles() []downloader.TorrentFile {
+	if d.resumeHash != "" || d.magnetURI != "" {
+		return nil
+	}
+	meta, err := d.parseMeta()
+	if err != nil {
+		return nil
+	}
+	return meta.TorrentFiles()
+}
+
+// InfoHash implements downloader.InfoHashDownloader.
+func (d *TransmissionDownloader) InfoHash() string {
+	if d.resumeHash != "" {
+		return ""
+	}
+	return aria2pkg.SourceInfoHash(filepath.Join(d.downloadDir, d.torrentFileName), d.magnetURI)
+}
+
+// SelectFiles implements downloader.FileSelector; deselected files are marked unwanted once the torrent is added.
+func (d *TransmissionDownloader) SelectFiles(indices []int) error {
+	if d.resumeHash != "" {
+		return fmt.Errorf("resume downloader has no torrent meta")
+	}
+	meta, err := d.parseMeta()
+	if err != nil {
+		return err
+	}
+	sel, err := aria2pkg.NewFileSelection(meta, indices)
+	if err != nil {
+		return err
+	}
+	d.selection = sel
+	return nil
+}
+
+func (d *TransmissionDownloader) StopDownload() error {
+	d.mu.Lock()
+	d.stoppedManually = true
+	hash := d.hash
+	d.mu.Unlock()
+	if hash == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	if err := d.client.RemoveTorrent(ctx, hash, false); err != nil {
+		logutils.Log.WithError(err).WithField("hash", hash).Warn("Transmission torrent-remove failed")
+		return err
+	}
+	return nil
+}
+
+// GetEarlyTvCompatibility returns preliminary TV compatibility from torrent file names, or yellow for magnet.
+func (d *TransmissionDownloader) GetEarlyTvCompatibility(_ context.Context) (string, error) {
+	mainFiles, _, err := d.GetFiles()
+	if err != nil {
+		return "", err
+	}
+	compat := tvcompat.CompatFromTorrentFileNames(mainFiles)
+	if compat == "" && d.magnetURI != "" {
+		return tvcompat.TvCompatYellow, nil
+	}
+	return compat, nil
+}
+
+// Ensure TransmissionDownloader implements downloader.Downloader and optional interfaces.
+var (
+	_ downloader.Downloader                = (*TransmissionDownloader)(nil)
+	_ downloader.EarlyCompatDownloader     = (*TransmissionDownloader)(nil)
+	_ downloader.QBittorrentHashDownloader = (*TransmissionDownloader)(nil)
+	_ downloader.OnHashKnownSetter         = (*TransmissionDownloader)(nil)
+	_ downloader.MagnetMetadataSyncSetter  = (*TransmissionDownloader)(nil)
+	_ downloader.FileSelector              = (*TransmissionDownloader)(nil)
+	_ downloader.InfoHashDownloader        = (*TransmissionDownloader)(nil)
+)
@@ -0,0 +1,66 @@
+package transmission
+
+import "testing"
+
+func TestTorrentReadyToFinalize(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name string
+		t    Torrent
+		want bool
+	}{
+		{
+			name: "downloading",
+			t:    Torrent{Status: StatusDownload, MetadataPercentComplete: 1, SizeWhenDone: 100, LeftUntilDone: 40},
+			want: false,
+		},
+		{
+			name: "magnet_without_metadata",
+			t:    Torrent{Status: StatusDownload, MetadataPercentComplete: 0.5, SizeWhenDone: 0, LeftUntilDone: 0},
+			want: false,
+		},
+		{
+			name: "verifying_complete_data",
+			t:    Torrent{Status: StatusCheck, MetadataPercentComplete: 1, SizeWhenDone: 100, LeftUntilDone: 0},
+			want: false,
+		},
+		{
+			name: "seeding",
+			t:    Torrent{Status: StatusSeed, MetadataPercentComplete: 1, SizeWhenDone: 100, LeftUntilDone: 0},
+			want: true,
+		},
+		{
+			name: "stopped_after_completion",
+			t:    Torrent{Status: StatusStopped, MetadataPercentComplete: 1, SizeWhenDone: 100, LeftUntilDone: 0},
+			want: true,
+		},
+		{
+			name: "local_error",
+			t:    Torrent{Status: StatusStopped, MetadataPercentComplete: 1, SizeWhenDone: 100, Error: errorLocal},
+			want: false,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := torrentReadyToFinalize(&tc.t); got != tc.want {
+				t.Fatalf("torrentReadyToFinalize(%+v) = %v, want %v", tc.t, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCountCompletedVideoFiles(t *testing.T) {
+	t.Parallel()
+	files := []TorrentFile{
+		{Name: "Show/S01E01.mkv", Length: 100, BytesCompleted: 100},
+		{Name: "Show/S01E02.mkv", Length: 100, BytesCompleted: 50},
+		{Name: "Show/readme.txt", Length: 10, BytesCompleted: 10},
+	}
+	if got := countVideoFiles(files); got != 2 {
+		t.Fatalf("countVideoFiles = %d, want 2", got)
+	}
+	if got := countCompletedVideoFiles(files); got != 1 {
+		t.Fatalf("countCompletedVideoFiles = %d, want 1", got)
+	}
+}
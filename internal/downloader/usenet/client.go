@@ -0,0 +1,69 @@
+package usenet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+)
+
+const requestTimeout = 30 * time.Second
+
+// JobState is the normalized state of a Usenet job across SABnzbd and NZBGet.
+type JobState int
+
+const (
+	JobQueued JobState = iota
+	JobDownloading
+	JobPostProcessing // verify/repair/unpack/move after the download itself finished
+	JobCompleted
+	JobFailed
+)
+
+// Job is the state of one NZB in the client's queue or history.
+type Job struct {
+	ID      string
+	Name    string
+	State   JobState
+	Percent float64 // download progress, 0-100
+	// StoragePath is the final folder once the job is completed.
+	StoragePath string
+	FailMessage string
+}
+
+// Client is a Usenet download client (SABnzbd or NZBGet).
+type Client interface {
+	// Name is the client name for logs and errors.
+	Name() string
+	// Version returns the daemon version; used as a readiness check.
+	Version(ctx context.Context) (string, error)
+	// AddNZB submits NZB contents and returns the job id.
+	AddNZB(ctx context.Context, name string, nzb []byte) (string, error)
+	// Job looks the id up in queue and history; nil when the client no longer knows it.
+	Job(ctx context.Context, id string) (*Job, error)
+	// Remove deletes the job from queue and history; deleteFiles also removes downloaded data.
+	Remove(ctx context.Context, id string, deleteFiles bool) error
+}
+
+// NewClientFromConfig returns the client from SABNZBD_URL or NZBGET_URL.
+func NewClientFromConfig(cfg *config.Config) (Client, error) {
+	switch {
+	case cfg.SABnzbdURL != "":
+		return NewSABnzbdClient(cfg.SABnzbdURL, cfg.SABnzbdAPIKey, cfg.UsenetCategory)
+	case cfg.NZBGetURL != "":
+		return NewNZBGetClient(cfg.NZBGetURL, cfg.NZBGetUsername, cfg.NZBGetPassword, cfg.UsenetCategory)
+	default:
+		return nil, fmt.Errorf("no Usenet client configured (set SABNZBD_URL or NZBGET_URL)")
+	}
+}
+
+// Configured reports whether a Usenet client is set up.
+func Configured(cfg *config.Config) bool {
+	return cfg.SABnzbdURL != "" || cfg.NZBGetURL != ""
+}
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: requestTimeout}
+}
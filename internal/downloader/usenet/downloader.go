@@ -0,0 +1,310 @@
+package usenet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/tvcompat"
+)
+
+const (
+	pollInterval = 3 * time.Second
+	stopTimeout  = 15 * time.Second
+	// progressBeforeFinalize keeps the bar below 100% while the client verifies and unpacks.
+	progressBeforeFinalize = 99.9
+)
+
+// UsenetDownloader submits an NZB to SABnzbd or NZBGet and polls the job until it is post-processed.
+// The final folder is then moved under MOVIE_PATH (when the client stores it elsewhere) and its files
+// are reported through the magnet metadata callback so they replace the placeholder MovieFile rows.
+type UsenetDownloader struct {
+	nzbFileName string
+	downloadDir string
+	cfg         *config.Config
+	client      Client
+
+	mu               sync.Mutex
+	jobID            string
+	stoppedManually  bool
+	onMagnetMetadata func(paths []string, totalBytes int64, videoFileCount int)
+}
+
+// NewUsenetDownloader creates a downloader for nzbFileName (an .nzb under moviePath).
+func NewUsenetDownloader(nzbFileName, moviePath string, cfg *config.Config) (downloader.Downloader, error) {
+	client, err := NewClientFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newUsenetDownloader(nzbFileName, moviePath, cfg, client), nil
+}
+
+func newUsenetDownloader(nzbFileName, moviePath string, cfg *config.Config, client Client) *UsenetDownloader {
+	return &UsenetDownloader{
+		nzbFileName: nzbFileName,
+		downloadDir: moviePath,
+		cfg:         cfg,
+		client:      client,
+	}
+}
+
+func (d *UsenetDownloader) nzbPath() string {
+	return filepath.Join(d.downloadDir, d.nzbFileName)
+}
+
+// GetTitle returns the NZB's name meta, or the file name without extension.
+func (d *UsenetDownloader) GetTitle() (string, error) {
+	n, err := ParseNZB(d.nzbPath())
+	if err != nil {
+		return "", err
+	}
+	if name := n.Name(); name != "" {
+		return name, nil
+	}
+	return strings.TrimSuffix(d.nzbFileName, filepath.Ext(d.nzbFileName)), nil
+}
+
+// GetFiles returns the release folder as a placeholder; the real file list is synced on completion.
+func (d *UsenetDownloader) GetFiles() (mainFiles, tempFiles []string, err error) {
+	title, err := d.GetTitle()
+	if err != nil {
+		return nil, nil, err
+	}
+	return []string{title}, []string{d.nzbFileName}, nil
+}
+
+func (d *UsenetDownloader) GetFileSize() (int64, error) {
+	n, err := ParseNZB(d.nzbPath())
+	if err != nil {
+		logutils.Log.WithError(err).Warn("Failed to parse NZB for file size, returning 0")
+		return 0, nil
+	}
+	return n.Size(), nil
+}
+
+// TotalEpisodes is unknown until the release is unpacked.
+func (*UsenetDownloader) TotalEpisodes() int { return 0 }
+
+func (d *UsenetDownloader) StoppedManually() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stoppedManually
+}
+
+// SetOnMagnetMetadataReady implements downloader.MagnetMetadataSyncSetter; it receives the final file list.
+func (d *UsenetDownloader) SetOnMagnetMetadataReady(cb func(paths []string, totalBytes int64, videoFileCount int)) {
+	d.onMagnetMetadata = cb
+}
+
+func (d *UsenetDownloader) StartDownload(
+	ctx context.Context,
+) (progressChan chan float64, errChan chan error, episodesChan <-chan int, err error) {
+	title, err := d.GetTitle()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to parse nzb: %w", err)
+	}
+	progressChan = make(chan float64)
+	errChan = make(chan error, 1)
+	go d.run(ctx, title, progressChan, errChan)
+	return progressChan, errChan, nil, nil
+}
+
+func (d *UsenetDownloader) stopErr(ctx context.Context) error {
+	if d.StoppedManually() {
+		return downloader.ErrStoppedByUser
+	}
+	return ctx.Err()
+}
+
+func (d *UsenetDownloader) run(ctx context.Context, title string, progressChan chan float64, errChan chan error) {
+	defer close(errChan)
+	defer close(progressChan)
+
+	body, err := os.ReadFile(d.nzbPath())
+	if err != nil {
+		errChan <- fmt.Errorf("read nzb file: %w", err)
+		return
+	}
+	id, err := d.client.AddNZB(ctx, title, body)
+	if err != nil {
+		errChan <- fmt.Errorf("%s add: %w", d.client.Name(), err)
+		return
+	}
+	d.mu.Lock()
+	d.jobID = id
+	d.mu.Unlock()
+	logutils.Log.WithFields(map[string]any{"job_id": id, "client": d.client.Name()}).Info("NZB submitted to Usenet client")
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	lastProgress := -1.0
+	for {
+		select {
+		case <-ctx.Done():
+			errChan <- d.stopErr(ctx)
+			return
+		case <-ticker.C:
+		}
+
+		job, err := d.client.Job(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				errChan <- d.stopErr(ctx)
+			} else {
+				errChan <- fmt.Errorf("%s status: %w", d.client.Name(), err)
+			}
+			return
+		}
+		if job == nil {
+			if d.StoppedManually() {
+				errChan <- downloader.ErrStoppedByUser
+			} else {
+				errChan <- fmt.Errorf("%s: job %s no longer exists", d.client.Name(), id)
+			}
+			return
+		}
+
+		switch job.State {
+		case JobFailed:
+			errChan <- fmt.Errorf("%s: download failed: %s", d.client.Name(), job.FailMessage)
+			return
+		case JobCompleted:
+			if err := d.finalize(job); err != nil {
+				errChan <- err
+				return
+			}
+			select {
+			case progressChan <- 100:
+			case <-ctx.Done():
+				errChan <- d.stopErr(ctx)
+				return
+			}
+			errChan <- nil
+			return
+		}
+
+		if progress := min(job.Percent, progressBeforeFinalize); progress != lastProgress {
+			lastProgress = progress
+			select {
+			case progressChan <- progress:
+			case <-ctx.Done():
+				errChan <- d.stopErr(ctx)
+				return
+			}
+		}
+	}
+}
+
+// finalize places the completed folder under MOVIE_PATH, syncs its files to the DB and drops the
+// job from the client's history.
+func (d *UsenetDownloader) finalize(job *Job) error {
+	folder, err := placeFinalFolder(job.StoragePath, d.downloadDir)
+	if err != nil {
+		return fmt.Errorf("%s: %w", d.client.Name(), err)
+	}
+	relPaths, totalBytes, videoCount, err := listFinalFiles(d.downloadDir, folder)
+	if err != nil {
+		return fmt.Errorf("%s: list completed files: %w", d.client.Name(), err)
+	}
+	if len(relPaths) == 0 {
+		return fmt.Errorf("%s: completed job has no files in %s", d.client.Name(), folder)
+	}
+	if d.onMagnetMetadata != nil {
+		d.onMagnetMetadata(relPaths, totalBytes, videoCount)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	if err := d.client.Remove(ctx, job.ID, false); err != nil {
+		logutils.Log.WithError(err).WithField("job_id", job.ID).Warn("Failed to remove completed job from Usenet client history")
+	}
+	logutils.Log.WithFields(map[string]any{
+		"job_id": job.ID,
+		"folder": folder,
+		"files":  len(relPaths),
+	}).Info("Usenet download completed")
+	return nil
+}
+
+// placeFinalFolder returns the completed folder under moviePath, moving it there when the client stored
+// it elsewhere. The move is a rename, so the client's complete folder must be on the same filesystem.
+func placeFinalFolder(storagePath, moviePath string) (string, error) {
+	if storagePath == "" {
+		return "", errors.New("completed job has no storage path")
+	}
+	storagePath = filepath.Clean(storagePath)
+	if rel, err := filepath.Rel(moviePath, storagePath); err == nil && !strings.HasPrefix(rel, "..") {
+		if rel == "." {
+			return "", errors.New("completed job was stored directly in MOVIE_PATH, not in its own folder")
+		}
+		return storagePath, nil
+	}
+	target := filepath.Join(moviePath, filepath.Base(storagePath))
+	if _, err := os.Stat(target); err == nil {
+		return "", fmt.Errorf("cannot move %s: %s already exists", storagePath, target)
+	}
+	if err := os.Rename(storagePath, target); err != nil {
+		return "", fmt.Errorf("move completed folder into MOVIE_PATH (use a complete folder on the same filesystem): %w", err)
+	}
+	return target, nil
+}
+
+// listFinalFiles returns the regular files of folder relative to moviePath (a single file when the
+// client stored the release as a file).
+func listFinalFiles(moviePath, folder string) (relPaths []string, totalBytes int64, videoCount int, err error) {
+	err = filepath.WalkDir(folder, func(path string, entry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, infoErr := entry.Info()
+		if infoErr != nil {
+			return infoErr
+		}
+		rel, relErr := filepath.Rel(moviePath, path)
+		if relErr != nil {
+			return relErr
+		}
+		relPaths = append(relPaths, rel)
+		totalBytes += info.Size()
+		if tvcompat.IsVideoFilePath(rel) {
+			videoCount++
+		}
+		return nil
+	})
+	return relPaths, totalBytes, videoCount, err
+}
+
+func (d *UsenetDownloader) StopDownload() error {
+	d.mu.Lock()
+	d.stoppedManually = true
+	id := d.jobID
+	d.mu.Unlock()
+	if id == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	if err := d.client.Remove(ctx, id, true); err != nil {
+		logutils.Log.WithError(err).WithField("job_id", id).Warnf("%s remove failed", d.client.Name())
+		return err
+	}
+	return nil
+}
+
+// Ensure UsenetDownloader implements downloader.Downloader and optional interfaces.
+var (
+	_ downloader.Downloader               = (*UsenetDownloader)(nil)
+	_ downloader.MagnetMetadataSyncSetter = (*UsenetDownloader)(nil)
+)
@@ -0,0 +1,73 @@
+package usenet
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// NZB is the subset of an .nzb document used for title and size.
+type NZB struct {
+	Meta  []nzbMeta `xml:"head>meta"`
+	Files []nzbFile `xml:"file"`
+}
+
+type nzbMeta struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type nzbFile struct {
+	Subject  string `xml:"subject,attr"`
+	Segments []struct {
+		Bytes int64 `xml:"bytes,attr"`
+	} `xml:"segments>segment"`
+}
+
+// IsNZB reports whether data looks like an NZB document.
+func IsNZB(data []byte) bool {
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	return bytes.Contains(bytes.ToLower(head), []byte("<nzb"))
+}
+
+// ParseNZB reads and decodes the NZB at path.
+func ParseNZB(path string) (*NZB, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nzb file: %w", err)
+	}
+	var n NZB
+	if err := xml.Unmarshal(data, &n); err != nil {
+		return nil, fmt.Errorf("failed to decode nzb: %w", err)
+	}
+	if len(n.Files) == 0 {
+		return nil, fmt.Errorf("nzb contains no files")
+	}
+	return &n, nil
+}
+
+// Name returns the release name from <meta type="name">, or "" when absent.
+func (n *NZB) Name() string {
+	for _, m := range n.Meta {
+		if strings.EqualFold(m.Type, "name") {
+			return strings.TrimSpace(m.Value)
+		}
+	}
+	return ""
+}
+
+// Size is the sum of all segment sizes (the encoded article size, close to the final size).
+func (n *NZB) Size() int64 {
+	var total int64
+	for i := range n.Files {
+		for _, seg := range n.Files[i].Segments {
+			total += seg.Bytes
+		}
+	}
+	return total
+}
@@ -0,0 +1,206 @@
+package usenet
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// NZBGetClient talks to the NZBGet JSON-RPC API (/jsonrpc) with basic auth.
+type NZBGetClient struct {
+	endpoint   string
+	username   string
+	password   string
+	category   string
+	httpClient *http.Client
+}
+
+// NewNZBGetClient creates a client for baseURL (e.g. http://localhost:6789).
+func NewNZBGetClient(baseURL, username, password, category string) (*NZBGetClient, error) {
+	u, err := url.Parse(strings.TrimSpace(baseURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid NZBGet URL: %q", baseURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	if !strings.HasSuffix(u.Path, "/jsonrpc") {
+		u.Path += "/jsonrpc"
+	}
+	return &NZBGetClient{
+		endpoint:   u.String(),
+		username:   username,
+		password:   password,
+		category:   category,
+		httpClient: newHTTPClient(),
+	}, nil
+}
+
+func (*NZBGetClient) Name() string { return "NZBGet" }
+
+func (c *NZBGetClient) call(ctx context.Context, method string, out any, params ...any) error {
+	if params == nil {
+		params = []any{}
+	}
+	body, err := json.Marshal(map[string]any{"version": "1.1", "method": method, "params": params})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.username != "" || c.password != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("nzbget: unauthorized (check NZBGET_USERNAME/NZBGET_PASSWORD)")
+	}
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return fmt.Errorf("nzbget %s: HTTP %d: %w", method, resp.StatusCode, err)
+	}
+	if envelope.Error != nil {
+		return fmt.Errorf("nzbget %s: %s", method, envelope.Error.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(envelope.Result, out)
+}
+
+func (c *NZBGetClient) Version(ctx context.Context) (string, error) {
+	var version string
+	err := c.call(ctx, "version", &version)
+	return version, err
+}
+
+func (c *NZBGetClient) AddNZB(ctx context.Context, name string, nzb []byte) (string, error) {
+	var id int64
+	err := c.call(ctx, "append",
+		&id,
+		name+".nzb",
+		base64.StdEncoding.EncodeToString(nzb),
+		c.category,
+		0,       // priority
+		false,   // add to top
+		false,   // add paused
+		"",      // dupe key
+		0,       // dupe score
+		"SCORE", // dupe mode
+		[]any{}, // post-processing parameters
+	)
+	if err != nil {
+		return "", err
+	}
+	if id <= 0 {
+		return "", fmt.Errorf("nzbget: NZB was not accepted")
+	}
+	return strconv.FormatInt(id, 10), nil
+}
+
+type nzbgetGroup struct {
+	NZBID           int64   `json:"NZBID"`
+	NZBName         string  `json:"NZBName"`
+	Status          string  `json:"Status"`
+	FileSizeMB      float64 `json:"FileSizeMB"`
+	RemainingSizeMB float64 `json:"RemainingSizeMB"`
+}
+
+type nzbgetHistoryItem struct {
+	NZBID    int64  `json:"NZBID"`
+	Name     string `json:"Name"`
+	Status   string `json:"Status"`
+	DestDir  string `json:"DestDir"`
+	FinalDir string `json:"FinalDir"`
+}
+
+func (c *NZBGetClient) Job(ctx context.Context, id string) (*Job, error) {
+	nzbID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("nzbget: invalid job id %q", id)
+	}
+	var groups []nzbgetGroup
+	if err := c.call(ctx, "listgroups", &groups, 0); err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		if g.NZBID != nzbID {
+			continue
+		}
+		job := &Job{ID: id, Name: g.NZBName}
+		if g.FileSizeMB > 0 {
+			job.Percent = (g.FileSizeMB - g.RemainingSizeMB) / g.FileSizeMB * 100
+		}
+		switch {
+		case g.Status == "QUEUED" || g.Status == "PAUSED":
+			job.State = JobQueued
+		case g.Status == "DOWNLOADING" || g.Status == "FETCHING":
+			job.State = JobDownloading
+		default: // PP_QUEUED, LOADING_PARS, VERIFYING_SOURCES, REPAIRING, UNPACKING, MOVING, ...
+			job.State = JobPostProcessing
+			job.Percent = 100
+		}
+		return job, nil
+	}
+
+	var history []nzbgetHistoryItem
+	if err := c.call(ctx, "history", &history, false); err != nil {
+		return nil, err
+	}
+	for _, h := range history {
+		if h.NZBID != nzbID {
+			continue
+		}
+		job := &Job{ID: id, Name: h.Name, Percent: 100, StoragePath: h.FinalDir}
+		if job.StoragePath == "" {
+			job.StoragePath = h.DestDir
+		}
+		// Status is "<total>/<detail>", e.g. SUCCESS/UNPACK, WARNING/SCRIPT, FAILURE/PAR.
+		if strings.HasPrefix(h.Status, "SUCCESS/") || h.Status == "WARNING/SCRIPT" {
+			job.State = JobCompleted
+		} else {
+			job.State = JobFailed
+			job.FailMessage = h.Status
+		}
+		return job, nil
+	}
+	return nil, nil
+}
+
+func (c *NZBGetClient) Remove(ctx context.Context, id string, deleteFiles bool) error {
+	nzbID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("nzbget: invalid job id %q", id)
+	}
+	// GroupDelete moves an unfinished job to history keeping its files; GroupFinalDelete drops it with them.
+	queueCmd := "GroupDelete"
+	if deleteFiles {
+		queueCmd = "GroupFinalDelete"
+	}
+	var ok bool
+	if err := c.call(ctx, "editqueue", &ok, queueCmd, "", []int64{nzbID}); err != nil {
+		return err
+	}
+	return c.call(ctx, "editqueue", &ok, "HistoryFinalDelete", "", []int64{nzbID})
+}
@@ -0,0 +1,210 @@
+package usenet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// SABnzbdClient talks to the SABnzbd HTTP API (/api?mode=...&output=json).
+type SABnzbdClient struct {
+	apiURL     string
+	apiKey     string
+	category   string
+	httpClient *http.Client
+}
+
+// NewSABnzbdClient creates a client for baseURL (e.g. http://localhost:8085 or http://host/sabnzbd).
+func NewSABnzbdClient(baseURL, apiKey, category string) (*SABnzbdClient, error) {
+	u, err := url.Parse(strings.TrimSpace(baseURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid SABnzbd URL: %q", baseURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	if !strings.HasSuffix(u.Path, "/api") {
+		u.Path += "/api"
+	}
+	return &SABnzbdClient{
+		apiURL:     u.String(),
+		apiKey:     apiKey,
+		category:   category,
+		httpClient: newHTTPClient(),
+	}, nil
+}
+
+func (*SABnzbdClient) Name() string { return "SABnzbd" }
+
+// do sends req and decodes the JSON answer into out; {"status":false,"error":...} becomes an error.
+func (c *SABnzbdClient) do(ctx context.Context, req *http.Request, out any) error {
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("sabnzbd: HTTP %d", resp.StatusCode)
+	}
+	var status struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &status); err == nil && status.Error != "" {
+		return fmt.Errorf("sabnzbd: %s", status.Error)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("sabnzbd: decode response: %w", err)
+	}
+	return nil
+}
+
+func (c *SABnzbdClient) query(mode string, params url.Values) string {
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("mode", mode)
+	params.Set("output", "json")
+	params.Set("apikey", c.apiKey)
+	return c.apiURL + "?" + params.Encode()
+}
+
+func (c *SABnzbdClient) get(ctx context.Context, mode string, params url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.query(mode, params), http.NoBody)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, req, out)
+}
+
+func (c *SABnzbdClient) Version(ctx context.Context) (string, error) {
+	var res struct {
+		Version string `json:"version"`
+	}
+	if err := c.get(ctx, "version", nil, &res); err != nil {
+		return "", err
+	}
+	return res.Version, nil
+}
+
+func (c *SABnzbdClient) AddNZB(ctx context.Context, name string, nzb []byte) (string, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("name", name+".nzb")
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(nzb); err != nil {
+		return "", err
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+	params := url.Values{}
+	params.Set("nzbname", name)
+	if c.category != "" {
+		params.Set("cat", c.category)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.query("addfile", params), &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	var res struct {
+		NzoIDs []string `json:"nzo_ids"`
+	}
+	if err := c.do(ctx, req, &res); err != nil {
+		return "", err
+	}
+	if len(res.NzoIDs) == 0 {
+		return "", fmt.Errorf("sabnzbd: NZB was not accepted")
+	}
+	return res.NzoIDs[0], nil
+}
+
+type sabQueueSlot struct {
+	NzoID      string `json:"nzo_id"`
+	Filename   string `json:"filename"`
+	Status     string `json:"status"`
+	Percentage string `json:"percentage"`
+}
+
+type sabHistorySlot struct {
+	NzoID       string `json:"nzo_id"`
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Storage     string `json:"storage"`
+	FailMessage string `json:"fail_message"`
+}
+
+func (c *SABnzbdClient) Job(ctx context.Context, id string) (*Job, error) {
+	params := url.Values{}
+	params.Set("nzo_ids", id)
+	var queue struct {
+		Queue struct {
+			Slots []sabQueueSlot `json:"slots"`
+		} `json:"queue"`
+	}
+	if err := c.get(ctx, "queue", params, &queue); err != nil {
+		return nil, err
+	}
+	for _, s := range queue.Queue.Slots {
+		if s.NzoID == id {
+			pct, _ := strconv.ParseFloat(s.Percentage, 64)
+			state := JobDownloading
+			if s.Status == "Queued" || s.Status == "Paused" {
+				state = JobQueued
+			}
+			return &Job{ID: id, Name: s.Filename, State: state, Percent: pct}, nil
+		}
+	}
+
+	var history struct {
+		History struct {
+			Slots []sabHistorySlot `json:"slots"`
+		} `json:"history"`
+	}
+	if err := c.get(ctx, "history", params, &history); err != nil {
+		return nil, err
+	}
+	for _, s := range history.History.Slots {
+		if s.NzoID != id {
+			continue
+		}
+		job := &Job{ID: id, Name: s.Name, Percent: 100, StoragePath: s.Storage, FailMessage: s.FailMessage}
+		switch s.Status {
+		case "Completed":
+			job.State = JobCompleted
+		case "Failed":
+			job.State = JobFailed
+		default: // Queued, QuickCheck, Verifying, Repairing, Fetching, Extracting, Moving, Running
+			job.State = JobPostProcessing
+		}
+		return job, nil
+	}
+	return nil, nil
+}
+
+func (c *SABnzbdClient) Remove(ctx context.Context, id string, deleteFiles bool) error {
+	params := url.Values{}
+	params.Set("name", "delete")
+	params.Set("value", id)
+	if deleteFiles {
+		params.Set("del_files", "1")
+	}
+	if err := c.get(ctx, "queue", params, nil); err != nil {
+		return err
+	}
+	return c.get(ctx, "history", params, nil)
+}
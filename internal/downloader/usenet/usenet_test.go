@@ -0,0 +1,304 @@
+package usenet
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/testutils"
+)
+
+const testNZB = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE nzb PUBLIC "-//newzBin//DTD NZB 1.1//EN" "http://www.newzbin.com/DTD/nzb/nzb-1.1.dtd">
+<nzb xmlns="http://www.newzbin.com/DTD/2003/nzb">
+  <head><meta type="name">Some.Movie.2024.1080p</meta></head>
+  <file poster="p" date="1" subject="Some.Movie.2024.1080p.part1.rar (1/2)">
+    <groups><group>alt.binaries.test</group></groups>
+    <segments>
+      <segment bytes="700" number="1">a@b</segment>
+      <segment bytes="300" number="2">c@d</segment>
+    </segments>
+  </file>
+  <file poster="p" date="1" subject="Some.Movie.2024.1080p.par2 (1/1)">
+    <segments><segment bytes="24" number="1">e@f</segment></segments>
+  </file>
+</nzb>`
+
+func writeNZB(t *testing.T, dir, name string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(testNZB), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseNZB(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	writeNZB(t, dir, "a.nzb")
+
+	n, err := ParseNZB(filepath.Join(dir, "a.nzb"))
+	if err != nil {
+		t.Fatalf("ParseNZB: %v", err)
+	}
+	if n.Name() != "Some.Movie.2024.1080p" {
+		t.Errorf("Name = %q", n.Name())
+	}
+	if n.Size() != 1024 {
+		t.Errorf("Size = %d, want 1024", n.Size())
+	}
+	if !IsNZB([]byte(testNZB)) {
+		t.Error("IsNZB(nzb) = false")
+	}
+	if IsNZB([]byte("d8:announce...")) {
+		t.Error("IsNZB(torrent) = true")
+	}
+}
+
+func TestSABnzbdClientJobStates(t *testing.T) {
+	t.Parallel()
+	var added string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/sabnzbd/api" || q.Get("apikey") != "key" || q.Get("output") != "json" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		switch q.Get("mode") {
+		case "addfile":
+			file, _, err := r.FormFile("name")
+			if err != nil || q.Get("cat") != "tms" {
+				_ = json.NewEncoder(w).Encode(map[string]any{"status": false, "error": "no file"})
+				return
+			}
+			body, _ := io.ReadAll(file)
+			added = string(body)
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": true, "nzo_ids": []string{"SABnzbd_nzo_1"}})
+		case "queue":
+			slots := []map[string]any{}
+			if q.Get("nzo_ids") == "SABnzbd_nzo_1" {
+				slots = append(slots, map[string]any{"nzo_id": "SABnzbd_nzo_1", "status": "Downloading", "percentage": "42"})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"queue": map[string]any{"slots": slots}})
+		case "history":
+			slots := []map[string]any{}
+			if q.Get("nzo_ids") == "SABnzbd_nzo_2" {
+				slots = append(slots, map[string]any{"nzo_id": "SABnzbd_nzo_2", "status": "Completed", "storage": "/done/x"})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"history": map[string]any{"slots": slots}})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": false, "error": "unknown mode"})
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewSABnzbdClient(srv.URL+"/sabnzbd/", "key", "tms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	id, err := c.AddNZB(ctx, "Some.Movie", []byte(testNZB))
+	if err != nil || id != "SABnzbd_nzo_1" || added != testNZB {
+		t.Fatalf("AddNZB = %q, %v (uploaded %d bytes)", id, err, len(added))
+	}
+
+	job, err := c.Job(ctx, id)
+	if err != nil || job == nil || job.State != JobDownloading || job.Percent != 42 {
+		t.Fatalf("Job(queue) = %+v, %v", job, err)
+	}
+	job, err = c.Job(ctx, "SABnzbd_nzo_2")
+	if err != nil || job == nil || job.State != JobCompleted || job.StoragePath != "/done/x" {
+		t.Fatalf("Job(history) = %+v, %v", job, err)
+	}
+	job, err = c.Job(ctx, "SABnzbd_nzo_3")
+	if err != nil || job != nil {
+		t.Fatalf("Job(unknown) = %+v, %v, want nil, nil", job, err)
+	}
+	if err := c.get(ctx, "bogus", nil, nil); err == nil {
+		t.Fatal("expected API error to be returned")
+	}
+}
+
+func TestNZBGetClientJobStates(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "nzbget" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var result any
+		switch req.Method {
+		case "append":
+			var content string
+			_ = json.Unmarshal(req.Params[1], &content)
+			if decoded, _ := base64.StdEncoding.DecodeString(content); string(decoded) != testNZB {
+				result = 0
+			} else {
+				result = 7
+			}
+		case "listgroups":
+			result = []map[string]any{{"NZBID": 7, "Status": "DOWNLOADING", "FileSizeMB": 200.0, "RemainingSizeMB": 50.0}}
+		case "history":
+			result = []map[string]any{
+				{"NZBID": 8, "Status": "SUCCESS/UNPACK", "DestDir": "/inter/x", "FinalDir": "/done/x"},
+				{"NZBID": 9, "Status": "FAILURE/PAR", "DestDir": "/inter/y"},
+			}
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"message": "unknown method"}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"result": result})
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := NewNZBGetClient(srv.URL, "nzbget", "secret", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	id, err := c.AddNZB(ctx, "Some.Movie", []byte(testNZB))
+	if err != nil || id != "7" {
+		t.Fatalf("AddNZB = %q, %v", id, err)
+	}
+	job, err := c.Job(ctx, "7")
+	if err != nil || job == nil || job.State != JobDownloading || job.Percent != 75 {
+		t.Fatalf("Job(7) = %+v, %v", job, err)
+	}
+	job, err = c.Job(ctx, "8")
+	if err != nil || job == nil || job.State != JobCompleted || job.StoragePath != "/done/x" {
+		t.Fatalf("Job(8) = %+v, %v", job, err)
+	}
+	job, err = c.Job(ctx, "9")
+	if err != nil || job == nil || job.State != JobFailed || job.FailMessage != "FAILURE/PAR" {
+		t.Fatalf("Job(9) = %+v, %v", job, err)
+	}
+	job, err = c.Job(ctx, "10")
+	if err != nil || job != nil {
+		t.Fatalf("Job(unknown) = %+v, %v, want nil, nil", job, err)
+	}
+
+	bad, _ := NewNZBGetClient(srv.URL, "nzbget", "wrong", "")
+	if _, err := bad.Version(ctx); err == nil {
+		t.Fatal("expected unauthorized error")
+	}
+}
+
+// fakeClient is an in-memory Client that completes the job on the second poll.
+type fakeClient struct {
+	mu      sync.Mutex
+	storage string
+	polls   int
+	removed map[string]bool
+}
+
+func (*fakeClient) Name() string                            { return "fake" }
+func (*fakeClient) Version(context.Context) (string, error) { return "1", nil }
+func (*fakeClient) AddNZB(context.Context, string, []byte) (string, error) {
+	return "job1", nil
+}
+
+func (c *fakeClient) Job(_ context.Context, id string) (*Job, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.removed[id] {
+		return nil, nil
+	}
+	c.polls++
+	if c.polls == 1 {
+		return &Job{ID: id, State: JobDownloading, Percent: 50}, nil
+	}
+	return &Job{ID: id, State: JobCompleted, Percent: 100, StoragePath: c.storage}, nil
+}
+
+func (c *fakeClient) Remove(_ context.Context, id string, _ bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removed[id] = true
+	return nil
+}
+
+func TestUsenetDownloaderMovesCompletedFolder(t *testing.T) {
+	logutils.InitLogger("debug")
+	moviePath := testutils.TempDir(t)
+	completeDir := filepath.Join(t.TempDir(), "Some.Movie.2024.1080p")
+	if err := os.MkdirAll(filepath.Join(completeDir, "Subs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"movie.mkv", "Subs/en.srt"} {
+		if err := os.WriteFile(filepath.Join(completeDir, name), []byte("data"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeNZB(t, moviePath, "nzb_test.nzb")
+
+	client := &fakeClient{storage: completeDir, removed: map[string]bool{}}
+	d := newUsenetDownloader("nzb_test.nzb", moviePath, testutils.TestConfig(moviePath), client)
+	var synced []string
+	var syncedVideos int
+	d.SetOnMagnetMetadataReady(func(paths []string, _ int64, videoFileCount int) {
+		synced, syncedVideos = paths, videoFileCount
+	})
+
+	progressChan, errChan, _, err := d.StartDownload(context.Background())
+	if err != nil {
+		t.Fatalf("StartDownload: %v", err)
+	}
+	var last float64
+	for p := range progressChan {
+		last = p
+	}
+	if err := <-errChan; err != nil {
+		t.Fatalf("download error: %v", err)
+	}
+	if last != 100 {
+		t.Fatalf("last progress = %v, want 100", last)
+	}
+
+	if _, err := os.Stat(filepath.Join(moviePath, "Some.Movie.2024.1080p", "movie.mkv")); err != nil {
+		t.Fatalf("completed folder was not moved into MOVIE_PATH: %v", err)
+	}
+	sort.Strings(synced)
+	want := []string{filepath.Join("Some.Movie.2024.1080p", "Subs", "en.srt"), filepath.Join("Some.Movie.2024.1080p", "movie.mkv")}
+	if len(synced) != 2 || synced[0] != want[0] || synced[1] != want[1] || syncedVideos != 1 {
+		t.Fatalf("synced = %v (videos %d), want %v", synced, syncedVideos, want)
+	}
+	if !client.removed["job1"] {
+		t.Fatal("completed job was not removed from client history")
+	}
+}
+
+func TestPlaceFinalFolderRejectsMoviePathItself(t *testing.T) {
+	t.Parallel()
+	moviePath := t.TempDir()
+	if _, err := placeFinalFolder(moviePath, moviePath); err == nil {
+		t.Fatal("expected error for storage path equal to MOVIE_PATH")
+	}
+	inside := filepath.Join(moviePath, "Release")
+	got, err := placeFinalFolder(inside+string(filepath.Separator), moviePath)
+	if err != nil || got != inside {
+		t.Fatalf("placeFinalFolder(inside) = %q, %v, want %q", got, err, inside)
+	}
+	if _, err := placeFinalFolder("", moviePath); err == nil {
+		t.Fatal("expected error for empty storage path")
+	}
+	if _, err := placeFinalFolder(filepath.Join(t.TempDir(), "missing"), moviePath); err == nil {
+		t.Fatal("expected move error for missing folder")
+	}
+}
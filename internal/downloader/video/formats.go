@@ -0,0 +1,185 @@
+package ytdlp
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/tvcompat"
+)
+
+// maxFormatChoices limits the formats offered by ListFormats so the bot keyboard stays compact.
+const maxFormatChoices = 8
+
+// codecH264 is the codec name every TV plays; these choices are the last to be cut by maxFormatChoices.
+const codecH264 = "H.264"
+
+// Format is one resolution/codec combination a single video can be downloaded in.
+type Format struct {
+	Height int
+	Codec  string // display name, e.g. "H.264", "VP9"
+	// Size is the estimated download size in bytes including the audio track, 0 when unknown.
+	Size int64
+	// TvCompat is tvcompat.TvCompatGreen/Red for the codec, "" when unknown.
+	TvCompat string
+	// Selector is the yt-dlp -f value for this format; video-only streams are merged with the best audio.
+	Selector string
+}
+
+// ListFormats returns the best format for every resolution/codec combination of the video, highest
+// resolution first. Playlists return nil: their entries are picked by the configured quality.
+func (d *YTDLPDownloader) ListFormats(ctx context.Context) ([]Format, error) {
+	if d.playlist != nil {
+		return nil, nil
+	}
+	info, err := d.fetchMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pickFormats(info.Formats), nil
+}
+
+// SetFormat makes the download use selector (a Format.Selector) instead of the configured quality.
+func (d *YTDLPDownloader) SetFormat(selector string) {
+	d.options.Format = selector
+}
+
+// selectedFormat returns the video format of a Format.Selector ("137" or "137+ba"), nil when the selector is
+// empty or names no listed video format.
+func (m *videoMetadata) selectedFormat(selector string) *formatMetadata {
+	if selector == "" {
+		return nil
+	}
+	id, _, _ := strings.Cut(selector, "+")
+	for i := range m.Formats {
+		if f := &m.Formats[i]; f.FormatID == id && !isNone(f.Vcodec) {
+			return f
+		}
+	}
+	return nil
+}
+
+// selectedFormatSize is the download size of selector for its video format f: the best audio is added when the
+// selector merges it in. 0 when the size of f is unknown.
+func selectedFormatSize(formats []formatMetadata, f *formatMetadata, selector string) int64 {
+	size := formatSize(f)
+	if size > 0 && strings.Contains(selector, "+") {
+		size += bestAudioSize(formats)
+	}
+	return size
+}
+
+// bestAudioSize is the size of the highest-bitrate audio-only format, the one "+ba" merges in.
+func bestAudioSize(formats []formatMetadata) int64 {
+	var audioSize int64
+	var audioTbr float64
+	for i := range formats {
+		f := &formats[i]
+		if isNone(f.Vcodec) && !isNone(f.Acodec) && f.Tbr >= audioTbr {
+			audioTbr, audioSize = f.Tbr, formatSize(f)
+		}
+	}
+	return audioSize
+}
+
+// pickFormats keeps the highest-bitrate video format per height and codec.
+func pickFormats(formats []formatMetadata) []Format {
+	audioSize := bestAudioSize(formats)
+
+	type key struct {
+		height int
+		codec  string
+	}
+	best := make(map[key]*formatMetadata)
+	for i := range formats {
+		f := &formats[i]
+		if isNone(f.Vcodec) || f.Height <= 0 || f.FormatID == "" {
+			continue
+		}
+		k := key{height: f.Height, codec: codecName(f.Vcodec)}
+		if cur, ok := best[k]; !ok || f.Tbr > cur.Tbr {
+			best[k] = f
+		}
+	}
+
+	result := make([]Format, 0, len(best))
+	for k, f := range best {
+		format := Format{
+			Height:   k.height,
+			Codec:    k.codec,
+			Size:     formatSize(f),
+			TvCompat: tvcompat.CompatFromVcodec(f.Vcodec),
+			Selector: f.FormatID,
+		}
+		if isNone(f.Acodec) {
+			format.Selector = f.FormatID + "+ba"
+			if format.Size > 0 {
+				format.Size += audioSize
+			}
+		}
+		result = append(result, format)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Height != result[j].Height {
+			return result[i].Height > result[j].Height
+		}
+		if (result[i].TvCompat == tvcompat.TvCompatGreen) != (result[j].TvCompat == tvcompat.TvCompatGreen) {
+			return result[i].TvCompat == tvcompat.TvCompatGreen
+		}
+		return result[i].Codec < result[j].Codec
+	})
+	return limitFormatChoices(result)
+}
+
+// limitFormatChoices cuts sorted formats to maxFormatChoices, dropping other codecs before the H.264 choice of
+// any height; the order of the kept formats is unchanged.
+func limitFormatChoices(formats []Format) []Format {
+	if len(formats) <= maxFormatChoices {
+		return formats
+	}
+	keep := make([]bool, len(formats))
+	kept := 0
+	for _, h264 := range []bool{true, false} {
+		for i := range formats {
+			if kept < maxFormatChoices && !keep[i] && (formats[i].Codec == codecH264) == h264 {
+				keep[i] = true
+				kept++
+			}
+		}
+	}
+	result := make([]Format, 0, maxFormatChoices)
+	for i := range formats {
+		if keep[i] {
+			result = append(result, formats[i])
+		}
+	}
+	return result
+}
+
+func formatSize(f *formatMetadata) int64 {
+	if f.Filesize > 0 {
+		return int64(f.Filesize)
+	}
+	return int64(f.FilesizeApprox)
+}
+
+func isNone(codec string) bool {
+	return codec == "" || codec == "none"
+}
+
+// codecName maps a yt-dlp vcodec ("avc1.64001f", "vp09.00.40.08") to a short display name.
+func codecName(vcodec string) string {
+	v := strings.ToLower(vcodec)
+	switch {
+	case strings.HasPrefix(v, "avc") || strings.Contains(v, "h264"):
+		return codecH264
+	case strings.HasPrefix(v, "vp09") || strings.HasPrefix(v, "vp9"):
+		return "VP9"
+	case strings.HasPrefix(v, "av01") || strings.Contains(v, "av1"):
+		return "AV1"
+	case strings.HasPrefix(v, "hev") || strings.HasPrefix(v, "hvc") || strings.Contains(v, "h265"):
+		return "HEVC"
+	}
+	name, _, _ := strings.Cut(vcodec, ".")
+	return strings.ToUpper(name)
+}
@@ -0,0 +1,169 @@
+package ytdlp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	tmsconfig "github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/testutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/tvcompat"
+)
+
+func TestPickFormats(t *testing.T) {
+	formats := []formatMetadata{
+		{FormatID: "sb0", Ext: "mhtml", Vcodec: "none", Acodec: "none"},
+		{FormatID: "140", Ext: "m4a", Vcodec: "none", Acodec: "mp4a.40.2", Filesize: 5 << 20, Tbr: 129},
+		{FormatID: "139", Ext: "m4a", Vcodec: "none", Acodec: "mp4a.40.5", Filesize: 2 << 20, Tbr: 48},
+		{FormatID: "136", Ext: "mp4", Height: 720, Vcodec: "avc1.4d401f", Acodec: "none", Filesize: 40 << 20, Tbr: 1500},
+		{FormatID: "247", Ext: "webm", Height: 720, Vcodec: "vp9", Acodec: "none", FilesizeApprox: 30 << 20, Tbr: 1200},
+		{FormatID: "137", Ext: "mp4", Height: 1080, Vcodec: "avc1.640028", Acodec: "none", Filesize: 80 << 20, Tbr: 3000},
+		{FormatID: "248", Ext: "webm", Height: 1080, Vcodec: "vp09.00.40.08", Acodec: "none", Tbr: 2500},
+		{FormatID: "248-low", Ext: "webm", Height: 1080, Vcodec: "vp9", Acodec: "none", Tbr: 900},
+		{FormatID: "18", Ext: "mp4", Height: 360, Vcodec: "avc1.42001E", Acodec: "mp4a.40.2", Filesize: 10 << 20, Tbr: 500},
+	}
+
+	got := pickFormats(formats)
+
+	want := []Format{
+		{Height: 1080, Codec: "H.264", Size: 85 << 20, TvCompat: tvcompat.TvCompatGreen, Selector: "137+ba"},
+		{Height: 1080, Codec: "VP9", Size: 0, TvCompat: tvcompat.TvCompatRed, Selector: "248+ba"},
+		{Height: 720, Codec: "H.264", Size: 45 << 20, TvCompat: tvcompat.TvCompatGreen, Selector: "136+ba"},
+		{Height: 720, Codec: "VP9", Size: 35 << 20, TvCompat: tvcompat.TvCompatRed, Selector: "247+ba"},
+		{Height: 360, Codec: "H.264", Size: 10 << 20, TvCompat: tvcompat.TvCompatGreen, Selector: "18"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("pickFormats() returned %d formats, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("format %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestPickFormatsLimitsChoices(t *testing.T) {
+	var formats []formatMetadata
+	for h := 100; h <= 1500; h += 100 {
+		formats = append(formats, formatMetadata{FormatID: "f", Height: h, Vcodec: "avc1", Acodec: "mp4a"})
+	}
+	got := pickFormats(formats)
+	if len(got) != maxFormatChoices {
+		t.Fatalf("pickFormats() returned %d formats, want %d", len(got), maxFormatChoices)
+	}
+	if got[0].Height != 1500 {
+		t.Errorf("first format height = %d, want the highest (1500)", got[0].Height)
+	}
+}
+
+func TestPickFormatsKeepsH264WhenLimiting(t *testing.T) {
+	// Five heights with VP9, AV1 and H.264 each: 15 choices, of which only 8 fit.
+	var formats []formatMetadata
+	for _, h := range []int{2160, 1440, 1080, 720, 480} {
+		formats = append(formats,
+			formatMetadata{FormatID: "vp9", Height: h, Vcodec: "vp9", Acodec: "none"},
+			formatMetadata{FormatID: "av1", Height: h, Vcodec: "av01.0.08M.08", Acodec: "none"},
+			formatMetadata{FormatID: "avc", Height: h, Vcodec: "avc1.640028", Acodec: "none"},
+		)
+	}
+	got := pickFormats(formats)
+	if len(got) != maxFormatChoices {
+		t.Fatalf("pickFormats() returned %d formats, want %d", len(got), maxFormatChoices)
+	}
+	h264 := make(map[int]bool)
+	for i, f := range got {
+		if f.Codec == "H.264" {
+			h264[f.Height] = true
+		}
+		if i > 0 && got[i-1].Height < f.Height {
+			t.Errorf("formats not sorted by height: %+v", got)
+		}
+	}
+	for _, h := range []int{2160, 1440, 1080, 720, 480} {
+		if !h264[h] {
+			t.Errorf("H.264 choice for %dp was dropped: %+v", h, got)
+		}
+	}
+}
+
+func TestCodecName(t *testing.T) {
+	tests := map[string]string{
+		"avc1.64001f":   "H.264",
+		"h264":          "H.264",
+		"vp09.00.40.08": "VP9",
+		"vp9":           "VP9",
+		"av01.0.08M.08": "AV1",
+		"hev1.1.6.L93":  "HEVC",
+		"theora":        "THEORA",
+	}
+	for vcodec, want := range tests {
+		if got := codecName(vcodec); got != want {
+			t.Errorf("codecName(%q) = %q, want %q", vcodec, got, want)
+		}
+	}
+}
+
+func TestSetFormatOverridesQuality(t *testing.T) {
+	cfg := testutils.TestConfig(testutils.TempDir(t))
+	cfg.VideoSettings = tmsconfig.VideoConfig{QualitySelector: "bv*+ba/b", MaxHeight: 1080}
+	d := &YTDLPDownloader{config: cfg, url: "https://example.com/video", options: Options{Quality: "720p"}}
+	d.SetFormat("247+ba")
+
+	args := d.buildYTDLPArgs("/tmp/test.mp4")
+	if got := argValue(args, "-f"); got != "247+ba/best" {
+		t.Errorf("-f = %q, want %q", got, "247+ba/best")
+	}
+	if got := argValue(args, "-S"); got != "" {
+		t.Errorf("-S = %q, want no height limit for an explicit format", got)
+	}
+}
+
+func TestSelectedFormatUsesCachedMetadata(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping fake yt-dlp script test on Windows")
+	}
+	cfg := testutils.TestConfig(testutils.TempDir(t))
+	// Fake yt-dlp -j: the default pick is VP9, the picker offers H.264 137 (video only) and audio 140.
+	calls := filepath.Join(t.TempDir(), "calls")
+	script := `#!/bin/sh
+echo run >> "` + calls + `"
+cat <<'JSON'
+{"title": "Clip", "vcodec": "vp9", "filesize": 1000, "formats": [
+  {"format_id": "140", "vcodec": "none", "acodec": "mp4a.40.2", "filesize": 5000, "tbr": 129},
+  {"format_id": "137", "height": 1080, "vcodec": "avc1.640028", "acodec": "none", "filesize": 80000, "tbr": 3000}
+]}
+JSON
+`
+	bin := filepath.Join(t.TempDir(), "yt-dlp")
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil { // #nosec G306 -- test executable
+		t.Fatalf("write fake yt-dlp: %v", err)
+	}
+	cfg.YtdlpPath = bin
+	d := &YTDLPDownloader{config: cfg, url: "https://example.com/video"}
+	ctx := context.Background()
+
+	if _, err := d.ListFormats(ctx); err != nil {
+		t.Fatalf("ListFormats: %v", err)
+	}
+	d.SetFormat("137+ba")
+	if compat, err := d.GetEarlyTvCompatibility(ctx); err != nil || compat != tvcompat.TvCompatGreen {
+		t.Errorf("GetEarlyTvCompatibility = %q, %v; want %q from the selected H.264 format", compat, err, tvcompat.TvCompatGreen)
+	}
+	if size, _ := d.GetFileSize(); size != 85000 {
+		t.Errorf("GetFileSize = %d, want 85000 (selected video plus best audio)", size)
+	}
+	if title, err := d.fetchTitle(); err != nil || title != "Clip" {
+		t.Errorf("fetchTitle = %q, %v; want %q", title, err, "Clip")
+	}
+
+	out, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(out), "run"); n != 1 {
+		t.Errorf("yt-dlp ran %d times, want 1", n)
+	}
+}
@@ -0,0 +1,121 @@
+package ytdlp
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tmsconfig "github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+)
+
+const (
+	// QualityAudioOnly downloads only the best audio stream.
+	QualityAudioOnly = "audio"
+	// QualityBest lifts VIDEO_MAX_HEIGHT and any height filter of VIDEO_QUALITY_SELECTOR.
+	QualityBest = "best"
+
+	defaultQualitySelector = "bv*+ba/b"
+	audioOnlySelector      = "ba[ext=m4a]/ba/b"
+	maxQualityHeight       = 4320
+	maxFormatLen           = 200
+)
+
+var (
+	audioLangRE    = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]+)?$`)
+	subtitleLangRE = regexp.MustCompile(`^[A-Za-z0-9.*-]+(,[A-Za-z0-9.*-]+)*$`)
+)
+
+// Options override the VIDEO_* quality, audio and subtitle settings for a single download.
+// Zero values keep the configured settings.
+type Options struct {
+	// Quality is "720p"/"1080p"-style maximum height, QualityBest or QualityAudioOnly.
+	Quality string `json:"quality,omitempty"`
+	// AudioLang selects the audio track language (VIDEO_AUDIO_LANG).
+	AudioLang string `json:"audio_lang,omitempty"`
+	// SubtitleLang lists subtitle languages for yt-dlp --sub-langs (VIDEO_SUBTITLE_LANG); setting it downloads subtitles.
+	SubtitleLang string `json:"subtitle_lang,omitempty"`
+	// WriteSubs turns subtitles on or off (VIDEO_WRITE_SUBS); nil keeps the configured value.
+	WriteSubs *bool `json:"write_subs,omitempty"`
+	// Format is an explicit yt-dlp -f selector, e.g. a Format.Selector from ListFormats; it takes precedence over Quality.
+	Format string `json:"format,omitempty"`
+}
+
+// IsZero reports whether o overrides nothing.
+func (o *Options) IsZero() bool {
+	return o.Quality == "" && o.AudioLang == "" && o.SubtitleLang == "" && o.WriteSubs == nil && o.Format == ""
+}
+
+// Validate normalizes o and checks its values.
+func (o *Options) Validate() error {
+	o.Quality = strings.ToLower(strings.TrimSpace(o.Quality))
+	o.AudioLang = strings.TrimSpace(o.AudioLang)
+	o.SubtitleLang = strings.TrimSpace(o.SubtitleLang)
+	o.Format = strings.TrimSpace(o.Format)
+	if o.Quality != "" && o.Quality != QualityBest && o.Quality != QualityAudioOnly {
+		if _, err := qualityHeight(o.Quality); err != nil {
+			return err
+		}
+	}
+	if o.Format != "" && (len(o.Format) > maxFormatLen || strings.ContainsAny(o.Format, " \t\n")) {
+		return fmt.Errorf("invalid format %q, expected a yt-dlp format selector such as 137+ba", o.Format)
+	}
+	if o.AudioLang != "" && !audioLangRE.MatchString(o.AudioLang) {
+		return fmt.Errorf("invalid audio language %q, expected a code like en or pt-BR", o.AudioLang)
+	}
+	if o.SubtitleLang != "" && !subtitleLangRE.MatchString(o.SubtitleLang) {
+		return fmt.Errorf("invalid subtitle languages %q, expected codes like en or en,ru", o.SubtitleLang)
+	}
+	if o.SubtitleLang != "" && o.WriteSubs != nil && !*o.WriteSubs {
+		return errors.New("subtitle languages are set but subtitles are turned off")
+	}
+	return nil
+}
+
+// apply returns settings with the overrides of o; the configured settings are left untouched.
+func (o *Options) apply(settings tmsconfig.VideoConfig) tmsconfig.VideoConfig {
+	switch o.Quality {
+	case "":
+	case QualityBest:
+		settings.QualitySelector = defaultQualitySelector
+		settings.MaxHeight = 0
+	case QualityAudioOnly:
+		settings.QualitySelector = audioOnlySelector
+		settings.MaxHeight = 0
+		// Recoding to a video container and the TV codec preferences make no sense without a video stream.
+		settings.CompatibilityMode = false
+		settings.EnableReencoding = false
+	default:
+		if height, err := qualityHeight(o.Quality); err == nil {
+			settings.QualitySelector = defaultQualitySelector
+			settings.MaxHeight = height
+		}
+	}
+	if o.Format != "" {
+		settings.QualitySelector = o.Format
+		settings.MaxHeight = 0
+	}
+	if o.AudioLang != "" {
+		settings.AudioLang = o.AudioLang
+	}
+	if o.WriteSubs != nil {
+		settings.WriteSubs = *o.WriteSubs
+		if !*o.WriteSubs {
+			settings.SubtitleLang = ""
+		}
+	}
+	if o.SubtitleLang != "" {
+		settings.SubtitleLang = o.SubtitleLang
+	}
+	return settings
+}
+
+// qualityHeight parses "720p" or "720" into a maximum video height.
+func qualityHeight(quality string) (int, error) {
+	height, err := strconv.Atoi(strings.TrimSuffix(quality, "p"))
+	if err != nil || height <= 0 || height > maxQualityHeight {
+		return 0, fmt.Errorf("invalid quality %q, expected e.g. 720p, 1080p, %s or %s", quality, QualityBest, QualityAudioOnly)
+	}
+	return height, nil
+}
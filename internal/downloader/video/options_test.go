@@ -0,0 +1,136 @@
+package ytdlp
+
+import (
+	"slices"
+	"testing"
+
+	tmsconfig "github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/testutils"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	off := false
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{name: "empty", opts: Options{}},
+		{name: "height with p", opts: Options{Quality: "720p"}},
+		{name: "height upper case", opts: Options{Quality: " 1080P "}},
+		{name: "audio only", opts: Options{Quality: "audio"}},
+		{name: "best", opts: Options{Quality: "best"}},
+		{name: "languages", opts: Options{AudioLang: "pt-BR", SubtitleLang: "en,ru"}},
+		{name: "unknown quality", opts: Options{Quality: "hd"}, wantErr: true},
+		{name: "zero height", opts: Options{Quality: "0p"}, wantErr: true},
+		{name: "audio lang with filter syntax", opts: Options{AudioLang: "en]+b"}, wantErr: true},
+		{name: "subtitle lang with spaces", opts: Options{SubtitleLang: "en ru"}, wantErr: true},
+		{name: "subtitles set but turned off", opts: Options{SubtitleLang: "en", WriteSubs: &off}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildYTDLPArgsWithOptions(t *testing.T) {
+	tempDir := testutils.TempDir(t)
+	cfg := testutils.TestConfig(tempDir)
+	cfg.VideoSettings = tmsconfig.VideoConfig{
+		QualitySelector:  "bv*[height<=1080]+ba/b",
+		MaxHeight:        1080,
+		AudioLang:        "ru",
+		SubtitleLang:     "ru",
+		EnableReencoding: true,
+		OutputFormat:     "mp4",
+	}
+	on, off := true, false
+
+	tests := []struct {
+		name        string
+		opts        Options
+		wantFormat  string
+		wantSort    string
+		wantSubs    string
+		wantRecode  bool
+		wantNoSubs  bool
+		wantNoLimit bool
+	}{
+		{
+			name:       "no overrides keep the configuration",
+			opts:       Options{},
+			wantFormat: "bv*[height<=1080]+ba[language=ru]/b[language=ru]/best",
+			wantSort:   "res:1080",
+			wantSubs:   "ru",
+			wantRecode: true,
+		},
+		{
+			name:       "720p with english audio and subtitles",
+			opts:       Options{Quality: "720p", AudioLang: "en", SubtitleLang: "en"},
+			wantFormat: "bv*+ba[language=en]/b[language=en]/best",
+			wantSort:   "res:720",
+			wantSubs:   "en",
+			wantRecode: true,
+		},
+		{
+			name:        "audio only",
+			opts:        Options{Quality: QualityAudioOnly, WriteSubs: &off},
+			wantFormat:  "ba[ext=m4a][language=ru]/ba[language=ru]/b[language=ru]/best",
+			wantNoSubs:  true,
+			wantNoLimit: true,
+		},
+		{
+			name:        "best lifts the height limit",
+			opts:        Options{Quality: QualityBest, WriteSubs: &on},
+			wantFormat:  "bv*+ba[language=ru]/b[language=ru]/best",
+			wantSubs:    "ru",
+			wantRecode:  true,
+			wantNoLimit: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &YTDLPDownloader{config: cfg, url: "https://example.com/video", options: tt.opts}
+			args := d.buildYTDLPArgs("/tmp/test.mp4")
+
+			if got := argValue(args, "-f"); got != tt.wantFormat {
+				t.Errorf("-f = %q, want %q", got, tt.wantFormat)
+			}
+			if tt.wantNoLimit {
+				if got := argValue(args, "-S"); got != "" {
+					t.Errorf("-S = %q, want no height limit", got)
+				}
+			} else if got := argValue(args, "-S"); got != tt.wantSort {
+				t.Errorf("-S = %q, want %q", got, tt.wantSort)
+			}
+			if tt.wantNoSubs {
+				if slices.Contains(args, "--write-subs") {
+					t.Errorf("args %v should not download subtitles", args)
+				}
+			} else if got := argValue(args, "--sub-langs"); got != tt.wantSubs {
+				t.Errorf("--sub-langs = %q, want %q", got, tt.wantSubs)
+			}
+			if got := slices.Contains(args, "--recode-video"); got != tt.wantRecode {
+				t.Errorf("--recode-video present = %v, want %v", got, tt.wantRecode)
+			}
+		})
+	}
+
+	if cfg.VideoSettings.MaxHeight != 1080 || cfg.VideoSettings.AudioLang != "ru" || !cfg.VideoSettings.EnableReencoding {
+		t.Errorf("options changed the global video settings: %+v", cfg.VideoSettings)
+	}
+}
+
+func argValue(args []string, flag string) string {
+	for i, arg := range args {
+		if arg == flag && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
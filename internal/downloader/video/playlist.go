@@ -0,0 +1,371 @@
+package ytdlp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tmsconfig "github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	tmsutils "github.com/NikitaDmitryuk/telegram-media-server/internal/utils"
+)
+
+// playlistEntry is one video of a playlist; FileName is relative to MOVIE_PATH (folder/NN_Title.mp4).
+type playlistEntry struct {
+	URL      string
+	Title    string
+	Duration float64
+	FileName string
+}
+
+// playlistInfo is a playlist or multi-episode page expanded by yt-dlp --flat-playlist.
+type playlistInfo struct {
+	Title   string
+	Folder  string
+	Entries []playlistEntry
+}
+
+// flatInfo is the subset of yt-dlp -J --flat-playlist output used to tell playlists from single videos.
+type flatInfo struct {
+	Type    string `json:"_type"`
+	Title   string `json:"title"`
+	Entries []struct {
+		URL        string  `json:"url"`
+		WebpageURL string  `json:"webpage_url"`
+		ID         string  `json:"id"`
+		Title      string  `json:"title"`
+		Duration   float64 `json:"duration"`
+		UploadDate string  `json:"upload_date"`
+	} `json:"entries"`
+}
+
+// probeURL runs yt-dlp -J --flat-playlist once. A playlist comes back as flat entries; a single video
+// comes back with its full metadata, returned as well so the title, formats and size need no second run.
+func probeURL(videoURL string, cfg *tmsconfig.Config) (*flatInfo, *videoMetadata, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ytdlpTimeout)
+	defer cancel()
+	out, err := runFlatJSON(ctx, videoURL, cfg, singleVideoArgs(videoURL)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	var info flatInfo
+	if err := json.Unmarshal(out, &info); err != nil {
+		return nil, nil, fmt.Errorf("parse yt-dlp json: %w", err)
+	}
+	if info.Type == "playlist" {
+		return &info, nil, nil
+	}
+	var metadata videoMetadata
+	if err := json.Unmarshal(out, &metadata); err != nil {
+		return nil, nil, fmt.Errorf("parse yt-dlp json: %w", err)
+	}
+	return &info, &metadata, nil
+}
+
+// singleVideoArgs returns --no-playlist for a link to one video inside a playlist (watch?v=...&list=...,
+// youtu.be/ID?list=...), so the linked video is downloaded rather than the whole list.
+func singleVideoArgs(rawURL string) []string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Query().Get("list") == "" {
+		return nil
+	}
+	if u.Query().Get("v") != "" || (u.Hostname() == "youtu.be" && strings.Trim(u.Path, "/") != "") {
+		return []string{"--no-playlist"}
+	}
+	return nil
+}
+
+func runFlat(ctx context.Context, videoURL string, cfg *tmsconfig.Config, extraArgs ...string) (*flatInfo, error) {
+	out, err := runFlatJSON(ctx, videoURL, cfg, extraArgs...)
+	if err != nil {
+		return nil, err
+	}
+	var info flatInfo
+	if err := json.Unmarshal(out, &info); err != nil {
+		return nil, fmt.Errorf("parse yt-dlp json: %w", err)
+	}
+	return &info, nil
+}
+
+func runFlatJSON(ctx context.Context, videoURL string, cfg *tmsconfig.Config, extraArgs ...string) ([]byte, error) {
+	args := append([]string{"-J", "--flat-playlist", "--no-warnings"}, extraArgs...)
+	args = append(args, proxyArgs(videoURL, cfg)...)
+	args = append(args, cookieArgs(videoURL, cfg)...)
+	args = append(args, videoURL)
+
+	cmd := exec.CommandContext(ctx, ytdlpBinary(cfg), args...) // #nosec G204 -- binary from config, args built from URL and fixed options
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("yt-dlp -J --flat-playlist: %w", err)
+	}
+	return out, nil
+}
+
+// FeedEntry is one video of a channel or playlist. Duration (seconds) and UploadDate (YYYYMMDD)
+// are often missing from flat metadata; use FetchEntryDetails when they are needed.
+type FeedEntry struct {
+	ID         string
+	URL        string
+	Title      string
+	Duration   float64
+	UploadDate string
+}
+
+// Feed is the newest entries of a channel or playlist.
+type Feed struct {
+	Title   string
+	Entries []FeedEntry
+}
+
+// FetchFeed lists the first limit entries of a channel or playlist (newest first for channels)
+// without downloading or fully extracting them.
+func FetchFeed(ctx context.Context, feedURL string, limit int, cfg *tmsconfig.Config) (*Feed, error) {
+	var extra []string
+	if limit > 0 {
+		extra = append(extra, "--playlist-end", strconv.Itoa(limit))
+	}
+	info, err := runFlat(ctx, feedURL, cfg, extra...)
+	if err != nil {
+		return nil, err
+	}
+	if info.Type != "playlist" {
+		return nil, fmt.Errorf("not a channel or playlist: %s", feedURL)
+	}
+	feed := &Feed{Title: strings.TrimSpace(info.Title)}
+	for _, e := range info.Entries {
+		entryURL := e.WebpageURL
+		if entryURL == "" {
+			entryURL = e.URL
+		}
+		if e.ID == "" || (!strings.HasPrefix(entryURL, "http://") && !strings.HasPrefix(entryURL, "https://")) {
+			continue
+		}
+		feed.Entries = append(feed.Entries, FeedEntry{
+			ID:         e.ID,
+			URL:        entryURL,
+			Title:      strings.TrimSpace(e.Title),
+			Duration:   e.Duration,
+			UploadDate: e.UploadDate,
+		})
+	}
+	return feed, nil
+}
+
+// FetchEntryDetails fills Duration and UploadDate of entry from full yt-dlp metadata.
+func FetchEntryDetails(ctx context.Context, entry *FeedEntry, cfg *tmsconfig.Config) error {
+	args := append([]string{"-j", "--no-download", "--no-warnings"}, singleVideoArgs(entry.URL)...)
+	args = append(args, proxyArgs(entry.URL, cfg)...)
+	args = append(args, cookieArgs(entry.URL, cfg)...)
+	args = append(args, entry.URL)
+	cmd := exec.CommandContext(ctx, ytdlpBinary(cfg), args...) // #nosec G204 -- binary from config, args built from URL and fixed options
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("yt-dlp -j: %w", err)
+	}
+	var info struct {
+		Title      string  `json:"title"`
+		Duration   float64 `json:"duration"`
+		UploadDate string  `json:"upload_date"`
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return fmt.Errorf("parse yt-dlp json: %w", err)
+	}
+	if entry.Title == "" {
+		entry.Title = info.Title
+	}
+	entry.Duration = info.Duration
+	entry.UploadDate = info.UploadDate
+	return nil
+}
+
+// newPlaylistInfo turns flat metadata into a download plan; nil when the URL is a single video
+// or the playlist has fewer than two downloadable entries.
+func newPlaylistInfo(info *flatInfo) *playlistInfo {
+	if info == nil || info.Type != "playlist" {
+		return nil
+	}
+	title := strings.TrimSpace(info.Title)
+	if title == "" {
+		title = "playlist"
+	}
+	p := &playlistInfo{Title: title, Folder: tmsutils.SanitizeFileName(title)}
+	for _, e := range info.Entries {
+		entryURL := e.WebpageURL
+		if entryURL == "" {
+			entryURL = e.URL
+		}
+		if !strings.HasPrefix(entryURL, "http://") && !strings.HasPrefix(entryURL, "https://") {
+			continue
+		}
+		entryTitle := strings.TrimSpace(e.Title)
+		if entryTitle == "" {
+			entryTitle = e.ID
+		}
+		index := len(p.Entries) + 1
+		p.Entries = append(p.Entries, playlistEntry{
+			URL:      entryURL,
+			Title:    entryTitle,
+			Duration: e.Duration,
+			FileName: filepath.Join(p.Folder, tmsutils.GenerateFileName(fmt.Sprintf("%02d %s", index, entryTitle))),
+		})
+	}
+	if len(p.Entries) < 2 {
+		return nil
+	}
+	return p
+}
+
+func (p *playlistInfo) files() (mainFiles, tempFiles []string) {
+	for i := range p.Entries {
+		entryMain, entryTemp := filePatterns(p.Entries[i].FileName)
+		mainFiles = append(mainFiles, entryMain...)
+		tempFiles = append(tempFiles, entryTemp...)
+	}
+	return mainFiles, tempFiles
+}
+
+// estimatedSize sums the ~1MB per minute estimate used for single videos; 0 when durations are unknown.
+func (p *playlistInfo) estimatedSize() int64 {
+	var total float64
+	for i := range p.Entries {
+		total += p.Entries[i].Duration
+	}
+	return int64(total * 1024 * 1024 / secondsPerMinute)
+}
+
+// startPlaylistDownload downloads entries one by one into the playlist folder. Overall progress is
+// (finished entries + current entry fraction) / total; episodesChan gets the number of finished entries.
+func (d *YTDLPDownloader) startPlaylistDownload(
+	ctx context.Context,
+) (progressChan chan float64, errChan chan error, episodesChan <-chan int, err error) {
+	if err := os.MkdirAll(filepath.Join(d.config.MoviePath, d.playlist.Folder), 0o755); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create playlist folder: %w", err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	d.cancel = cancel
+	d.done = make(chan struct{})
+
+	progressChan = make(chan float64)
+	errChan = make(chan error, 1)
+	epCh := make(chan int, len(d.playlist.Entries))
+	go d.runPlaylist(ctx, cancel, progressChan, errChan, epCh)
+	return progressChan, errChan, epCh, nil
+}
+
+func (d *YTDLPDownloader) runPlaylist(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	progressChan chan float64,
+	errChan chan error,
+	epCh chan int,
+) {
+	defer close(d.done)
+	defer cancel()
+	defer close(errChan)
+	defer close(progressChan)
+	defer close(epCh)
+
+	total := len(d.playlist.Entries)
+	completed := 0
+	var lastErr error
+	for i := range d.playlist.Entries {
+		entry := &d.playlist.Entries[i]
+		err := d.downloadEntry(ctx, entry, func(percent float64) {
+			select {
+			case progressChan <- (float64(i) + percent/100) / float64(total) * 100:
+			case <-ctx.Done():
+			}
+		})
+		if ctx.Err() != nil {
+			// Stop and cancellation are reported through StoppedManually / context, as for single videos.
+			logutils.Log.WithField("entry", entry.Title).Info("yt-dlp playlist download stopped")
+			errChan <- nil
+			return
+		}
+		if err != nil {
+			// One private or removed video should not fail the whole playlist.
+			logutils.Log.WithError(err).WithFields(map[string]any{
+				"playlist": d.playlist.Title,
+				"entry":    entry.Title,
+				"index":    i + 1,
+			}).Warn("Failed to download playlist entry, skipping")
+			lastErr = err
+			continue
+		}
+		completed++
+		epCh <- completed
+	}
+
+	if completed == 0 {
+		errChan <- fmt.Errorf("no playlist entries could be downloaded: %w", lastErr)
+		return
+	}
+	logutils.Log.WithFields(map[string]any{
+		"playlist":  d.playlist.Title,
+		"completed": completed,
+		"total":     total,
+	}).Info("yt-dlp playlist download finished")
+	errChan <- nil
+}
+
+// downloadEntry runs yt-dlp for one entry and reports its own 0-100 progress.
+func (d *YTDLPDownloader) downloadEntry(ctx context.Context, entry *playlistEntry, onProgress func(float64)) error {
+	outputPath := filepath.Join(d.config.MoviePath, entry.FileName)
+	cmdArgs := d.buildYTDLPArgsForURL(entry.URL, outputPath)
+	cmdArgs = append(proxyArgs(entry.URL, d.config), cmdArgs...)
+	cmdArgs = append(cookieArgs(entry.URL, d.config), cmdArgs...)
+	cmd := exec.CommandContext(ctx, ytdlpBinary(d.config), cmdArgs...) // #nosec G204 -- binary from config, cmdArgs built from URL and options
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start yt-dlp: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "[download]") {
+			continue
+		}
+		if fields := strings.Fields(line); len(fields) >= 2 {
+			if percent, err := strconv.ParseFloat(strings.TrimSuffix(fields[1], "%"), 64); err == nil {
+				onProgress(percent)
+			}
+		}
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("yt-dlp failed (exit code: %w):\n%s", err, stderr.String())
+	}
+	return nil
+}
+
+// stopPlaylist cancels the running entry and waits for the playlist goroutine before cleaning up.
+func (d *YTDLPDownloader) stopPlaylist() error {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	if d.done != nil {
+		select {
+		case <-d.done:
+		case <-time.After(gracefulStopTimeout):
+			logutils.Log.Warn("yt-dlp playlist download did not stop in time")
+		}
+	}
+	if err := d.cleanupTempFiles(); err != nil {
+		logutils.Log.WithError(err).Warn("Failed to cleanup temporary files after stop")
+	}
+	logutils.Log.Info("yt-dlp playlist download stopped manually")
+	return nil
+}
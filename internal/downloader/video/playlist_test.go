@@ -0,0 +1,216 @@
+package ytdlp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/testutils"
+)
+
+const flatPlaylistJSON = `{
+  "_type": "playlist",
+  "title": "My Series: Season 1",
+  "entries": [
+    {"_type": "url", "url": "https://example.com/watch?v=a1", "id": "a1", "title": "Pilot", "duration": 120},
+    {"_type": "url", "url": "https://example.com/watch?v=bad", "id": "bad", "title": "Removed video"},
+    {"_type": "url", "url": "a3", "id": "a3", "title": "Not a URL"},
+    {"_type": "url", "url": "https://example.com/watch?v=a4", "id": "a4", "duration": 60}
+  ]
+}`
+
+func TestNewPlaylistInfo(t *testing.T) {
+	logutils.InitLogger("error")
+	var info flatInfo
+	if err := json.Unmarshal([]byte(flatPlaylistJSON), &info); err != nil {
+		t.Fatal(err)
+	}
+	p := newPlaylistInfo(&info)
+	if p == nil {
+		t.Fatal("expected playlist")
+	}
+	if p.Folder != "My_Series_Season_1" || len(p.Entries) != 3 {
+		t.Fatalf("playlist = %+v", p)
+	}
+	want := []string{
+		filepath.Join("My_Series_Season_1", "01_Pilot.mp4"),
+		filepath.Join("My_Series_Season_1", "02_Removed_video.mp4"),
+		filepath.Join("My_Series_Season_1", "03_a4.mp4"),
+	}
+	for i, e := range p.Entries {
+		if e.FileName != want[i] {
+			t.Errorf("entry %d file = %q, want %q", i, e.FileName, want[i])
+		}
+	}
+	if got := p.estimatedSize(); got != 3*1024*1024 {
+		t.Errorf("estimatedSize = %d, want %d", got, 3*1024*1024)
+	}
+
+	if newPlaylistInfo(&flatInfo{Type: "video", Title: "Single"}) != nil {
+		t.Error("single video must not be treated as playlist")
+	}
+	info.Entries = info.Entries[:1]
+	if newPlaylistInfo(&info) != nil {
+		t.Error("playlist with one entry must be downloaded as a single video")
+	}
+}
+
+func TestSingleVideoArgs(t *testing.T) {
+	tests := map[string]bool{
+		"https://www.youtube.com/watch?v=a1&list=PL1":    true,
+		"https://youtu.be/a1?list=PL1":                   true,
+		"https://www.youtube.com/playlist?list=PL1":      false,
+		"https://www.youtube.com/watch?v=a1":             false,
+		"https://example.com/series/season-1?list=PL1":   false,
+		"https://youtu.be/?list=PL1":                     false,
+		"https://www.youtube.com/watch?v=a1&list=":       false,
+		"https://www.youtube.com/@channel/videos?list=1": false,
+	}
+	for rawURL, want := range tests {
+		got := slices.Contains(singleVideoArgs(rawURL), "--no-playlist")
+		if got != want {
+			t.Errorf("singleVideoArgs(%q) has --no-playlist = %v, want %v", rawURL, got, want)
+		}
+	}
+
+	cfg := testutils.TestConfig(testutils.TempDir(t))
+	d := &YTDLPDownloader{config: cfg}
+	if args := d.buildYTDLPArgsForURL("https://www.youtube.com/watch?v=a1", "/tmp/a1.mp4"); slices.Contains(args, "--no-playlist") {
+		t.Errorf("plain video link must not get --no-playlist: %v", args)
+	}
+	if args := d.buildYTDLPArgsForURL("https://www.youtube.com/watch?v=a1&list=PL1", "/tmp/a1.mp4"); !slices.Contains(args, "--no-playlist") {
+		t.Errorf("video link inside a playlist must get --no-playlist: %v", args)
+	}
+}
+
+func TestNewYTDLPDownloaderProbesOnce(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping fake yt-dlp script test on Windows")
+	}
+	logutils.InitLogger("error")
+	cfg := testutils.TestConfig(testutils.TempDir(t))
+	// Fake yt-dlp -J: a single video with formats, as printed with --flat-playlist for a non-playlist URL.
+	calls := filepath.Join(t.TempDir(), "calls")
+	script := `#!/bin/sh
+echo "$@" >> "` + calls + `"
+cat <<'JSON'
+{"_type": "video", "title": "Clip", "vcodec": "avc1.640028", "filesize": 1000, "formats": [
+  {"format_id": "140", "vcodec": "none", "acodec": "mp4a.40.2", "filesize": 5000, "tbr": 129},
+  {"format_id": "137", "height": 1080, "vcodec": "avc1.640028", "acodec": "none", "filesize": 80000, "tbr": 3000}
+]}
+JSON
+`
+	bin := filepath.Join(t.TempDir(), "yt-dlp")
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil { // #nosec G306 -- test executable
+		t.Fatalf("write fake yt-dlp: %v", err)
+	}
+	cfg.YtdlpPath = bin
+
+	d, ok := NewYTDLPDownloader("https://example.com/watch?v=clip", cfg).(*YTDLPDownloader)
+	if !ok {
+		t.Fatal("expected *YTDLPDownloader")
+	}
+	if d.playlist != nil || d.title != "Clip" {
+		t.Fatalf("title = %q, playlist = %v; want single video %q", d.title, d.playlist, "Clip")
+	}
+	ctx := context.Background()
+	if formats, err := d.ListFormats(ctx); err != nil || len(formats) != 1 {
+		t.Errorf("ListFormats = %+v, %v; want the 1080p format", formats, err)
+	}
+	if _, err := d.GetEarlyTvCompatibility(ctx); err != nil {
+		t.Errorf("GetEarlyTvCompatibility: %v", err)
+	}
+
+	out, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runs := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(runs) != 1 {
+		t.Fatalf("yt-dlp ran %d times, want 1: %q", len(runs), runs)
+	}
+	if strings.Contains(runs[0], "--no-playlist") {
+		t.Errorf("probe of a plain video link passed --no-playlist: %q", runs[0])
+	}
+}
+
+func TestPlaylistDownloadReportsEpisodes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping fake yt-dlp script test on Windows")
+	}
+	logutils.InitLogger("error")
+	tempDir := testutils.TempDir(t)
+	cfg := testutils.TestConfig(tempDir)
+
+	// Fake yt-dlp: fails for the "bad" entry, otherwise prints progress and writes the -o file.
+	script := `#!/bin/sh
+out=""
+prev=""
+for a in "$@"; do
+  if [ "$prev" = "-o" ]; then out="$a"; fi
+  case "$a" in *v=bad*) echo "ERROR: Video unavailable" >&2; exit 1;; esac
+  prev="$a"
+done
+echo "[download]  50.0% of 1.00MiB"
+echo "[download] 100.0% of 1.00MiB"
+echo data > "$out"
+`
+	bin := filepath.Join(t.TempDir(), "yt-dlp")
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil { // #nosec G306 -- test executable
+		t.Fatalf("write fake yt-dlp: %v", err)
+	}
+	cfg.YtdlpPath = bin
+
+	var info flatInfo
+	if err := json.Unmarshal([]byte(flatPlaylistJSON), &info); err != nil {
+		t.Fatal(err)
+	}
+	d := &YTDLPDownloader{url: "https://example.com/playlist", config: cfg, playlist: newPlaylistInfo(&info)}
+	d.title = d.playlist.Title
+	if d.TotalEpisodes() != 3 {
+		t.Fatalf("TotalEpisodes = %d, want 3", d.TotalEpisodes())
+	}
+
+	progressChan, errChan, episodesChan, err := d.StartDownload(context.Background())
+	if err != nil {
+		t.Fatalf("StartDownload: %v", err)
+	}
+	var last float64
+	for p := range progressChan {
+		last = p
+	}
+	select {
+	case err := <-errChan:
+		if err != nil {
+			t.Fatalf("playlist download error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for result")
+	}
+	var episodes []int
+	for n := range episodesChan {
+		episodes = append(episodes, n)
+	}
+	if len(episodes) != 2 || episodes[0] != 1 || episodes[1] != 2 {
+		t.Fatalf("episodes = %v, want [1 2] (failed entry skipped)", episodes)
+	}
+	if last != 100 {
+		t.Fatalf("last progress = %v, want 100", last)
+	}
+	for _, name := range []string{"01_Pilot.mp4", "03_a4.mp4"} {
+		if _, err := os.Stat(filepath.Join(tempDir, "My_Series_Season_1", name)); err != nil {
+			t.Errorf("expected %s in playlist folder: %v", name, err)
+		}
+	}
+	mainFiles, _, _ := d.GetFiles()
+	if mainFiles[0] != filepath.Join("My_Series_Season_1", "01_Pilot.mp4") {
+		t.Errorf("first main file = %q", mainFiles[0])
+	}
+}
@@ -14,11 +14,14 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	tmsconfig "github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/cookies"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/proxy"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/tvcompat"
 	tmsutils "github.com/NikitaDmitryuk/telegram-media-server/internal/utils"
 )
@@ -50,31 +53,87 @@ type YTDLPDownloader struct {
 	cancel          context.CancelFunc
 	stoppedManually bool
 	config          *tmsconfig.Config
+	// options override the configured video settings for this download only.
+	options Options
+	// playlist is set when the URL expands to several videos; each is downloaded into playlist.Folder.
+	playlist *playlistInfo
+	done     chan struct{}
+	// metadata caches the yt-dlp -j output shared by the title, format picker, size and early TV compatibility.
+	metadataMu sync.Mutex
+	metadata   *videoMetadata
 }
 
 func NewYTDLPDownloader(videoURL string, config *tmsconfig.Config) downloader.Downloader {
-	videoTitle, err := getVideoTitle(videoURL, config)
+	return NewYTDLPDownloaderWithOptions(videoURL, config, Options{})
+}
+
+// NewYTDLPDownloaderWithOptions is NewYTDLPDownloader with per-download quality, audio and subtitle overrides.
+func NewYTDLPDownloaderWithOptions(videoURL string, config *tmsconfig.Config, opts Options) downloader.Downloader {
+	info, metadata, err := probeURL(videoURL, config)
+	if err != nil {
+		logutils.Log.WithError(err).WithField("url", videoURL).Debug("Failed to probe URL with yt-dlp")
+	}
+	if playlist := newPlaylistInfo(info); playlist != nil {
+		logutils.Log.WithFields(map[string]any{
+			"url":     videoURL,
+			"title":   playlist.Title,
+			"entries": len(playlist.Entries),
+		}).Info("URL is a playlist, downloading entries into a folder")
+		return &YTDLPDownloader{
+			url:      videoURL,
+			title:    playlist.Title,
+			config:   config,
+			options:  opts,
+			playlist: playlist,
+		}
+	}
+
+	d := &YTDLPDownloader{
+		url:      videoURL,
+		config:   config,
+		options:  opts,
+		metadata: metadata,
+	}
+	if info != nil && strings.TrimSpace(info.Title) != "" {
+		d.title = strings.TrimSpace(info.Title)
+	} else {
+		d.title, err = d.fetchTitle()
+	}
 	if err != nil {
 		logutils.Log.WithError(err).Error("Failed to retrieve video title, generating fallback title")
-		videoTitle, _ = extractVideoID(videoURL)
-		if videoTitle == "" {
-			videoTitle = "unknown_video"
+		d.title, _ = extractVideoID(videoURL)
+		if d.title == "" {
+			d.title = "unknown_video"
 		}
 	}
+	d.outputFileName = tmsutils.GenerateFileName(d.title)
+	return d
+}
 
-	outputFileName := tmsutils.GenerateFileName(videoTitle)
-	return &YTDLPDownloader{
-		url:            videoURL,
-		title:          videoTitle,
-		outputFileName: outputFileName,
-		config:         config,
+// fetchTitle returns the title from the video metadata, "Unknown Title" when yt-dlp reports none.
+func (d *YTDLPDownloader) fetchTitle() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ytdlpTimeout)
+	defer cancel()
+	info, err := d.fetchMetadata(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get video title: %w", err)
+	}
+	if title := strings.TrimSpace(info.Title); title != "" {
+		return title, nil
 	}
+	return "Unknown Title", nil
 }
 
-func (*YTDLPDownloader) TotalEpisodes() int { return 0 }
+// TotalEpisodes is the number of playlist entries, 0 for a single video.
+func (d *YTDLPDownloader) TotalEpisodes() int {
+	if d.playlist != nil {
+		return len(d.playlist.Entries)
+	}
+	return 0
+}
 
-// GetEarlyTvCompatibility runs yt-dlp -j --no-download to get format info and returns a preliminary
-// TV compatibility (green/yellow/red) from vcodec so the circle can be shown immediately.
+// GetEarlyTvCompatibility returns a preliminary TV compatibility (green/yellow/red) from the vcodec in the video
+// metadata so the circle can be shown immediately.
 func (d *YTDLPDownloader) GetEarlyTvCompatibility(ctx context.Context) (string, error) {
 	vcodec, err := d.fetchVcodecFromMetadata(ctx)
 	if err != nil {
@@ -84,28 +143,15 @@ func (d *YTDLPDownloader) GetEarlyTvCompatibility(ctx context.Context) (string,
 	return tvcompat.CompatFromVcodec(vcodec), nil
 }
 
-// fetchVcodecFromMetadata runs yt-dlp -j --no-download and returns the first non-empty vcodec (top-level or from formats).
+// fetchVcodecFromMetadata returns the vcodec of the format picked with SetFormat, otherwise the first non-empty
+// vcodec (top-level or from formats).
 func (d *YTDLPDownloader) fetchVcodecFromMetadata(ctx context.Context) (string, error) {
-	args := []string{"-j", "--no-download", "--no-warnings", d.url}
-	if useProxy, _ := shouldUseProxy(d.url, d.config); useProxy && d.config.Proxy != "" {
-		args = append([]string{"--proxy", d.config.Proxy}, args...)
-	}
-	cmd := exec.CommandContext(
-		ctx,
-		ytdlpBinary(d.config),
-		args...) // #nosec G204 -- binary from config, args built from URL and fixed options
-	out, err := cmd.Output()
+	info, err := d.fetchMetadata(ctx)
 	if err != nil {
-		return "", fmt.Errorf("yt-dlp -j: %w", err)
-	}
-	var info struct {
-		Vcodec  string `json:"vcodec"`
-		Formats []struct {
-			Vcodec string `json:"vcodec"`
-		} `json:"formats"`
+		return "", err
 	}
-	if err := json.Unmarshal(out, &info); err != nil {
-		return "", fmt.Errorf("parse yt-dlp json: %w", err)
+	if f := info.selectedFormat(d.options.Format); f != nil {
+		return f.Vcodec, nil
 	}
 	if info.Vcodec != "" && info.Vcodec != "none" {
 		return info.Vcodec, nil
@@ -118,27 +164,78 @@ func (d *YTDLPDownloader) fetchVcodecFromMetadata(ctx context.Context) (string,
 	return "", nil
 }
 
+// videoMetadata is the part of yt-dlp -j output used for the title, size, TV compatibility and the format picker.
+type videoMetadata struct {
+	Title          string           `json:"title"`
+	Vcodec         string           `json:"vcodec"`
+	Filesize       float64          `json:"filesize"`
+	FilesizeApprox float64          `json:"filesize_approx"`
+	Duration       float64          `json:"duration"`
+	Formats        []formatMetadata `json:"formats"`
+}
+
+type formatMetadata struct {
+	FormatID       string  `json:"format_id"`
+	Ext            string  `json:"ext"`
+	Height         int     `json:"height"`
+	Vcodec         string  `json:"vcodec"`
+	Acodec         string  `json:"acodec"`
+	Filesize       float64 `json:"filesize"`
+	FilesizeApprox float64 `json:"filesize_approx"`
+	Tbr            float64 `json:"tbr"`
+}
+
+// fetchMetadata runs yt-dlp -j --no-download for the video (the first entry of a playlist) once unless the
+// constructor probe already returned it; later calls reuse the result. A failed run is not cached, so the next call retries.
+func (d *YTDLPDownloader) fetchMetadata(ctx context.Context) (*videoMetadata, error) {
+	d.metadataMu.Lock()
+	defer d.metadataMu.Unlock()
+	if d.metadata != nil {
+		return d.metadata, nil
+	}
+	probeURL := d.url
+	if d.playlist != nil {
+		probeURL = d.playlist.Entries[0].URL
+	}
+	args := append([]string{"-j", "--no-download", "--no-warnings"}, singleVideoArgs(probeURL)...)
+	args = append(args, probeURL)
+	args = append(proxyArgs(probeURL, d.config), args...)
+	args = append(cookieArgs(probeURL, d.config), args...)
+	cmd := exec.CommandContext(
+		ctx,
+		ytdlpBinary(d.config),
+		args...) // #nosec G204 -- binary from config, args built from URL and fixed options
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("yt-dlp -j: %w", err)
+	}
+	var info videoMetadata
+	if err := json.Unmarshal(out, &info); err != nil {
+		return nil, fmt.Errorf("parse yt-dlp json: %w", err)
+	}
+	d.metadata = &info
+	return d.metadata, nil
+}
+
 func (d *YTDLPDownloader) StartDownload(
 	ctx context.Context,
 ) (progressChan chan float64, errChan chan error, episodesChan <-chan int, err error) {
-	useProxy, err := shouldUseProxy(d.url, d.config)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("error checking proxy requirement: %w", err)
+	if d.playlist != nil {
+		return d.startPlaylistDownload(ctx)
 	}
-
 	outputPath := filepath.Join(d.config.MoviePath, d.outputFileName)
 	ctx, cancel := context.WithCancel(ctx)
 	d.cancel = cancel
 
 	cmdArgs := d.buildYTDLPArgs(outputPath)
 
-	if useProxy {
-		proxy := d.config.Proxy
-		logutils.Log.WithField("proxy", proxy).Infof("Using proxy for URL: %s", d.url)
-		cmdArgs = append([]string{"--proxy", proxy}, cmdArgs...)
+	if args := proxyArgs(d.url, d.config); args != nil {
+		logutils.Log.WithField("proxy", redactProxy(args[1])).Infof("Using proxy route for URL: %s", d.url)
+		cmdArgs = append(args, cmdArgs...)
 	} else {
-		logutils.Log.Infof("No proxy used for URL: %s", d.url)
+		logutils.Log.Infof("No proxy rule for URL: %s", d.url)
 	}
+	cmdArgs = append(cookieArgs(d.url, d.config), cmdArgs...)
 
 	cmd := exec.CommandContext(
 		ctx,
@@ -272,6 +369,9 @@ func (d *YTDLPDownloader) monitorDownload(
 
 func (d *YTDLPDownloader) StopDownload() error {
 	d.stoppedManually = true
+	if d.playlist != nil {
+		return d.stopPlaylist()
+	}
 
 	if d.cancel != nil {
 		logutils.Log.Info("Canceling yt-dlp download context")
@@ -328,9 +428,19 @@ func (d *YTDLPDownloader) GetTitle() (string, error) {
 }
 
 func (d *YTDLPDownloader) GetFiles() (mainFiles, tempFiles []string, err error) {
-	baseName := strings.TrimSuffix(d.outputFileName, ".mp4")
+	if d.playlist != nil {
+		mainFiles, tempFiles = d.playlist.files()
+		return mainFiles, tempFiles, nil
+	}
+	mainFiles, tempFiles = filePatterns(d.outputFileName)
+	return mainFiles, tempFiles, nil
+}
+
+// filePatterns returns the output file with its subtitle patterns and the temp files yt-dlp may leave for it.
+func filePatterns(outputFileName string) (mainFiles, tempFiles []string) {
+	baseName := strings.TrimSuffix(outputFileName, ".mp4")
 	mainFiles = []string{
-		d.outputFileName,
+		outputFileName,
 		// Subtitle files that yt-dlp can download
 		baseName + ".*.vtt", // WebVTT subtitles (e.g., video.ru.vtt, video.en.vtt)
 		baseName + ".*.srt", // SubRip subtitles
@@ -349,9 +459,9 @@ func (d *YTDLPDownloader) GetFiles() (mainFiles, tempFiles []string, err error)
 		baseName + ".ytdl",
 		baseName + ".ytdlp",
 		// Video-specific temp files
-		d.outputFileName + ".part*", // e.g., video.mp4.part
-		d.outputFileName + ".ytdl",  // e.g., video.mp4.ytdl
-		d.outputFileName + ".ytdlp", // e.g., video.mp4.ytdlp
+		outputFileName + ".part*", // e.g., video.mp4.part
+		outputFileName + ".ytdl",  // e.g., video.mp4.ytdl
+		outputFileName + ".ytdlp", // e.g., video.mp4.ytdlp
 		// Format-specific temp files (yt-dlp uses f-codes for different formats)
 		baseName + ".f*.mp4",
 		baseName + ".f*.mp4.part*",
@@ -372,10 +482,10 @@ func (d *YTDLPDownloader) GetFiles() (mainFiles, tempFiles []string, err error)
 		// Additional common patterns
 		baseName + ".temp",
 		baseName + ".tmp",
-		d.outputFileName + ".temp",
-		d.outputFileName + ".tmp",
+		outputFileName + ".temp",
+		outputFileName + ".tmp",
 	}
-	return mainFiles, tempFiles, nil
+	return mainFiles, tempFiles
 }
 
 func (d *YTDLPDownloader) cleanupTempFiles() error {
@@ -423,102 +533,73 @@ func (d *YTDLPDownloader) cleanupTempFiles() error {
 	return nil
 }
 
+// GetFileSize returns the size of the format picked with SetFormat when the metadata knows it, otherwise the
+// size yt-dlp reports for the video, estimated from the duration as a last resort.
 func (d *YTDLPDownloader) GetFileSize() (int64, error) {
-	useProxy, err := shouldUseProxy(d.url, d.config)
-	if err != nil {
-		logutils.Log.WithError(err).Warn("Failed to determine proxy usage for file size check")
-		return 0, nil
+	if d.playlist != nil {
+		return d.playlist.estimatedSize(), nil
 	}
-
-	cmdArgs := []string{"--skip-download", "--print-json", d.url}
-	if useProxy {
-		cmdArgs = append([]string{"--proxy", d.config.Proxy}, cmdArgs...)
-	}
-
 	ctx, cancel := context.WithTimeout(context.Background(), ytdlpTimeout)
 	defer cancel()
-
-	cmd := exec.CommandContext(
-		ctx,
-		ytdlpBinary(d.config),
-		cmdArgs...) // #nosec G204 -- binary from config, cmdArgs built from URL and options
-	output, err := cmd.Output()
+	info, err := d.fetchMetadata(ctx)
 	if err != nil {
 		logutils.Log.WithError(err).Warn("Failed to get video metadata for file size")
 		return 0, nil
 	}
 
-	var info map[string]any
-	if err := json.Unmarshal(output, &info); err != nil {
-		logutils.Log.WithError(err).Warn("Failed to parse video metadata JSON")
-		return 0, nil
+	if f := info.selectedFormat(d.options.Format); f != nil {
+		if size := selectedFormatSize(info.Formats, f, d.options.Format); size > 0 {
+			logutils.Log.WithFields(map[string]any{
+				"format": d.options.Format,
+				"size":   size,
+				"url":    d.url,
+			}).Debug("Got file size of the selected format")
+			return size, nil
+		}
 	}
-
-	// Try different filesize fields in order of preference
-	sizeFields := []string{
-		"filesize",        // Exact file size (if known)
-		"filesize_approx", // Approximate file size
-		"duration",        // For fallback calculation (duration * estimated bitrate)
-	}
-
-	for _, field := range sizeFields {
-		if field == "duration" {
-			// Fallback: estimate size based on duration
-			if duration, ok := info["duration"].(float64); ok && duration > 0 {
-				// Estimate ~1MB per minute for standard quality video
-				estimatedSize := int64(duration * 1024 * 1024 / secondsPerMinute)
-				logutils.Log.WithFields(map[string]any{
-					"duration":       duration,
-					"estimated_size": estimatedSize,
-					"url":            d.url,
-				}).Debug("Estimating file size based on duration")
-				return estimatedSize, nil
-			}
-		} else {
-			if size, ok := info[field].(float64); ok && size > 0 {
-				logutils.Log.WithFields(map[string]any{
-					"size_field": field,
-					"size":       int64(size),
-					"url":        d.url,
-				}).Debug("Got file size from video metadata")
-				return int64(size), nil
-			}
+	for _, size := range []float64{info.Filesize, info.FilesizeApprox} {
+		if size > 0 {
+			logutils.Log.WithFields(map[string]any{
+				"size": int64(size),
+				"url":  d.url,
+			}).Debug("Got file size from video metadata")
+			return int64(size), nil
 		}
 	}
+	if info.Duration > 0 {
+		// Estimate ~1MB per minute for standard quality video
+		estimatedSize := int64(info.Duration * 1024 * 1024 / secondsPerMinute)
+		logutils.Log.WithFields(map[string]any{
+			"duration":       info.Duration,
+			"estimated_size": estimatedSize,
+			"url":            d.url,
+		}).Debug("Estimating file size based on duration")
+		return estimatedSize, nil
+	}
 
-	// If no size information is available, log the available fields for debugging
-	logutils.Log.WithFields(map[string]any{
-		"available_fields": getMapKeys(info),
-		"url":              d.url,
-	}).Warn("No file size information available in video metadata")
-
+	logutils.Log.WithField("url", d.url).Warn("No file size information available in video metadata")
 	return 0, nil
 }
 
-func getMapKeys(m map[string]any) []string {
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
-	}
-	return keys
-}
-
 func (d *YTDLPDownloader) StoppedManually() bool {
 	return d.stoppedManually
 }
 
 func (d *YTDLPDownloader) buildYTDLPArgs(outputPath string) []string {
-	videoSettings := d.config.GetVideoSettings()
+	return d.buildYTDLPArgsForURL(d.url, outputPath)
+}
+
+func (d *YTDLPDownloader) buildYTDLPArgsForURL(videoURL, outputPath string) []string {
+	videoSettings := d.options.apply(d.config.GetVideoSettings())
 
 	qualitySelector := prepareQualitySelector(&videoSettings)
 
 	args := []string{
 		"--newline",
 		"--remote-components", "ejs:github", // Enable remote components for JS challenge solving
-		"-f", qualitySelector,
-		"-o", outputPath,
-		d.url,
 	}
+	args = append(args, singleVideoArgs(videoURL)...)
+	args = append(args, "-f", qualitySelector, "-o", outputPath, videoURL)
 
 	args = appendFormatSortArgs(args, &videoSettings)
 	args = appendReencodingArgs(args, &videoSettings)
@@ -617,57 +698,33 @@ func appendSubtitleArgs(args []string, videoSettings *tmsconfig.VideoConfig) []s
 	return args
 }
 
-func shouldUseProxy(rawURL string, cfg *tmsconfig.Config) (bool, error) {
-	if cfg.Proxy == "" {
-		return false, nil
-	}
-
-	if cfg.ProxyDomains == "" {
-		return true, nil
-	}
-
-	parsedURL, err := url.Parse(rawURL)
-	if err != nil {
-		return false, fmt.Errorf("failed to parse URL: %w", err)
-	}
-
-	hostname := parsedURL.Hostname()
-	domains := strings.Split(cfg.ProxyDomains, ",")
-	for _, domain := range domains {
-		if strings.Contains(hostname, strings.TrimSpace(domain)) {
-			return true, nil
-		}
+// proxyArgs routes yt-dlp by PROXY_RULES: --proxy with the proxy of the host, --proxy "" for a direct rule,
+// nothing when no rule covers the host so yt-dlp keeps its own environment settings.
+func proxyArgs(rawURL string, cfg *tmsconfig.Config) []string {
+	rule, ok := proxy.NewContent(cfg).Route(rawURL)
+	if !ok {
+		return nil
 	}
-
-	return false, nil
+	return []string{"--proxy", rule.Proxy}
 }
 
-func getVideoTitle(videoURL string, cfg *tmsconfig.Config) (string, error) {
-	useProxy, err := shouldUseProxy(videoURL, cfg)
-	if err != nil {
-		return "", fmt.Errorf("failed to determine proxy usage: %w", err)
+// redactProxy hides proxy credentials in logs; "" is a direct connection.
+func redactProxy(raw string) string {
+	if raw == "" {
+		return tmsconfig.ProxyDirect
 	}
-
-	args := []string{"--get-title", "--no-playlist"}
-	if useProxy && cfg.Proxy != "" {
-		args = append(args, "--proxy", cfg.Proxy)
-	}
-	args = append(args, videoURL)
-
-	ctx, cancel := context.WithTimeout(context.Background(), ytdlpTimeout)
-	defer cancel()
-	cmd := exec.CommandContext(ctx, ytdlpBinary(cfg), args...) // #nosec G204 -- binary from config, args built from URL and fixed options
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("failed to get video title: %w", err)
+	if u, err := url.Parse(raw); err == nil {
+		return u.Redacted()
 	}
+	return raw
+}
 
-	title := strings.TrimSpace(string(output))
-	if title == "" {
-		return "Unknown Title", nil
+// cookieArgs passes the cookies.txt profile matching the host of rawURL (see /cookies), if any.
+func cookieArgs(rawURL string, cfg *tmsconfig.Config) []string {
+	if file := cookies.FileFor(cookies.Dir(cfg), rawURL); file != "" {
+		return []string{"--cookies", file}
 	}
-
-	return title, nil
+	return nil
 }
 
 func extractVideoID(rawURL string) (string, error) {
@@ -1,56 +1,66 @@
 package ytdlp
 
 import (
+	"slices"
 	"testing"
 
 	tmsconfig "github.com/NikitaDmitryuk/telegram-media-server/internal/config"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/testutils"
 )
 
-func TestShouldUseProxy(t *testing.T) {
+func TestProxyArgs(t *testing.T) {
 	tempDir := testutils.TempDir(t)
 	cfg := testutils.TestConfig(tempDir)
 
 	tests := []struct {
 		url          string
 		proxyDomains string
-		expected     bool
+		proxyRules   string
+		expected     []string
 	}{
 		{
 			url:          "https://youtube.com/watch?v=123",
 			proxyDomains: "youtube.com",
-			expected:     true,
+			expected:     []string{"--proxy", "http://proxy.example.com:8080"},
 		},
 		{
 			url:          "https://vimeo.com/123",
 			proxyDomains: "youtube.com, vimeo.com",
-			expected:     true,
+			expected:     []string{"--proxy", "http://proxy.example.com:8080"},
 		},
 		{
 			url:          "https://dailymotion.com/video/x123",
 			proxyDomains: "youtube.com",
-			expected:     false,
+			expected:     nil,
 		},
 		{
 			url:          "https://example.com",
 			proxyDomains: "",
-			expected:     true,
+			expected:     []string{"--proxy", "http://proxy.example.com:8080"},
+		},
+		{
+			url:        "https://music.youtube.com/watch?v=1",
+			proxyRules: "youtube.com=socks5://127.0.0.1:1080",
+			expected:   []string{"--proxy", "socks5://127.0.0.1:1080"},
+		},
+		{
+			url:          "https://example.com",
+			proxyDomains: "",
+			proxyRules:   "example.com=direct",
+			expected:     []string{"--proxy", ""},
 		},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.url, func(t *testing.T) {
+		t.Run(tt.url+" "+tt.proxyRules, func(t *testing.T) {
 			tempCfg := *cfg
 			tempCfg.ProxyDomains = tt.proxyDomains
 			tempCfg.Proxy = "http://proxy.example.com:8080"
-			result, err := shouldUseProxy(tt.url, &tempCfg)
-			if err != nil {
-				t.Errorf("shouldUseProxy(%s) failed: %v", tt.url, err)
-				return
-			}
-			if result != tt.expected {
-				t.Errorf("shouldUseProxy(%s, %s) = %v, expected %v",
-					tt.url, tt.proxyDomains, result, tt.expected)
+			tempCfg.ProxyRules = tt.proxyRules
+			result := proxyArgs(tt.url, &tempCfg)
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("proxyArgs(%s, %q, %q) = %q, expected %q",
+					tt.url, tt.proxyDomains, tt.proxyRules, result, tt.expected)
 			}
 		})
 	}
@@ -98,30 +108,25 @@ func TestYTDLPDownloader(t *testing.T) {
 	t.Skip("YTDLPDownloader integration tests require yt-dlp executable - skipping for now")
 }
 
-func TestShouldUseProxy_NoProxy(t *testing.T) {
+func TestProxyArgs_NoProxy(t *testing.T) {
 	tempDir := testutils.TempDir(t)
 	cfg := testutils.TestConfig(tempDir)
 	cfg.Proxy = ""
 	cfg.ProxyDomains = ""
 
-	useProxy, err := shouldUseProxy("https://youtube.com/watch?v=123", cfg)
-	if err != nil {
-		t.Fatalf("shouldUseProxy: %v", err)
-	}
-	if useProxy {
-		t.Error("shouldUseProxy should return false when no proxy configured")
+	if args := proxyArgs("https://youtube.com/watch?v=123", cfg); args != nil {
+		t.Errorf("proxyArgs should return nothing when no proxy configured, got %q", args)
 	}
 }
 
-func TestShouldUseProxy_InvalidURL(t *testing.T) {
+func TestProxyArgs_InvalidURL(t *testing.T) {
 	tempDir := testutils.TempDir(t)
 	cfg := testutils.TestConfig(tempDir)
 	cfg.Proxy = "http://proxy:8080"
 	cfg.ProxyDomains = "youtube.com"
 
-	_, err := shouldUseProxy("://bad-url", cfg)
-	if err == nil {
-		t.Error("shouldUseProxy should return error for invalid URL")
+	if args := proxyArgs("://bad-url", cfg); args != nil {
+		t.Errorf("proxyArgs should return nothing for an invalid URL, got %q", args)
 	}
 }
 
@@ -342,37 +347,6 @@ func TestAppendSubtitleArgs(t *testing.T) {
 	}
 }
 
-func TestGetMapKeys(t *testing.T) {
-	m := map[string]any{
-		"title":    "test",
-		"duration": 120,
-		"filesize": 1024,
-	}
-
-	keys := getMapKeys(m)
-	if len(keys) != 3 {
-		t.Fatalf("Expected 3 keys, got %d: %v", len(keys), keys)
-	}
-
-	// Check all keys present (order not guaranteed)
-	keySet := map[string]bool{}
-	for _, k := range keys {
-		keySet[k] = true
-	}
-	for _, expected := range []string{"title", "duration", "filesize"} {
-		if !keySet[expected] {
-			t.Errorf("Missing key %q in result: %v", expected, keys)
-		}
-	}
-}
-
-func TestGetMapKeys_Empty(t *testing.T) {
-	keys := getMapKeys(map[string]any{})
-	if len(keys) != 0 {
-		t.Errorf("Expected empty keys, got %v", keys)
-	}
-}
-
 func TestAddAudioLanguageFilter(t *testing.T) {
 	tests := []struct {
 		name     string
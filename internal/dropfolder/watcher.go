@@ -0,0 +1,257 @@
+package dropfolder
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/factory"
+	aria2 "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/torrent"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/utils"
+	"github.com/google/uuid"
+)
+
+const (
+	// ArchiveDir and ErrorDir are the subfolders of DROP_FOLDER that started and rejected files are moved to.
+	ArchiveDir = "archive"
+	ErrorDir   = "error"
+
+	// settleTime skips files modified this recently: they may still be being written.
+	settleTime   = 2 * time.Second
+	startTimeout = 2 * time.Minute
+	maxFileBytes = 10 * 1024 * 1024 // same cap as .torrent files fetched by URL
+	dirMode      = 0o750
+	fileMode     = 0o600
+)
+
+// Item is a dropped file resolved to what the factory starts: a magnet/URL, or a .torrent file path.
+type Item struct {
+	Link        string
+	TorrentPath string
+}
+
+// startFunc starts the download of one item and returns its title; replaced in tests.
+type startFunc func(ctx context.Context, a *app.App, item Item) (string, error)
+
+// StartWatcher scans DROP_FOLDER right away and then every DROP_FOLDER_INTERVAL. Does nothing when
+// DROP_FOLDER is not set. Blocks until ctx is done.
+func StartWatcher(ctx context.Context, a *app.App) {
+	dir := a.Config.DropFolder
+	if dir == "" {
+		return
+	}
+	for _, sub := range []string{ArchiveDir, ErrorDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), dirMode); err != nil {
+			logutils.Log.WithError(err).WithField("dir", sub).Error("Drop folder: failed to create subfolder, watcher disabled")
+			return
+		}
+	}
+	ticker := time.NewTicker(a.Config.DropFolderInterval)
+	defer ticker.Stop()
+
+	logutils.Log.WithFields(map[string]any{
+		"dir":      dir,
+		"interval": a.Config.DropFolderInterval,
+	}).Info("Starting drop folder watcher")
+	Scan(ctx, a)
+	for {
+		select {
+		case <-ctx.Done():
+			logutils.Log.Info("Stopping drop folder watcher")
+			return
+		case <-ticker.C:
+			Scan(ctx, a)
+		}
+	}
+}
+
+// Scan starts every settled .torrent, .magnet and .url file in DROP_FOLDER, moves it to the archive or
+// error subfolder and tells the admins. Other files are left alone.
+func Scan(ctx context.Context, a *app.App) {
+	scan(ctx, a, time.Now(), startItem)
+}
+
+func scan(ctx context.Context, a *app.App, now time.Time, start startFunc) {
+	dir := a.Config.DropFolder
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		logutils.Log.WithError(err).Warn("Drop folder: ReadDir failed")
+		return
+	}
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+		if !entry.Type().IsRegular() || !isSupported(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || now.Sub(info.ModTime()) < settleTime {
+			continue
+		}
+		process(ctx, a, entry.Name(), start)
+	}
+}
+
+func isSupported(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".torrent", ".magnet", ".url":
+		return !strings.HasPrefix(name, ".")
+	default:
+		return false
+	}
+}
+
+func process(ctx context.Context, a *app.App, name string, start startFunc) {
+	path := filepath.Join(a.Config.DropFolder, name)
+	log := logutils.Log.WithField("file", name)
+
+	item, err := ReadItem(path)
+	var title string
+	if err == nil {
+		startCtx, cancel := context.WithTimeout(ctx, startTimeout)
+		title, err = start(startCtx, a, item)
+		cancel()
+	}
+
+	target := ArchiveDir
+	var message string
+	if err != nil {
+		log.WithError(err).Warn("Drop folder: file rejected")
+		target = ErrorDir
+		message = lang.Translate("error.drop_folder.failed", map[string]any{
+			"File":  name,
+			"Error": utils.DownloadErrorMessage(err),
+		})
+	} else {
+		log.WithField("title", title).Info("Drop folder: download started")
+		message = lang.Translate("general.drop_folder.started", map[string]any{
+			"File":  name,
+			"Title": title,
+		})
+	}
+	if moveErr := moveTo(path, target); moveErr != nil {
+		// The file stays in place and would be started again on every scan.
+		log.WithError(moveErr).Error("Drop folder: failed to move processed file")
+	}
+	app.ChatsNotifier{App: a, ChatIDs: app.RecipientChats(ctx, a, 0)}.Send(message)
+}
+
+// moveTo moves path into the subfolder, prefixed with the time so repeated names never collide.
+func moveTo(path, sub string) error {
+	name := time.Now().Format("20060102-150405") + "_" + filepath.Base(path)
+	return os.Rename(path, filepath.Join(filepath.Dir(path), sub, name))
+}
+
+// ReadItem validates a dropped file: a .torrent with ValidateTorrentFile, a .magnet text file
+// holding a magnet link, or a .url file (an "[InternetShortcut]" with URL=, or just the link).
+func ReadItem(path string) (Item, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Item{}, err
+	}
+	if info.Size() > maxFileBytes {
+		return Item{}, fmt.Errorf("file is too big: %d bytes", info.Size())
+	}
+	if strings.EqualFold(filepath.Ext(path), ".torrent") {
+		if err := aria2.ValidateTorrentFile(path); err != nil {
+			return Item{}, err
+		}
+		return Item{TorrentPath: path}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Item{}, err
+	}
+	link := readLink(data)
+	if link == "" {
+		return Item{}, errors.New("no link found in file")
+	}
+	lower := strings.ToLower(link)
+	switch {
+	case strings.HasPrefix(lower, "magnet:"):
+		if err := aria2.ValidateMagnetBtih(link); err != nil {
+			return Item{}, err
+		}
+	case strings.EqualFold(filepath.Ext(path), ".magnet"):
+		return Item{}, errors.New("not a magnet link")
+	case !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://"):
+		return Item{}, fmt.Errorf("unsupported link: %s", link)
+	}
+	return Item{Link: link}, nil
+}
+
+// readLink returns the URL= value of an Internet shortcut, otherwise the first non-empty line.
+func readLink(data []byte) string {
+	var first string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "[") {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok && strings.EqualFold(key, "URL") {
+			return strings.TrimSpace(value)
+		}
+		if first == "" {
+			first = line
+		}
+	}
+	return first
+}
+
+// startItem starts the download through the factory; the admins get the completion messages.
+func startItem(ctx context.Context, a *app.App, item Item) (string, error) {
+	dl, cleanup, err := newDownloader(ctx, a, item)
+	if err != nil {
+		return "", err
+	}
+	// The "started from the drop folder" message is sent by the scan once the file is moved.
+	title, err := app.StartAndNotify(ctx, a, dl, app.RecipientChats(ctx, a, 0), nil)
+	if err != nil {
+		cleanup()
+		return "", err
+	}
+	return title, nil
+}
+
+// newDownloader copies a dropped .torrent into MOVIE_PATH under a unique name; cleanup removes the copy
+// when the download is not started.
+func newDownloader(ctx context.Context, a *app.App, item Item) (dl downloader.Downloader, cleanup func(), err error) {
+	cleanup = func() {}
+	if item.Link != "" {
+		dl, err = factory.CreateDownloaderFromURL(ctx, item.Link, a.Config.MoviePath, a.Config)
+		return dl, cleanup, err
+	}
+	data, err := os.ReadFile(item.TorrentPath)
+	if err != nil {
+		return nil, cleanup, err
+	}
+	name := "drop_" + uuid.New().String()[:8] + ".torrent"
+	copyPath := filepath.Join(a.Config.MoviePath, name)
+	if err := os.WriteFile(copyPath, data, fileMode); err != nil {
+		return nil, cleanup, fmt.Errorf("copy torrent file: %w", err)
+	}
+	cleanup = func() {
+		if err := os.Remove(copyPath); err != nil && !os.IsNotExist(err) {
+			logutils.Log.WithError(err).WithField("file", name).Warn("Drop folder: failed to remove torrent copy")
+		}
+	}
+	dl, err = factory.NewTorrentDownloader(name, a.Config.MoviePath, a.Config)
+	if err != nil {
+		cleanup()
+		return nil, func() {}, err
+	}
+	return dl, cleanup, nil
+}
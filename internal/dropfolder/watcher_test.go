@@ -0,0 +1,140 @@
+package dropfolder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/testutils"
+)
+
+const testMagnet = "magnet:?xt=urn:btih:1234567890abcdef1234567890abcdef12345678&dn=Drop"
+
+// adminDB reports one admin, chat 42.
+type adminDB struct {
+	database.Database
+}
+
+func (adminDB) GetUsersByRole(context.Context, database.UserRole) ([]database.User, error) {
+	return []database.User{{ChatID: 42}}, nil
+}
+
+func writeDropFile(t *testing.T, dir, name, content string, modTime time.Time) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadItem(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{name: "a.magnet", content: "\n" + testMagnet + "\n", want: testMagnet},
+		{name: "b.url", content: "[InternetShortcut]\r\nURL=https://example.com/video\r\n", want: "https://example.com/video"},
+		{name: "c.url", content: "https://example.com/file.torrent", want: "https://example.com/file.torrent"},
+		{name: "d.magnet", content: "https://example.com/video", wantErr: true},
+		{name: "e.magnet", content: "magnet:?xt=urn:btih:1234", wantErr: true},
+		{name: "f.url", content: "ftp://example.com/file", wantErr: true},
+		{name: "g.url", content: "[InternetShortcut]\n", wantErr: true},
+		{name: "h.torrent", content: "not a torrent", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := ReadItem(writeDropFile(t, dir, tt.name, tt.content, old))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ReadItem() = %+v, want an error", item)
+				}
+				return
+			}
+			if err != nil || item.Link != tt.want {
+				t.Fatalf("ReadItem() = %+v, %v; want link %q", item, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanMovesFilesAndNotifiesAdmins(t *testing.T) {
+	ctx := context.Background()
+	cfg := testutils.TestConfig(testutils.TempDir(t))
+	cfg.DropFolder = t.TempDir()
+	bot := &testutils.MockBot{}
+	a := &app.App{DB: adminDB{testutils.TestDatabase(t)}, Bot: bot, Config: cfg}
+	for _, sub := range []string{ArchiveDir, ErrorDir} {
+		if err := os.MkdirAll(filepath.Join(cfg.DropFolder, sub), dirMode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+	old := now.Add(-time.Minute)
+	writeDropFile(t, cfg.DropFolder, "good.magnet", testMagnet, old)
+	writeDropFile(t, cfg.DropFolder, "full.url", "https://example.com/video", old)
+	writeDropFile(t, cfg.DropFolder, "broken.torrent", "not a torrent", old)
+	writeDropFile(t, cfg.DropFolder, "fresh.magnet", testMagnet, now)
+	writeDropFile(t, cfg.DropFolder, "notes.txt", "hello", old)
+
+	var started []string
+	start := func(_ context.Context, _ *app.App, item Item) (string, error) {
+		if strings.HasPrefix(item.Link, "https://") {
+			return "", app.ErrNotEnoughSpace
+		}
+		started = append(started, item.Link)
+		return "Drop", nil
+	}
+	scan(ctx, a, now, start)
+
+	if len(started) != 1 || started[0] != testMagnet {
+		t.Fatalf("started %v, want only the settled magnet", started)
+	}
+	assertFiles(t, cfg.DropFolder, false, []string{"fresh.magnet", "notes.txt"})
+	assertFiles(t, filepath.Join(cfg.DropFolder, ArchiveDir), true, []string{"good.magnet"})
+	assertFiles(t, filepath.Join(cfg.DropFolder, ErrorDir), true, []string{"broken.torrent", "full.url"})
+
+	if len(bot.SentMessages) != 3 {
+		t.Fatalf("sent %d messages, want 3 (one per processed file)", len(bot.SentMessages))
+	}
+	for _, msg := range bot.SentMessages {
+		if msg.ChatID != 42 {
+			t.Errorf("message sent to chat %d, want the admin", msg.ChatID)
+		}
+	}
+}
+
+// assertFiles checks the regular files of dir; moved strips the time prefix added by moveTo.
+func assertFiles(t *testing.T, dir string, moved bool, want []string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			name := entry.Name()
+			if _, rest, ok := strings.Cut(name, "_"); ok && moved {
+				name = rest
+			}
+			got = append(got, name)
+		}
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("%s contains %v, want %v", filepath.Base(dir), got, want)
+	}
+}
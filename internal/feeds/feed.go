@@ -0,0 +1,190 @@
+package feeds
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	aria2 "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/torrent"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/proxy"
+)
+
+const (
+	fetchTimeout    = 60 * time.Second
+	maxFeedBodySize = 10 * 1024 * 1024 // 10 MiB
+)
+
+// Feed is a parsed RSS or Torznab feed.
+type Feed struct {
+	Title    string
+	Releases []Release
+}
+
+// Release is one feed item. Size, Seeders and Categories are zero/-1/nil when the feed does not report them.
+type Release struct {
+	Title string
+	GUID  string
+	// Link is a magnet, or the .torrent/NZB download URL.
+	Link       string
+	InfoHash   string // lowercase hex, "" when unknown
+	Size       int64
+	Seeders    int
+	Categories []int
+}
+
+// Key identifies the release across feeds: the info hash, or the GUID (or link) when the feed has none.
+func (r *Release) Key() string {
+	if r.InfoHash != "" {
+		return r.InfoHash
+	}
+	if r.GUID != "" {
+		return "guid:" + r.GUID
+	}
+	return "guid:" + r.Link
+}
+
+type rssDocument struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssItem struct {
+	Title      string   `xml:"title"`
+	GUID       string   `xml:"guid"`
+	Link       string   `xml:"link"`
+	Size       int64    `xml:"size"`
+	Categories []string `xml:"category"`
+	Enclosure  struct {
+		URL    string `xml:"url,attr"`
+		Length int64  `xml:"length,attr"`
+	} `xml:"enclosure"`
+	// Attrs are torznab:attr / newznab:attr elements (seeders, size, infohash, magneturl, category).
+	Attrs []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value,attr"`
+	} `xml:"attr"`
+	// InfoHash and MagnetURI come from the ezRSS torrent namespace used by many tracker feeds.
+	InfoHash  string `xml:"infoHash"`
+	MagnetURI string `xml:"magnetURI"`
+}
+
+// Fetch downloads and parses the feed through the configured proxy. The Prowlarr API key is sent for feeds
+// served by PROWLARR_URL.
+func Fetch(ctx context.Context, feedURL string, cfg *config.Config) (*Feed, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	if isProwlarrURL(cfg, feedURL) && cfg.ProwlarrAPIKey != "" {
+		req.Header.Set("X-Api-Key", cfg.ProwlarrAPIKey)
+	}
+	client := &http.Client{
+		Timeout:   fetchTimeout,
+		Transport: proxy.Transport(cfg),
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch feed: status %d", resp.StatusCode)
+	}
+	return Parse(io.LimitReader(resp.Body, maxFeedBodySize))
+}
+
+// Parse reads an RSS 2.0 feed with optional Torznab/Newznab attributes.
+func Parse(r io.Reader) (*Feed, error) {
+	var doc rssDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse feed: %w", err)
+	}
+	feed := &Feed{Title: strings.TrimSpace(doc.Channel.Title)}
+	for i := range doc.Channel.Items {
+		if rel, ok := newRelease(&doc.Channel.Items[i]); ok {
+			feed.Releases = append(feed.Releases, rel)
+		}
+	}
+	return feed, nil
+}
+
+func newRelease(item *rssItem) (Release, bool) {
+	rel := Release{
+		Title:   strings.TrimSpace(item.Title),
+		GUID:    strings.TrimSpace(item.GUID),
+		Size:    item.Size,
+		Seeders: -1,
+	}
+	if rel.Size == 0 {
+		rel.Size = item.Enclosure.Length
+	}
+	var magnet string
+	for _, a := range item.Attrs {
+		value := strings.TrimSpace(a.Value)
+		switch strings.ToLower(a.Name) {
+		case "seeders":
+			if n, err := strconv.Atoi(value); err == nil {
+				rel.Seeders = n
+			}
+		case "size":
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil && n > 0 {
+				rel.Size = n
+			}
+		case "infohash":
+			rel.InfoHash = aria2.NormalizeInfoHash(value)
+		case "magneturl":
+			magnet = value
+		case "category":
+			if n, err := strconv.Atoi(value); err == nil {
+				rel.Categories = append(rel.Categories, n)
+			}
+		}
+	}
+	if len(rel.Categories) == 0 {
+		for _, c := range item.Categories {
+			if n, err := strconv.Atoi(strings.TrimSpace(c)); err == nil {
+				rel.Categories = append(rel.Categories, n)
+			}
+		}
+	}
+	if magnet == "" {
+		magnet = strings.TrimSpace(item.MagnetURI)
+	}
+	if rel.InfoHash == "" {
+		rel.InfoHash = aria2.NormalizeInfoHash(item.InfoHash)
+	}
+	if rel.InfoHash == "" && magnet != "" {
+		rel.InfoHash = aria2.MagnetInfoHash(magnet)
+	}
+
+	switch {
+	case magnet != "":
+		rel.Link = magnet
+	case item.Enclosure.URL != "":
+		rel.Link = strings.TrimSpace(item.Enclosure.URL)
+	default:
+		rel.Link = strings.TrimSpace(item.Link)
+	}
+	if rel.InfoHash == "" && strings.HasPrefix(strings.ToLower(rel.Link), "magnet:") {
+		rel.InfoHash = aria2.MagnetInfoHash(rel.Link)
+	}
+	return rel, rel.Title != "" && rel.Link != ""
+}
+
+func isProwlarrURL(cfg *config.Config, rawURL string) bool {
+	if cfg.ProwlarrURL == "" {
+		return false
+	}
+	u, err := url.Parse(rawURL)
+	base, baseErr := url.Parse(cfg.ProwlarrURL)
+	return err == nil && baseErr == nil && u.Scheme == base.Scheme && u.Host == base.Host
+}
@@ -0,0 +1,101 @@
+package feeds
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+)
+
+const torznabFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:torznab="http://torznab.com/schemas/2015/feed">
+<channel>
+  <title>Prowlarr: Tracker</title>
+  <item>
+    <title>Show.S01E02.1080p.WEB</title>
+    <guid>https://tracker.example/t/2</guid>
+    <link>https://prowlarr.example/1/download?link=abc</link>
+    <enclosure url="https://prowlarr.example/1/download?link=abc" length="1500000000" type="application/x-bittorrent"/>
+    <torznab:attr name="seeders" value="42"/>
+    <torznab:attr name="category" value="5040"/>
+    <torznab:attr name="infohash" value="ABCDEF0123456789ABCDEF0123456789ABCDEF01"/>
+  </item>
+  <item>
+    <title>Movie.2024.2160p</title>
+    <guid>movie-2024</guid>
+    <torznab:attr name="size" value="20000000000"/>
+    <torznab:attr name="magneturl" value="magnet:?xt=urn:btih:VPG66AJDIVTYTK6N54ASGRLHRGV433YB&amp;dn=Movie"/>
+  </item>
+  <item>
+    <title>Plain RSS item</title>
+    <link>https://tracker.example/download/3.torrent</link>
+  </item>
+  <item>
+    <title>No link</title>
+  </item>
+</channel>
+</rss>`
+
+func TestParse(t *testing.T) {
+	feed, err := Parse(strings.NewReader(torznabFeed))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if feed.Title != "Prowlarr: Tracker" || len(feed.Releases) != 3 {
+		t.Fatalf("feed = %q with %d releases, want 3", feed.Title, len(feed.Releases))
+	}
+
+	show := feed.Releases[0]
+	if show.Seeders != 42 || show.Size != 1500000000 || len(show.Categories) != 1 || show.Categories[0] != 5040 {
+		t.Errorf("torznab attrs not parsed: %+v", show)
+	}
+	if show.Key() != "abcdef0123456789abcdef0123456789abcdef01" {
+		t.Errorf("Key = %q, want lowercase info hash", show.Key())
+	}
+	if show.Link != "https://prowlarr.example/1/download?link=abc" {
+		t.Errorf("Link = %q, want enclosure URL", show.Link)
+	}
+
+	movie := feed.Releases[1]
+	if !strings.HasPrefix(movie.Link, "magnet:") || movie.Size != 20000000000 || movie.Seeders != -1 {
+		t.Errorf("magnet release: %+v", movie)
+	}
+	if movie.InfoHash != "abcdef0123456789abcdef0123456789abcdef01" {
+		t.Errorf("base32 btih = %q, want hex", movie.InfoHash)
+	}
+
+	plain := feed.Releases[2]
+	if plain.InfoHash != "" || plain.Key() != "guid:https://tracker.example/download/3.torrent" {
+		t.Errorf("plain release key = %q", plain.Key())
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse(strings.NewReader("<html><body>login")); err == nil {
+		t.Error("expected error for non-XML feed")
+	}
+}
+
+func TestFetchUsesProxyRules(t *testing.T) {
+	var proxied string
+	proxySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		_, _ = w.Write([]byte(torznabFeed))
+	}))
+	defer proxySrv.Close()
+	cfg := &config.Config{ProxyRules: "tracker.example=" + proxySrv.URL}
+
+	feed, err := Fetch(context.Background(), "http://tracker.example/rss", cfg)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if proxied != "http://tracker.example/rss" {
+		t.Errorf("proxy got %q, want the feed request", proxied)
+	}
+	if len(feed.Releases) == 0 {
+		t.Error("Fetch returned no releases")
+	}
+}
@@ -0,0 +1,111 @@
+package feeds
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+)
+
+// Reject reasons for releases that do not pass a rule.
+const (
+	rejectInclude  = "include"
+	rejectExclude  = "exclude"
+	rejectSize     = "size"
+	rejectSeeders  = "seeders"
+	rejectCategory = "category"
+)
+
+const categoryGroup = 1000
+
+// Validate checks the feed URL and filters of rule.
+func Validate(rule *database.FeedRule) error {
+	rule.FeedURL = strings.TrimSpace(rule.FeedURL)
+	u, err := url.Parse(rule.FeedURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("feed url must be an http(s) RSS or Torznab link")
+	}
+	for _, re := range []string{rule.Include, rule.Exclude} {
+		if re == "" {
+			continue
+		}
+		if _, err := regexp.Compile(re); err != nil {
+			return fmt.Errorf("invalid regex %q: %w", re, err)
+		}
+	}
+	if rule.MinSizeBytes < 0 || rule.MaxSizeBytes < 0 || rule.MinSeeders < 0 || rule.Category < 0 {
+		return errors.New("size, seeders and category must not be negative")
+	}
+	if rule.MaxSizeBytes > 0 && rule.MinSizeBytes > rule.MaxSizeBytes {
+		return errors.New("min size is larger than max size")
+	}
+	return nil
+}
+
+var sizeUnits = []struct {
+	suffix string
+	bytes  float64
+}{
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize accepts a size with a unit ("700MB", "4.5GB") or a bare number of gigabytes ("4.5").
+func ParseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	multiplier := float64(1 << 30)
+	for _, u := range sizeUnits {
+		if num, ok := strings.CutSuffix(s, u.suffix); ok {
+			s, multiplier = strings.TrimSpace(num), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q, expected e.g. 700MB or 4.5GB", s)
+	}
+	return int64(n * multiplier), nil
+}
+
+// rejectReason returns why rel does not pass rule, or "" when it should be downloaded.
+// Values the feed does not report (size, seeders, category) pass.
+func rejectReason(rule *database.FeedRule, rel *Release) string {
+	if rule.Include != "" {
+		if re, err := regexp.Compile(rule.Include); err == nil && !re.MatchString(rel.Title) {
+			return rejectInclude
+		}
+	}
+	if rule.Exclude != "" {
+		if re, err := regexp.Compile(rule.Exclude); err == nil && re.MatchString(rel.Title) {
+			return rejectExclude
+		}
+	}
+	if rel.Size > 0 && ((rule.MinSizeBytes > 0 && rel.Size < rule.MinSizeBytes) ||
+		(rule.MaxSizeBytes > 0 && rel.Size > rule.MaxSizeBytes)) {
+		return rejectSize
+	}
+	if rule.MinSeeders > 0 && rel.Seeders >= 0 && rel.Seeders < rule.MinSeeders {
+		return rejectSeeders
+	}
+	if rule.Category > 0 && len(rel.Categories) > 0 && !inCategory(rule.Category, rel.Categories) {
+		return rejectCategory
+	}
+	return ""
+}
+
+// inCategory matches want exactly, or as a whole group when it is a multiple of 1000 (2000 matches 2040).
+func inCategory(want int, categories []int) bool {
+	for _, c := range categories {
+		if c == want || (want%categoryGroup == 0 && c/categoryGroup == want/categoryGroup) {
+			return true
+		}
+	}
+	return false
+}
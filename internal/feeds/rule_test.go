@@ -0,0 +1,78 @@
+package feeds
+
+import (
+	"testing"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{"700MB": 700 << 20, "4.5GB": 9 << 29, "2": 2 << 30, "1 tb": 1 << 40}
+	for in, want := range tests {
+		got, err := ParseSize(in)
+		if err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "big", "-1GB"} {
+		if _, err := ParseSize(in); err == nil {
+			t.Errorf("ParseSize(%q) = nil error", in)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(&database.FeedRule{FeedURL: " https://prowlarr.example/1/api?t=search ", Include: "1080p"}); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	invalid := []database.FeedRule{
+		{FeedURL: "magnet:?xt=urn:btih:abc"},
+		{FeedURL: "https://x.example/rss", Include: "("},
+		{FeedURL: "https://x.example/rss", Exclude: "[a"},
+		{FeedURL: "https://x.example/rss", MinSizeBytes: 10, MaxSizeBytes: 5},
+		{FeedURL: "https://x.example/rss", MinSeeders: -1},
+	}
+	for i := range invalid {
+		if err := Validate(&invalid[i]); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", invalid[i])
+		}
+	}
+}
+
+func TestRejectReason(t *testing.T) {
+	rule := &database.FeedRule{
+		Include:      `(?i)show\.s\d+e\d+`,
+		Exclude:      `(?i)\bcam\b`,
+		MinSizeBytes: 1 << 30,
+		MaxSizeBytes: 10 << 30,
+		MinSeeders:   5,
+		Category:     5000,
+	}
+	ok := Release{Title: "Show.S01E02.1080p", Size: 2 << 30, Seeders: 10, Categories: []int{5040}}
+	tests := []struct {
+		name string
+		mod  func(r *Release)
+		want string
+	}{
+		{"passes", func(*Release) {}, ""},
+		{"include", func(r *Release) { r.Title = "Other.Show.2024" }, rejectInclude},
+		{"exclude", func(r *Release) { r.Title = "Show.S01E02.CAM" }, rejectExclude},
+		{"too small", func(r *Release) { r.Size = 100 << 20 }, rejectSize},
+		{"too large", func(r *Release) { r.Size = 20 << 30 }, rejectSize},
+		{"few seeders", func(r *Release) { r.Seeders = 1 }, rejectSeeders},
+		{"other category", func(r *Release) { r.Categories = []int{2040} }, rejectCategory},
+		{"unknown values pass", func(r *Release) { r.Size, r.Seeders, r.Categories = 0, -1, nil }, ""},
+	}
+	for _, tt := range tests {
+		rel := ok
+		tt.mod(&rel)
+		if got := rejectReason(rule, &rel); got != tt.want {
+			t.Errorf("%s: rejectReason = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	exact := &database.FeedRule{Category: 5040}
+	if rejectReason(exact, &Release{Title: "x", Categories: []int{5030}}) != rejectCategory {
+		t.Error("category 5040 must not match 5030")
+	}
+}
@@ -0,0 +1,235 @@
+package feeds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/factory"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+)
+
+const checkTimeout = 5 * time.Minute
+
+// checkMu serializes checks so two rules matching the same release never both start it.
+var checkMu sync.Mutex
+
+// startFunc starts the download of one matching release; replaced in tests.
+type startFunc func(ctx context.Context, a *app.App, rule *database.FeedRule, rel *Release) error
+
+// Match is a release that passes a rule. Known is set when the rule already handled it or any rule downloaded it.
+type Match struct {
+	Release
+	Known bool
+}
+
+// StartWatcher polls the feeds of all active rules every FEED_CHECK_INTERVAL. Does nothing when the
+// interval is 0. Blocks until ctx is done.
+func StartWatcher(ctx context.Context, a *app.App) {
+	interval := a.Config.FeedCheckInterval
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logutils.Log.WithField("interval", interval).Info("Starting feed rule watcher")
+	for {
+		select {
+		case <-ctx.Done():
+			logutils.Log.Info("Stopping feed rule watcher")
+			return
+		case <-ticker.C:
+			CheckAll(ctx, a)
+		}
+	}
+}
+
+// CheckAll checks every active rule once; a feed shared by several rules is fetched once.
+func CheckAll(ctx context.Context, a *app.App) {
+	rules, err := a.DB.GetFeedRules(ctx)
+	if err != nil {
+		logutils.Log.WithError(err).Warn("Feeds: GetFeedRules failed")
+		return
+	}
+	fetched := make(map[string]*Feed)
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Paused {
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		feed, ok := fetched[rule.FeedURL]
+		if !ok {
+			feed, err = Fetch(ctx, rule.FeedURL, a.Config)
+			if err != nil {
+				logutils.Log.WithError(err).WithField("feed_url", rule.FeedURL).Warn("Feeds: fetch failed")
+			}
+			fetched[rule.FeedURL] = feed
+		}
+		if feed == nil {
+			continue
+		}
+		if _, err := check(ctx, a, rule, feed, startRelease); err != nil {
+			logutils.Log.WithError(err).WithField("rule_id", rule.ID).Warn("Feed rule check failed")
+		}
+	}
+}
+
+// Create validates and stores rule, then runs its first check in the background.
+func Create(ctx context.Context, a *app.App, rule *database.FeedRule) error {
+	if err := Validate(rule); err != nil {
+		return err
+	}
+	if err := a.DB.AddFeedRule(ctx, rule); err != nil {
+		return fmt.Errorf("save feed rule: %w", err)
+	}
+	created := *rule
+	go func() {
+		if _, err := Check(context.Background(), a, &created); err != nil {
+			logutils.Log.WithError(err).WithField("rule_id", created.ID).Warn("First feed rule check failed")
+		}
+	}()
+	return nil
+}
+
+// Check fetches the feed of rule and starts downloads for matching releases not handled yet.
+// Returns the started releases.
+func Check(ctx context.Context, a *app.App, rule *database.FeedRule) ([]Release, error) {
+	feed, err := Fetch(ctx, rule.FeedURL, a.Config)
+	if err != nil {
+		return nil, err
+	}
+	return check(ctx, a, rule, feed, startRelease)
+}
+
+// Test fetches the feed of rule and returns the feed and the releases passing the filters, without
+// starting anything.
+func Test(ctx context.Context, a *app.App, rule *database.FeedRule) (*Feed, []Match, error) {
+	feed, err := Fetch(ctx, rule.FeedURL, a.Config)
+	if err != nil {
+		return nil, nil, err
+	}
+	matches, err := match(ctx, a, rule, feed)
+	return feed, matches, err
+}
+
+// match returns the releases of feed passing rule, each release once, with Known set from the database.
+func match(ctx context.Context, a *app.App, rule *database.FeedRule, feed *Feed) ([]Match, error) {
+	var matches []Match
+	var keys []string
+	inFeed := make(map[string]struct{})
+	for i := range feed.Releases {
+		rel := &feed.Releases[i]
+		if reason := rejectReason(rule, rel); reason != "" {
+			continue
+		}
+		key := rel.Key()
+		if _, dup := inFeed[key]; dup {
+			continue
+		}
+		inFeed[key] = struct{}{}
+		keys = append(keys, key)
+		matches = append(matches, Match{Release: *rel})
+	}
+	known, err := a.DB.GetKnownFeedReleases(ctx, rule.ID, keys)
+	if err != nil {
+		return nil, err
+	}
+	for i := range matches {
+		_, matches[i].Known = known[matches[i].Key()]
+	}
+	return matches, nil
+}
+
+func check(ctx context.Context, a *app.App, rule *database.FeedRule, feed *Feed, start startFunc) ([]Release, error) {
+	checkMu.Lock()
+	defer checkMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	matches, err := match(ctx, a, rule, feed)
+	if err != nil {
+		return nil, err
+	}
+
+	// The first check only records what already matches, so a new rule does not grab the whole feed.
+	// Those records are per rule; only downloaded releases are skipped by the other rules.
+	baseline := rule.LastCheckedAt == nil
+	var handled []database.FeedRelease
+	var started []Release
+	for i := range matches {
+		m := &matches[i]
+		if m.Known {
+			continue
+		}
+		record := database.FeedRelease{Key: m.Key(), RuleID: rule.ID, Title: m.Title}
+		if baseline {
+			handled = append(handled, record)
+			continue
+		}
+		if err := start(ctx, a, rule, &m.Release); err != nil {
+			if errors.Is(err, app.ErrAlreadyExists) {
+				record.Downloaded = true
+				handled = append(handled, record)
+				continue
+			}
+			// Not recorded: e.g. not enough space now, retried on the next check.
+			logutils.Log.WithError(err).WithField("release", m.Title).Warn("Feeds: failed to start download")
+			continue
+		}
+		record.Downloaded = true
+		handled = append(handled, record)
+		started = append(started, m.Release)
+	}
+
+	if err := a.DB.AddFeedReleases(ctx, handled); err != nil {
+		return started, fmt.Errorf("record feed releases: %w", err)
+	}
+	if err := a.DB.MarkFeedRuleChecked(ctx, rule.ID, feed.Title, time.Now()); err != nil {
+		return started, err
+	}
+	logutils.Log.WithFields(map[string]any{
+		"rule_id":  rule.ID,
+		"feed":     feed.Title,
+		"baseline": baseline,
+		"matched":  len(matches),
+		"started":  len(started),
+	}).Info("Feed rule checked")
+	return started, nil
+}
+
+// startRelease starts the download through the magnet/torrent factory and tells the rule owner.
+func startRelease(ctx context.Context, a *app.App, rule *database.FeedRule, rel *Release) error {
+	dl, err := factory.CreateDownloaderFromReleaseURL(ctx, rel.Link, a.Config.MoviePath, a.Config)
+	if err != nil {
+		return err
+	}
+	_, err = app.StartAndNotify(ctx, a, dl, app.RecipientChats(ctx, a, rule.ChatID), func(string) string {
+		return lang.Translate("general.feeds.new_release", map[string]any{
+			"Rule":  DisplayName(rule),
+			"Title": rel.Title,
+		})
+	})
+	return err
+}
+
+// DisplayName is the feed title once known, otherwise the feed host.
+func DisplayName(rule *database.FeedRule) string {
+	if rule.Title != "" {
+		return rule.Title
+	}
+	if u, err := url.Parse(rule.FeedURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return rule.FeedURL
+}
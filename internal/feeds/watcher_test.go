@@ -0,0 +1,89 @@
+package feeds
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/testutils"
+)
+
+func release(hash, title string) Release {
+	return Release{Title: title, Link: "magnet:?xt=urn:btih:" + hash, InfoHash: hash, Seeders: -1}
+}
+
+func TestCheckBaselineThenDedupeByInfoHash(t *testing.T) {
+	ctx := context.Background()
+	a := &app.App{DB: testutils.TestDatabase(t), Config: testutils.TestConfig(testutils.TempDir(t))}
+
+	var startedTitles []string
+	failing := map[string]bool{}
+	start := func(_ context.Context, _ *app.App, _ *database.FeedRule, rel *Release) error {
+		if failing[rel.Title] {
+			return errors.New("not enough space")
+		}
+		startedTitles = append(startedTitles, rel.Title)
+		return nil
+	}
+	newRule := func(include string) *database.FeedRule {
+		rule := &database.FeedRule{FeedURL: "https://tracker.example/rss", Include: include}
+		if err := a.DB.AddFeedRule(ctx, rule); err != nil {
+			t.Fatal(err)
+		}
+		return rule
+	}
+	reload := func(id uint) *database.FeedRule {
+		rule, err := a.DB.GetFeedRuleByID(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &rule
+	}
+
+	rule := newRule("Show")
+	feed := &Feed{Title: "Tracker", Releases: []Release{release("aa", "Show.E01"), release("bb", "Movie")}}
+	if _, err := check(ctx, a, rule, feed, start); err != nil {
+		t.Fatalf("baseline check: %v", err)
+	}
+	if len(startedTitles) != 0 {
+		t.Fatalf("baseline check started %v", startedTitles)
+	}
+	if r := reload(rule.ID); r.LastCheckedAt == nil || r.Title != "Tracker" {
+		t.Fatalf("rule not marked checked: %+v", r)
+	}
+
+	feed.Releases = append([]Release{
+		release("cc", "Show.E02"),
+		release("cc", "Show.E02 (mirror)"), // same info hash listed twice
+		release("dd", "Show.E03"),
+	}, feed.Releases...)
+	failing["Show.E03"] = true
+	started, err := check(ctx, a, reload(rule.ID), feed, start)
+	if err != nil {
+		t.Fatalf("second check: %v", err)
+	}
+	if len(started) != 1 || started[0].Title != "Show.E02" {
+		t.Fatalf("second check started %+v, want only Show.E02", started)
+	}
+
+	// Another rule on the same release does not start it again; the failed one is retried.
+	other := newRule("E0")
+	if _, err := check(ctx, a, other, feed, start); err != nil {
+		t.Fatal(err)
+	}
+	failing["Show.E03"] = false
+	if _, err := check(ctx, a, reload(other.ID), feed, start); err != nil {
+		t.Fatal(err)
+	}
+	if len(startedTitles) != 1 {
+		t.Fatalf("started = %v; E03 is in the baseline of the second rule", startedTitles)
+	}
+	if _, err := check(ctx, a, reload(rule.ID), feed, start); err != nil {
+		t.Fatal(err)
+	}
+	if len(startedTitles) != 2 || startedTitles[1] != "Show.E03" {
+		t.Fatalf("started = %v, want [Show.E02 Show.E03]", startedTitles)
+	}
+}
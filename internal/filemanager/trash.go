@@ -0,0 +1,181 @@
+package filemanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tmsdb "github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	tmsdmanager "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/manager"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+)
+
+// TrashDirName is the folder under MOVIE_PATH that holds trashed movies as .trash/<movie_id>/<relative path>.
+// It starts with a dot so minidlna and similar scanners skip it.
+const TrashDirName = ".trash"
+
+const trashDirMode = 0o750
+
+var (
+	ErrNotInTrash    = errors.New("movie is not in trash")
+	ErrRestoreTarget = errors.New("restore target already exists in library")
+)
+
+// TrashDir returns the trash folder of a single movie.
+func TrashDir(moviePath string, movieID uint) string {
+	return filepath.Join(moviePath, TrashDirName, strconv.FormatUint(uint64(movieID), 10))
+}
+
+// TrashMovie moves the main files of a movie into the trash and marks it trashed in the DB.
+// Temporary files (.torrent, .magnet, partial data) are deleted right away; they are not needed for restore.
+func TrashMovie(movieID uint, moviePath string, db tmsdb.Database, downloadManager tmsdmanager.Service) error {
+	ctx := context.Background()
+	movie, err := db.GetMovieByID(ctx, movieID)
+	if err != nil {
+		return fmt.Errorf("get movie %d: %w", movieID, err)
+	}
+	if movie.IsTrashed() {
+		return nil
+	}
+
+	if err := downloadManager.RemoveQBittorrentTorrent(ctx, movieID); err != nil {
+		logutils.Log.WithError(err).WithField("movie_id", movieID).Debug("RemoveQBittorrentTorrent failed (torrent may already be removed)")
+	}
+	if err := DeleteTemporaryFilesByMovieID(movieID, moviePath, db, downloadManager); err != nil {
+		logutils.Log.WithError(err).WithField("movie_id", movieID).Error("Failed to delete temporary files before trashing")
+	}
+
+	files, err := db.GetFilesByMovieID(ctx, movieID)
+	if err != nil {
+		return fmt.Errorf("get files for movie %d: %w", movieID, err)
+	}
+	trashDir := TrashDir(moviePath, movieID)
+	if err := moveMovieFiles(files, moviePath, trashDir); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := db.SetMovieTrashed(ctx, movieID, &now); err != nil {
+		// Keep disk and DB consistent: put the files back so the movie stays in the library.
+		if rollbackErr := moveMovieFiles(files, trashDir, moviePath); rollbackErr != nil {
+			logutils.Log.WithError(rollbackErr).WithField("movie_id", movieID).Error("Failed to roll back trashed files")
+		}
+		return fmt.Errorf("mark movie %d as trashed: %w", movieID, err)
+	}
+	removeDirIfEmpty(trashDir)
+
+	logutils.Log.WithFields(map[string]any{
+		"movie_id": movieID,
+		"files":    len(files),
+	}).Info("Movie moved to trash")
+	return nil
+}
+
+// RestoreMovie moves a trashed movie back into the library. It refuses to overwrite files that
+// appeared at the original paths since the movie was trashed (e.g. the same release downloaded again).
+func RestoreMovie(movieID uint, moviePath string, db tmsdb.Database) (tmsdb.Movie, error) {
+	ctx := context.Background()
+	movie, err := db.GetMovieByID(ctx, movieID)
+	if err != nil {
+		return tmsdb.Movie{}, ErrNotInTrash
+	}
+	if !movie.IsTrashed() {
+		return tmsdb.Movie{}, ErrNotInTrash
+	}
+	files, err := db.GetFilesByMovieID(ctx, movieID)
+	if err != nil {
+		return tmsdb.Movie{}, fmt.Errorf("get files for movie %d: %w", movieID, err)
+	}
+	for i := range files {
+		if _, statErr := os.Stat(filepath.Join(moviePath, files[i].FilePath)); statErr == nil {
+			return tmsdb.Movie{}, ErrRestoreTarget
+		}
+	}
+
+	trashDir := TrashDir(moviePath, movieID)
+	if err := moveMovieFiles(files, trashDir, moviePath); err != nil {
+		return tmsdb.Movie{}, err
+	}
+	if err := db.SetMovieTrashed(ctx, movieID, nil); err != nil {
+		if rollbackErr := moveMovieFiles(files, moviePath, trashDir); rollbackErr != nil {
+			logutils.Log.WithError(rollbackErr).WithField("movie_id", movieID).Error("Failed to roll back restored files")
+		}
+		return tmsdb.Movie{}, fmt.Errorf("restore movie %d: %w", movieID, err)
+	}
+	if err := os.RemoveAll(trashDir); err != nil {
+		logutils.Log.WithError(err).WithField("path", trashDir).Warn("Failed to remove trash folder after restore")
+	}
+	movie.TrashedAt = nil
+
+	logutils.Log.WithField("movie_id", movieID).Info("Movie restored from trash")
+	return movie, nil
+}
+
+// PurgeTrashedMovie permanently removes a trashed movie: its trash folder and DB records.
+func PurgeTrashedMovie(movieID uint, moviePath string, db tmsdb.Database) error {
+	ctx := context.Background()
+	movie, err := db.GetMovieByID(ctx, movieID)
+	if err != nil || !movie.IsTrashed() {
+		return ErrNotInTrash
+	}
+	trashDir := TrashDir(moviePath, movieID)
+	if err := os.RemoveAll(trashDir); err != nil {
+		return fmt.Errorf("remove trash folder %s: %w", trashDir, err)
+	}
+	if err := db.RemoveMovie(ctx, movieID); err != nil {
+		return fmt.Errorf("remove trashed movie %d from database: %w", movieID, err)
+	}
+	logutils.Log.WithField("movie_id", movieID).Info("Trashed movie purged")
+	return nil
+}
+
+// moveMovieFiles renames each relative file path from srcRoot to dstRoot. On failure, already moved
+// files are moved back so the movie is never split between library and trash.
+func moveMovieFiles(files []tmsdb.MovieFile, srcRoot, dstRoot string) error {
+	moved := make([]tmsdb.MovieFile, 0, len(files))
+	sourceFolders := make(map[string]struct{})
+	for i := range files {
+		src := filepath.Join(srcRoot, files[i].FilePath)
+		dst := filepath.Join(dstRoot, files[i].FilePath)
+		if _, err := os.Stat(src); os.IsNotExist(err) {
+			logutils.Log.Warnf("File %s does not exist, skipping", src)
+			continue
+		}
+		err := os.MkdirAll(filepath.Dir(dst), trashDirMode)
+		if err == nil {
+			err = os.Rename(src, dst)
+		}
+		if err != nil {
+			if rollbackErr := moveMovieFiles(moved, dstRoot, srcRoot); rollbackErr != nil {
+				logutils.Log.WithError(rollbackErr).Error("Failed to roll back partially moved files")
+			}
+			return fmt.Errorf("move %s: %w", files[i].FilePath, err)
+		}
+		moved = append(moved, files[i])
+		sourceFolders[filepath.Dir(src)] = struct{}{}
+	}
+	for folder := range sourceFolders {
+		// Walk up nested release folders (Show/Season 1/...) but never remove srcRoot itself.
+		for folder != srcRoot && strings.HasPrefix(folder, srcRoot) && removeDirIfEmpty(folder) {
+			folder = filepath.Dir(folder)
+		}
+	}
+	return nil
+}
+
+func removeDirIfEmpty(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) > 0 {
+		return false
+	}
+	if err := os.Remove(dir); err != nil {
+		logutils.Log.WithError(err).Warnf("Failed to delete folder %s", dir)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,140 @@
+package filemanager
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/testutils"
+)
+
+func writeTestFiles(t *testing.T, root string, rels ...string) {
+	t.Helper()
+	for _, rel := range rels {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("content"), 0600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+}
+
+func TestTrashAndRestoreMovie(t *testing.T) {
+	logutils.InitLogger("debug")
+
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	db := testutils.TestDatabase(t)
+	manager := &deleteMovieManagerMock{}
+
+	mainFile := filepath.Join("Show", "Season 1", "e01.mkv")
+	movieID, err := db.AddMovie(ctx, "Show", 1024, []string{mainFile}, []string{"show.torrent"}, 0)
+	if err != nil {
+		t.Fatalf("AddMovie: %v", err)
+	}
+	writeTestFiles(t, tempDir, mainFile, "show.torrent")
+
+	if trashErr := TrashMovie(movieID, tempDir, db, manager); trashErr != nil {
+		t.Fatalf("TrashMovie: %v", trashErr)
+	}
+	if _, statErr := os.Stat(filepath.Join(TrashDir(tempDir, movieID), mainFile)); statErr != nil {
+		t.Fatalf("file should be in trash: %v", statErr)
+	}
+	if _, statErr := os.Stat(filepath.Join(tempDir, "Show")); !os.IsNotExist(statErr) {
+		t.Fatalf("emptied release folder should be removed, stat err: %v", statErr)
+	}
+	if _, statErr := os.Stat(filepath.Join(tempDir, "show.torrent")); !os.IsNotExist(statErr) {
+		t.Fatalf("temp file should be deleted, stat err: %v", statErr)
+	}
+	list, err := db.GetMovieList(ctx)
+	if err != nil {
+		t.Fatalf("GetMovieList: %v", err)
+	}
+	if len(list) != 0 {
+		t.Fatalf("trashed movie should be hidden from list, got %d movies", len(list))
+	}
+	if len(manager.removedIDs) != 1 || manager.removedIDs[0] != movieID {
+		t.Fatalf("RemoveQBittorrentTorrent calls = %v, want [%d]", manager.removedIDs, movieID)
+	}
+
+	movie, err := RestoreMovie(movieID, tempDir, db)
+	if err != nil {
+		t.Fatalf("RestoreMovie: %v", err)
+	}
+	if movie.IsTrashed() {
+		t.Fatal("restored movie should not be trashed")
+	}
+	if _, statErr := os.Stat(filepath.Join(tempDir, mainFile)); statErr != nil {
+		t.Fatalf("file should be back in library: %v", statErr)
+	}
+	if _, statErr := os.Stat(TrashDir(tempDir, movieID)); !os.IsNotExist(statErr) {
+		t.Fatalf("trash folder should be removed, stat err: %v", statErr)
+	}
+	if _, restoreErr := RestoreMovie(movieID, tempDir, db); !errors.Is(restoreErr, ErrNotInTrash) {
+		t.Fatalf("second RestoreMovie: got %v, want ErrNotInTrash", restoreErr)
+	}
+}
+
+func TestRestoreMovieRefusesToOverwrite(t *testing.T) {
+	logutils.InitLogger("debug")
+
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	db := testutils.TestDatabase(t)
+
+	movieID, err := db.AddMovie(ctx, "Movie", 1024, []string{"movie.mkv"}, nil, 0)
+	if err != nil {
+		t.Fatalf("AddMovie: %v", err)
+	}
+	writeTestFiles(t, tempDir, "movie.mkv")
+	if trashErr := TrashMovie(movieID, tempDir, db, &deleteMovieManagerMock{}); trashErr != nil {
+		t.Fatalf("TrashMovie: %v", trashErr)
+	}
+	writeTestFiles(t, tempDir, "movie.mkv")
+
+	if _, restoreErr := RestoreMovie(movieID, tempDir, db); !errors.Is(restoreErr, ErrRestoreTarget) {
+		t.Fatalf("RestoreMovie: got %v, want ErrRestoreTarget", restoreErr)
+	}
+	if _, statErr := os.Stat(filepath.Join(TrashDir(tempDir, movieID), "movie.mkv")); statErr != nil {
+		t.Fatalf("trashed file should stay in trash: %v", statErr)
+	}
+}
+
+func TestPurgeTrashedMovie(t *testing.T) {
+	logutils.InitLogger("debug")
+
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	db := testutils.TestDatabase(t)
+
+	movieID, err := db.AddMovie(ctx, "Movie", 1024, []string{"movie.mkv"}, nil, 0)
+	if err != nil {
+		t.Fatalf("AddMovie: %v", err)
+	}
+	writeTestFiles(t, tempDir, "movie.mkv")
+
+	if purgeErr := PurgeTrashedMovie(movieID, tempDir, db); !errors.Is(purgeErr, ErrNotInTrash) {
+		t.Fatalf("PurgeTrashedMovie before trash: got %v, want ErrNotInTrash", purgeErr)
+	}
+	if trashErr := TrashMovie(movieID, tempDir, db, &deleteMovieManagerMock{}); trashErr != nil {
+		t.Fatalf("TrashMovie: %v", trashErr)
+	}
+	if purgeErr := PurgeTrashedMovie(movieID, tempDir, db); purgeErr != nil {
+		t.Fatalf("PurgeTrashedMovie: %v", purgeErr)
+	}
+	if _, statErr := os.Stat(TrashDir(tempDir, movieID)); !os.IsNotExist(statErr) {
+		t.Fatalf("trash folder should be removed, stat err: %v", statErr)
+	}
+	exists, err := db.MovieExistsId(ctx, movieID)
+	if err != nil {
+		t.Fatalf("MovieExistsId: %v", err)
+	}
+	if exists {
+		t.Fatal("purged movie still exists in DB")
+	}
+}
@@ -220,13 +220,19 @@ func deleteFileOrFolder(path string) error {
 }
 
 func GetAvailableSpaceGB(path string) (float64, error) {
+	availableBytes, err := GetAvailableSpaceBytes(path)
+	if err != nil {
+		return 0, err
+	}
+	availableSpaceGB := float64(availableBytes) / (1024 * 1024 * 1024)
+	return availableSpaceGB, nil
+}
+
+func GetAvailableSpaceBytes(path string) (int64, error) {
 	var stat syscall.Statfs_t
 	if err := syscall.Statfs(path, &stat); err != nil {
 		logutils.Log.WithError(err).Error("Failed to get filesystem stats")
 		return 0, err
 	}
-
-	availableBytes := stat.Bavail * uint64(stat.Bsize) // #nosec G115
-	availableSpaceGB := float64(availableBytes) / (1024 * 1024 * 1024)
-	return availableSpaceGB, nil
+	return int64(stat.Bavail * uint64(stat.Bsize)), nil // #nosec G115
 }
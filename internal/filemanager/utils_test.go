@@ -5,7 +5,9 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
 	tmsdownloader "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/notifier"
@@ -28,6 +30,18 @@ func (*deleteMovieManagerMock) ResumeDownload(uint, tmsdownloader.Downloader, st
 	return nil, nil
 }
 
+func (*deleteMovieManagerMock) ScheduleDownload(
+	tmsdownloader.Downloader, time.Time, string, string, notifier.QueueNotifier,
+) (uint, chan float64, chan error, error) {
+	return 0, nil, nil, nil
+}
+
+func (*deleteMovieManagerMock) ResumeScheduledDownload(
+	uint, tmsdownloader.Downloader, string, time.Time, notifier.QueueNotifier,
+) chan error {
+	return nil
+}
+
 func (m *deleteMovieManagerMock) StopDownload(movieID uint) error {
 	m.stoppedIDs = append(m.stoppedIDs, movieID)
 	return nil
@@ -47,6 +61,8 @@ func (m *deleteMovieManagerMock) RemoveQBittorrentTorrent(_ context.Context, mov
 
 func (*deleteMovieManagerMock) ResumePendingTVConversions(context.Context) {}
 
+func (*deleteMovieManagerMock) ReservedBytes(context.Context) int64 { return 0 }
+
 func TestHasEnoughSpace(t *testing.T) {
 	logutils.InitLogger("debug")
 
@@ -226,9 +242,9 @@ func TestDeleteMovieRemovesDBFilesAndQBittorrentTorrent(t *testing.T) {
 	if err != nil {
 		t.Fatalf("AddMovie: %v", err)
 	}
-	hashErr := db.SetQBittorrentHash(ctx, movieID, "fake-hash")
+	hashErr := db.SetTorrentClientHash(ctx, movieID, config.TorrentClientQBittorrent, "fake-hash")
 	if hashErr != nil {
-		t.Fatalf("SetQBittorrentHash: %v", hashErr)
+		t.Fatalf("SetTorrentClientHash: %v", hashErr)
 	}
 	for _, rel := range []string{"bbb.mp4", filepath.Join("incomplete", "bbb.torrent")} {
 		path := filepath.Join(tempDir, rel)
@@ -0,0 +1,137 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/cookies"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const cookiesDateLayout = "02.01.2006"
+
+// CookiesHandler handles /cookies: without arguments it lists the yt-dlp cookie profiles with their
+// expiry, "/cookies rm <domain>" removes one. Profiles are uploaded with CookiesUploadHandler.
+func CookiesHandler(a *app.App, update *tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	dir := cookies.Dir(a.Config)
+	args := strings.Fields(update.Message.Text)[1:]
+	switch {
+	case len(args) == 0:
+		profiles, err := cookies.List(dir)
+		if err != nil {
+			logutils.Log.WithError(err).Error("Failed to list cookie profiles")
+			a.Bot.SendMessage(chatID, lang.Translate("error.cookies.save_error", nil), nil)
+			return
+		}
+		a.Bot.SendMessage(chatID, profilesMessage(profiles, time.Now()), nil)
+	case len(args) == 2 && args[0] == "rm":
+		err := cookies.Delete(dir, args[1])
+		switch {
+		case err == nil:
+			a.Bot.SendMessage(chatID, lang.Translate("general.cookies.removed", map[string]any{"Domain": args[1]}), nil)
+		case errors.Is(err, cookies.ErrNotFound), errors.Is(err, cookies.ErrInvalidDomain):
+			a.Bot.SendMessage(chatID, lang.Translate("error.cookies.not_found", map[string]any{"Domain": args[1]}), nil)
+		default:
+			logutils.Log.WithError(err).Error("Failed to remove cookie profile")
+			a.Bot.SendMessage(chatID, lang.Translate("error.cookies.save_error", nil), nil)
+		}
+	default:
+		a.Bot.SendMessage(chatID, lang.Translate("error.cookies.usage", nil), nil)
+	}
+}
+
+// IsCookiesUpload reports a document captioned "/cookies <domain>".
+func IsCookiesUpload(msg *tgbotapi.Message) bool {
+	if msg.Document == nil {
+		return false
+	}
+	fields := strings.Fields(msg.Caption)
+	if len(fields) == 0 {
+		return false
+	}
+	command, _, _ := strings.Cut(fields[0], "@")
+	return command == "/cookies"
+}
+
+// CookiesUploadHandler stores a Netscape cookies.txt document as the profile of the domain in its caption.
+// The message is deleted afterwards since it carries session credentials.
+func CookiesUploadHandler(a *app.App, update *tgbotapi.Update) {
+	msg := update.Message
+	chatID := msg.Chat.ID
+	args := strings.Fields(msg.Caption)[1:]
+	if len(args) != 1 {
+		a.Bot.SendMessage(chatID, lang.Translate("error.cookies.usage", nil), nil)
+		return
+	}
+	data, err := a.Bot.ReadFile(context.Background(), msg.Document.FileID, cookies.MaxFileBytes)
+	if err != nil {
+		logutils.Log.WithError(err).Error("Failed to read cookies file")
+		a.Bot.SendMessage(chatID, lang.Translate("error.cookies.invalid", map[string]any{"Error": err.Error()}), nil)
+		return
+	}
+	profile, err := cookies.Save(cookies.Dir(a.Config), args[0], data)
+	switch {
+	case errors.Is(err, cookies.ErrInvalidDomain):
+		a.Bot.SendMessage(chatID, lang.Translate("error.cookies.usage", nil), nil)
+		return
+	case errors.Is(err, cookies.ErrInvalidFile):
+		logutils.Log.WithError(err).Warn("Rejected cookies file")
+		a.Bot.SendMessage(chatID, lang.Translate("error.cookies.invalid", map[string]any{"Error": err.Error()}), nil)
+		return
+	case err != nil:
+		logutils.Log.WithError(err).Error("Failed to save cookie profile")
+		a.Bot.SendMessage(chatID, lang.Translate("error.cookies.save_error", nil), nil)
+		return
+	}
+	if err := a.Bot.DeleteMessage(chatID, msg.MessageID); err != nil {
+		logutils.Log.WithError(err).Warn("Failed to delete the cookies message")
+	}
+	logutils.Log.WithFields(map[string]any{
+		"domain":  profile.Domain,
+		"cookies": profile.Cookies,
+	}).Info("Cookie profile saved")
+	a.Bot.SendMessage(chatID, lang.Translate("general.cookies.saved", map[string]any{
+		"Profile": profileLine(&profile, time.Now()),
+	}), nil)
+}
+
+func profilesMessage(profiles []cookies.Profile, now time.Time) string {
+	if len(profiles) == 0 {
+		return lang.Translate("general.cookies.empty", nil)
+	}
+	lines := make([]string, 0, len(profiles)+1)
+	lines = append(lines, lang.Translate("general.cookies.list_header", nil))
+	for i := range profiles {
+		lines = append(lines, profileLine(&profiles[i], now))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// profileLine shows the domain, the cookie count and the latest expiry, flagging cookies already expired.
+func profileLine(p *cookies.Profile, now time.Time) string {
+	params := map[string]any{
+		"Domain":  p.Domain,
+		"Count":   p.Cookies,
+		"Expired": p.Expired,
+		"Expires": p.Expires.Format(cookiesDateLayout),
+	}
+	var line string
+	switch {
+	case p.Expires.IsZero():
+		line = lang.Translate("general.cookies.item_session", params)
+	case p.Expires.Before(now):
+		return lang.Translate("general.cookies.item_expired", params)
+	default:
+		line = lang.Translate("general.cookies.item", params)
+	}
+	if p.Expired > 0 {
+		line += " " + lang.Translate("general.cookies.partly_expired", params)
+	}
+	return line
+}
@@ -0,0 +1,63 @@
+package admin
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/cookies"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/testutils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestCookiesUploadAndList(t *testing.T) {
+	expires := time.Date(2030, 5, 1, 0, 0, 0, 0, time.UTC)
+	data := ".youtube.com\tTRUE\t/\tTRUE\t" + strconv.FormatInt(expires.Unix(), 10) + "\tSID\tsecret\n"
+	bot := &testutils.MockBot{Files: map[string][]byte{"cookies-file": []byte(data)}}
+	a := &app.App{Bot: bot, Config: testutils.TestConfig(t.TempDir())}
+
+	upload := &tgbotapi.Update{Message: &tgbotapi.Message{
+		MessageID: 7,
+		Chat:      &tgbotapi.Chat{ID: 1},
+		Document:  &tgbotapi.Document{FileID: "cookies-file", FileName: "cookies.txt"},
+		Caption:   "/cookies www.youtube.com",
+	}}
+	if !IsCookiesUpload(upload.Message) {
+		t.Fatal("IsCookiesUpload() = false for a captioned cookies.txt")
+	}
+	CookiesUploadHandler(a, upload)
+	if got := cookies.FileFor(cookies.Dir(a.Config), "https://www.youtube.com/watch?v=1"); got == "" {
+		t.Fatal("uploaded cookies are not used for youtube.com")
+	}
+
+	CookiesHandler(a, newSpeedUpdate("/cookies"))
+	msg := bot.SentMessages[len(bot.SentMessages)-1].Text
+	if !strings.Contains(msg, "youtube.com") || !strings.Contains(msg, expires.Format(cookiesDateLayout)) {
+		t.Errorf("/cookies = %q, want the domain and its expiry", msg)
+	}
+
+	CookiesHandler(a, newSpeedUpdate("/cookies rm youtube.com"))
+	CookiesHandler(a, newSpeedUpdate("/cookies"))
+	if msg := bot.SentMessages[len(bot.SentMessages)-1].Text; strings.Contains(msg, "youtube.com") {
+		t.Errorf("/cookies after rm = %q, want no profiles", msg)
+	}
+}
+
+func TestCookiesUploadRejectsInvalidFile(t *testing.T) {
+	bot := &testutils.MockBot{Files: map[string][]byte{"f": []byte("<html>login</html>")}}
+	a := &app.App{Bot: bot, Config: testutils.TestConfig(t.TempDir())}
+
+	CookiesUploadHandler(a, &tgbotapi.Update{Message: &tgbotapi.Message{
+		Chat:     &tgbotapi.Chat{ID: 1},
+		Document: &tgbotapi.Document{FileID: "f"},
+		Caption:  "/cookies vimeo.com",
+	}})
+	if msg := bot.SentMessages[len(bot.SentMessages)-1].Text; !strings.HasPrefix(msg, "❌") {
+		t.Errorf("invalid cookies file answered with %q", msg)
+	}
+	if profiles, _ := cookies.List(cookies.Dir(a.Config)); len(profiles) != 0 {
+		t.Errorf("invalid file was stored: %+v", profiles)
+	}
+}
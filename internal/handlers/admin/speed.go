@@ -0,0 +1,99 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/bandwidth"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SpeedHandler handles /speed: without arguments it shows the speed limits in effect,
+// "/speed <download> [upload] [duration]" overrides the schedule for a while (1h by default)
+// and "/speed auto" returns to the schedule.
+func SpeedHandler(a *app.App, update *tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	if a.Bandwidth == nil {
+		a.Bot.SendMessage(chatID, lang.Translate("error.commands.unknown_command", nil), nil)
+		return
+	}
+	args := strings.Fields(update.Message.Text)[1:]
+	switch {
+	case len(args) == 0:
+	case len(args) == 1 && strings.EqualFold(args[0], "auto"):
+		a.Bandwidth.ClearOverride()
+	default:
+		limits, duration, err := parseSpeedArgs(args)
+		if err != nil {
+			a.Bot.SendMessage(chatID, lang.Translate("error.commands.speed_usage", nil), nil)
+			return
+		}
+		a.Bandwidth.SetOverride(limits, duration)
+	}
+	a.Bot.SendMessage(chatID, speedStatus(a.Bandwidth), nil)
+}
+
+// parseSpeedArgs parses "<download> [upload] [duration]"; the upload is unlimited when omitted.
+func parseSpeedArgs(args []string) (bandwidth.Limits, time.Duration, error) {
+	var limits bandwidth.Limits
+	duration := bandwidth.DefaultOverrideDuration
+	if len(args) > 3 {
+		return limits, 0, errors.New("too many arguments")
+	}
+	var err error
+	if limits.Download, err = config.ParseRate(args[0]); err != nil {
+		return limits, 0, err
+	}
+	if len(args) > 1 {
+		if limits.Upload, err = config.ParseRate(args[1]); err != nil {
+			return limits, 0, err
+		}
+	}
+	if len(args) > 2 {
+		duration, err = time.ParseDuration(args[2])
+		if err != nil || duration <= 0 {
+			return limits, 0, fmt.Errorf("invalid duration %q", args[2])
+		}
+	}
+	return limits, duration, nil
+}
+
+func speedStatus(s *bandwidth.Scheduler) string {
+	limits, until := s.Current()
+	params := map[string]any{
+		"Download": formatRate(limits.Download),
+		"Upload":   formatRate(limits.Upload),
+	}
+	var msg string
+	if until.IsZero() {
+		msg = lang.Translate("general.speed.scheduled", params)
+	} else {
+		layout := "15:04"
+		if time.Until(until) >= 24*time.Hour {
+			layout = "02.01 15:04"
+		}
+		params["Until"] = until.Format(layout)
+		msg = lang.Translate("general.speed.override", params)
+	}
+	if !s.Live() {
+		msg += "\n" + lang.Translate("general.speed.new_downloads_only", nil)
+	}
+	return msg
+}
+
+func formatRate(bytesPerSecond int64) string {
+	switch {
+	case bytesPerSecond <= 0:
+		return lang.Translate("general.speed.unlimited", nil)
+	case bytesPerSecond >= 1<<20:
+		return fmt.Sprintf("%.1f MB/s", float64(bytesPerSecond)/(1<<20))
+	case bytesPerSecond >= 1<<10:
+		return fmt.Sprintf("%d KB/s", bytesPerSecond>>10)
+	}
+	return fmt.Sprintf("%d B/s", bytesPerSecond)
+}
@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/bandwidth"
+	tmsconfig "github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/testutils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func newSpeedUpdate(text string) *tgbotapi.Update {
+	return &tgbotapi.Update{
+		Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: 1},
+			From: &tgbotapi.User{ID: 1, UserName: "admin"},
+			Text: text,
+		},
+	}
+}
+
+func TestSpeedHandler(t *testing.T) {
+	bot := &testutils.MockBot{}
+	a := &app.App{Bot: bot, Bandwidth: bandwidth.NewScheduler(&tmsconfig.Config{})}
+
+	SpeedHandler(a, newSpeedUpdate("/speed 2M 512K 3h"))
+	limits, until := a.Bandwidth.Current()
+	if limits != (bandwidth.Limits{Download: 2 << 20, Upload: 512 << 10}) {
+		t.Fatalf("limits = %+v, want 2M/512K", limits)
+	}
+	if d := time.Until(until); d < 2*time.Hour || d > 3*time.Hour {
+		t.Errorf("override lasts %v, want 3h", d)
+	}
+	if msg := bot.SentMessages[len(bot.SentMessages)-1].Text; !strings.Contains(msg, "2.0 MB/s") || !strings.Contains(msg, "512 KB/s") {
+		t.Errorf("status message = %q", msg)
+	}
+
+	SpeedHandler(a, newSpeedUpdate("/speed auto"))
+	if limits, until = a.Bandwidth.Current(); limits != (bandwidth.Limits{}) || !until.IsZero() {
+		t.Errorf("after /speed auto: %+v until %v, want the (empty) schedule", limits, until)
+	}
+
+	SpeedHandler(a, newSpeedUpdate("/speed fast"))
+	if msg := bot.SentMessages[len(bot.SentMessages)-1].Text; !strings.Contains(msg, "/speed auto") || !strings.HasPrefix(msg, "Usage") {
+		t.Errorf("expected usage message, got %q", msg)
+	}
+}
+
+func TestParseSpeedArgs(t *testing.T) {
+	limits, duration, err := parseSpeedArgs([]string{"1M"})
+	if err != nil || limits != (bandwidth.Limits{Download: 1 << 20}) || duration != bandwidth.DefaultOverrideDuration {
+		t.Errorf("parseSpeedArgs(1M) = %+v, %v, %v", limits, duration, err)
+	}
+	for _, bad := range [][]string{{"1M", "1M", "soon"}, {"1M", "1M", "-1h"}, {"1M", "x"}, {"1M", "1M", "1h", "extra"}} {
+		if _, _, err := parseSpeedArgs(bad); err == nil {
+			t.Errorf("parseSpeedArgs(%v) should fail", bad)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	tmsdownloads "github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/downloads"
 	movies "github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/movies"
 	tmssession "github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/session"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
@@ -36,6 +37,9 @@ func HandleCallbackQuery(
 	case strings.HasPrefix(callbackData, "delete_movie:"):
 		handleDeleteMovieCallback(a, update, chatID, role, callbackData)
 
+	case strings.HasPrefix(callbackData, "restore_movie:"):
+		handleRestoreMovieCallback(a, update, chatID, role, callbackData)
+
 	case callbackData == "cancel_delete_menu":
 		_ = a.Bot.DeleteMessage(chatID, update.CallbackQuery.Message.MessageID)
 
@@ -55,6 +59,26 @@ func HandleCallbackQuery(
 		tmssession.HandleTorrentSearchCallback(a, update)
 		return
 
+	case strings.HasPrefix(callbackData, tmsdownloads.OptionsCallbackPrefix):
+		tmsdownloads.HandleOptionsCallback(a, update)
+		return
+
+	case strings.HasPrefix(callbackData, tmsdownloads.FormatCallbackPrefix):
+		tmsdownloads.HandleFormatCallback(a, update)
+		return
+
+	case strings.HasPrefix(callbackData, tmsdownloads.FilesCallbackPrefix):
+		tmsdownloads.HandleFilesCallback(a, update)
+		return
+
+	case strings.HasPrefix(callbackData, tmsdownloads.StartCallbackPrefix):
+		tmsdownloads.HandleStartCallback(a, update)
+		return
+
+	case strings.HasPrefix(callbackData, tmsdownloads.BatchCallbackPrefix):
+		tmsdownloads.HandleBatchCallback(a, update)
+		return
+
 	default:
 		logutils.Log.Warnf("Unknown callback data: %s", callbackData)
 		a.Bot.SendMessage(chatID, lang.Translate("error.commands.unknown_command", nil), nil)
@@ -102,8 +126,31 @@ func handleDeleteMovieCallback(
 	updateDeleteMenuWithMovies(a, chatID, update.CallbackQuery.Message.MessageID, remainingMovies)
 
 	if a.DeleteQueue != nil {
-		a.DeleteQueue.Enqueue(id)
+		movies.EnqueueDeletion(a, chatID, id)
+	}
+}
+
+func handleRestoreMovieCallback(
+	a *app.App,
+	update *tgbotapi.Update,
+	chatID int64,
+	role database.UserRole,
+	callbackData string,
+) {
+	if role != database.AdminRole && role != database.RegularRole {
+		a.Bot.SendMessage(chatID, lang.Translate("error.authentication.access_denied", nil), nil)
+		return
+	}
+
+	a.Bot.AnswerCallbackQuery(tgbotapi.NewCallback(update.CallbackQuery.ID, ""))
+
+	movieIDStr := strings.TrimPrefix(callbackData, "restore_movie:")
+	movieID, err := strconv.ParseUint(movieIDStr, 10, 32)
+	if err != nil {
+		logutils.Log.WithError(err).Errorf("Invalid movie ID: %s", movieIDStr)
+		return
 	}
+	movies.RestoreMovieByID(a, chatID, uint(movieID))
 }
 
 func updateDeleteMenuWithMovies(a *app.App, chatID int64, messageID int, movieList []database.Movie) {
@@ -6,8 +6,10 @@ import (
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/auth"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/callbacks"
 	tmsdownloads "github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/downloads"
+	tmsfeeds "github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/feeds"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/movies"
 	tmssession "github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/session"
+	tmssubscriptions "github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/subscriptions"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/ui"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/models"
@@ -71,14 +73,72 @@ func handleCommand(
 		movies.ListMoviesHandler(a, update)
 	case "rm":
 		movies.DeleteMoviesHandler(a, update)
+	case "trash":
+		movies.TrashListHandler(a, update)
+	case "restore":
+		movies.RestoreMovieHandler(a, update)
 	case "temp":
 		auth.GenerateTempPasswordHandler(a, update)
+	case "keep", "unkeep":
+		if role != models.AdminRole {
+			a.Bot.SendMessage(update.Message.Chat.ID, lang.Translate("error.authentication.access_denied", nil), nil)
+			return
+		}
+		movies.PinMovieHandler(a, update, command == "keep")
+	case "seed", "unseed":
+		if role != models.AdminRole {
+			a.Bot.SendMessage(update.Message.Chat.ID, lang.Translate("error.authentication.access_denied", nil), nil)
+			return
+		}
+		movies.SeedMovieHandler(a, update, command == "seed")
+	case "subscribe", "subscriptions", "unsubscribe":
+		if !role.HasPermission("subscribe") {
+			a.Bot.SendMessage(update.Message.Chat.ID, lang.Translate("error.authentication.access_denied", nil), nil)
+			return
+		}
+		switch command {
+		case "subscribe":
+			tmssubscriptions.SubscribeHandler(a, update)
+		case "subscriptions":
+			tmssubscriptions.ListSubscriptionsHandler(a, update, role)
+		default:
+			tmssubscriptions.UnsubscribeHandler(a, update, role)
+		}
+	case "feedadd", "feeds", "feedtest", "feedpause", "feedresume", "feedrm":
+		if !role.HasPermission("subscribe") {
+			a.Bot.SendMessage(update.Message.Chat.ID, lang.Translate("error.authentication.access_denied", nil), nil)
+			return
+		}
+		switch command {
+		case "feedadd":
+			tmsfeeds.AddFeedRuleHandler(a, update)
+		case "feeds":
+			tmsfeeds.ListFeedRulesHandler(a, update, role)
+		case "feedtest":
+			tmsfeeds.TestFeedRuleHandler(a, update, role)
+		case "feedpause", "feedresume":
+			tmsfeeds.PauseFeedRuleHandler(a, update, role, command == "feedpause")
+		default:
+			tmsfeeds.RemoveFeedRuleHandler(a, update, role)
+		}
 	case "logs":
 		if role != models.AdminRole {
 			a.Bot.SendMessage(update.Message.Chat.ID, lang.Translate("error.authentication.access_denied", nil), nil)
 			return
 		}
 		admin.LogsHandler(a, update)
+	case "speed":
+		if role != models.AdminRole {
+			a.Bot.SendMessage(update.Message.Chat.ID, lang.Translate("error.authentication.access_denied", nil), nil)
+			return
+		}
+		admin.SpeedHandler(a, update)
+	case "cookies":
+		if role != models.AdminRole {
+			a.Bot.SendMessage(update.Message.Chat.ID, lang.Translate("error.authentication.access_denied", nil), nil)
+			return
+		}
+		admin.CookiesHandler(a, update)
 	default:
 		a.Bot.SendMessage(update.Message.Chat.ID, lang.Translate("error.commands.unknown_command", nil), nil)
 	}
@@ -119,21 +179,59 @@ func handleMessage(
 				}
 			}
 		}
-		handleUnknownMessage(a, update, text, chatID)
+		handleUnknownMessage(a, update, chatID)
 	}
 }
 
 func handleUnknownMessage(
 	a *app.App,
 	update *tgbotapi.Update,
-	text string,
 	chatID int64,
 ) {
-	if link, ok := ExtractLink(text); ok {
-		tmsdownloads.HandleDownloadLink(a, update, link)
-	} else if doc := update.Message.Document; doc != nil && IsTorrentFile(doc.FileName) {
+	msg := update.Message
+	// Commands in captions are not parsed by Telegram, so cookies.txt uploads are recognized here.
+	if admin.IsCookiesUpload(msg) {
+		if !auth.CheckAccessWithRole(update, []models.UserRole{models.AdminRole}, a.DB) {
+			a.Bot.SendMessage(chatID, lang.Translate("error.authentication.access_denied", nil), nil)
+			return
+		}
+		admin.CookiesUploadHandler(a, update)
+		return
+	}
+	// A torrent or video sent with a caption is downloaded itself; the caption links of other files still count.
+	hasFile := msg.Document != nil || msg.Video != nil
+	links := ExtractLinks(msg)
+	if len(links) > 0 && !hasFile {
+		handleLinks(a, update, links)
+	} else if link, ok := replyLink(msg); ok {
+		tmsdownloads.SendOptionsMenu(a, chatID, link)
+	} else if doc := msg.Document; doc != nil && (IsTorrentFile(doc.FileName) || IsNZBFile(doc.FileName)) {
 		tmsdownloads.HandleTorrentFile(a, update)
+	} else if file, ok := tmsdownloads.TelegramVideoFile(msg); ok {
+		tmsdownloads.HandleTelegramFile(a, chatID, file)
+	} else if len(links) > 0 {
+		handleLinks(a, update, links)
 	} else {
 		a.Bot.SendMessage(chatID, lang.Translate("error.commands.unknown_command", nil), nil)
 	}
 }
+
+// handleLinks starts a single link right away and asks once before downloading several.
+func handleLinks(a *app.App, update *tgbotapi.Update, links []string) {
+	if len(links) == 1 {
+		tmsdownloads.HandleDownloadLink(a, update, links[0])
+		return
+	}
+	tmsdownloads.SendBatchPrompt(a, update.Message.Chat.ID, links)
+}
+
+// replyLink returns the link of the message msg replies to, which opens the download options menu.
+func replyLink(msg *tgbotapi.Message) (string, bool) {
+	if msg.ReplyToMessage == nil {
+		return "", false
+	}
+	if links := ExtractLinks(msg.ReplyToMessage); len(links) > 0 {
+		return links[0], true
+	}
+	return "", false
+}
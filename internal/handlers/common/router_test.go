@@ -2,6 +2,10 @@ package common
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -13,6 +17,8 @@ import (
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/models"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/notifier"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/testutils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/jackpal/bencode-go"
 )
 
 type routerAccessDB struct {
@@ -25,6 +31,10 @@ func (*routerAccessDB) IsUserAccessAllowed(_ context.Context, _ int64) (allowed
 
 type routerDownloadManager struct {
 	started chan struct{}
+	once    sync.Once
+	starts  atomic.Int32
+	mu      sync.Mutex
+	dl      downloader.Downloader
 }
 
 func newRouterDownloadManager() *routerDownloadManager {
@@ -32,10 +42,14 @@ func newRouterDownloadManager() *routerDownloadManager {
 }
 
 func (m *routerDownloadManager) StartDownload(
-	downloader.Downloader,
-	notifier.QueueNotifier,
+	dl downloader.Downloader,
+	_ notifier.QueueNotifier,
 ) (movieID uint, progressChan chan float64, outerErrChan chan error, err error) {
-	close(m.started)
+	m.mu.Lock()
+	m.dl = dl
+	m.mu.Unlock()
+	m.starts.Add(1)
+	m.once.Do(func() { close(m.started) })
 	errChan := make(chan error, 1)
 	errChan <- downloader.ErrStoppedByDeletion
 	return 1, make(chan float64), errChan, nil
@@ -45,6 +59,16 @@ func (*routerDownloadManager) ResumeDownload(uint, downloader.Downloader, string
 	return nil, nil
 }
 
+func (*routerDownloadManager) ScheduleDownload(
+	downloader.Downloader, time.Time, string, string, notifier.QueueNotifier,
+) (uint, chan float64, chan error, error) {
+	return 0, nil, nil, nil
+}
+
+func (*routerDownloadManager) ResumeScheduledDownload(uint, downloader.Downloader, string, time.Time, notifier.QueueNotifier) chan error {
+	return nil
+}
+
 func (*routerDownloadManager) StopDownload(uint) error { return nil }
 
 func (*routerDownloadManager) StopDownloadSilent(uint) error { return nil }
@@ -59,6 +83,8 @@ func (*routerDownloadManager) RemoveQBittorrentTorrent(context.Context, uint) er
 
 func (*routerDownloadManager) ResumePendingTVConversions(context.Context) {}
 
+func (*routerDownloadManager) ReservedBytes(context.Context) int64 { return 0 }
+
 func TestRouterExtractsLinkFromFreeFormMessage(t *testing.T) {
 	logutils.InitLogger("debug")
 
@@ -84,6 +110,21 @@ func TestRouterExtractsLinkFromFreeFormMessage(t *testing.T) {
 
 	Router(a, update)
 
+	prompt := bot.GetLastMessage()
+	if prompt == nil {
+		t.Fatal("Router did not answer a free-form message containing a link")
+	}
+	if _, ok := prompt.Keyboard.(tgbotapi.InlineKeyboardMarkup); !ok {
+		t.Fatalf("link answered with %T, want an inline start prompt", prompt.Keyboard)
+	}
+	select {
+	case <-dm.started:
+		t.Fatal("download started before now or tonight was chosen")
+	default:
+	}
+
+	Router(a, testutils.CallbackUpdate(123, 456, "user", "dl_start:go", len(bot.SentMessages)))
+
 	select {
 	case <-dm.started:
 	case <-time.After(time.Second):
@@ -117,3 +158,200 @@ func TestRouterKeepsUnknownMessageWithoutLink(t *testing.T) {
 		t.Fatalf("Router response = %q, want %q", last.Text, want)
 	}
 }
+
+func TestRouterReplyToLinkOpensDownloadOptions(t *testing.T) {
+	logutils.InitLogger("debug")
+
+	cfg := testutils.TestConfig(t.TempDir())
+	if err := lang.InitLocalizer(cfg); err != nil {
+		t.Fatalf("InitLocalizer: %v", err)
+	}
+	bot := &testutils.MockBot{}
+	dm := newRouterDownloadManager()
+	a := &app.App{
+		Bot:             bot,
+		DB:              &routerAccessDB{},
+		Config:          cfg,
+		DownloadManager: dm,
+	}
+
+	update := testutils.TextUpdate(123, 456, "user", "options")
+	update.Message.ReplyToMessage = &tgbotapi.Message{
+		Text: "magnet:?xt=urn:btih:1234567890abcdef1234567890abcdef12345678&dn=OptionsTest",
+	}
+	Router(a, update)
+
+	menu := bot.GetLastMessage()
+	if menu == nil {
+		t.Fatal("Router did not answer a reply to a link")
+	}
+	if _, ok := menu.Keyboard.(tgbotapi.InlineKeyboardMarkup); !ok {
+		t.Fatalf("reply to a link answered with %T, want an inline options menu", menu.Keyboard)
+	}
+	select {
+	case <-dm.started:
+		t.Fatal("download started before the options were confirmed")
+	default:
+	}
+
+	menuID := len(bot.SentMessages)
+	Router(a, testutils.CallbackUpdate(123, 456, "user", "dl_opt:q:720p", menuID))
+	Router(a, testutils.CallbackUpdate(123, 456, "user", "dl_opt:go", menuID))
+
+	select {
+	case <-dm.started:
+	case <-time.After(time.Second):
+		t.Fatal("Download button did not start the download")
+	}
+}
+
+func TestRouterTorrentFilePreviewSelectsFiles(t *testing.T) {
+	logutils.InitLogger("debug")
+
+	cfg := testutils.TestConfig(t.TempDir())
+	cfg.TorrentFilePreview = true
+	if err := lang.InitLocalizer(cfg); err != nil {
+		t.Fatalf("InitLocalizer: %v", err)
+	}
+	f, err := os.Create(filepath.Join(cfg.MoviePath, "season.torrent"))
+	if err != nil {
+		t.Fatalf("create torrent: %v", err)
+	}
+	err = bencode.Marshal(f, map[string]any{
+		"announce": "http://tracker.example.com/announce",
+		"info": map[string]any{
+			"name":         "Season",
+			"piece length": int64(32768),
+			"pieces":       "01234567890123456789",
+			"files": []map[string]any{
+				{"length": int64(100), "path": []string{"E01.mkv"}},
+				{"length": int64(200), "path": []string{"E02.mkv"}},
+				{"length": int64(50), "path": []string{"Sample.mkv"}},
+			},
+		},
+	})
+	f.Close()
+	if err != nil {
+		t.Fatalf("write torrent: %v", err)
+	}
+
+	bot := &testutils.MockBot{}
+	dm := newRouterDownloadManager()
+	a := &app.App{Bot: bot, DB: &routerAccessDB{}, Config: cfg, DownloadManager: dm}
+
+	Router(a, testutils.TorrentDocumentUpdate(123, 456, "user", "file-id", "season.torrent"))
+	menu := bot.GetLastMessage()
+	if menu == nil {
+		t.Fatal("Router did not show the file preview")
+	}
+	if _, ok := menu.Keyboard.(tgbotapi.InlineKeyboardMarkup); !ok {
+		t.Fatalf("torrent upload answered with %T, want an inline file preview", menu.Keyboard)
+	}
+
+	menuID := len(bot.SentMessages)
+	Router(a, testutils.CallbackUpdate(123, 456, "user", "dl_files:t:2", menuID))
+	Router(a, testutils.CallbackUpdate(123, 456, "user", "dl_files:go", menuID))
+
+	select {
+	case <-dm.started:
+	case <-time.After(time.Second):
+		t.Fatal("Download button did not start the download")
+	}
+	if n := dm.dl.TotalEpisodes(); n != 2 {
+		t.Errorf("TotalEpisodes() = %d, want 2 without the sample", n)
+	}
+	if size, _ := dm.dl.GetFileSize(); size != 300 {
+		t.Errorf("GetFileSize() = %d, want 300 without the sample", size)
+	}
+}
+
+func TestRouterStartsDownloadForVideo(t *testing.T) {
+	logutils.InitLogger("debug")
+
+	cfg := testutils.TestConfig(t.TempDir())
+	if err := lang.InitLocalizer(cfg); err != nil {
+		t.Fatalf("InitLocalizer: %v", err)
+	}
+	dm := newRouterDownloadManager()
+	a := &app.App{Bot: &testutils.MockBot{}, DB: &routerAccessDB{}, Config: cfg, DownloadManager: dm}
+
+	Router(a, testutils.VideoUpdate(123, 456, "user", "video-id", "Channel Movie.mp4", 5*1024*1024))
+
+	select {
+	case <-dm.started:
+	case <-time.After(time.Second):
+		t.Fatal("Router did not start a download for a video message")
+	}
+	if title, _ := dm.dl.GetTitle(); title != "Channel Movie" {
+		t.Errorf("download title = %q, want %q", title, "Channel Movie")
+	}
+}
+
+func TestRouterRejectsVideoOverCloudLimit(t *testing.T) {
+	logutils.InitLogger("debug")
+
+	cfg := testutils.TestConfig(t.TempDir())
+	if err := lang.InitLocalizer(cfg); err != nil {
+		t.Fatalf("InitLocalizer: %v", err)
+	}
+	bot := &testutils.MockBot{}
+	a := &app.App{Bot: bot, DB: &routerAccessDB{}, Config: cfg, DownloadManager: newRouterDownloadManager()}
+
+	Router(a, testutils.VideoUpdate(123, 456, "user", "video-id", "Big.mkv", 2*1024*1024*1024))
+
+	last := bot.GetLastMessage()
+	if last == nil {
+		t.Fatal("Router did not answer a video over the cloud Bot API limit")
+	}
+	want := lang.Translate("error.downloads.telegram_file_too_big", map[string]any{"Size": "2.0 " + lang.Translate("general.unit_gb", nil)})
+	if last.Text != want {
+		t.Fatalf("Router response = %q, want %q", last.Text, want)
+	}
+}
+
+func TestRouterBatchPromptStartsEveryLink(t *testing.T) {
+	logutils.InitLogger("debug")
+
+	cfg := testutils.TestConfig(t.TempDir())
+	if err := lang.InitLocalizer(cfg); err != nil {
+		t.Fatalf("InitLocalizer: %v", err)
+	}
+	bot := &testutils.MockBot{}
+	dm := newRouterDownloadManager()
+	a := &app.App{
+		Bot:             bot,
+		DB:              &routerAccessDB{},
+		Config:          cfg,
+		DownloadManager: dm,
+	}
+
+	update := testutils.TextUpdate(123, 456, "user", "New releases:\nmagnet:?xt=urn:btih:1234567890abcdef1234567890abcdef12345678&dn=One")
+	update.Message.ReplyMarkup = &tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{
+		{tgbotapi.NewInlineKeyboardButtonURL("Two", "magnet:?xt=urn:btih:abcdef1234567890abcdef1234567890abcdef12&dn=Two")},
+	}}
+	Router(a, update)
+
+	prompt := bot.GetLastMessage()
+	if prompt == nil {
+		t.Fatal("Router did not answer a message with several links")
+	}
+	if _, ok := prompt.Keyboard.(tgbotapi.InlineKeyboardMarkup); !ok {
+		t.Fatalf("message with several links answered with %T, want an inline prompt", prompt.Keyboard)
+	}
+	select {
+	case <-dm.started:
+		t.Fatal("download started before the prompt was confirmed")
+	default:
+	}
+
+	Router(a, testutils.CallbackUpdate(123, 456, "user", "dl_batch:go", len(bot.SentMessages)))
+
+	deadline := time.After(time.Second)
+	for dm.starts.Load() < 2 {
+		select {
+		case <-deadline:
+			t.Fatalf("confirmed prompt started %d downloads, want 2", dm.starts.Load())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
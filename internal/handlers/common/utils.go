@@ -4,6 +4,8 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 var linkCandidateRE = regexp.MustCompile(`(?i)(https?://\S+|magnet:\?\S+)`)
@@ -23,6 +25,46 @@ func ExtractLink(text string) (string, bool) {
 	return "", false
 }
 
+// ExtractLinks collects every supported link of msg, in order and without repeats: from the text and caption,
+// from hidden text_link entities and from inline URL buttons (as in forwarded posts of release channels).
+func ExtractLinks(msg *tgbotapi.Message) []string {
+	var links []string
+	seen := make(map[string]struct{})
+	add := func(link string) {
+		link = cleanLinkCandidate(link)
+		if !isSupportedLink(link) {
+			return
+		}
+		if _, dup := seen[link]; dup {
+			return
+		}
+		seen[link] = struct{}{}
+		links = append(links, link)
+	}
+	for _, text := range []string{msg.Text, msg.Caption} {
+		for _, candidate := range linkCandidateRE.FindAllString(text, -1) {
+			add(candidate)
+		}
+	}
+	for _, entities := range [][]tgbotapi.MessageEntity{msg.Entities, msg.CaptionEntities} {
+		for i := range entities {
+			if entities[i].IsTextLink() {
+				add(entities[i].URL)
+			}
+		}
+	}
+	if msg.ReplyMarkup != nil {
+		for _, row := range msg.ReplyMarkup.InlineKeyboard {
+			for _, button := range row {
+				if button.URL != nil {
+					add(*button.URL)
+				}
+			}
+		}
+	}
+	return links
+}
+
 func cleanLinkCandidate(candidate string) string {
 	link := strings.TrimSpace(candidate)
 	link = strings.Trim(link, "<>\"'`“”‘’")
@@ -49,3 +91,7 @@ func isSupportedLink(link string) bool {
 func IsTorrentFile(fileName string) bool {
 	return strings.HasSuffix(fileName, ".torrent")
 }
+
+func IsNZBFile(fileName string) bool {
+	return strings.HasSuffix(strings.ToLower(fileName), ".nzb")
+}
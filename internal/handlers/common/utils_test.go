@@ -1,7 +1,10 @@
 package common
 
 import (
+	"slices"
 	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 func TestIsValidLink(t *testing.T) {
@@ -262,3 +265,31 @@ func BenchmarkIsTorrentFile(b *testing.B) {
 		IsTorrentFile(fileName)
 	}
 }
+
+func TestExtractLinks(t *testing.T) {
+	const (
+		magnetA = "magnet:?xt=urn:btih:1234567890abcdef1234567890abcdef12345678&dn=A"
+		magnetB = "magnet:?xt=urn:btih:abcdef1234567890abcdef1234567890abcdef12&dn=B"
+		page    = "https://example.com/watch/1"
+		button  = "https://example.com/watch/2"
+	)
+	msg := &tgbotapi.Message{
+		Text:    "Two releases: " + magnetA + " and " + magnetB + ", watch here",
+		Caption: "again " + magnetA,
+		Entities: []tgbotapi.MessageEntity{
+			{Type: "text_link", URL: page},
+		},
+		ReplyMarkup: &tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{
+			{tgbotapi.NewInlineKeyboardButtonURL("Watch", button), tgbotapi.NewInlineKeyboardButtonData("Like", "like")},
+		}},
+	}
+
+	got := ExtractLinks(msg)
+	want := []string{magnetA, magnetB, page, button}
+	if !slices.Equal(got, want) {
+		t.Fatalf("ExtractLinks() = %q, want %q", got, want)
+	}
+	if links := ExtractLinks(&tgbotapi.Message{Text: "no links here"}); len(links) != 0 {
+		t.Fatalf("ExtractLinks() = %q, want none", links)
+	}
+}
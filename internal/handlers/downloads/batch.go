@@ -0,0 +1,82 @@
+package downloads
+
+import (
+	"context"
+	"strings"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	tmsfactory "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/factory"
+	tmslang "github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// BatchCallbackPrefix prefixes the callback data of the "download N items?" prompt.
+const BatchCallbackPrefix = "dl_batch:"
+
+// maxBatchLinks keeps the prompt within Telegram's message size; further links of the message are ignored.
+const maxBatchLinks = 20
+
+// batchMenu is a message with several links waiting for the user to confirm downloading all of them.
+type batchMenu struct {
+	links []string
+}
+
+var batchMenus = newMenuStore[batchMenu]()
+
+// SendBatchPrompt asks once whether to download every link found in a message (text, caption, entities, buttons).
+func SendBatchPrompt(a *app.App, chatID int64, links []string) {
+	if len(links) > maxBatchLinks {
+		logutils.Log.WithField("links", len(links)).Warnf("Message has too many links, offering the first %d", maxBatchLinks)
+		links = links[:maxBatchLinks]
+	}
+	text := tmslang.Translate("general.download_batch.prompt", map[string]any{
+		"Count": len(links),
+		"Links": strings.Join(links, "\n"),
+	})
+	markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(tmslang.Translate("general.download_batch.download", map[string]any{
+			"Count": len(links),
+		}), BatchCallbackPrefix+"go"),
+		tgbotapi.NewInlineKeyboardButtonData(tmslang.Translate("general.interface.cancel", nil), BatchCallbackPrefix+"cancel"),
+	))
+	messageID, err := a.Bot.SendMessageReturningID(chatID, text, markup)
+	if err != nil {
+		return
+	}
+	batchMenus.add(menuKey{chatID: chatID, messageID: messageID}, batchMenu{links: links})
+}
+
+// HandleBatchCallback starts every link of the prompt ("go") or drops them ("cancel").
+func HandleBatchCallback(a *app.App, update *tgbotapi.Update) {
+	query := update.CallbackQuery
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+	action := strings.TrimPrefix(query.Data, BatchCallbackPrefix)
+
+	menu, ok := batchMenus.take(menuKey{chatID: chatID, messageID: messageID})
+	if !ok {
+		answerExpiredMenu(a, query)
+		return
+	}
+	a.Bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+	_ = a.Bot.DeleteMessage(chatID, messageID)
+	if action != "go" {
+		return
+	}
+	go startBatchDownloads(a, chatID, menu.links)
+}
+
+// startBatchDownloads starts the links one by one with the configured settings; the format picker and
+// the torrent file preview are skipped, a link that fails gets its own error message.
+func startBatchDownloads(a *app.App, chatID int64, links []string) {
+	for _, link := range links {
+		dl, err := tmsfactory.CreateDownloaderFromURL(context.Background(), link, a.Config.MoviePath, a.Config)
+		if err != nil {
+			logutils.Log.WithError(err).WithField("link", link).Debug("startBatchDownloads: CreateDownloaderFromURL failed")
+			sendDownloadStartError(a, chatID, err, nil)
+			continue
+		}
+		handleDownloadAsync(a, chatID, dl, downloadStart{source: link})
+	}
+}
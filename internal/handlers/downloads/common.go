@@ -27,13 +27,14 @@ func HandleDownload(
 	chatID int64,
 	downloaderInstance tmsdownloader.Downloader,
 ) {
-	go handleDownloadAsync(a, chatID, downloaderInstance)
+	go handleDownloadAsync(a, chatID, downloaderInstance, downloadStart{})
 }
 
 func handleDownloadAsync(
 	a *app.App,
 	chatID int64,
 	downloaderInstance tmsdownloader.Downloader,
+	start downloadStart,
 ) {
 	videoTitle, err := downloaderInstance.GetTitle()
 	if err != nil {
@@ -46,7 +47,14 @@ func handleDownloadAsync(
 
 	if validateErr := app.ValidateDownloadStart(context.Background(), a, downloaderInstance); validateErr != nil {
 		logutils.Log.WithError(validateErr).Debug("ValidateDownloadStart failed")
+		var duplicate *app.DuplicateError
 		switch {
+		case errors.As(validateErr, &duplicate):
+			logutils.Log.WithField("movie_id", duplicate.MovieID).Warn("Torrent already in the library")
+			a.Bot.SendMessage(chatID, tmslang.Translate("error.movies.duplicate_torrent", map[string]any{
+				"ID":    duplicate.MovieID,
+				"Title": duplicate.Title,
+			}), nil)
 		case errors.Is(validateErr, app.ErrAlreadyExists):
 			logutils.Log.Warn("Media already exists")
 			a.Bot.SendMessage(chatID, tmslang.Translate("error.movies.already_exists", nil), nil)
@@ -62,16 +70,32 @@ func handleDownloadAsync(
 	}
 
 	tgNotifier := telegramNotifier{chatID: chatID, app: a}
-	movieID, _, completionChan, err := a.DownloadManager.StartDownload(downloaderInstance, tgNotifier)
+	var (
+		movieID        uint
+		completionChan chan error
+	)
+	if start.scheduled() {
+		movieID, _, completionChan, err = a.DownloadManager.ScheduleDownload(
+			downloaderInstance, start.startAt, start.source, start.options.Encode(), tgNotifier)
+	} else {
+		movieID, _, completionChan, err = a.DownloadManager.StartDownload(downloaderInstance, tgNotifier)
+	}
 	if err != nil {
 		logutils.Log.WithError(err).Error("Failed to start download")
 		sendDownloadStartError(a, chatID, err, nil)
 		return
 	}
 
-	a.Bot.SendMessage(chatID, tmslang.Translate("general.video_downloading", map[string]any{
-		"Title": videoTitle,
-	}), nil)
+	if start.scheduled() {
+		a.Bot.SendMessage(chatID, tmslang.Translate("general.download_scheduled", map[string]any{
+			"Title":   videoTitle,
+			"StartAt": start.startAt.Format(scheduleTimeLayout),
+		}), nil)
+	} else {
+		a.Bot.SendMessage(chatID, tmslang.Translate("general.video_downloading", map[string]any{
+			"Title": videoTitle,
+		}), nil)
+	}
 
 	go app.RunCompletionLoop(a, completionChan, downloaderInstance, movieID, videoTitle, tgNotifier)
 }
@@ -0,0 +1,218 @@
+package downloads
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	tmsdownloader "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
+	tmslang "github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// FilesCallbackPrefix prefixes the callback data of the torrent file preview.
+const FilesCallbackPrefix = "dl_files:"
+
+const (
+	// maxPreviewFiles keeps the preview within Telegram's inline keyboard limits; bigger torrents are downloaded whole.
+	maxPreviewFiles = 50
+	// maxFileLabelRunes shortens long file names on the buttons, keeping their end (episode number, extension).
+	maxFileLabelRunes = 40
+)
+
+// filesMenu is a multi-file torrent waiting for the user to pick the files to download.
+type filesMenu struct {
+	dl       tmsdownloader.Downloader
+	selector tmsdownloader.FileSelector
+	title    string
+	files    []tmsdownloader.TorrentFile
+	selected []bool
+	start    downloadStart
+}
+
+var filesMenus = newMenuStore[filesMenu]()
+
+// startTorrentDownload shows the file preview of a multi-file .torrent, or starts (or schedules) the download
+// through startOrAsk.
+func startTorrentDownload(a *app.App, chatID int64, dl tmsdownloader.Downloader, start downloadStart) {
+	selector, ok := dl.(tmsdownloader.FileSelector)
+	if !ok || !a.Config.TorrentFilePreview {
+		startOrAsk(a, chatID, dl, start)
+		return
+	}
+	files := selector.TorrentFiles()
+	if len(files) < 2 || len(files) > maxPreviewFiles {
+		startOrAsk(a, chatID, dl, start)
+		return
+	}
+	title, _ := dl.GetTitle()
+	menu := filesMenu{dl: dl, selector: selector, title: title, files: files, selected: make([]bool, len(files)), start: start}
+	for i := range menu.selected {
+		menu.selected[i] = true
+	}
+	messageID, err := a.Bot.SendMessageReturningID(chatID, filesMenuText(&menu), filesMenuMarkup(&menu))
+	if err != nil {
+		startOrAsk(a, chatID, dl, start)
+		return
+	}
+	filesMenus.add(menuKey{chatID: chatID, messageID: messageID}, menu)
+}
+
+// HandleFilesCallback toggles files ("t:<n>", "all", "none"), starts ("go") or schedules ("tonight")
+// the download, or drops it ("cancel").
+func HandleFilesCallback(a *app.App, update *tgbotapi.Update) {
+	query := update.CallbackQuery
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+	key := menuKey{chatID: chatID, messageID: messageID}
+	action := strings.TrimPrefix(query.Data, FilesCallbackPrefix)
+
+	switch action {
+	case "cancel":
+		if _, ok := filesMenus.take(key); !ok {
+			answerExpiredMenu(a, query)
+			return
+		}
+		a.Bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		_ = a.Bot.DeleteMessage(chatID, messageID)
+	case "go", "tonight":
+		menu, ok := filesMenus.update(key, func(m filesMenu) filesMenu { return m })
+		if !ok {
+			answerExpiredMenu(a, query)
+			return
+		}
+		indices := menu.selectedIndices()
+		if len(indices) == 0 {
+			a.Bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, tmslang.Translate("general.torrent_files.none_selected", nil)))
+			return
+		}
+		if _, ok := filesMenus.take(key); !ok {
+			answerExpiredMenu(a, query)
+			return
+		}
+		a.Bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		_ = a.Bot.DeleteMessage(chatID, messageID)
+		start := menu.start
+		start.ask = false
+		if len(indices) < len(menu.files) {
+			if err := menu.selector.SelectFiles(indices); err != nil {
+				logutils.Log.WithError(err).Error("Failed to select torrent files")
+				sendDownloadStartError(a, chatID, err, nil)
+				return
+			}
+			start.options.Files = indices
+		}
+		if action == "tonight" {
+			if start, ok = tonightStart(a, start); !ok {
+				return
+			}
+		}
+		go handleDownloadAsync(a, chatID, menu.dl, start)
+	default:
+		menu, ok := filesMenus.update(key, func(m filesMenu) filesMenu {
+			applyFilesAction(&m, action)
+			return m
+		})
+		if !ok {
+			answerExpiredMenu(a, query)
+			return
+		}
+		a.Bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+		if err := a.Bot.EditMessageTextAndMarkup(chatID, messageID, filesMenuText(&menu), filesMenuMarkup(&menu)); err != nil {
+			logutils.Log.WithError(err).Debug("Failed to update torrent file preview")
+		}
+	}
+}
+
+// applyFilesAction toggles one file ("t:<n>", n is the position in the preview) or all of them.
+func applyFilesAction(menu *filesMenu, action string) {
+	selected := make([]bool, len(menu.selected))
+	copy(selected, menu.selected)
+	switch {
+	case action == "all" || action == "none":
+		for i := range selected {
+			selected[i] = action == "all"
+		}
+	case strings.HasPrefix(action, "t:"):
+		i, err := strconv.Atoi(strings.TrimPrefix(action, "t:"))
+		if err != nil || i < 0 || i >= len(selected) {
+			logutils.Log.Warnf("Unknown torrent file preview action: %s", action)
+			return
+		}
+		selected[i] = !selected[i]
+	default:
+		logutils.Log.Warnf("Unknown torrent file preview action: %s", action)
+		return
+	}
+	menu.selected = selected
+}
+
+// selectedIndices returns the torrent file indices (downloader.TorrentFile.Index) of the checked files.
+func (m *filesMenu) selectedIndices() []int {
+	var indices []int
+	for i := range m.files {
+		if m.selected[i] {
+			indices = append(indices, m.files[i].Index)
+		}
+	}
+	return indices
+}
+
+func filesMenuText(menu *filesMenu) string {
+	var count int
+	var size int64
+	for i := range menu.files {
+		if menu.selected[i] {
+			count++
+			size += menu.files[i].Size
+		}
+	}
+	return tmslang.Translate("general.torrent_files.prompt", map[string]any{
+		"Title":    menu.title,
+		"Selected": count,
+		"Total":    len(menu.files),
+		"Size":     sizeLabel(size),
+	})
+}
+
+func filesMenuMarkup(menu *filesMenu) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(menu.files)+2)
+	for i := range menu.files {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+			fileLabel(menu.title, &menu.files[i], menu.selected[i]), FilesCallbackPrefix+"t:"+strconv.Itoa(i),
+		)))
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tmslang.Translate("general.torrent_files.all", nil), FilesCallbackPrefix+"all"),
+			tgbotapi.NewInlineKeyboardButtonData(tmslang.Translate("general.torrent_files.none", nil), FilesCallbackPrefix+"none"),
+		),
+	)
+	buttons := []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData(tmslang.Translate("general.download_options.download", nil), FilesCallbackPrefix+"go"),
+	}
+	if !menu.start.scheduled() {
+		buttons = append(buttons,
+			tgbotapi.NewInlineKeyboardButtonData(tmslang.Translate("general.download_options.tonight", nil), FilesCallbackPrefix+"tonight"))
+	}
+	buttons = append(buttons,
+		tgbotapi.NewInlineKeyboardButtonData(tmslang.Translate("general.interface.cancel", nil), FilesCallbackPrefix+"cancel"))
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(buttons...))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// fileLabel renders a file as "✅ Extras/Sample.mkv · 50 MB", relative to the torrent's root folder.
+func fileLabel(title string, f *tmsdownloader.TorrentFile, selected bool) string {
+	box := "⬜"
+	if selected {
+		box = "✅"
+	}
+	name := strings.TrimPrefix(filepath.ToSlash(f.Path), title+"/")
+	if runes := []rune(name); len(runes) > maxFileLabelRunes {
+		name = "…" + string(runes[len(runes)-maxFileLabelRunes+1:])
+	}
+	return fmt.Sprintf("%s %s · %s", box, name, sizeLabel(f.Size))
+}
@@ -0,0 +1,140 @@
+package downloads
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	tmsdownloader "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
+	tmsfactory "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/factory"
+	tmslang "github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/tvcompat"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// FormatCallbackPrefix prefixes the callback data of the format picker.
+const FormatCallbackPrefix = "dl_fmt:"
+
+const formatListTimeout = 30 * time.Second
+
+// formatMenu is a yt-dlp download waiting for the user to pick one of formats.
+type formatMenu struct {
+	dl      tmsdownloader.Downloader
+	picker  tmsfactory.FormatPicker
+	formats []tmsfactory.VideoFormat
+	start   downloadStart
+}
+
+var formatMenus = newMenuStore[formatMenu]()
+
+// offerFormats shows the formats of a single video to pick from; the download starts right away when
+// yt-dlp reports fewer than two choices or the metadata cannot be read (after the start prompt when start.ask is set).
+func offerFormats(a *app.App, chatID int64, dl tmsdownloader.Downloader, picker tmsfactory.FormatPicker, start downloadStart) {
+	ctx, cancel := context.WithTimeout(context.Background(), formatListTimeout)
+	defer cancel()
+	formats, err := picker.ListFormats(ctx)
+	if err != nil {
+		logutils.Log.WithError(err).Debug("Failed to list video formats, downloading with the configured quality")
+	}
+	if len(formats) < 2 {
+		startOrAsk(a, chatID, dl, start)
+		return
+	}
+	title, _ := dl.GetTitle()
+	text := tmslang.Translate("general.format_picker.prompt", map[string]any{"Title": title})
+	messageID, err := a.Bot.SendMessageReturningID(chatID, text, formatMenuMarkup(formats, start.scheduled()))
+	if err != nil {
+		startOrAsk(a, chatID, dl, start)
+		return
+	}
+	formatMenus.add(menuKey{chatID: chatID, messageID: messageID}, formatMenu{dl: dl, picker: picker, formats: formats, start: start})
+}
+
+// HandleFormatCallback starts the download with the picked format or the configured quality ("auto"),
+// schedules it for tonight with the configured quality ("tonight"), or drops it.
+func HandleFormatCallback(a *app.App, update *tgbotapi.Update) {
+	query := update.CallbackQuery
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+	action := strings.TrimPrefix(query.Data, FormatCallbackPrefix)
+
+	menu, ok := formatMenus.take(menuKey{chatID: chatID, messageID: messageID})
+	if !ok {
+		answerExpiredMenu(a, query)
+		return
+	}
+	a.Bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+	_ = a.Bot.DeleteMessage(chatID, messageID)
+
+	start := menu.start
+	start.ask = false
+	switch action {
+	case "cancel":
+		return
+	case "auto":
+	case "tonight":
+		if start, ok = tonightStart(a, start); !ok {
+			return
+		}
+	default:
+		i, err := strconv.Atoi(action)
+		if err != nil || i < 0 || i >= len(menu.formats) {
+			logutils.Log.Warnf("Unknown format picker callback data: %s", query.Data)
+			return
+		}
+		menu.picker.SetFormat(menu.formats[i].Selector)
+		start.options.Video.Format = menu.formats[i].Selector
+	}
+	go handleDownloadAsync(a, chatID, menu.dl, start)
+}
+
+// formatMenuMarkup lists the formats; the "⏰ Tonight" button is left out when the download is already scheduled.
+func formatMenuMarkup(formats []tmsfactory.VideoFormat, scheduled bool) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(formats)+1)
+	for i := range formats {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(formatLabel(&formats[i]), FormatCallbackPrefix+strconv.Itoa(i)),
+		))
+	}
+	buttons := []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData(tmslang.Translate("general.format_picker.auto", nil), FormatCallbackPrefix+"auto"),
+	}
+	if !scheduled {
+		buttons = append(buttons,
+			tgbotapi.NewInlineKeyboardButtonData(tmslang.Translate("general.download_options.tonight", nil), FormatCallbackPrefix+"tonight"))
+	}
+	buttons = append(buttons,
+		tgbotapi.NewInlineKeyboardButtonData(tmslang.Translate("general.interface.cancel", nil), FormatCallbackPrefix+"cancel"))
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(buttons...))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// formatLabel renders a format as "🟢 1080p H.264 · 1.2 GB"; the circle is the TV compatibility of the codec.
+func formatLabel(f *tmsfactory.VideoFormat) string {
+	var circle string
+	switch f.TvCompat {
+	case tvcompat.TvCompatGreen:
+		circle = "🟢"
+	case tvcompat.TvCompatRed:
+		circle = "🔴"
+	default:
+		circle = "⚪"
+	}
+	label := fmt.Sprintf("%s %dp %s", circle, f.Height, f.Codec)
+	if f.Size > 0 {
+		label += " · " + sizeLabel(f.Size)
+	}
+	return label
+}
+
+// sizeLabel renders a size in GB from 1 GB up and in whole MB below that.
+func sizeLabel(size int64) string {
+	if size >= 1<<30 {
+		return fmt.Sprintf("%.1f %s", float64(size)/(1<<30), tmslang.Translate("general.unit_gb", nil))
+	}
+	return fmt.Sprintf("%d %s", max(size>>20, 1), tmslang.Translate("general.unit_mb", nil))
+}
@@ -1,11 +1,8 @@
 package downloads
 
 import (
-	"context"
-
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
 	tmsfactory "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/factory"
-	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
@@ -14,16 +11,5 @@ func HandleDownloadLink(
 	update *tgbotapi.Update,
 	link string,
 ) {
-	message := update.Message
-	chatID := message.Chat.ID
-
-	logutils.Log.WithField("link", link).Info("Starting download for a valid link")
-	ctx := context.Background()
-	downloaderInstance, err := tmsfactory.CreateDownloaderFromURL(ctx, link, a.Config.MoviePath, a.Config)
-	if err != nil {
-		logutils.Log.WithError(err).WithField("link", link).Debug("HandleDownloadLink: CreateDownloaderFromURL failed")
-		sendDownloadStartError(a, chatID, err, nil)
-		return
-	}
-	HandleDownload(a, chatID, downloaderInstance)
+	startLinkDownload(a, update.Message.Chat.ID, link, tmsfactory.VideoOptions{}, downloadStart{source: link, ask: true})
 }
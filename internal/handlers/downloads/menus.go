@@ -0,0 +1,66 @@
+package downloads
+
+import (
+	"sync"
+	"time"
+)
+
+// pendingMenuTTL is how long an inline menu waits for its final button before it is forgotten.
+const pendingMenuTTL = time.Hour
+
+type menuKey struct {
+	chatID    int64
+	messageID int
+}
+
+type pendingMenu[T any] struct {
+	state     T
+	createdAt time.Time
+}
+
+// menuStore keeps the state of inline menus, keyed by the message that shows them.
+type menuStore[T any] struct {
+	mu    sync.Mutex
+	menus map[menuKey]pendingMenu[T]
+}
+
+func newMenuStore[T any]() *menuStore[T] {
+	return &menuStore[T]{menus: make(map[menuKey]pendingMenu[T])}
+}
+
+// add stores state for the menu message and forgets menus older than pendingMenuTTL.
+func (s *menuStore[T]) add(key menuKey, state T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, m := range s.menus {
+		if time.Since(m.createdAt) > pendingMenuTTL {
+			delete(s.menus, k)
+		}
+	}
+	s.menus[key] = pendingMenu[T]{state: state, createdAt: time.Now()}
+}
+
+// update applies fn to the stored state and returns the result; ok is false for unknown or expired menus.
+func (s *menuStore[T]) update(key menuKey, fn func(T) T) (state T, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.menus[key]
+	if !ok || time.Since(m.createdAt) > pendingMenuTTL {
+		return state, false
+	}
+	m.state = fn(m.state)
+	s.menus[key] = m
+	return m.state, true
+}
+
+// take removes the menu and returns its state.
+func (s *menuStore[T]) take(key menuKey) (state T, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.menus[key]
+	delete(s.menus, key)
+	if !ok || time.Since(m.createdAt) > pendingMenuTTL {
+		return state, false
+	}
+	return m.state, true
+}
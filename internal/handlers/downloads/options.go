@@ -0,0 +1,192 @@
+package downloads
+
+import (
+	"context"
+	"strings"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	tmsfactory "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/factory"
+	tmslang "github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// OptionsCallbackPrefix prefixes the callback data of the download options menu.
+const OptionsCallbackPrefix = "dl_opt:"
+
+const subtitlesOff = "off"
+
+// Values the audio and subtitle buttons cycle through; "" keeps the configured setting.
+var (
+	audioLangChoices    = []string{"", "en", "ru"}
+	subtitleLangChoices = []string{"", "en", "ru", subtitlesOff}
+)
+
+// optionsMenu is the state of one options menu until Download or Cancel is pressed.
+type optionsMenu struct {
+	link      string
+	quality   string
+	audioLang string
+	subtitles string
+}
+
+var optionMenus = newMenuStore[optionsMenu]()
+
+// SendOptionsMenu answers a reply to a link with an inline menu for quality, audio language and subtitles.
+func SendOptionsMenu(a *app.App, chatID int64, link string) {
+	menu := optionsMenu{link: link}
+	messageID, err := a.Bot.SendMessageReturningID(chatID, optionsMenuText(&menu), optionsMenuMarkup(&menu))
+	if err != nil {
+		return
+	}
+	optionMenus.add(menuKey{chatID: chatID, messageID: messageID}, menu)
+}
+
+// HandleOptionsCallback applies a button press of the options menu.
+func HandleOptionsCallback(a *app.App, update *tgbotapi.Update) {
+	query := update.CallbackQuery
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+	action := strings.TrimPrefix(query.Data, OptionsCallbackPrefix)
+	key := menuKey{chatID: chatID, messageID: messageID}
+
+	var menu optionsMenu
+	var ok bool
+	if action == "go" || action == "tonight" || action == "cancel" {
+		menu, ok = optionMenus.take(key)
+	} else {
+		menu, ok = optionMenus.update(key, func(m optionsMenu) optionsMenu {
+			applyOptionsAction(&m, action)
+			return m
+		})
+	}
+	if !ok {
+		answerExpiredMenu(a, query)
+		return
+	}
+	a.Bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+
+	switch action {
+	case "cancel":
+		_ = a.Bot.DeleteMessage(chatID, messageID)
+	case "go", "tonight":
+		_ = a.Bot.DeleteMessage(chatID, messageID)
+		opts := menu.videoOptions()
+		start := downloadStart{source: menu.link, options: tmsfactory.StartOptions{Video: opts}}
+		if action == "tonight" {
+			if start, ok = tonightStart(a, start); !ok {
+				return
+			}
+		}
+		startLinkDownload(a, chatID, menu.link, opts, start)
+	default:
+		if err := a.Bot.EditMessageTextAndMarkup(chatID, messageID, optionsMenuText(&menu), optionsMenuMarkup(&menu)); err != nil {
+			logutils.Log.WithError(err).Debug("Failed to update download options menu")
+		}
+	}
+}
+
+// answerExpiredMenu tells the user the menu is gone (bot restart or pendingMenuTTL) and removes it.
+func answerExpiredMenu(a *app.App, query *tgbotapi.CallbackQuery) {
+	a.Bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, tmslang.Translate("general.download_options.expired", nil)))
+	_ = a.Bot.DeleteMessage(query.Message.Chat.ID, query.Message.MessageID)
+}
+
+// applyOptionsAction updates menu for a quality ("q:<value>"), "audio" or "subs" press.
+func applyOptionsAction(menu *optionsMenu, action string) {
+	switch {
+	case strings.HasPrefix(action, "q:"):
+		quality := strings.TrimPrefix(action, "q:")
+		if menu.quality == quality {
+			quality = ""
+		}
+		menu.quality = quality
+	case action == "audio":
+		menu.audioLang = nextChoice(audioLangChoices, menu.audioLang)
+	case action == "subs":
+		menu.subtitles = nextChoice(subtitleLangChoices, menu.subtitles)
+	}
+}
+
+func nextChoice(choices []string, current string) string {
+	for i, c := range choices {
+		if c == current {
+			return choices[(i+1)%len(choices)]
+		}
+	}
+	return choices[0]
+}
+
+func (m *optionsMenu) videoOptions() tmsfactory.VideoOptions {
+	opts := tmsfactory.VideoOptions{Quality: m.quality, AudioLang: m.audioLang}
+	switch m.subtitles {
+	case "":
+	case subtitlesOff:
+		off := false
+		opts.WriteSubs = &off
+	default:
+		opts.SubtitleLang = m.subtitles
+	}
+	return opts
+}
+
+func optionsMenuText(menu *optionsMenu) string {
+	return tmslang.Translate("general.download_options.prompt", map[string]any{"Link": menu.link})
+}
+
+func optionsMenuMarkup(menu *optionsMenu) tgbotapi.InlineKeyboardMarkup {
+	qualityButton := func(label, quality string) tgbotapi.InlineKeyboardButton {
+		if menu.quality == quality {
+			label = "✅ " + label
+		}
+		return tgbotapi.NewInlineKeyboardButtonData(label, OptionsCallbackPrefix+"q:"+quality)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			qualityButton("720p", "720p"),
+			qualityButton("1080p", "1080p"),
+			qualityButton(tmslang.Translate("general.download_options.audio_only", nil), tmsfactory.QualityAudioOnly),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tmslang.Translate("general.download_options.audio_lang", map[string]any{
+				"Value": choiceLabel(menu.audioLang),
+			}), OptionsCallbackPrefix+"audio"),
+			tgbotapi.NewInlineKeyboardButtonData(tmslang.Translate("general.download_options.subtitles", map[string]any{
+				"Value": choiceLabel(menu.subtitles),
+			}), OptionsCallbackPrefix+"subs"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tmslang.Translate("general.download_options.download", nil), OptionsCallbackPrefix+"go"),
+			tgbotapi.NewInlineKeyboardButtonData(tmslang.Translate("general.download_options.tonight", nil), OptionsCallbackPrefix+"tonight"),
+			tgbotapi.NewInlineKeyboardButtonData(tmslang.Translate("general.interface.cancel", nil), OptionsCallbackPrefix+"cancel"),
+		),
+	)
+}
+
+func choiceLabel(value string) string {
+	switch value {
+	case "":
+		return tmslang.Translate("general.download_options.default", nil)
+	case subtitlesOff:
+		return tmslang.Translate("general.download_options.off", nil)
+	default:
+		return value
+	}
+}
+
+// startLinkDownload creates the downloader for link and starts (or schedules) it, offering the format picker first for
+// yt-dlp links without explicit options.
+func startLinkDownload(a *app.App, chatID int64, link string, opts tmsfactory.VideoOptions, start downloadStart) {
+	logutils.Log.WithField("link", link).Info("Starting download for a valid link")
+	downloaderInstance, err := tmsfactory.CreateDownloaderFromURLWithOptions(context.Background(), link, a.Config.MoviePath, a.Config, opts)
+	if err != nil {
+		logutils.Log.WithError(err).WithField("link", link).Debug("startLinkDownload: CreateDownloaderFromURL failed")
+		sendDownloadStartError(a, chatID, err, nil)
+		return
+	}
+	if picker, ok := downloaderInstance.(tmsfactory.FormatPicker); ok && opts.IsZero() && a.Config.VideoSettings.FormatPicker {
+		go offerFormats(a, chatID, downloaderInstance, picker, start)
+		return
+	}
+	startTorrentDownload(a, chatID, downloaderInstance, start)
+}
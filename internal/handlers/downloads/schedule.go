@@ -0,0 +1,109 @@
+package downloads
+
+import (
+	"strings"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	tmsdownloader "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader"
+	tmsfactory "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/factory"
+	tmslang "github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// StartCallbackPrefix prefixes the callback data of the start prompt.
+const StartCallbackPrefix = "dl_start:"
+
+// scheduleTimeLayout renders the start time of scheduled downloads in bot messages.
+const scheduleTimeLayout = "02.01 15:04"
+
+// downloadStart says when a bot download starts: right away, or queued until startAt ("⏰ Tonight").
+type downloadStart struct {
+	startAt time.Time
+	// source is the link the download was added from, kept to queue it again after a restart.
+	source string
+	// options are the video options and torrent files picked in the menus, kept with a scheduled download.
+	options tmsfactory.StartOptions
+	// ask offers the start prompt (Download / Tonight) when no other menu asks when to start.
+	ask bool
+}
+
+func (s downloadStart) scheduled() bool {
+	return !s.startAt.IsZero()
+}
+
+// tonightStart returns start queued for the "⏰ Tonight" button (TONIGHT_START); false when it cannot be computed.
+func tonightStart(a *app.App, start downloadStart) (downloadStart, bool) {
+	startAt, err := a.Config.DownloadSettings.NextTonightStart(time.Now())
+	if err != nil {
+		logutils.Log.WithError(err).Error("Failed to compute tonight's download start")
+		return downloadStart{}, false
+	}
+	start.startAt = startAt
+	start.ask = false
+	return start, true
+}
+
+// startMenu is a download waiting for the user to start it now or tonight.
+type startMenu struct {
+	dl    tmsdownloader.Downloader
+	start downloadStart
+}
+
+var startMenus = newMenuStore[startMenu]()
+
+// startOrAsk starts (or schedules) the download, first asking whether to start it now or tonight when start.ask is set.
+func startOrAsk(a *app.App, chatID int64, dl tmsdownloader.Downloader, start downloadStart) {
+	if !start.ask || start.scheduled() {
+		go handleDownloadAsync(a, chatID, dl, start)
+		return
+	}
+	title, _ := dl.GetTitle()
+	text := tmslang.Translate("general.download_start.prompt", map[string]any{"Title": title})
+	messageID, err := a.Bot.SendMessageReturningID(chatID, text, startMenuMarkup())
+	if err != nil {
+		go handleDownloadAsync(a, chatID, dl, start)
+		return
+	}
+	startMenus.add(menuKey{chatID: chatID, messageID: messageID}, startMenu{dl: dl, start: start})
+}
+
+// HandleStartCallback starts ("go") or schedules ("tonight") the download, or drops it ("cancel").
+func HandleStartCallback(a *app.App, update *tgbotapi.Update) {
+	query := update.CallbackQuery
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+	action := strings.TrimPrefix(query.Data, StartCallbackPrefix)
+
+	menu, ok := startMenus.take(menuKey{chatID: chatID, messageID: messageID})
+	if !ok {
+		answerExpiredMenu(a, query)
+		return
+	}
+	a.Bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+	_ = a.Bot.DeleteMessage(chatID, messageID)
+
+	start := menu.start
+	switch action {
+	case "cancel":
+		return
+	case "go":
+	case "tonight":
+		if start, ok = tonightStart(a, start); !ok {
+			return
+		}
+	default:
+		logutils.Log.Warnf("Unknown start prompt callback data: %s", query.Data)
+		return
+	}
+	go handleDownloadAsync(a, chatID, menu.dl, start)
+}
+
+func startMenuMarkup() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(tmslang.Translate("general.download_options.download", nil), StartCallbackPrefix+"go"),
+		tgbotapi.NewInlineKeyboardButtonData(tmslang.Translate("general.download_options.tonight", nil), StartCallbackPrefix+"tonight"),
+		tgbotapi.NewInlineKeyboardButtonData(tmslang.Translate("general.interface.cancel", nil), StartCallbackPrefix+"cancel"),
+	))
+}
@@ -0,0 +1,89 @@
+package downloads
+
+import (
+	"strings"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	tmsbot "github.com/NikitaDmitryuk/telegram-media-server/internal/bot"
+	tmsfactory "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/factory"
+	tmslang "github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/tvcompat"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxCaptionTitleRunes bounds titles taken from captions of videos sent without a file name.
+const maxCaptionTitleRunes = 80
+
+// videoMimeExtensions gives videos sent without a file name an extension from their MIME type.
+var videoMimeExtensions = map[string]string{
+	"video/mp4":        ".mp4",
+	"video/x-matroska": ".mkv",
+	"video/webm":       ".webm",
+	"video/quicktime":  ".mov",
+	"video/x-msvideo":  ".avi",
+}
+
+// TelegramVideoFile returns the video carried by msg: a video message, or a document with a video MIME type
+// or extension (how channels usually share films).
+func TelegramVideoFile(msg *tgbotapi.Message) (tmsfactory.TelegramFile, bool) {
+	switch {
+	case msg.Video != nil:
+		v := msg.Video
+		return tmsfactory.TelegramFile{
+			ID:   v.FileID,
+			Name: videoFileName(v.FileName, v.MimeType, v.FileUniqueID, msg.Caption),
+			Size: int64(v.FileSize),
+		}, true
+	case msg.Document != nil && isVideoDocument(msg.Document):
+		d := msg.Document
+		return tmsfactory.TelegramFile{
+			ID:   d.FileID,
+			Name: videoFileName(d.FileName, d.MimeType, d.FileUniqueID, msg.Caption),
+			Size: int64(d.FileSize),
+		}, true
+	default:
+		return tmsfactory.TelegramFile{}, false
+	}
+}
+
+func isVideoDocument(doc *tgbotapi.Document) bool {
+	return strings.HasPrefix(doc.MimeType, "video/") || tvcompat.IsVideoFilePath(doc.FileName)
+}
+
+// videoFileName keeps the sender's file name; unnamed videos are named after the first caption line.
+func videoFileName(name, mimeType, uniqueID, caption string) string {
+	if name != "" {
+		return name
+	}
+	ext, ok := videoMimeExtensions[mimeType]
+	if !ok {
+		ext = ".mp4"
+	}
+	title, _, _ := strings.Cut(strings.TrimSpace(caption), "\n")
+	title = strings.TrimSpace(title)
+	if runes := []rune(title); len(runes) > maxCaptionTitleRunes {
+		title = strings.TrimSpace(string(runes[:maxCaptionTitleRunes]))
+	}
+	if title == "" {
+		title = "telegram_" + uniqueID
+	}
+	return title + ext
+}
+
+// HandleTelegramFile downloads a video sent or forwarded to the bot into MOVIE_PATH like any other download.
+// Without a local Bot API server (TELEGRAM_API_URL) Telegram only hands out files up to 20 MB.
+func HandleTelegramFile(a *app.App, chatID int64, file tmsfactory.TelegramFile) {
+	if a.Config.TelegramAPIURL == "" && file.Size > tmsbot.CloudFileSizeLimit {
+		logutils.Log.WithField("size", file.Size).Warn("Telegram file is over the cloud Bot API limit")
+		a.Bot.SendMessage(chatID, tmslang.Translate("error.downloads.telegram_file_too_big", map[string]any{
+			"Size": sizeLabel(file.Size),
+		}), nil)
+		return
+	}
+	logutils.Log.WithFields(map[string]any{
+		"file_name": file.Name,
+		"size":      file.Size,
+	}).Info("Received a video file")
+	HandleDownload(a, chatID, tmsfactory.NewTelegramFileDownloader(a.Bot, file, a.Config))
+}
@@ -1,6 +1,7 @@
 package downloads
 
 import (
+	"context"
 	"strings"
 
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
@@ -10,6 +11,7 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// HandleTorrentFile starts a download from an uploaded .torrent or .nzb (the latter needs a Usenet client).
 func HandleTorrentFile(
 	a *app.App,
 	update *tgbotapi.Update,
@@ -18,7 +20,8 @@ func HandleTorrentFile(
 	chatID := message.Chat.ID
 	doc := message.Document
 
-	if !strings.HasSuffix(doc.FileName, ".torrent") {
+	isNZB := strings.HasSuffix(strings.ToLower(doc.FileName), ".nzb")
+	if !strings.HasSuffix(doc.FileName, ".torrent") && !isNZB {
 		logutils.Log.Warnf("Unsupported file type: %s", doc.FileName)
 		a.Bot.SendMessage(chatID, lang.Translate("error.file_management.unsupported_type", nil), tgbotapi.NewRemoveKeyboard(false))
 		return
@@ -26,17 +29,17 @@ func HandleTorrentFile(
 
 	logutils.Log.WithField("file_name", doc.FileName).Info("Received a torrent file")
 
-	if err := a.Bot.DownloadFile(doc.FileID, doc.FileName); err != nil {
+	if err := a.Bot.DownloadFile(context.Background(), doc.FileID, doc.FileName, nil); err != nil {
 		logutils.Log.WithError(err).Error("Failed to download torrent file")
 		sendDownloadStartError(a, chatID, err, tgbotapi.NewRemoveKeyboard(false))
 		return
 	}
 
-	downloaderInstance, err := tmsfactory.NewTorrentDownloader(doc.FileName, a.Config.MoviePath, a.Config)
+	downloaderInstance, err := tmsfactory.NewDownloaderFromFile(doc.FileName, a.Config.MoviePath, a.Config)
 	if err != nil {
-		logutils.Log.WithError(err).Error("Failed to create torrent downloader")
+		logutils.Log.WithError(err).WithField("nzb", isNZB).Error("Failed to create downloader for uploaded file")
 		sendDownloadStartError(a, chatID, err, tgbotapi.NewRemoveKeyboard(false))
 		return
 	}
-	HandleDownload(a, chatID, downloaderInstance)
+	startTorrentDownload(a, chatID, downloaderInstance, downloadStart{ask: true})
 }
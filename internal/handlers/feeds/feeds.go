@@ -0,0 +1,260 @@
+package feeds
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	tmsfeeds "github.com/NikitaDmitryuk/telegram-media-server/internal/feeds"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/ui"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxTestLines limits the releases listed by /feedtest.
+const maxTestLines = 15
+
+// AddFeedRuleHandler handles /feedadd <URL> [include=<regex>] [exclude=<regex>] [minsize=<size>] [maxsize=<size>]
+// [seeders=<n>] [cat=<id>].
+func AddFeedRuleHandler(a *app.App, update *tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		a.Bot.SendMessage(chatID, lang.Translate("general.feeds.usage", nil), ui.GetMainMenuKeyboard())
+		return
+	}
+	rule, err := ParseRuleArgs(args)
+	if err == nil {
+		rule.ChatID = chatID
+		err = tmsfeeds.Create(context.Background(), a, rule)
+	}
+	if err != nil {
+		logutils.Log.WithError(err).WithField("chat_id", chatID).Warn("Failed to create feed rule")
+		a.Bot.SendMessage(chatID, lang.Translate("error.feeds.invalid", map[string]any{
+			"Error": err.Error(),
+		}), ui.GetMainMenuKeyboard())
+		return
+	}
+	a.Bot.SendMessage(chatID, lang.Translate("general.feeds.created", map[string]any{
+		"ID": rule.ID,
+	}), ui.GetMainMenuKeyboard())
+}
+
+// ParseRuleArgs builds a feed rule from the feed URL and key=value filter arguments.
+func ParseRuleArgs(args []string) (*database.FeedRule, error) {
+	rule := &database.FeedRule{FeedURL: args[0]}
+	for _, arg := range args[1:] {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("unknown argument %q", arg)
+		}
+		var err error
+		switch strings.ToLower(key) {
+		case "include":
+			rule.Include = value
+		case "exclude":
+			rule.Exclude = value
+		case "minsize":
+			rule.MinSizeBytes, err = tmsfeeds.ParseSize(value)
+		case "maxsize":
+			rule.MaxSizeBytes, err = tmsfeeds.ParseSize(value)
+		case "seeders":
+			rule.MinSeeders, err = strconv.Atoi(value)
+		case "cat":
+			rule.Category, err = strconv.Atoi(value)
+		default:
+			return nil, fmt.Errorf("unknown filter %q (use include, exclude, minsize, maxsize, seeders or cat)", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %q", key, value)
+		}
+	}
+	return rule, tmsfeeds.Validate(rule)
+}
+
+// ListFeedRulesHandler handles /feeds: the chat's rules (all of them for admins).
+func ListFeedRulesHandler(a *app.App, update *tgbotapi.Update, role models.UserRole) {
+	chatID := update.Message.Chat.ID
+	rules, err := a.DB.GetFeedRules(context.Background())
+	if err != nil {
+		logutils.Log.WithError(err).Error("Failed to list feed rules")
+		a.Bot.SendMessage(chatID, lang.Translate("error.feeds.save_error", nil), ui.GetMainMenuKeyboard())
+		return
+	}
+	lines := []string{lang.Translate("general.feeds.list_header", nil)}
+	for i := range rules {
+		if !canManage(&rules[i], chatID, role) {
+			continue
+		}
+		paused := ""
+		if rules[i].Paused {
+			paused = " " + lang.Translate("general.feeds.paused_marker", nil)
+		}
+		lines = append(lines, lang.Translate("general.feeds.item", map[string]any{
+			"ID":      rules[i].ID,
+			"Title":   tmsfeeds.DisplayName(&rules[i]),
+			"Paused":  paused,
+			"Filters": filtersText(&rules[i]),
+		}))
+	}
+	if len(lines) == 1 {
+		a.Bot.SendMessage(chatID, lang.Translate("general.feeds.empty", nil), ui.GetMainMenuKeyboard())
+		return
+	}
+	a.Bot.SendMessage(chatID, strings.Join(lines, "\n\n"), ui.GetMainMenuKeyboard())
+}
+
+// PauseFeedRuleHandler handles /feedpause <ID> and /feedresume <ID>.
+func PauseFeedRuleHandler(a *app.App, update *tgbotapi.Update, role models.UserRole, paused bool) {
+	rule, ok := ruleFromArgs(a, update, role)
+	if !ok {
+		return
+	}
+	chatID := update.Message.Chat.ID
+	if err := a.DB.SetFeedRulePaused(context.Background(), rule.ID, paused); err != nil {
+		logutils.Log.WithError(err).WithField("rule_id", rule.ID).Error("Failed to pause feed rule")
+		a.Bot.SendMessage(chatID, lang.Translate("error.feeds.save_error", nil), ui.GetMainMenuKeyboard())
+		return
+	}
+	key := "general.feeds.resumed"
+	if paused {
+		key = "general.feeds.paused"
+	}
+	a.Bot.SendMessage(chatID, lang.Translate(key, map[string]any{"ID": rule.ID}), ui.GetMainMenuKeyboard())
+}
+
+// RemoveFeedRuleHandler handles /feedrm <ID>.
+func RemoveFeedRuleHandler(a *app.App, update *tgbotapi.Update, role models.UserRole) {
+	rule, ok := ruleFromArgs(a, update, role)
+	if !ok {
+		return
+	}
+	chatID := update.Message.Chat.ID
+	if err := a.DB.RemoveFeedRule(context.Background(), rule.ID); err != nil {
+		logutils.Log.WithError(err).WithField("rule_id", rule.ID).Error("Failed to remove feed rule")
+		a.Bot.SendMessage(chatID, lang.Translate("error.feeds.save_error", nil), ui.GetMainMenuKeyboard())
+		return
+	}
+	a.Bot.SendMessage(chatID, lang.Translate("general.feeds.removed", map[string]any{
+		"ID":    rule.ID,
+		"Title": tmsfeeds.DisplayName(rule),
+	}), ui.GetMainMenuKeyboard())
+}
+
+// TestFeedRuleHandler handles /feedtest <ID>: shows which releases of the current feed match, without downloading.
+func TestFeedRuleHandler(a *app.App, update *tgbotapi.Update, role models.UserRole) {
+	rule, ok := ruleFromArgs(a, update, role)
+	if !ok {
+		return
+	}
+	chatID := update.Message.Chat.ID
+	feed, matches, err := tmsfeeds.Test(context.Background(), a, rule)
+	if err != nil {
+		logutils.Log.WithError(err).WithField("rule_id", rule.ID).Warn("Feed rule test failed")
+		a.Bot.SendMessage(chatID, lang.Translate("error.feeds.fetch_failed", map[string]any{
+			"Error": err.Error(),
+		}), ui.GetMainMenuKeyboard())
+		return
+	}
+	lines := []string{lang.Translate("general.feeds.test_header", map[string]any{
+		"ID":      rule.ID,
+		"Matched": len(matches),
+		"Total":   len(feed.Releases),
+	})}
+	for i := range matches {
+		if i == maxTestLines {
+			lines = append(lines, "…")
+			break
+		}
+		marker := "🆕"
+		if matches[i].Known {
+			marker = "✔️"
+		}
+		lines = append(lines, marker+" "+matches[i].Title+releaseDetails(&matches[i].Release))
+	}
+	if len(matches) > 0 {
+		lines = append(lines, "", lang.Translate("general.feeds.test_legend", nil))
+	}
+	a.Bot.SendMessage(chatID, strings.Join(lines, "\n"), ui.GetMainMenuKeyboard())
+}
+
+// ruleFromArgs loads the rule named by the first command argument and checks the caller may manage it;
+// on failure the user has already been answered.
+func ruleFromArgs(a *app.App, update *tgbotapi.Update, role models.UserRole) (*database.FeedRule, bool) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		a.Bot.SendMessage(chatID, lang.Translate("error.commands.invalid_format", nil), ui.GetMainMenuKeyboard())
+		return nil, false
+	}
+	id64, err := strconv.ParseUint(strings.TrimPrefix(args[0], "#"), 10, 32)
+	if err != nil {
+		a.Bot.SendMessage(chatID, lang.Translate("error.validation.invalid_ids", map[string]any{
+			"IDs": args[0],
+		}), ui.GetMainMenuKeyboard())
+		return nil, false
+	}
+	rule, err := a.DB.GetFeedRuleByID(context.Background(), uint(id64))
+	if err != nil || !canManage(&rule, chatID, role) {
+		a.Bot.SendMessage(chatID, lang.Translate("error.feeds.not_found", map[string]any{
+			"ID": id64,
+		}), ui.GetMainMenuKeyboard())
+		return nil, false
+	}
+	return &rule, true
+}
+
+func canManage(rule *database.FeedRule, chatID int64, role models.UserRole) bool {
+	return role == models.AdminRole || rule.ChatID == chatID
+}
+
+// filtersText renders the filters in /feedadd syntax on their own line, "" when there are none.
+func filtersText(rule *database.FeedRule) string {
+	var parts []string
+	if rule.Include != "" {
+		parts = append(parts, "include="+rule.Include)
+	}
+	if rule.Exclude != "" {
+		parts = append(parts, "exclude="+rule.Exclude)
+	}
+	if rule.MinSizeBytes > 0 {
+		parts = append(parts, "minsize="+formatSize(rule.MinSizeBytes))
+	}
+	if rule.MaxSizeBytes > 0 {
+		parts = append(parts, "maxsize="+formatSize(rule.MaxSizeBytes))
+	}
+	if rule.MinSeeders > 0 {
+		parts = append(parts, fmt.Sprintf("seeders=%d", rule.MinSeeders))
+	}
+	if rule.Category > 0 {
+		parts = append(parts, fmt.Sprintf("cat=%d", rule.Category))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "\n" + strings.Join(parts, " ")
+}
+
+// formatSize renders bytes in the unit syntax accepted by ParseSize.
+func formatSize(size int64) string {
+	return strconv.FormatFloat(float64(size)/(1<<30), 'f', -1, 64) + "GB"
+}
+
+func releaseDetails(rel *tmsfeeds.Release) string {
+	var parts []string
+	if rel.Size > 0 {
+		parts = append(parts, fmt.Sprintf("%.2f %s", float64(rel.Size)/(1<<30), lang.Translate("general.unit_gb", nil)))
+	}
+	if rel.Seeders >= 0 {
+		parts = append(parts, fmt.Sprintf("S:%d", rel.Seeders))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
@@ -70,7 +70,7 @@ func deleteMoviesByID(
 		validIDs = append(validIDs, uint(id64))
 	}
 	for _, id := range validIDs {
-		a.DeleteQueue.Enqueue(id)
+		EnqueueDeletion(a, chatID, id)
 	}
 	if len(validIDs) == 0 {
 		return
@@ -3,17 +3,21 @@ package movies
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
-	"github.com/NikitaDmitryuk/telegram-media-server/internal/filemanager"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/ui"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// scheduleTimeLayout renders the start time of scheduled downloads.
+const scheduleTimeLayout = "02.01 15:04"
+
 func ListMoviesHandler(a *app.App, update *tgbotapi.Update) {
 	chatID := update.Message.Chat.ID
 
@@ -38,29 +42,47 @@ func ListMoviesHandler(a *app.App, update *tgbotapi.Update) {
 		messages = append(messages, buildMovieListLine(&movies[i], compatMode))
 	}
 
-	availableSpaceGB, err := filemanager.GetAvailableSpaceGB(a.Config.MoviePath)
+	space, err := app.GetDiskSpace(ctx, a)
 	if err != nil {
 		logutils.Log.WithError(err).Error("Failed to get available disk space")
-		availableSpaceGB = 0
 	}
-
-	formattedSpace := fmt.Sprintf("%.2f", availableSpaceGB)
-
-	messages = append(messages, lang.Translate("general.disk_space_info", map[string]any{
-		"AvailableSpaceGB": formattedSpace,
-	}))
+	messages = append(messages, formatDiskSpaceInfo(space))
 
 	message := strings.Join(messages, "\n")
 	a.Bot.SendMessage(chatID, message, ui.GetMainMenuKeyboard())
 }
 
+// formatDiskSpaceInfo shows free space minus what active and queued downloads will still write.
+func formatDiskSpaceInfo(space app.DiskSpace) string {
+	if space.ReservedBytes <= 0 {
+		return lang.Translate("general.disk_space_info", map[string]any{
+			"AvailableSpaceGB": formatBytesGB(space.AvailableBytes()),
+		})
+	}
+	return lang.Translate("general.disk_space_reserved_info", map[string]any{
+		"AvailableSpaceGB": formatBytesGB(space.AvailableBytes()),
+		"ReservedSpaceGB":  formatBytesGB(space.ReservedBytes),
+	})
+}
+
+func formatBytesGB(size int64) string {
+	return fmt.Sprintf("%.2f", float64(size)/(1024*1024*1024))
+}
+
 func buildMovieListLine(movie *database.Movie, compatMode bool) string {
 	formattedSize := formatListMovieSizeGB(movie)
 	episodes := formatListEpisodesPrefix(movie)
 	progressStr, sticker := formatListProgressAndSticker(movie, compatMode)
+	if movie.StartAt != nil {
+		progressStr = formatListSchedule(movie)
+	}
+	name := movie.Name
+	if movie.Pinned {
+		name = pinnedMarker + name
+	}
 	return lang.Translate("general.downloaded_list", map[string]any{
 		"ID":       movie.ID,
-		"Name":     movie.Name,
+		"Name":     name,
 		"Progress": progressStr,
 		"Sticker":  sticker,
 		"Episodes": episodes,
@@ -85,8 +107,10 @@ func formatListEpisodesPrefix(movie *database.Movie) string {
 }
 
 func formatListProgressAndSticker(movie *database.Movie, compatMode bool) (progressStr, sticker string) {
+	extraction := formatListExtraction(movie)
+	seeding := formatListSeeding(movie)
 	if !compatMode {
-		return fmt.Sprintf("DL %d%%", movie.DownloadedPercentage), ""
+		return fmt.Sprintf("DL %d%%%s%s", movie.DownloadedPercentage, extraction, seeding), ""
 	}
 	convPct := movie.ConversionPercentage
 	if movie.DownloadedPercentage >= 100 && (movie.ConversionStatus == "done" || movie.ConversionStatus == "skipped") &&
@@ -101,7 +125,8 @@ func formatListProgressAndSticker(movie *database.Movie, compatMode bool) (progr
 	if tvStatus == "" {
 		tvStatus = "unknown"
 	}
-	progressStr = fmt.Sprintf("DL %d%% | CV %s %d%% | TV %s", movie.DownloadedPercentage, convStatus, convPct, tvStatus)
+	progressStr = fmt.Sprintf("DL %d%%%s | CV %s %d%%%s | TV %s%s",
+		movie.DownloadedPercentage, extraction, convStatus, convPct, formatListConversionDetails(movie), tvStatus, seeding)
 	switch movie.TvCompatibility {
 	case "green":
 		sticker = "🟢 "
@@ -114,3 +139,43 @@ func formatListProgressAndSticker(movie *database.Movie, compatMode bool) (progr
 	}
 	return progressStr, sticker
 }
+
+// formatListConversionDetails shows the file, ffmpeg speed and ETA of a running conversion.
+func formatListConversionDetails(movie *database.Movie) string {
+	if movie.ConversionStatus != "in_progress" || movie.ConversionFile == "" {
+		return ""
+	}
+	details := []string{filepath.Base(movie.ConversionFile)}
+	if movie.ConversionSpeed > 0 {
+		details = append(details, fmt.Sprintf("%.1fx", movie.ConversionSpeed))
+	}
+	if movie.ConversionETA > 0 {
+		details = append(details, "ETA "+(time.Duration(movie.ConversionETA)*time.Second).String())
+	}
+	return " (" + strings.Join(details, ", ") + ")"
+}
+
+// formatListExtraction shows archive extraction while it runs or when it failed, like the CV part does for conversion.
+func formatListExtraction(movie *database.Movie) string {
+	switch movie.ExtractionStatus {
+	case "in_progress":
+		return fmt.Sprintf(" | EX %s %d%%", movie.ExtractionStatus, movie.ExtractionPercentage)
+	case "failed":
+		return " | EX failed"
+	default:
+		return ""
+	}
+}
+
+// formatListSeeding shows the upload ratio while the completed torrent is kept in qBittorrent for seeding.
+func formatListSeeding(movie *database.Movie) string {
+	if !movie.Seeding {
+		return ""
+	}
+	return fmt.Sprintf(" | SEED %.2f", movie.SeedRatio)
+}
+
+// formatListSchedule shows when a scheduled download (⏰ Tonight or the API start_at) leaves the queue.
+func formatListSchedule(movie *database.Movie) string {
+	return "⏰ " + movie.StartAt.Local().Format(scheduleTimeLayout)
+}
@@ -169,6 +169,29 @@ func TestFormatListProgressAndSticker_StatusVariants(t *testing.T) {
 			wantProgress: "DL 100% | CV skipped 100% | TV red",
 			wantSticker:  "🔴 ",
 		},
+		{
+			name:         "extracting_archives",
+			movie:        database.Movie{DownloadedPercentage: 100, ExtractionStatus: "in_progress", ExtractionPercentage: 40},
+			wantProgress: "DL 100% | EX in_progress 40% | CV waiting 0% | TV unknown",
+			wantSticker:  "⚪ ",
+		},
+		{
+			name: "converting_with_details",
+			movie: database.Movie{
+				DownloadedPercentage: 100, ConversionStatus: "in_progress", ConversionPercentage: 42, TvCompatibility: "red",
+				ConversionFile: "Season 1/S01E02.mkv", ConversionSpeed: 2.46, ConversionETA: 750,
+			},
+			wantProgress: "DL 100% | CV in_progress 42% (S01E02.mkv, 2.5x, ETA 12m30s) | TV red",
+			wantSticker:  "🔴 ",
+		},
+		{
+			name: "seeding",
+			movie: database.Movie{
+				DownloadedPercentage: 100, ConversionStatus: "skipped", TvCompatibility: "green", Seeding: true, SeedRatio: 1.234,
+			},
+			wantProgress: "DL 100% | CV skipped 100% | TV green | SEED 1.23",
+			wantSticker:  "🟢 ",
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -261,3 +284,16 @@ func (*MockErrorDatabase) IsUserAccessAllowed(_ context.Context, _ int64) (allow
 func (*MockErrorDatabase) GenerateTemporaryPassword(_ context.Context, _ time.Duration) (string, error) {
 	return "", fmt.Errorf("database error")
 }
+
+func TestBuildMovieListLine_Scheduled(t *testing.T) {
+	startAt := time.Date(2030, 1, 2, 1, 0, 0, 0, time.Local)
+	movie := database.Movie{ID: 7, Name: "Night Movie", StartAt: &startAt}
+
+	line := buildMovieListLine(&movie, false)
+	if !strings.Contains(line, "⏰ 02.01 01:00") {
+		t.Errorf("Expected the start time for a scheduled download, got: %s", line)
+	}
+	if strings.Contains(line, "DL 0%") {
+		t.Errorf("Scheduled download should not show progress, got: %s", line)
+	}
+}
@@ -0,0 +1,55 @@
+package movies
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/ui"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const pinnedMarker = "📌 "
+
+// PinMovieHandler handles /keep <ID> (pinned=true) and /unkeep <ID> (pinned=false).
+// Pinned movies are skipped by the retention policy.
+func PinMovieHandler(a *app.App, update *tgbotapi.Update, pinned bool) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.Text)
+	if len(args) < 2 {
+		a.Bot.SendMessage(chatID, lang.Translate("error.commands.invalid_format", nil), ui.GetMainMenuKeyboard())
+		return
+	}
+	ctx := context.Background()
+	for _, idStr := range args[1:] {
+		id64, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			a.Bot.SendMessage(chatID, lang.Translate("error.validation.invalid_ids", map[string]any{
+				"IDs": idStr,
+			}), ui.GetMainMenuKeyboard())
+			continue
+		}
+		movie, err := a.DB.GetMovieByID(ctx, uint(id64))
+		if err != nil || movie.IsTrashed() {
+			a.Bot.SendMessage(chatID, lang.Translate("error.movies.not_found", map[string]any{
+				"ID": id64,
+			}), ui.GetMainMenuKeyboard())
+			continue
+		}
+		if err := a.DB.SetMoviePinned(ctx, movie.ID, pinned); err != nil {
+			logutils.Log.WithError(err).WithField("movie_id", movie.ID).Error("Failed to update movie pin")
+			a.Bot.SendMessage(chatID, lang.Translate("error.movies.pin_error", nil), ui.GetMainMenuKeyboard())
+			continue
+		}
+		key := "general.status_messages.movie_unpinned"
+		if pinned {
+			key = "general.status_messages.movie_pinned"
+		}
+		a.Bot.SendMessage(chatID, lang.Translate(key, map[string]any{
+			"Title": movie.Name,
+		}), ui.GetMainMenuKeyboard())
+	}
+}
@@ -0,0 +1,66 @@
+package movies
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/ui"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SeedMovieHandler handles /seed <ID> (seed=true) and /unseed <ID> (seed=false).
+// /seed keeps the qBittorrent torrent seeding until /unseed; /unseed stops seeding regardless of the policy.
+func SeedMovieHandler(a *app.App, update *tgbotapi.Update, seed bool) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.Text)
+	if len(args) < 2 {
+		a.Bot.SendMessage(chatID, lang.Translate("error.commands.invalid_format", nil), ui.GetMainMenuKeyboard())
+		return
+	}
+	ctx := context.Background()
+	for _, idStr := range args[1:] {
+		id64, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			a.Bot.SendMessage(chatID, lang.Translate("error.validation.invalid_ids", map[string]any{
+				"IDs": idStr,
+			}), ui.GetMainMenuKeyboard())
+			continue
+		}
+		movie, err := a.DB.GetMovieByID(ctx, uint(id64))
+		if err != nil || movie.IsTrashed() {
+			a.Bot.SendMessage(chatID, lang.Translate("error.movies.not_found", map[string]any{
+				"ID": id64,
+			}), ui.GetMainMenuKeyboard())
+			continue
+		}
+		if err := app.SetSeeding(ctx, a, &movie, seed); err != nil {
+			var key string
+			switch {
+			case errors.Is(err, app.ErrSeedingUnsupported):
+				key = "error.movies.seed_unsupported"
+			case errors.Is(err, app.ErrSeedingFinished):
+				key = "error.movies.seed_finished"
+			default:
+				logutils.Log.WithError(err).WithField("movie_id", movie.ID).Error("Failed to update movie seed mode")
+				key = "error.movies.pin_error"
+			}
+			a.Bot.SendMessage(chatID, lang.Translate(key, map[string]any{
+				"Title": movie.Name,
+			}), ui.GetMainMenuKeyboard())
+			continue
+		}
+		key := "general.status_messages.movie_seeding_off"
+		if seed {
+			key = "general.status_messages.movie_seeding_on"
+		}
+		a.Bot.SendMessage(chatID, lang.Translate(key, map[string]any{
+			"ID":    movie.ID,
+			"Title": movie.Name,
+		}), ui.GetMainMenuKeyboard())
+	}
+}
@@ -0,0 +1,127 @@
+package movies
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/deletion"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/filemanager"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/ui"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const trashDateLayout = "2006-01-02 15:04"
+
+// EnqueueDeletion sends the movie to the delete queue. When the queue supports undo, the chat gets
+// an "Undo" button once the movie is in the trash.
+func EnqueueDeletion(a *app.App, chatID int64, movieID uint) {
+	if uq, ok := a.DeleteQueue.(deletion.UndoableQueue); ok {
+		uq.EnqueueWithUndo(movieID, func(id uint, title string) {
+			a.Bot.SendMessage(chatID, lang.Translate("general.status_messages.moved_to_trash", map[string]any{
+				"Title": title,
+			}), CreateUndoMarkup(id))
+		})
+		return
+	}
+	a.DeleteQueue.Enqueue(movieID)
+}
+
+func CreateUndoMarkup(movieID uint) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(lang.Translate("general.interface.undo", nil), restoreCallbackData(movieID)),
+	))
+}
+
+func CreateTrashMenuMarkup(movies []database.Movie) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := range movies {
+		m := &movies[i]
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(lang.Translate("general.interface.undo", nil)+" "+m.Name, restoreCallbackData(m.ID)),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func restoreCallbackData(movieID uint) string {
+	return "restore_movie:" + strconv.FormatUint(uint64(movieID), 10)
+}
+
+// TrashListHandler handles /trash: lists trashed movies with restore buttons.
+func TrashListHandler(a *app.App, update *tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	trashed, err := a.DB.GetTrashedMovies(context.Background())
+	if err != nil {
+		logutils.Log.WithError(err).Error("Failed to retrieve trashed movies")
+		a.Bot.SendMessage(chatID, lang.Translate("error.movies.fetch_error", nil), ui.GetMainMenuKeyboard())
+		return
+	}
+	if len(trashed) == 0 {
+		a.Bot.SendMessage(chatID, lang.Translate("general.trash.empty", nil), ui.GetMainMenuKeyboard())
+		return
+	}
+
+	retention := a.Config.TrashSettings.Retention
+	messages := []string{lang.Translate("general.trash.header", nil)}
+	for i := range trashed {
+		m := &trashed[i]
+		messages = append(messages, lang.Translate("general.trash.item", map[string]any{
+			"ID":        m.ID,
+			"Name":      m.Name,
+			"SizeGB":    formatListMovieSizeGB(m),
+			"PurgeAt":   m.TrashedAt.Add(retention).Format(trashDateLayout),
+			"TrashedAt": m.TrashedAt.Format(trashDateLayout),
+		}))
+	}
+	a.Bot.SendMessage(chatID, strings.Join(messages, "\n"), CreateTrashMenuMarkup(trashed))
+}
+
+// RestoreMovieHandler handles /restore <ID>.
+func RestoreMovieHandler(a *app.App, update *tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.Text)
+	if len(args) < 2 {
+		a.Bot.SendMessage(chatID, lang.Translate("error.commands.invalid_format", nil), ui.GetMainMenuKeyboard())
+		return
+	}
+	for _, idStr := range args[1:] {
+		id64, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			a.Bot.SendMessage(chatID, lang.Translate("error.validation.invalid_ids", map[string]any{
+				"IDs": idStr,
+			}), ui.GetMainMenuKeyboard())
+			continue
+		}
+		RestoreMovieByID(a, chatID, uint(id64))
+	}
+}
+
+// RestoreMovieByID moves a trashed movie back into the library and reports the result to the chat.
+func RestoreMovieByID(a *app.App, chatID int64, movieID uint) {
+	if a.DeleteQueue != nil && a.DeleteQueue.IsPendingDeletion(movieID) {
+		a.Bot.SendMessage(chatID, lang.Translate("error.trash.pending", nil), nil)
+		return
+	}
+	movie, err := filemanager.RestoreMovie(movieID, a.Config.MoviePath, a.DB)
+	switch {
+	case err == nil:
+		a.Bot.SendMessage(chatID, lang.Translate("general.status_messages.restored_movie", map[string]any{
+			"Title": movie.Name,
+		}), nil)
+	case errors.Is(err, filemanager.ErrNotInTrash):
+		a.Bot.SendMessage(chatID, lang.Translate("error.trash.not_in_trash", nil), nil)
+	case errors.Is(err, filemanager.ErrRestoreTarget):
+		a.Bot.SendMessage(chatID, lang.Translate("error.trash.restore_conflict", nil), nil)
+	default:
+		logutils.Log.WithError(err).WithField("movie_id", movieID).Error("Failed to restore movie from trash")
+		a.Bot.SendMessage(chatID, lang.Translate("error.trash.restore_failed", map[string]any{
+			"Error": err.Error(),
+		}), nil)
+	}
+}
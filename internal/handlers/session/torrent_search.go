@@ -12,11 +12,13 @@ import (
 	tmsbot "github.com/NikitaDmitryuk/telegram-media-server/internal/bot"
 	tmsfactory "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/factory"
 	aria2 "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/torrent"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/usenet"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/downloads"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/ui"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
 	"github.com/NikitaDmitryuk/telegram-media-server/internal/prowlarr"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/proxy"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/google/uuid"
 )
@@ -154,7 +156,7 @@ func HandleTorrentSearchQuery(a *app.App, update *tgbotapi.Update) {
 		ui.SendMainMenuNoText(a.Bot, chatID)
 		return
 	}
-	client := prowlarr.NewProwlarr(a.Config.ProwlarrURL, a.Config.ProwlarrAPIKey)
+	client := prowlarr.NewProwlarr(a.Config.ProwlarrURL, a.Config.ProwlarrAPIKey, proxy.Transport(a.Config))
 	page, err := client.SearchTorrents(query, 0, searchPageSize, nil, nil)
 	if err != nil {
 		logutils.Log.WithError(err).Error("Prowlarr search failed")
@@ -163,6 +165,9 @@ func HandleTorrentSearchQuery(a *app.App, update *tgbotapi.Update) {
 		ui.SendMainMenuNoText(a.Bot, chatID)
 		return
 	}
+	if !usenet.Configured(a.Config) {
+		page.Results = prowlarr.WithoutUsenet(page.Results)
+	}
 	if len(page.Results) == 0 {
 		a.Bot.SendMessage(chatID, lang.Translate("general.torrent_search.not_found", nil), ui.GetEmptyKeyboard())
 		DeleteSearchSession(chatID)
@@ -207,6 +212,9 @@ func ShowTorrentSearchResults(bot tmsbot.Service, chatID int64) {
 			t.Title,
 			float64(t.Size)/gbDivisor,
 		)
+		if t.IsUsenet() {
+			text += "\n" + lang.Translate("general.torrent_search.usenet", nil)
+		}
 		btn := tgbotapi.NewInlineKeyboardMarkup(
 			tgbotapi.NewInlineKeyboardRow(
 				tgbotapi.NewInlineKeyboardButtonData(
@@ -237,12 +245,23 @@ func handleTorrentDownloadCallback(bot tmsbot.Service, chatID int64, data string
 		return "", errors.New(lang.Translate("general.torrent_search.invalid_choice", nil))
 	}
 	candidate := ss.Results[idx]
-	client := prowlarr.NewProwlarr(a.Config.ProwlarrURL, a.Config.ProwlarrAPIKey)
+	client := prowlarr.NewProwlarr(a.Config.ProwlarrURL, a.Config.ProwlarrAPIKey, proxy.Transport(a.Config))
 	fileBytes, err := client.GetTorrentFile(candidate.TorrentURL)
 	if err != nil {
 		return "", errors.New(lang.Translate("general.torrent_search.download_failed", nil))
 	}
 
+	if candidate.IsUsenet() {
+		if !usenet.IsNZB(fileBytes) {
+			return "", errors.New(lang.Translate("general.torrent_search.download_failed", nil))
+		}
+		fileName := "nzb_" + uuid.New().String()[:8] + ".nzb"
+		if err := bot.SaveFile(fileName, fileBytes); err != nil {
+			return "", errors.New(lang.Translate("general.torrent_search.save_failed", nil))
+		}
+		return fileName, nil
+	}
+
 	bodyStr := strings.TrimSpace(string(fileBytes))
 	// Some indexers return magnet in response body instead of .torrent; save as .magnet so aria2 gets correct format
 	var fileName string
@@ -349,7 +368,7 @@ func HandleTorrentSearchCallback(
 				_ = a.Bot.DeleteMessage(chatID, msgID)
 			}
 		}
-		downloaderInstance, err := tmsfactory.NewTorrentDownloader(fileName, a.Config.MoviePath, a.Config)
+		downloaderInstance, err := tmsfactory.NewDownloaderFromFile(fileName, a.Config.MoviePath, a.Config)
 		if err != nil {
 			a.Bot.SendMessage(chatID, err.Error(), ui.GetEmptyKeyboard())
 			if update.CallbackQuery != nil {
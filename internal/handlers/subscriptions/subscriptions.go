@@ -0,0 +1,162 @@
+package subscriptions
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/handlers/ui"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/models"
+	tmssubs "github.com/NikitaDmitryuk/telegram-media-server/internal/subscriptions"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SubscribeHandler handles /subscribe <URL> [match=<regex>] [maxdur=<minutes|1h30m>] [after=<YYYY-MM-DD>].
+func SubscribeHandler(a *app.App, update *tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		a.Bot.SendMessage(chatID, lang.Translate("general.subscriptions.usage", nil), ui.GetMainMenuKeyboard())
+		return
+	}
+	sub, err := ParseSubscribeArgs(args)
+	if err == nil {
+		sub.ChatID = chatID
+		err = tmssubs.Create(context.Background(), a, sub)
+	}
+	if err != nil {
+		logutils.Log.WithError(err).WithField("chat_id", chatID).Warn("Failed to create subscription")
+		a.Bot.SendMessage(chatID, lang.Translate("error.subscriptions.invalid", map[string]any{
+			"Error": err.Error(),
+		}), ui.GetMainMenuKeyboard())
+		return
+	}
+	a.Bot.SendMessage(chatID, lang.Translate("general.subscriptions.created", map[string]any{
+		"ID":  sub.ID,
+		"URL": sub.URL,
+	}), ui.GetMainMenuKeyboard())
+}
+
+// ParseSubscribeArgs builds a subscription from the URL and key=value filter arguments.
+func ParseSubscribeArgs(args []string) (*database.Subscription, error) {
+	sub := &database.Subscription{URL: args[0]}
+	for _, arg := range args[1:] {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("unknown argument %q", arg)
+		}
+		switch strings.ToLower(key) {
+		case "match":
+			sub.TitleRegex = value
+		case "maxdur":
+			seconds, err := tmssubs.ParseMaxDuration(value)
+			if err != nil {
+				return nil, err
+			}
+			sub.MaxDurationSec = seconds
+		case "after":
+			sub.DateAfter = value
+		default:
+			return nil, fmt.Errorf("unknown filter %q (use match, maxdur or after)", key)
+		}
+	}
+	return sub, tmssubs.Validate(sub)
+}
+
+// ListSubscriptionsHandler handles /subscriptions: the chat's subscriptions (all of them for admins).
+func ListSubscriptionsHandler(a *app.App, update *tgbotapi.Update, role models.UserRole) {
+	chatID := update.Message.Chat.ID
+	subs, err := a.DB.GetSubscriptions(context.Background())
+	if err != nil {
+		logutils.Log.WithError(err).Error("Failed to list subscriptions")
+		a.Bot.SendMessage(chatID, lang.Translate("error.subscriptions.save_error", nil), ui.GetMainMenuKeyboard())
+		return
+	}
+	lines := []string{lang.Translate("general.subscriptions.list_header", nil)}
+	for i := range subs {
+		if !canManage(&subs[i], chatID, role) {
+			continue
+		}
+		lines = append(lines, lang.Translate("general.subscriptions.item", map[string]any{
+			"ID":      subs[i].ID,
+			"Title":   subscriptionTitle(&subs[i]),
+			"URL":     subs[i].URL,
+			"Filters": filtersText(&subs[i]),
+		}))
+	}
+	if len(lines) == 1 {
+		a.Bot.SendMessage(chatID, lang.Translate("general.subscriptions.empty", nil), ui.GetMainMenuKeyboard())
+		return
+	}
+	a.Bot.SendMessage(chatID, strings.Join(lines, "\n\n"), ui.GetMainMenuKeyboard())
+}
+
+// UnsubscribeHandler handles /unsubscribe <ID>...; users remove their own subscriptions, admins any.
+func UnsubscribeHandler(a *app.App, update *tgbotapi.Update, role models.UserRole) {
+	chatID := update.Message.Chat.ID
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		a.Bot.SendMessage(chatID, lang.Translate("error.commands.invalid_format", nil), ui.GetMainMenuKeyboard())
+		return
+	}
+	ctx := context.Background()
+	for _, idStr := range args {
+		id64, err := strconv.ParseUint(strings.TrimPrefix(idStr, "#"), 10, 32)
+		if err != nil {
+			a.Bot.SendMessage(chatID, lang.Translate("error.validation.invalid_ids", map[string]any{
+				"IDs": idStr,
+			}), ui.GetMainMenuKeyboard())
+			continue
+		}
+		sub, err := a.DB.GetSubscriptionByID(ctx, uint(id64))
+		if err != nil || !canManage(&sub, chatID, role) {
+			a.Bot.SendMessage(chatID, lang.Translate("error.subscriptions.not_found", map[string]any{
+				"ID": id64,
+			}), ui.GetMainMenuKeyboard())
+			continue
+		}
+		if err := a.DB.RemoveSubscription(ctx, sub.ID); err != nil {
+			logutils.Log.WithError(err).WithField("subscription_id", sub.ID).Error("Failed to remove subscription")
+			a.Bot.SendMessage(chatID, lang.Translate("error.subscriptions.save_error", nil), ui.GetMainMenuKeyboard())
+			continue
+		}
+		a.Bot.SendMessage(chatID, lang.Translate("general.subscriptions.removed", map[string]any{
+			"ID":    sub.ID,
+			"Title": subscriptionTitle(&sub),
+		}), ui.GetMainMenuKeyboard())
+	}
+}
+
+func canManage(sub *database.Subscription, chatID int64, role models.UserRole) bool {
+	return role == models.AdminRole || sub.ChatID == chatID
+}
+
+func subscriptionTitle(sub *database.Subscription) string {
+	if sub.Title != "" {
+		return sub.Title
+	}
+	return sub.URL
+}
+
+// filtersText renders the filters in /subscribe syntax on their own line, "" when there are none.
+func filtersText(sub *database.Subscription) string {
+	var parts []string
+	if sub.TitleRegex != "" {
+		parts = append(parts, "match="+sub.TitleRegex)
+	}
+	if sub.MaxDurationSec > 0 {
+		parts = append(parts, fmt.Sprintf("maxdur=%d", sub.MaxDurationSec/60))
+	}
+	if sub.DateAfter != "" {
+		parts = append(parts, "after="+sub.DateAfter)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "\n" + strings.Join(parts, " ")
+}
@@ -12,15 +12,63 @@ type Movie struct {
 	// ConversionStatus: "", "pending", "in_progress", "done", "failed", "skipped"
 	ConversionStatus     string `json:"conversion_status"     gorm:"not null;default:''"`
 	ConversionPercentage int    `json:"conversion_percentage" gorm:"not null;default:0"`
+	// ConversionFile, ConversionSpeed (ffmpeg speed factor, e.g. 2.5 for 2.5x) and ConversionETA (seconds)
+	// describe a running conversion; cleared when only the percentage is stored.
+	ConversionFile  string  `json:"conversion_file"       gorm:"not null;default:''"`
+	ConversionSpeed float64 `json:"conversion_speed"      gorm:"not null;default:0"`
+	ConversionETA   int     `json:"conversion_eta"        gorm:"not null;default:0"`
+	// ExtractionStatus: "", "in_progress", "done", "failed" (only for releases shipped as RAR/ZIP/7z archives)
+	ExtractionStatus     string `json:"extraction_status"     gorm:"not null;default:''"`
+	ExtractionPercentage int    `json:"extraction_percentage" gorm:"not null;default:0"`
 	// TvCompatibility: "", "green", "yellow", "red" (only in compatibility mode)
 	TvCompatibility string `json:"tv_compatibility"      gorm:"not null;default:''"`
-	// QBittorrentHash: set when downloaded via qBittorrent; used to remove from Web UI on delete.
-	// Explicit column matches migrations and SetQBittorrentHash(..., "qbittorrent_hash", ...).
+	// QBittorrentHash: id of the download in the torrent daemon named by TorrentClient (the info hash for
+	// qBittorrent and Transmission, the GID for aria2 RPC); used to resume it and to remove it on delete.
+	// Explicit column matches migrations and SetTorrentClientHash(..., "qbittorrent_hash", ...).
 	// Without it, GORM may use q_bittorrent_hash and reads would miss the stored value.
-	QBittorrentHash string      `json:"qbittorrent_hash"      gorm:"not null;default:'';column:qbittorrent_hash"`
-	Files           []MovieFile `json:"files"                 gorm:"foreignKey:MovieID"`
-	CreatedAt       time.Time   `json:"created_at"            gorm:"autoCreateTime"`
-	UpdatedAt       time.Time   `json:"updated_at"            gorm:"autoUpdateTime"`
+	QBittorrentHash string `json:"qbittorrent_hash"      gorm:"not null;default:'';column:qbittorrent_hash"`
+	// TorrentClient: config.TorrentClient* value of the daemon holding QBittorrentHash. Rows stored before
+	// the column existed have "" and are handled by the currently configured daemon.
+	TorrentClient string `json:"torrent_client"        gorm:"not null;default:''"`
+	// InfoHash: BitTorrent info hash (lowercase hex) of torrent downloads; a second add of the same release is a duplicate.
+	InfoHash string `json:"info_hash,omitempty"   gorm:"not null;default:'';index"`
+	// CompletedAt: set by SetLoaded when the download finished; the retention policy ages movies from it.
+	// Movies finished before the column existed have none and fall back to CreatedAt.
+	CompletedAt *time.Time `json:"completed_at,omitempty" gorm:"index"`
+	// TrashedAt: set when the movie was moved to MOVIE_PATH/.trash instead of being deleted (undo window).
+	// Trashed movies are hidden from the library until restored or purged.
+	TrashedAt *time.Time `json:"trashed_at,omitempty"  gorm:"index"`
+	// Pinned: set by admins with /keep; the retention policy never evicts pinned movies.
+	Pinned bool `json:"pinned"                gorm:"not null;default:false"`
+	// SeedMode: "" follows the seeding policy, "on" seeds even without one, "off" never seeds (set with /seed, /unseed or the API).
+	SeedMode string `json:"seed_mode,omitempty"   gorm:"not null;default:''"`
+	// Seeding: the completed torrent is still in qBittorrent; SeedRatio is its last seen upload ratio.
+	Seeding   bool    `json:"seeding"               gorm:"not null;default:false"`
+	SeedRatio float64 `json:"seed_ratio"            gorm:"not null;default:0"`
+	// StartAt: the download waits in the queue until this time ("start at" / ⏰ Tonight); cleared once it starts.
+	StartAt *time.Time `json:"start_at,omitempty"    gorm:"index"`
+	// Source: the link a scheduled or direct download was added from, to queue a scheduled download again and
+	// to resume a direct one from its partial file after a failure or restart (empty for .torrent/.nzb files).
+	Source string `json:"-"                     gorm:"not null;default:''"`
+	// StartOptions: factory.StartOptions (video options, selected torrent files) of a scheduled download, as JSON.
+	StartOptions string      `json:"-"                     gorm:"not null;default:''"`
+	Files        []MovieFile `json:"files"                 gorm:"foreignKey:MovieID"`
+	CreatedAt    time.Time   `json:"created_at"            gorm:"autoCreateTime"`
+	UpdatedAt    time.Time   `json:"updated_at"            gorm:"autoUpdateTime"`
+}
+
+// IsTrashed reports whether the movie currently sits in the trash.
+func (m *Movie) IsTrashed() bool {
+	return m.TrashedAt != nil
+}
+
+// ConversionProgress is the state of a running conversion stored with UpdateConversionPercentage.
+// File, Speed and ETA are empty when only the percentage is known (e.g. queued or finished).
+type ConversionProgress struct {
+	Percentage int
+	File       string        // video being converted, relative to the movie root
+	Speed      float64       // ffmpeg speed factor: media seconds encoded per second
+	ETA        time.Duration // estimated time left for the whole movie
 }
 
 type MovieFile struct {
@@ -31,6 +79,67 @@ type MovieFile struct {
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 }
 
+// Subscription is a channel or playlist URL checked periodically for new videos.
+// ChatID is the subscriber; 0 for subscriptions created through the API (admins are notified).
+type Subscription struct {
+	ID     uint   `json:"id"                     gorm:"primaryKey"`
+	ChatID int64  `json:"chat_id"                gorm:"not null;default:0;index"`
+	URL    string `json:"url"                    gorm:"not null"`
+	Title  string `json:"title"                  gorm:"not null;default:''"`
+	// TitleRegex: only entries whose title matches are downloaded ("" = all).
+	TitleRegex string `json:"title_regex,omitempty"  gorm:"not null;default:''"`
+	// MaxDurationSec: entries longer than this are skipped (0 = no limit).
+	MaxDurationSec int `json:"max_duration_sec,omitempty" gorm:"not null;default:0"`
+	// DateAfter: YYYYMMDD; entries uploaded before this day are skipped ("" = no limit).
+	DateAfter string `json:"date_after,omitempty"   gorm:"not null;default:''"`
+	// LastCheckedAt is nil until the first check, which only records the existing entries.
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"             gorm:"autoCreateTime"`
+}
+
+// SubscriptionItem is the download archive of a subscription: entries already seen are never started again.
+type SubscriptionItem struct {
+	ID             uint      `json:"id"              gorm:"primaryKey"`
+	SubscriptionID uint      `json:"subscription_id" gorm:"not null;uniqueIndex:idx_subscription_video;constraint:OnDelete:CASCADE;"`
+	VideoID        string    `json:"video_id"        gorm:"not null;uniqueIndex:idx_subscription_video"`
+	CreatedAt      time.Time `json:"created_at"      gorm:"autoCreateTime"`
+}
+
+// FeedRule auto-downloads releases from an indexer RSS or Prowlarr Torznab feed that pass its filters.
+// ChatID is the owner; 0 for rules created without a chat (admins are notified).
+type FeedRule struct {
+	ID      uint   `json:"id"       gorm:"primaryKey"`
+	ChatID  int64  `json:"chat_id"  gorm:"not null;default:0;index"`
+	FeedURL string `json:"feed_url" gorm:"not null"`
+	Title   string `json:"title"    gorm:"not null;default:''"`
+	// Include/Exclude: regexes on the release title ("" = no filter).
+	Include string `json:"include,omitempty" gorm:"not null;default:''"`
+	Exclude string `json:"exclude,omitempty" gorm:"not null;default:''"`
+	// MinSizeBytes/MaxSizeBytes: size bounds (0 = no bound).
+	MinSizeBytes int64 `json:"min_size_bytes,omitempty" gorm:"not null;default:0"`
+	MaxSizeBytes int64 `json:"max_size_bytes,omitempty" gorm:"not null;default:0"`
+	MinSeeders   int   `json:"min_seeders,omitempty"    gorm:"not null;default:0"`
+	// Category: Torznab category, e.g. 2000 (movies) or 5040 (TV HD); a whole group matches when it ends in 000 (0 = any).
+	Category int  `json:"category,omitempty" gorm:"not null;default:0"`
+	Paused   bool `json:"paused"             gorm:"not null;default:false"`
+	// LastCheckedAt is nil until the first check, which only records the matching releases already in the feed.
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"               gorm:"autoCreateTime"`
+}
+
+// FeedRelease is a release already handled by a feed rule. Keyed by info hash: once downloaded, the
+// same release seen in another feed or matched by another rule is not downloaded again.
+type FeedRelease struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+	// Key is the lowercase hex info hash, or "guid:<guid>" when the feed does not carry one.
+	Key    string `json:"key"     gorm:"not null;uniqueIndex:idx_feed_release_rule"`
+	RuleID uint   `json:"rule_id" gorm:"not null;uniqueIndex:idx_feed_release_rule"`
+	Title  string `json:"title"   gorm:"not null;default:''"`
+	// Downloaded is false for releases only recorded by the first check of the rule.
+	Downloaded bool      `json:"downloaded" gorm:"not null;default:false"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
 type UserRole string
 
 const (
@@ -56,7 +165,7 @@ func (r UserRole) HasPermission(action string) bool {
 	switch action {
 	case "download":
 		return r == AdminRole || r == RegularRole || r == TemporaryRole
-	case "delete":
+	case "delete", "subscribe":
 		return r == AdminRole || r == RegularRole
 	case "manage_users":
 		return r == AdminRole
@@ -60,6 +60,8 @@ func TestUserRole_HasPermission(t *testing.T) {
 		{"admin can delete", AdminRole, "delete", true},
 		{"regular can delete", RegularRole, "delete", true},
 		{"temporary cannot delete", TemporaryRole, "delete", false},
+		{"regular can subscribe", RegularRole, "subscribe", true},
+		{"temporary cannot subscribe", TemporaryRole, "subscribe", false},
 		{"admin can manage users", AdminRole, "manage_users", true},
 		{"regular cannot manage users", RegularRole, "manage_users", false},
 		{"admin can generate temp password", AdminRole, "generate_temp_password", true},
@@ -3,6 +3,7 @@ package prowlarr
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
@@ -17,6 +18,14 @@ type Prowlarr struct {
 	BaseURL string
 }
 
+// Release protocols reported by Prowlarr search.
+const (
+	ProtocolTorrent = "torrent"
+	ProtocolUsenet  = "usenet"
+)
+
+// TorrentSearchResult is one Prowlarr release. For Usenet results TorrentURL is the NZB download URL
+// and Magnet/InfoHash/Peers are empty.
 type TorrentSearchResult struct {
 	Title       string
 	Size        int64
@@ -25,6 +34,23 @@ type TorrentSearchResult struct {
 	IndexerName string
 	InfoHash    string
 	Peers       int
+	Protocol    string
+}
+
+// IsUsenet reports whether the release is an NZB rather than a torrent.
+func (r *TorrentSearchResult) IsUsenet() bool {
+	return r.Protocol == ProtocolUsenet
+}
+
+// WithoutUsenet drops NZB releases, for setups without a Usenet download client.
+func WithoutUsenet(results []TorrentSearchResult) []TorrentSearchResult {
+	out := results[:0:0]
+	for i := range results {
+		if !results[i].IsUsenet() {
+			out = append(out, results[i])
+		}
+	}
+	return out
 }
 
 type TorrentSearchPage struct {
@@ -34,8 +60,13 @@ type TorrentSearchPage struct {
 	Limit   int
 }
 
-func NewProwlarr(baseURL, apiKey string) *Prowlarr {
+// NewProwlarr creates the client; transport (nil = default) carries the PROXY_RULES routing, which also
+// covers release files fetched by GetTorrentFile.
+func NewProwlarr(baseURL, apiKey string, transport http.RoundTripper) *Prowlarr {
 	client := resty.New().SetBaseURL(baseURL).SetHeader("X-Api-Key", apiKey)
+	if transport != nil {
+		client.SetTransport(transport)
+	}
 	logutils.Log.Infof("Initialized Prowlarr client with baseURL: %s", baseURL)
 	return &Prowlarr{
 		Client:  client,
@@ -120,6 +151,10 @@ func (p *Prowlarr) searchTorrents(
 		torrentURL, _ := r["downloadUrl"].(string)
 		indexerName, _ := r["indexerName"].(string)
 		infoHash, _ := r["infoHash"].(string)
+		protocol, _ := r["protocol"].(string)
+		if protocol == "" {
+			protocol = ProtocolTorrent
+		}
 		peers := 0
 		if v, ok := r["peers"].(float64); ok {
 			peers = int(v)
@@ -138,6 +173,7 @@ func (p *Prowlarr) searchTorrents(
 			IndexerName: indexerName,
 			InfoHash:    infoHash,
 			Peers:       peers,
+			Protocol:    protocol,
 		})
 	}
 	return TorrentSearchPage{
@@ -181,6 +217,8 @@ func (p *Prowlarr) GetIndexers() ([]Indexer, error) {
 	return res, nil
 }
 
+// GetTorrentFile downloads a release file through Prowlarr: a .torrent (or magnet text) for torrent
+// results, or the NZB for Usenet results.
 func (p *Prowlarr) GetTorrentFile(torrentURL string) ([]byte, error) {
 	logutils.Log.Infof("Downloading torrent file from URL: %s", torrentURL)
 	resp, err := p.Client.R().SetHeader("X-Api-Key", p.ApiKey).Get(torrentURL)
@@ -0,0 +1,132 @@
+package proxy
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+)
+
+// Router picks the proxy for outgoing connections from PROXY_RULES and the older CONTENT_PROXY settings.
+type Router struct {
+	rules []config.ProxyRule
+}
+
+// New builds the router for torrent, tracker and indexer traffic: PROXY_RULES only, so a CONTENT_PROXY
+// set for yt-dlp never reroutes them. Invalid rules are rejected by config validation and ignored here.
+func New(cfg *config.Config) Router {
+	return newRouter(cfg, false)
+}
+
+// NewContent builds the router for yt-dlp and direct file downloads: PROXY_RULES followed by the older
+// CONTENT_PROXY / CONTENT_PROXY_DOMAINS rules.
+func NewContent(cfg *config.Config) Router {
+	return newRouter(cfg, true)
+}
+
+func newRouter(cfg *config.Config, withLegacy bool) Router {
+	if cfg == nil {
+		return Router{}
+	}
+	rules, _ := cfg.ParseProxyRules()
+	if !withLegacy {
+		rules = slices.DeleteFunc(rules, func(rule config.ProxyRule) bool { return rule.Legacy })
+	}
+	return Router{rules: rules}
+}
+
+// Route returns the rule for the host of rawURL; ok is false when no rule covers it.
+func (r Router) Route(rawURL string) (rule config.ProxyRule, ok bool) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return config.ProxyRule{}, false
+	}
+	return r.RouteHost(u.Hostname())
+}
+
+// RouteAny returns the most specific rule among the hosts of urls, e.g. the trackers of a torrent.
+func (r Router) RouteAny(urls []string) (rule config.ProxyRule, ok bool) {
+	for _, raw := range urls {
+		if candidate, found := r.Route(raw); found && (!ok || specificity(candidate) > specificity(rule)) {
+			rule, ok = candidate, true
+		}
+	}
+	return rule, ok
+}
+
+// RouteHost returns the most specific rule whose domain is host or one of its parents, else the "*" rule.
+// Local and private hosts (Prowlarr, download clients) only follow explicit rules.
+func (r Router) RouteHost(host string) (rule config.ProxyRule, ok bool) {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" {
+		return config.ProxyRule{}, false
+	}
+	best := -1
+	for i := range r.rules {
+		d := r.rules[i].Domain
+		if d == "*" {
+			if isLocal(host) {
+				continue
+			}
+		} else if host != d && !strings.HasSuffix(host, "."+d) {
+			continue
+		}
+		if best < 0 || specificity(r.rules[i]) > specificity(r.rules[best]) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return config.ProxyRule{}, false
+	}
+	return r.rules[best], true
+}
+
+// ProxyFunc is an http.Transport Proxy that applies the rules to every request, redirects included.
+// Hosts without a rule keep the HTTP_PROXY / HTTPS_PROXY / NO_PROXY environment behaviour.
+func (r Router) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		rule, ok := r.RouteHost(req.URL.Hostname())
+		if !ok {
+			return http.ProxyFromEnvironment(req)
+		}
+		if rule.Proxy == "" {
+			return nil, nil
+		}
+		return url.Parse(rule.Proxy)
+	}
+}
+
+// Transport returns a clone of http.DefaultTransport routed by the rules of New.
+func Transport(cfg *config.Config) *http.Transport {
+	return transportFor(New(cfg))
+}
+
+// ContentTransport returns a clone of http.DefaultTransport routed by the rules of NewContent.
+func ContentTransport(cfg *config.Config) *http.Transport {
+	return transportFor(NewContent(cfg))
+}
+
+func transportFor(router Router) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = router.ProxyFunc()
+	return transport
+}
+
+// specificity ranks domain rules by length; "*" is the least specific.
+func specificity(rule config.ProxyRule) int {
+	if rule.Domain == "*" {
+		return 0
+	}
+	return len(rule.Domain)
+}
+
+func isLocal(host string) bool {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast()
+	}
+	// Single-label names such as "prowlarr" are Docker services or LAN hosts.
+	return host == "localhost" || strings.HasSuffix(host, ".localhost") || !strings.Contains(host, ".")
+}
@@ -0,0 +1,94 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+)
+
+func TestRouteHost(t *testing.T) {
+	cfg := &config.Config{
+		ProxyRules: "youtube.com=socks5://127.0.0.1:1080, music.youtube.com=direct, *.rutracker.org=http://proxy:3128, *=http://default:3128",
+	}
+	router := New(cfg)
+
+	tests := []struct {
+		host    string
+		wantOK  bool
+		wantVia string
+	}{
+		{host: "youtube.com", wantOK: true, wantVia: "socks5://127.0.0.1:1080"},
+		{host: "www.youtube.com", wantOK: true, wantVia: "socks5://127.0.0.1:1080"},
+		{host: "music.youtube.com", wantOK: true, wantVia: ""},
+		{host: "bt.rutracker.org", wantOK: true, wantVia: "http://proxy:3128"},
+		{host: "notyoutube.com", wantOK: true, wantVia: "http://default:3128"},
+		{host: "localhost", wantOK: false},
+		{host: "192.168.1.10", wantOK: false},
+		{host: "prowlarr", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			rule, ok := router.RouteHost(tt.host)
+			if ok != tt.wantOK || rule.Proxy != tt.wantVia {
+				t.Errorf("RouteHost(%q) = %+v, %v; want proxy %q, %v", tt.host, rule, ok, tt.wantVia, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRouteLegacyContentProxy(t *testing.T) {
+	cfg := &config.Config{
+		Proxy:        "127.0.0.1:8080",
+		ProxyDomains: "youtube.com,youtu.be",
+		ProxyRules:   "youtu.be=direct",
+	}
+	router := NewContent(cfg)
+	if rule, ok := router.Route("https://www.youtube.com/watch?v=1"); !ok || rule.Proxy != "http://127.0.0.1:8080" {
+		t.Errorf("youtube.com routed to %+v, %v; want CONTENT_PROXY", rule, ok)
+	}
+	if rule, ok := router.Route("https://youtu.be/1"); !ok || rule.Proxy != "" {
+		t.Errorf("youtu.be routed to %+v, %v; PROXY_RULES should win over CONTENT_PROXY_DOMAINS", rule, ok)
+	}
+	if _, ok := router.Route("https://vimeo.com/1"); ok {
+		t.Error("vimeo.com has no rule and should not be routed")
+	}
+	if _, ok := New(cfg).Route("https://www.youtube.com/watch?v=1"); ok {
+		t.Error("CONTENT_PROXY should only route yt-dlp and direct downloads, not torrent or indexer traffic")
+	}
+}
+
+func TestRouteAnyPicksMostSpecific(t *testing.T) {
+	router := New(&config.Config{ProxyRules: "*=http://default:3128,tracker.example.org=direct"})
+	rule, ok := router.RouteAny([]string{"udp://open.tracker.net:6969/announce", "http://tracker.example.org/announce"})
+	if !ok || rule.Domain != "tracker.example.org" {
+		t.Errorf("RouteAny() = %+v, %v; want the tracker.example.org rule", rule, ok)
+	}
+	if _, ok := router.RouteAny(nil); ok {
+		t.Error("RouteAny(nil) should not match")
+	}
+}
+
+func TestTransportUsesProxyRule(t *testing.T) {
+	proxySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// An HTTP proxy receives the absolute URL of the target.
+		_, _ = io.WriteString(w, "via proxy "+r.URL.Host)
+	}))
+	defer proxySrv.Close()
+
+	cfg := &config.Config{ProxyRules: "example.test=" + proxySrv.URL}
+	client := &http.Client{Transport: Transport(cfg)}
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://files.example.test/movie.mkv", http.NoBody)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("GET through proxy: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "via proxy files.example.test" {
+		t.Errorf("response = %q, want it served by the proxy", body)
+	}
+}
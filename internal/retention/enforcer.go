@@ -0,0 +1,178 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/deletion"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+)
+
+// StartEnforcer applies the retention policy every RETENTION_CHECK_INTERVAL (daily by default).
+// Does nothing when no limit is configured. Blocks until ctx is done.
+func StartEnforcer(ctx context.Context, a *app.App) {
+	policy := a.Config.GetRetentionSettings()
+	if !policy.Enabled() {
+		return
+	}
+	ticker := time.NewTicker(policy.CheckInterval)
+	defer ticker.Stop()
+
+	logutils.Log.WithFields(map[string]any{
+		"max_age":             policy.MaxAge,
+		"max_library_size_gb": policy.MaxLibrarySizeGB,
+		"min_free_space_gb":   policy.MinFreeSpaceGB,
+		"interval":            policy.CheckInterval,
+	}).Info("Starting retention enforcer")
+
+	// A bot restarted more often than the interval would otherwise never enforce.
+	Enforce(ctx, a, time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			logutils.Log.Info("Stopping retention enforcer")
+			return
+		case now := <-ticker.C:
+			Enforce(ctx, a, now)
+		}
+	}
+}
+
+// Enforce runs one pass: plans evictions, sends them to the delete queue (bypassing the trash when
+// the queue supports it), waits for the queue to delete them and notifies admins about the movies that
+// are actually gone. Returns those evictions.
+func Enforce(ctx context.Context, a *app.App, now time.Time) []Eviction {
+	movies, err := a.DB.GetMovieList(ctx)
+	if err != nil {
+		logutils.Log.WithError(err).Warn("Retention: GetMovieList failed")
+		return nil
+	}
+	movies = withoutBusy(a, movies)
+
+	space, err := app.GetDiskSpace(ctx, a)
+	if err != nil {
+		logutils.Log.WithError(err).Warn("Retention: failed to get disk space")
+		return nil
+	}
+	evictions := Plan(movies, a.Config.GetRetentionSettings(), space.AvailableBytes(), now)
+	if len(evictions) == 0 {
+		return nil
+	}
+
+	permanent, canSkipTrash := a.DeleteQueue.(deletion.PermanentQueue)
+	if !canSkipTrash {
+		// Without confirmation there is nothing reliable to report; the queue logs the outcome.
+		for i := range evictions {
+			logEviction(&evictions[i])
+			a.DeleteQueue.Enqueue(evictions[i].Movie.ID)
+		}
+		return nil
+	}
+
+	type result struct {
+		movieID uint
+		err     error
+	}
+	results := make(chan result, len(evictions))
+	for i := range evictions {
+		logEviction(&evictions[i])
+		permanent.EnqueuePermanent(evictions[i].Movie.ID, func(movieID uint, err error) {
+			results <- result{movieID: movieID, err: err}
+		})
+	}
+	deleted := make(map[uint]bool, len(evictions))
+	for range evictions {
+		select {
+		case r := <-results:
+			if r.err != nil {
+				logutils.Log.WithError(r.err).WithField("movie_id", r.movieID).Warn("Retention: eviction failed")
+				continue
+			}
+			deleted[r.movieID] = true
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	done := evictions[:0]
+	for i := range evictions {
+		if deleted[evictions[i].Movie.ID] {
+			done = append(done, evictions[i])
+		}
+	}
+	if len(done) == 0 {
+		return nil
+	}
+	notifyAdmins(ctx, a, done)
+	return done
+}
+
+func logEviction(e *Eviction) {
+	logutils.Log.WithFields(map[string]any{
+		"movie_id": e.Movie.ID,
+		"reason":   e.Reason,
+	}).Info("Retention: evicting movie")
+}
+
+// withoutBusy drops movies that are already being deleted or still have an active download job.
+func withoutBusy(a *app.App, movies []database.Movie) []database.Movie {
+	active := make(map[uint]struct{})
+	for _, id := range a.DownloadManager.GetActiveDownloads() {
+		active[id] = struct{}{}
+	}
+	out := movies[:0]
+	for i := range movies {
+		if _, ok := active[movies[i].ID]; ok {
+			continue
+		}
+		if a.DeleteQueue.IsPendingDeletion(movies[i].ID) {
+			continue
+		}
+		out = append(out, movies[i])
+	}
+	return out
+}
+
+func notifyAdmins(ctx context.Context, a *app.App, evictions []Eviction) {
+	admins, err := a.DB.GetUsersByRole(ctx, database.AdminRole)
+	if err != nil {
+		logutils.Log.WithError(err).Warn("Retention: failed to load admins for summary")
+		return
+	}
+	if len(admins) == 0 {
+		return
+	}
+	message := Summary(evictions)
+	for i := range admins {
+		a.Bot.SendMessage(admins[i].ChatID, message, nil)
+	}
+}
+
+// Summary is the admin report: evicted movies with the reason and the total space reclaimed.
+func Summary(evictions []Eviction) string {
+	var reclaimed int64
+	lines := make([]string, 0, len(evictions))
+	for i := range evictions {
+		m := &evictions[i].Movie
+		reclaimed += m.FileSize
+		lines = append(lines, lang.Translate("general.retention.item", map[string]any{
+			"Name":   m.Name,
+			"SizeGB": formatGB(m.FileSize),
+			"Reason": lang.Translate("general.retention.reason."+string(evictions[i].Reason), nil),
+		}))
+	}
+	header := lang.Translate("general.retention.summary", map[string]any{
+		"Count":       len(evictions),
+		"ReclaimedGB": formatGB(reclaimed),
+	})
+	return header + "\n" + strings.Join(lines, "\n")
+}
+
+func formatGB(size int64) string {
+	return fmt.Sprintf("%.2f", float64(size)/bytesPerGB)
+}
@@ -0,0 +1,90 @@
+package retention
+
+import (
+	"slices"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+)
+
+const (
+	bytesPerGB         = 1024 * 1024 * 1024
+	completePercentage = 100
+)
+
+// Reason explains why a movie was picked for eviction.
+type Reason string
+
+const (
+	ReasonMaxAge      Reason = "max_age"
+	ReasonLibrarySize Reason = "library_size"
+	ReasonFreeSpace   Reason = "free_space"
+)
+
+// Eviction is one movie selected by Plan.
+type Eviction struct {
+	Movie  database.Movie
+	Reason Reason
+}
+
+// Plan selects movies to evict under the policy. Only finished, unpinned movies that are not being
+// converted are candidates, oldest-completed first: everything completed more than MaxAge ago, then more while the library
+// exceeds MaxLibrarySizeGB, then more while freeBytes (plus what was already selected) is below MinFreeSpaceGB.
+func Plan(movies []database.Movie, policy config.RetentionConfig, freeBytes int64, now time.Time) []Eviction {
+	var librarySize int64
+	candidates := make([]database.Movie, 0, len(movies))
+	for i := range movies {
+		librarySize += movies[i].FileSize
+		if isCandidate(&movies[i]) {
+			candidates = append(candidates, movies[i])
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b database.Movie) int {
+		return completedAt(&a).Compare(completedAt(&b))
+	})
+
+	var evictions []Eviction
+	next := 0
+	evict := func(reason Reason) {
+		m := candidates[next]
+		evictions = append(evictions, Eviction{Movie: m, Reason: reason})
+		librarySize -= m.FileSize
+		freeBytes += m.FileSize
+		next++
+	}
+
+	if policy.MaxAge > 0 {
+		for next < len(candidates) && now.Sub(completedAt(&candidates[next])) > policy.MaxAge {
+			evict(ReasonMaxAge)
+		}
+	}
+	if policy.MaxLibrarySizeGB > 0 {
+		limit := int64(policy.MaxLibrarySizeGB * bytesPerGB)
+		for next < len(candidates) && librarySize > limit {
+			evict(ReasonLibrarySize)
+		}
+	}
+	if policy.MinFreeSpaceGB > 0 {
+		watermark := int64(policy.MinFreeSpaceGB * bytesPerGB)
+		for next < len(candidates) && freeBytes < watermark {
+			evict(ReasonFreeSpace)
+		}
+	}
+	return evictions
+}
+
+// completedAt is when the download finished; movies completed before that was recorded use CreatedAt.
+func completedAt(m *database.Movie) time.Time {
+	if m.CompletedAt != nil {
+		return *m.CompletedAt
+	}
+	return m.CreatedAt
+}
+
+func isCandidate(m *database.Movie) bool {
+	if m.Pinned || m.IsTrashed() || m.DownloadedPercentage < completePercentage {
+		return false
+	}
+	return m.ExtractionStatus != "in_progress" && m.ConversionStatus != "pending" && m.ConversionStatus != "in_progress"
+}
@@ -0,0 +1,168 @@
+package retention
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+)
+
+const gb = bytesPerGB
+
+func testMovie(id uint, ageDays int, sizeGB int64, now time.Time) database.Movie {
+	return database.Movie{
+		ID:                   id,
+		Name:                 "Movie",
+		DownloadedPercentage: 100,
+		FileSize:             sizeGB * gb,
+		CreatedAt:            now.Add(-time.Duration(ageDays) * 24 * time.Hour),
+	}
+}
+
+func evictedIDs(evictions []Eviction) []uint {
+	ids := make([]uint, 0, len(evictions))
+	for i := range evictions {
+		ids = append(ids, evictions[i].Movie.ID)
+	}
+	return ids
+}
+
+func equalIDs(a, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPlan(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	pinned := testMovie(1, 60, 10, now)
+	pinned.Pinned = true
+	downloading := testMovie(2, 50, 10, now)
+	downloading.DownloadedPercentage = 40
+	converting := testMovie(3, 45, 10, now)
+	converting.ConversionStatus = "in_progress"
+
+	movies := []database.Movie{
+		testMovie(10, 5, 10, now),
+		pinned,
+		testMovie(11, 40, 10, now),
+		downloading,
+		testMovie(12, 20, 10, now),
+		converting,
+		testMovie(13, 1, 10, now),
+	}
+
+	tests := []struct {
+		name      string
+		policy    config.RetentionConfig
+		freeBytes int64
+		want      []uint
+	}{
+		{
+			name:   "disabled policy evicts nothing",
+			policy: config.RetentionConfig{},
+			want:   []uint{},
+		},
+		{
+			name:   "max age evicts only old unpinned finished movies",
+			policy: config.RetentionConfig{MaxAge: 10 * 24 * time.Hour},
+			want:   []uint{11, 12},
+		},
+		{
+			name:   "library size evicts oldest first until under the limit",
+			policy: config.RetentionConfig{MaxLibrarySizeGB: 45},
+			// library is 70 GB; 11 and 12 bring it to 50, 10 to 40
+			want: []uint{11, 12, 10},
+		},
+		{
+			name:      "low watermark counts space freed by earlier evictions",
+			policy:    config.RetentionConfig{MaxAge: 30 * 24 * time.Hour, MinFreeSpaceGB: 25},
+			freeBytes: 5 * gb,
+			// 11 is too old; 12 then brings free space to exactly 25 GB
+			want: []uint{11, 12},
+		},
+		{
+			name:      "enough free space",
+			policy:    config.RetentionConfig{MinFreeSpaceGB: 25},
+			freeBytes: 30 * gb,
+			want:      []uint{},
+		},
+		{
+			name:      "runs out of candidates",
+			policy:    config.RetentionConfig{MinFreeSpaceGB: 1000},
+			freeBytes: 0,
+			want:      []uint{11, 12, 10, 13},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evictedIDs(Plan(movies, tt.policy, tt.freeBytes, now))
+			if !equalIDs(got, tt.want) {
+				t.Errorf("Plan() evicted %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanReasons(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	movies := []database.Movie{testMovie(1, 40, 10, now), testMovie(2, 5, 10, now)}
+	policy := config.RetentionConfig{MaxAge: 30 * 24 * time.Hour, MinFreeSpaceGB: 25}
+
+	evictions := Plan(movies, policy, 10*gb, now)
+	if len(evictions) != 2 {
+		t.Fatalf("len(Plan()) = %d, want 2", len(evictions))
+	}
+	if evictions[0].Reason != ReasonMaxAge || evictions[1].Reason != ReasonFreeSpace {
+		t.Errorf("reasons = %s, %s; want %s, %s", evictions[0].Reason, evictions[1].Reason, ReasonMaxAge, ReasonFreeSpace)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	evictions := []Eviction{
+		{Movie: database.Movie{Name: "Old Movie", FileSize: 2 * gb}, Reason: ReasonMaxAge},
+		{Movie: database.Movie{Name: "Big Movie", FileSize: 3 * gb}, Reason: ReasonFreeSpace},
+	}
+	summary := Summary(evictions)
+	for _, want := range []string{"Old Movie", "Big Movie", "5.00", "too old", "low disk space"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Summary() = %q, want it to contain %q", summary, want)
+		}
+	}
+}
+
+func TestPlanAgesFromCompletion(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(d int) *time.Time {
+		at := now.Add(-time.Duration(d) * 24 * time.Hour)
+		return &at
+	}
+	// Added long ago but only finished yesterday (slow or queued download).
+	slow := testMovie(1, 40, 10, now)
+	slow.CompletedAt = daysAgo(1)
+	// Added and finished two weeks ago.
+	finished := testMovie(2, 14, 10, now)
+	finished.CompletedAt = daysAgo(14)
+	// Finished before completion times were recorded: aged from CreatedAt.
+	legacy := testMovie(3, 20, 10, now)
+	movies := []database.Movie{slow, finished, legacy}
+
+	got := evictedIDs(Plan(movies, config.RetentionConfig{MaxAge: 10 * 24 * time.Hour}, 0, now))
+	if want := []uint{3, 2}; !equalIDs(got, want) {
+		t.Errorf("max age evicted %v, want %v", got, want)
+	}
+	got = evictedIDs(Plan(movies, config.RetentionConfig{MinFreeSpaceGB: 1000}, 0, now))
+	if want := []uint{3, 2, 1}; !equalIDs(got, want) {
+		t.Errorf("oldest-completed order = %v, want %v", got, want)
+	}
+}
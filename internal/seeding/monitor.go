@@ -0,0 +1,146 @@
+package seeding
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/archive"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/qbittorrent"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/tvcompat"
+)
+
+// StartMonitor checks the torrents kept in qBittorrent for seeding every SEED_CHECK_INTERVAL and removes
+// them (keeping the files) once their rule is met. Does nothing without qBittorrent. Blocks until ctx is done.
+func StartMonitor(ctx context.Context, cfg *config.Config, db database.Database) {
+	if cfg.QBittorrentURL == "" {
+		return
+	}
+	settings := cfg.GetSeedingSettings()
+	ticker := time.NewTicker(settings.CheckInterval)
+	defer ticker.Stop()
+
+	logutils.Log.WithFields(map[string]any{
+		"ratio":    settings.Ratio,
+		"time":     settings.Time,
+		"trackers": settings.TrackerRules,
+		"interval": settings.CheckInterval,
+	}).Info("Starting seeding monitor")
+
+	for {
+		select {
+		case <-ctx.Done():
+			logutils.Log.Info("Stopping seeding monitor")
+			return
+		case <-ticker.C:
+			Check(ctx, cfg, db)
+		}
+	}
+}
+
+// Check runs one pass over the seeding movies: refreshes their ratio and removes the torrents whose rule
+// is met (or whose seed toggle was switched off) from qBittorrent. Returns the movies that stopped seeding.
+func Check(ctx context.Context, cfg *config.Config, db database.Database) []uint {
+	if cfg.QBittorrentURL == "" {
+		return nil
+	}
+	movies, err := db.GetMovieList(ctx)
+	if err != nil {
+		logutils.Log.WithError(err).Warn("Seeding: GetMovieList failed")
+		return nil
+	}
+	var seeding []database.Movie
+	hashes := make([]string, 0, len(movies))
+	for i := range movies {
+		if movies[i].Seeding && movies[i].QBittorrentHash != "" && isQBittorrent(&movies[i]) {
+			seeding = append(seeding, movies[i])
+			hashes = append(hashes, movies[i].QBittorrentHash)
+		}
+	}
+	if len(seeding) == 0 {
+		return nil
+	}
+
+	client, err := qbittorrent.NewClient(cfg.QBittorrentURL, cfg.QBittorrentUsername, cfg.QBittorrentPassword)
+	if err != nil {
+		logutils.Log.WithError(err).Warn("Seeding: failed to create qBittorrent client")
+		return nil
+	}
+	if err = client.Login(ctx); err != nil {
+		logutils.Log.WithError(err).Warn("Seeding: qBittorrent login failed")
+		return nil
+	}
+	list, err := client.TorrentsInfo(ctx, strings.Join(hashes, "|"), "", false)
+	if err != nil {
+		logutils.Log.WithError(err).Warn("Seeding: failed to get torrents from qBittorrent")
+		return nil
+	}
+	byHash := make(map[string]*qbittorrent.TorrentInfo, len(list))
+	for i := range list {
+		byHash[strings.ToLower(list[i].Hash)] = &list[i]
+	}
+
+	policy := NewPolicy(cfg.GetSeedingSettings())
+	var stopped []uint
+	for i := range seeding {
+		movie := &seeding[i]
+		t, ok := byHash[strings.ToLower(movie.QBittorrentHash)]
+		if !ok {
+			// Removed from qBittorrent by hand; nothing left to monitor.
+			logutils.Log.WithField("movie_id", movie.ID).Info("Seeding: torrent is no longer in qBittorrent")
+			if stopSeeding(ctx, cfg, db, movie, movie.SeedRatio) {
+				stopped = append(stopped, movie.ID)
+			}
+			continue
+		}
+		seedingTime := time.Duration(t.SeedingTime) * time.Second
+		if !policy.Done(movie.SeedMode, t.Tracker, t.Ratio, seedingTime) {
+			if err := db.UpdateSeedingState(ctx, movie.ID, true, t.Ratio); err != nil {
+				logutils.Log.WithError(err).WithField("movie_id", movie.ID).Warn("Seeding: failed to store ratio")
+			}
+			continue
+		}
+		if err := client.DeleteTorrent(ctx, movie.QBittorrentHash, false); err != nil {
+			logutils.Log.WithError(err).WithField("movie_id", movie.ID).Warn("Seeding: failed to remove torrent from qBittorrent")
+			continue
+		}
+		logutils.Log.WithFields(map[string]any{
+			"movie_id":     movie.ID,
+			"ratio":        t.Ratio,
+			"seeding_time": seedingTime,
+		}).Info("Seeding: rule met, removed torrent from qBittorrent")
+		if stopSeeding(ctx, cfg, db, movie, t.Ratio) {
+			stopped = append(stopped, movie.ID)
+		}
+	}
+	return stopped
+}
+
+// stopSeeding marks the movie as no longer seeding and deletes the archives kept for the torrent after extraction
+// and the originals kept after a TV compatibility transcode.
+func stopSeeding(ctx context.Context, cfg *config.Config, db database.Database, movie *database.Movie, ratio float64) bool {
+	if err := db.UpdateSeedingState(ctx, movie.ID, false, ratio); err != nil {
+		logutils.Log.WithError(err).WithField("movie_id", movie.ID).Warn("Seeding: failed to store seeding state")
+		return false
+	}
+	if movie.ExtractionStatus == "done" {
+		if err := archive.RemoveArchives(ctx, movie.ID, cfg.MoviePath, db); err != nil {
+			logutils.Log.WithError(err).WithField("movie_id", movie.ID).Warn("Seeding: failed to delete extracted archives")
+		}
+	}
+	if movie.ConversionStatus == "done" {
+		if err := tvcompat.RemoveTranscodedOriginals(ctx, movie.ID, cfg.MoviePath, db); err != nil {
+			logutils.Log.WithError(err).WithField("movie_id", movie.ID).Warn("Seeding: failed to delete transcoded originals")
+		}
+	}
+	return true
+}
+
+// isQBittorrent reports whether the movie's torrent is in qBittorrent; rows stored before the client was
+// recorded were downloaded by the configured one, which Check requires to be qBittorrent.
+func isQBittorrent(movie *database.Movie) bool {
+	return movie.TorrentClient == "" || movie.TorrentClient == config.TorrentClientQBittorrent
+}
@@ -0,0 +1,84 @@
+package seeding
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/qbittorrent"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/testutils"
+)
+
+func TestCheck(t *testing.T) {
+	logutils.InitLogger("error")
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var deleted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/auth/login":
+			_, _ = w.Write([]byte("Ok."))
+		case "/api/v2/torrents/info":
+			_ = json.NewEncoder(w).Encode([]qbittorrent.TorrentInfo{
+				{Hash: "aaa", Ratio: 2.5, SeedingTime: 3600, Tracker: "https://tracker.example.org/announce"},
+				{Hash: "bbb", Ratio: 0.3, SeedingTime: 3600, Tracker: "udp://open.tracker.net:80"},
+				{Hash: "ddd", Ratio: 0.1, SeedingTime: 60},
+			})
+		case "/api/v2/torrents/delete":
+			mu.Lock()
+			deleted = append(deleted, r.FormValue("hashes"))
+			mu.Unlock()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	db := testutils.TestDatabase(t)
+	add := func(name, hash, mode string) uint {
+		id, err := db.AddMovie(ctx, name, 1024, []string{name + ".mkv"}, nil, 0)
+		if err != nil {
+			t.Fatalf("AddMovie: %v", err)
+		}
+		_ = db.SetTorrentClientHash(ctx, id, config.TorrentClientQBittorrent, hash)
+		_ = db.SetMovieSeedMode(ctx, id, mode)
+		_ = db.UpdateSeedingState(ctx, id, true, 0)
+		return id
+	}
+	ratioMet := add("ratio-met", "aaa", "")
+	below := add("below", "bbb", "")
+	gone := add("gone", "ccc", "")
+	switchedOff := add("switched-off", "ddd", ModeOff)
+
+	cfg := &config.Config{
+		QBittorrentURL:  srv.URL,
+		SeedingSettings: config.SeedingConfig{Ratio: 1, TrackerRules: "tracker.example.org=2"},
+	}
+	stopped := Check(ctx, cfg, db)
+	slices.Sort(stopped)
+	if want := []uint{ratioMet, gone, switchedOff}; !slices.Equal(stopped, want) {
+		t.Errorf("stopped = %v, want %v", stopped, want)
+	}
+	slices.Sort(deleted)
+	if want := []string{"aaa", "ddd"}; !slices.Equal(deleted, want) {
+		t.Errorf("deleted torrents = %v, want %v", deleted, want)
+	}
+
+	movie, err := db.GetMovieByID(ctx, below)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !movie.Seeding || movie.SeedRatio != 0.3 {
+		t.Errorf("below limits: seeding=%v ratio=%v, want still seeding with ratio 0.3", movie.Seeding, movie.SeedRatio)
+	}
+	if movie, _ = db.GetMovieByID(ctx, ratioMet); movie.Seeding || movie.SeedRatio != 2.5 {
+		t.Errorf("ratio met: seeding=%v ratio=%v, want stopped with ratio 2.5", movie.Seeding, movie.SeedRatio)
+	}
+}
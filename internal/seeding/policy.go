@@ -0,0 +1,82 @@
+package seeding
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+)
+
+// Per-movie seeding toggles stored in Movie.SeedMode; "" follows the policy.
+const (
+	ModeOn  = "on"  // seed until the toggle is switched off, ignoring the limits
+	ModeOff = "off" // remove the torrent from the client as soon as it completes
+)
+
+// Policy decides whether a completed torrent keeps seeding, from SEED_RATIO / SEED_TIME and the
+// per-tracker overrides in SEED_TRACKER_RULES.
+type Policy struct {
+	global config.SeedRule
+	rules  []config.SeedRule
+}
+
+// NewPolicy builds the policy from the seeding settings; invalid tracker rules are rejected by config
+// validation and ignored here.
+func NewPolicy(s config.SeedingConfig) Policy {
+	rules, _ := s.ParseTrackerRules()
+	return Policy{global: config.SeedRule{Ratio: s.Ratio, Time: s.Time}, rules: rules}
+}
+
+// Rule returns the limits for the tracker URL: the most specific matching tracker rule, else the global one.
+func (p Policy) Rule(tracker string) config.SeedRule {
+	host := trackerHost(tracker)
+	best := -1
+	for i := range p.rules {
+		r := p.rules[i].Tracker
+		if host != r && !strings.HasSuffix(host, "."+r) {
+			continue
+		}
+		if best < 0 || len(r) > len(p.rules[best].Tracker) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return p.global
+	}
+	return p.rules[best]
+}
+
+// Keep reports whether a torrent that just completed stays in the client to seed.
+func (p Policy) Keep(mode, tracker string) bool {
+	switch mode {
+	case ModeOn:
+		return true
+	case ModeOff:
+		return false
+	}
+	r := p.Rule(tracker)
+	return r.Ratio > 0 || r.Time > 0
+}
+
+// Done reports whether a seeding torrent has met its rule and can be removed from the client.
+func (p Policy) Done(mode, tracker string, ratio float64, seedingTime time.Duration) bool {
+	switch mode {
+	case ModeOn:
+		return false
+	case ModeOff:
+		return true
+	}
+	r := p.Rule(tracker)
+	if r.Ratio <= 0 && r.Time <= 0 {
+		return true
+	}
+	return (r.Ratio > 0 && ratio >= r.Ratio) || (r.Time > 0 && seedingTime >= r.Time)
+}
+
+func trackerHost(tracker string) string {
+	if u, err := url.Parse(strings.TrimSpace(tracker)); err == nil && u.Host != "" {
+		return strings.ToLower(u.Hostname())
+	}
+	return strings.ToLower(strings.TrimSpace(tracker))
+}
@@ -0,0 +1,63 @@
+package seeding
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+)
+
+func TestPolicyRule(t *testing.T) {
+	p := NewPolicy(config.SeedingConfig{
+		Ratio:        1,
+		TrackerRules: "example.org=3,tracker.example.org=2/72h",
+	})
+	cases := []struct {
+		tracker string
+		want    config.SeedRule
+	}{
+		{"https://tracker.example.org/announce?passkey=x", config.SeedRule{Tracker: "tracker.example.org", Ratio: 2, Time: 72 * time.Hour}},
+		{"udp://bt.example.org:6969/announce", config.SeedRule{Tracker: "example.org", Ratio: 3}},
+		{"http://notexample.org/announce", config.SeedRule{Ratio: 1}},
+		{"", config.SeedRule{Ratio: 1}},
+	}
+	for _, tc := range cases {
+		if got := p.Rule(tc.tracker); got != tc.want {
+			t.Errorf("Rule(%q) = %+v, want %+v", tc.tracker, got, tc.want)
+		}
+	}
+}
+
+func TestPolicyKeepAndDone(t *testing.T) {
+	const tracker = "https://tracker.example.org/announce"
+	limited := NewPolicy(config.SeedingConfig{Ratio: 2, Time: 48 * time.Hour})
+	unlimited := NewPolicy(config.SeedingConfig{TrackerRules: "other.net=1"})
+
+	if !limited.Keep("", tracker) || limited.Keep(ModeOff, tracker) {
+		t.Error("limited policy: want keep by default and not with the toggle off")
+	}
+	if unlimited.Keep("", tracker) || !unlimited.Keep(ModeOn, tracker) {
+		t.Error("policy without limits for the tracker: want keep only with the toggle on")
+	}
+
+	cases := []struct {
+		name  string
+		p     Policy
+		mode  string
+		ratio float64
+		time  time.Duration
+		want  bool
+	}{
+		{"below limits", limited, "", 1.5, time.Hour, false},
+		{"ratio reached", limited, "", 2, time.Hour, true},
+		{"time reached", limited, "", 0.1, 48 * time.Hour, true},
+		{"toggle on ignores limits", limited, ModeOn, 10, 100 * time.Hour, false},
+		{"toggle off", limited, ModeOff, 0, 0, true},
+		{"no limits", unlimited, "", 0, 0, true},
+	}
+	for _, tc := range cases {
+		if got := tc.p.Done(tc.mode, tracker, tc.ratio, tc.time); got != tc.want {
+			t.Errorf("%s: Done() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package subscriptions
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	ytdlp "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/video"
+)
+
+const dateLayout = "20060102"
+
+// Skip reasons for entries that do not pass the subscription filters.
+const (
+	skipTitle    = "title"
+	skipDuration = "duration"
+	skipDate     = "date"
+)
+
+// Validate checks the URL and filters of sub and normalizes DateAfter to YYYYMMDD.
+func Validate(sub *database.Subscription) error {
+	sub.URL = strings.TrimSpace(sub.URL)
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an http(s) channel or playlist link")
+	}
+	if sub.TitleRegex != "" {
+		if _, err := regexp.Compile(sub.TitleRegex); err != nil {
+			return fmt.Errorf("invalid title regex: %w", err)
+		}
+	}
+	if sub.MaxDurationSec < 0 {
+		return errors.New("max duration must not be negative")
+	}
+	if sub.DateAfter != "" {
+		date, err := NormalizeDate(sub.DateAfter)
+		if err != nil {
+			return err
+		}
+		sub.DateAfter = date
+	}
+	return nil
+}
+
+// NormalizeDate accepts YYYYMMDD or YYYY-MM-DD and returns YYYYMMDD (yt-dlp's upload_date format).
+func NormalizeDate(s string) (string, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), "-", "")
+	if _, err := time.Parse(dateLayout, s); err != nil {
+		return "", fmt.Errorf("invalid date %q, expected YYYY-MM-DD or YYYYMMDD", s)
+	}
+	return s, nil
+}
+
+// ParseMaxDuration accepts minutes ("45") or a Go duration ("1h30m") and returns seconds.
+func ParseMaxDuration(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if minutes, err := strconv.Atoi(s); err == nil && minutes >= 0 {
+		return minutes * 60, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q, expected minutes or e.g. 1h30m", s)
+	}
+	return int(d.Seconds()), nil
+}
+
+// needsDetails reports whether the duration or date filter cannot be decided from flat metadata.
+func needsDetails(sub *database.Subscription, entry *ytdlp.FeedEntry) bool {
+	return (sub.MaxDurationSec > 0 && entry.Duration <= 0) || (sub.DateAfter != "" && entry.UploadDate == "")
+}
+
+// skipReason returns why entry does not pass the filters of sub, or "" when it should be downloaded.
+// Unknown durations and dates pass: yt-dlp does not report them for every site.
+func skipReason(sub *database.Subscription, entry *ytdlp.FeedEntry) string {
+	if sub.TitleRegex != "" {
+		re, err := regexp.Compile(sub.TitleRegex)
+		if err == nil && !re.MatchString(entry.Title) {
+			return skipTitle
+		}
+	}
+	if sub.MaxDurationSec > 0 && entry.Duration > float64(sub.MaxDurationSec) {
+		return skipDuration
+	}
+	if sub.DateAfter != "" && entry.UploadDate != "" && entry.UploadDate < sub.DateAfter {
+		return skipDate
+	}
+	return ""
+}
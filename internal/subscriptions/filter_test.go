@@ -0,0 +1,72 @@
+package subscriptions
+
+import (
+	"testing"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	ytdlp "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/video"
+)
+
+func TestValidate(t *testing.T) {
+	sub := &database.Subscription{URL: " https://www.youtube.com/@channel/videos ", DateAfter: "2024-03-01"}
+	if err := Validate(sub); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if sub.URL != "https://www.youtube.com/@channel/videos" || sub.DateAfter != "20240301" {
+		t.Errorf("not normalized: %+v", sub)
+	}
+
+	invalid := []database.Subscription{
+		{URL: "ftp://example.com/list"},
+		{URL: "not a url"},
+		{URL: "https://example.com/list", TitleRegex: "("},
+		{URL: "https://example.com/list", MaxDurationSec: -1},
+		{URL: "https://example.com/list", DateAfter: "2024-13-01"},
+	}
+	for i := range invalid {
+		if err := Validate(&invalid[i]); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", invalid[i])
+		}
+	}
+}
+
+func TestParseMaxDuration(t *testing.T) {
+	tests := map[string]int{"45": 2700, "1h30m": 5400, "90s": 90, "0": 0}
+	for in, want := range tests {
+		got, err := ParseMaxDuration(in)
+		if err != nil || got != want {
+			t.Errorf("ParseMaxDuration(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "abc", "-5m"} {
+		if _, err := ParseMaxDuration(in); err == nil {
+			t.Errorf("ParseMaxDuration(%q) = nil error", in)
+		}
+	}
+}
+
+func TestSkipReason(t *testing.T) {
+	sub := &database.Subscription{TitleRegex: "(?i)episode", MaxDurationSec: 600, DateAfter: "20240301"}
+	tests := []struct {
+		entry ytdlp.FeedEntry
+		want  string
+	}{
+		{ytdlp.FeedEntry{Title: "Episode 3", Duration: 300, UploadDate: "20240302"}, ""},
+		{ytdlp.FeedEntry{Title: "Trailer", Duration: 300, UploadDate: "20240302"}, skipTitle},
+		{ytdlp.FeedEntry{Title: "Episode 4", Duration: 3600, UploadDate: "20240302"}, skipDuration},
+		{ytdlp.FeedEntry{Title: "Episode 1", Duration: 300, UploadDate: "20240101"}, skipDate},
+		{ytdlp.FeedEntry{Title: "Episode 5"}, ""},
+	}
+	for _, tt := range tests {
+		if got := skipReason(sub, &tt.entry); got != tt.want {
+			t.Errorf("skipReason(%+v) = %q, want %q", tt.entry, got, tt.want)
+		}
+	}
+
+	if !needsDetails(sub, &ytdlp.FeedEntry{Title: "Episode 5"}) {
+		t.Error("needsDetails = false for entry without duration and date")
+	}
+	if needsDetails(&database.Subscription{TitleRegex: "x"}, &ytdlp.FeedEntry{}) {
+		t.Error("needsDetails = true without duration and date filters")
+	}
+}
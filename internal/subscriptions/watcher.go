@@ -0,0 +1,194 @@
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/factory"
+	ytdlp "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/video"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/lang"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+)
+
+const (
+	// feedLimit is how many of the newest entries are looked at on each check.
+	feedLimit    = 30
+	checkTimeout = 5 * time.Minute
+)
+
+// checkMu serializes checks so the periodic pass and the first check after /subscribe never race
+// on the same archive.
+var checkMu sync.Mutex
+
+// startFunc starts the download of one new entry; replaced in tests.
+type startFunc func(ctx context.Context, a *app.App, sub *database.Subscription, entry *ytdlp.FeedEntry) error
+
+// StartWatcher checks all subscriptions every SUBSCRIPTION_CHECK_INTERVAL. Does nothing when the
+// interval is 0. Blocks until ctx is done.
+func StartWatcher(ctx context.Context, a *app.App) {
+	interval := a.Config.SubscriptionCheckInterval
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logutils.Log.WithField("interval", interval).Info("Starting subscription watcher")
+	for {
+		select {
+		case <-ctx.Done():
+			logutils.Log.Info("Stopping subscription watcher")
+			return
+		case <-ticker.C:
+			CheckAll(ctx, a)
+		}
+	}
+}
+
+// CheckAll checks every subscription once.
+func CheckAll(ctx context.Context, a *app.App) {
+	subs, err := a.DB.GetSubscriptions(ctx)
+	if err != nil {
+		logutils.Log.WithError(err).Warn("Subscriptions: GetSubscriptions failed")
+		return
+	}
+	for i := range subs {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := Check(ctx, a, &subs[i]); err != nil {
+			logutils.Log.WithError(err).WithFields(map[string]any{
+				"subscription_id": subs[i].ID,
+				"url":             subs[i].URL,
+			}).Warn("Subscription check failed")
+		}
+	}
+}
+
+// Create validates and stores sub, then runs its first check in the background.
+func Create(ctx context.Context, a *app.App, sub *database.Subscription) error {
+	if err := Validate(sub); err != nil {
+		return err
+	}
+	if err := a.DB.AddSubscription(ctx, sub); err != nil {
+		return fmt.Errorf("save subscription: %w", err)
+	}
+	created := *sub
+	go func() {
+		if _, err := Check(context.Background(), a, &created); err != nil {
+			logutils.Log.WithError(err).WithField("subscription_id", created.ID).Warn("First subscription check failed")
+		}
+	}()
+	return nil
+}
+
+// Check fetches the newest entries of sub and starts downloads for those not in its archive.
+// Returns the started entries.
+func Check(ctx context.Context, a *app.App, sub *database.Subscription) ([]ytdlp.FeedEntry, error) {
+	return check(ctx, a, sub, startEntry)
+}
+
+func check(ctx context.Context, a *app.App, sub *database.Subscription, start startFunc) ([]ytdlp.FeedEntry, error) {
+	checkMu.Lock()
+	defer checkMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	feed, err := ytdlp.FetchFeed(ctx, sub.URL, feedLimit, a.Config)
+	if err != nil {
+		return nil, err
+	}
+	seen, err := a.DB.GetSubscriptionVideoIDs(ctx, sub.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without a date filter the first check only records what is already there, so subscribing to
+	// a channel does not download its back catalogue.
+	baseline := sub.LastCheckedAt == nil && sub.DateAfter == ""
+	var archived []string
+	var started []ytdlp.FeedEntry
+	for i := range feed.Entries {
+		entry := &feed.Entries[i]
+		if _, ok := seen[entry.ID]; ok {
+			continue
+		}
+		if baseline {
+			archived = append(archived, entry.ID)
+			continue
+		}
+		if needsDetails(sub, entry) {
+			if err := ytdlp.FetchEntryDetails(ctx, entry, a.Config); err != nil {
+				logutils.Log.WithError(err).WithField("url", entry.URL).Warn("Subscriptions: failed to fetch entry details, will retry")
+				continue
+			}
+		}
+		if reason := skipReason(sub, entry); reason != "" {
+			logutils.Log.WithFields(map[string]any{
+				"subscription_id": sub.ID,
+				"entry":           entry.Title,
+				"reason":          reason,
+			}).Debug("Subscriptions: entry filtered out")
+			archived = append(archived, entry.ID)
+			continue
+		}
+		if err := start(ctx, a, sub, entry); err != nil {
+			if errors.Is(err, app.ErrAlreadyExists) {
+				archived = append(archived, entry.ID)
+				continue
+			}
+			// Not archived: e.g. not enough space now, retried on the next check.
+			logutils.Log.WithError(err).WithField("url", entry.URL).Warn("Subscriptions: failed to start download")
+			continue
+		}
+		archived = append(archived, entry.ID)
+		started = append(started, *entry)
+	}
+
+	if err := a.DB.AddSubscriptionVideoIDs(ctx, sub.ID, archived); err != nil {
+		return started, fmt.Errorf("update subscription archive: %w", err)
+	}
+	title := feed.Title
+	if title == "" {
+		title = sub.Title
+	}
+	if err := a.DB.MarkSubscriptionChecked(ctx, sub.ID, title, time.Now()); err != nil {
+		return started, err
+	}
+	logutils.Log.WithFields(map[string]any{
+		"subscription_id": sub.ID,
+		"title":           title,
+		"baseline":        baseline,
+		"archived":        len(archived),
+		"started":         len(started),
+	}).Info("Subscription checked")
+	return started, nil
+}
+
+// startEntry starts the download through the download manager and tells the subscribers.
+func startEntry(ctx context.Context, a *app.App, sub *database.Subscription, entry *ytdlp.FeedEntry) error {
+	dl, err := factory.CreateDownloaderFromURL(ctx, entry.URL, a.Config.MoviePath, a.Config)
+	if err != nil {
+		return err
+	}
+	_, err = app.StartAndNotify(ctx, a, dl, app.RecipientChats(ctx, a, sub.ChatID), func(title string) string {
+		return lang.Translate("general.subscriptions.new_item", map[string]any{
+			"Subscription": displayName(sub),
+			"Title":        title,
+		})
+	})
+	return err
+}
+
+func displayName(sub *database.Subscription) string {
+	if sub.Title != "" {
+		return sub.Title
+	}
+	return sub.URL
+}
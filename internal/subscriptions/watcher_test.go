@@ -0,0 +1,118 @@
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/app"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	ytdlp "github.com/NikitaDmitryuk/telegram-media-server/internal/downloader/video"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/testutils"
+)
+
+// writeFeed makes the fake yt-dlp list the given entries for -J.
+func writeFeed(t *testing.T, path string, entries ...string) {
+	t.Helper()
+	feed := `{"_type": "playlist", "title": "Test Channel", "entries": [` + strings.Join(entries, ",") + `]}`
+	if err := os.WriteFile(path, []byte(feed), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func entryJSON(id, title string) string {
+	return `{"_type": "url", "id": "` + id + `", "url": "https://example.com/watch?v=` + id + `", "title": "` + title + `"}`
+}
+
+func TestCheckBaselineThenNewEntries(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping fake yt-dlp script test on Windows")
+	}
+	tempDir := testutils.TempDir(t)
+	cfg := testutils.TestConfig(tempDir)
+	feedPath := filepath.Join(tempDir, "feed.json")
+
+	// Fake yt-dlp: -J prints the feed file, -j reports a 5 minute video uploaded in March 2024.
+	script := "#!/bin/sh\n" +
+		"for a in \"$@\"; do\n" +
+		"  case \"$a\" in\n" +
+		"    -J) cat '" + feedPath + "'; exit 0;;\n" +
+		"    -j) echo '{\"duration\": 300, \"upload_date\": \"20240302\"}'; exit 0;;\n" +
+		"  esac\n" +
+		"done\n" +
+		"exit 1\n"
+	cfg.YtdlpPath = filepath.Join(tempDir, "yt-dlp")
+	if err := os.WriteFile(cfg.YtdlpPath, []byte(script), 0o700); err != nil { // #nosec G306 -- test script must be executable
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	a := &app.App{DB: testutils.TestDatabase(t), Config: cfg}
+	sub := &database.Subscription{URL: "https://example.com/channel", TitleRegex: "Episode", MaxDurationSec: 600}
+	if err := a.DB.AddSubscription(ctx, sub); err != nil {
+		t.Fatal(err)
+	}
+
+	var startedIDs []string
+	failing := map[string]bool{"e5": true}
+	start := func(_ context.Context, _ *app.App, _ *database.Subscription, entry *ytdlp.FeedEntry) error {
+		if failing[entry.ID] {
+			return errors.New("not enough space")
+		}
+		startedIDs = append(startedIDs, entry.ID)
+		return nil
+	}
+	reload := func() *database.Subscription {
+		s, err := a.DB.GetSubscriptionByID(ctx, sub.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &s
+	}
+
+	writeFeed(t, feedPath, entryJSON("e2", "Episode 2"), entryJSON("e1", "Episode 1"))
+	if _, err := check(ctx, a, sub, start); err != nil {
+		t.Fatalf("first check: %v", err)
+	}
+	if len(startedIDs) != 0 {
+		t.Fatalf("baseline check started %v", startedIDs)
+	}
+	sub = reload()
+	if sub.LastCheckedAt == nil || sub.Title != "Test Channel" {
+		t.Fatalf("subscription not marked checked: %+v", sub)
+	}
+
+	writeFeed(t, feedPath,
+		entryJSON("e5", "Episode 5"), entryJSON("e4", "Trailer"), entryJSON("e3", "Episode 3"), entryJSON("e2", "Episode 2"))
+	started, err := check(ctx, a, sub, start)
+	if err != nil {
+		t.Fatalf("second check: %v", err)
+	}
+	if len(started) != 1 || started[0].ID != "e3" || started[0].Duration != 300 {
+		t.Fatalf("second check started %+v, want e3 with details", started)
+	}
+	seen, err := a.DB.GetSubscriptionVideoIDs(ctx, sub.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"e1", "e2", "e3", "e4"} {
+		if _, ok := seen[id]; !ok {
+			t.Errorf("%s not archived", id)
+		}
+	}
+	if _, ok := seen["e5"]; ok {
+		t.Error("entry that failed to start must not be archived")
+	}
+
+	failing["e5"] = false
+	if _, err := check(ctx, a, reload(), start); err != nil {
+		t.Fatalf("third check: %v", err)
+	}
+	if len(startedIDs) != 2 || startedIDs[1] != "e5" {
+		t.Fatalf("started = %v, want [e3 e5]", startedIDs)
+	}
+}
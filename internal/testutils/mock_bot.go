@@ -1,6 +1,8 @@
 package testutils
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"sync"
 	"time"
@@ -35,6 +37,9 @@ type MockBot struct {
 
 	// SendDocumentError, if set, is returned by SendDocument.
 	SendDocumentError error
+
+	// Files holds the content ReadFile returns, by file ID.
+	Files map[string][]byte
 }
 
 func (m *MockBot) SendMessage(chatID int64, text string, keyboard any) {
@@ -47,8 +52,16 @@ func (m *MockBot) SendMessage(chatID int64, text string, keyboard any) {
 	})
 }
 
-func (*MockBot) SendMessageReturningID(_ int64, _ string, _ any) (int, error) {
-	return 0, nil
+// SendMessageReturningID records the message like SendMessage; its ID is its 1-based position in SentMessages.
+func (m *MockBot) SendMessageReturningID(chatID int64, text string, keyboard any) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.SentMessages = append(m.SentMessages, MockMessage{
+		ChatID:   chatID,
+		Text:     text,
+		Keyboard: keyboard,
+	})
+	return len(m.SentMessages), nil
 }
 
 func (m *MockBot) SendDocument(chatID int64, fileName string, data []byte) error {
@@ -65,7 +78,17 @@ func (m *MockBot) SendDocument(chatID int64, fileName string, data []byte) error
 	return nil
 }
 
-func (*MockBot) DownloadFile(_, _ string) error { return nil }
+func (*MockBot) DownloadFile(_ context.Context, _, _ string, _ func(int64)) error { return nil }
+
+func (m *MockBot) ReadFile(_ context.Context, fileID string, _ int64) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data, ok := m.Files[fileID]
+	if !ok {
+		return nil, errors.New("file not found")
+	}
+	return data, nil
+}
 
 func (*MockBot) AnswerCallbackQuery(_ tgbotapi.CallbackConfig) {}
 
@@ -38,6 +38,18 @@ func (*DatabaseStub) MovieExistsFiles(_ context.Context, _ []string) (bool, erro
 	return false, nil
 }
 
+func (*DatabaseStub) FindMovieByInfoHash(_ context.Context, _ string) (*database.Movie, error) {
+	return nil, nil
+}
+
+func (*DatabaseStub) SetMovieInfoHash(_ context.Context, _ uint, _ string) error {
+	return nil
+}
+
+func (*DatabaseStub) SetMovieSource(_ context.Context, _ uint, _ string) error {
+	return nil
+}
+
 func (*DatabaseStub) MovieExistsUploadedFile(_ context.Context, _ string) (bool, error) {
 	return false, nil
 }
@@ -46,6 +58,10 @@ func (*DatabaseStub) GetIncompleteQBittorrentDownloads(_ context.Context) ([]dat
 	return nil, nil
 }
 
+func (*DatabaseStub) GetTrashedMovies(_ context.Context) ([]database.Movie, error) {
+	return nil, nil
+}
+
 // MovieWriter methods.
 
 func (*DatabaseStub) AddMovie(_ context.Context, _ string, _ int64, _, _ []string, _ int) (uint, error) {
@@ -72,13 +88,21 @@ func (*DatabaseStub) UpdateConversionStatus(_ context.Context, _ uint, _ string)
 	return nil
 }
 
-func (*DatabaseStub) UpdateConversionPercentage(_ context.Context, _ uint, _ int) error {
+func (*DatabaseStub) UpdateConversionPercentage(_ context.Context, _ uint, _ database.ConversionProgress) error {
+	return nil
+}
+
+func (*DatabaseStub) UpdateExtractionStatus(_ context.Context, _ uint, _ string) error {
+	return nil
+}
+
+func (*DatabaseStub) UpdateExtractionPercentage(_ context.Context, _ uint, _ int) error {
 	return nil
 }
 
 func (*DatabaseStub) SetTvCompatibility(_ context.Context, _ uint, _ string) error { return nil }
 
-func (*DatabaseStub) SetQBittorrentHash(_ context.Context, _ uint, _ string) error { return nil }
+func (*DatabaseStub) SetTorrentClientHash(_ context.Context, _ uint, _, _ string) error { return nil }
 
 func (*DatabaseStub) RemoveFilesByMovieID(_ context.Context, _ uint) error { return nil }
 
@@ -90,6 +114,20 @@ func (*DatabaseStub) UpdateMovieTotalEpisodes(_ context.Context, _ uint, _ int)
 
 func (*DatabaseStub) RemoveTempFilesByMovieID(_ context.Context, _ uint) error { return nil }
 
+func (*DatabaseStub) SetMovieTrashed(_ context.Context, _ uint, _ *time.Time) error { return nil }
+
+func (*DatabaseStub) SetMoviePinned(_ context.Context, _ uint, _ bool) error { return nil }
+
+func (*DatabaseStub) SetMovieSeedMode(_ context.Context, _ uint, _ string) error { return nil }
+
+func (*DatabaseStub) UpdateSeedingState(_ context.Context, _ uint, _ bool, _ float64) error {
+	return nil
+}
+
+func (*DatabaseStub) SetMovieSchedule(_ context.Context, _ uint, _ *time.Time, _, _ string) error {
+	return nil
+}
+
 // AuthStore methods.
 
 func (*DatabaseStub) Login(_ context.Context, _ string, _ int64, _ string, _ *tmsconfig.Config) (bool, error) {
@@ -120,6 +158,58 @@ func (*DatabaseStub) GetUserByChatID(_ context.Context, _ int64) (database.User,
 	return database.User{}, nil
 }
 
+func (*DatabaseStub) GetUsersByRole(_ context.Context, _ database.UserRole) ([]database.User, error) {
+	return nil, nil
+}
+
 // Init method.
 
 func (*DatabaseStub) Init(_ *tmsconfig.Config) error { return nil }
+
+// SubscriptionStore methods.
+
+func (*DatabaseStub) AddSubscription(_ context.Context, _ *database.Subscription) error { return nil }
+
+func (*DatabaseStub) GetSubscriptions(_ context.Context) ([]database.Subscription, error) {
+	return nil, nil
+}
+
+func (*DatabaseStub) GetSubscriptionByID(_ context.Context, _ uint) (database.Subscription, error) {
+	return database.Subscription{}, nil
+}
+
+func (*DatabaseStub) RemoveSubscription(_ context.Context, _ uint) error { return nil }
+
+func (*DatabaseStub) MarkSubscriptionChecked(_ context.Context, _ uint, _ string, _ time.Time) error {
+	return nil
+}
+
+func (*DatabaseStub) GetSubscriptionVideoIDs(_ context.Context, _ uint) (map[string]struct{}, error) {
+	return map[string]struct{}{}, nil
+}
+
+func (*DatabaseStub) AddSubscriptionVideoIDs(_ context.Context, _ uint, _ []string) error { return nil }
+
+// FeedRuleStore methods.
+
+func (*DatabaseStub) AddFeedRule(_ context.Context, _ *database.FeedRule) error { return nil }
+
+func (*DatabaseStub) GetFeedRules(_ context.Context) ([]database.FeedRule, error) { return nil, nil }
+
+func (*DatabaseStub) GetFeedRuleByID(_ context.Context, _ uint) (database.FeedRule, error) {
+	return database.FeedRule{}, nil
+}
+
+func (*DatabaseStub) RemoveFeedRule(_ context.Context, _ uint) error { return nil }
+
+func (*DatabaseStub) SetFeedRulePaused(_ context.Context, _ uint, _ bool) error { return nil }
+
+func (*DatabaseStub) MarkFeedRuleChecked(_ context.Context, _ uint, _ string, _ time.Time) error {
+	return nil
+}
+
+func (*DatabaseStub) GetKnownFeedReleases(_ context.Context, _ uint, _ []string) (map[string]struct{}, error) {
+	return map[string]struct{}{}, nil
+}
+
+func (*DatabaseStub) AddFeedReleases(_ context.Context, _ []database.FeedRelease) error { return nil }
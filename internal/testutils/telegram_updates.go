@@ -36,3 +36,17 @@ func TorrentDocumentUpdate(chatID, userID int64, userName, fileID, fileName stri
 		},
 	}}
 }
+
+// VideoUpdate returns a message carrying a video of the given size, as sent or forwarded to the bot.
+func VideoUpdate(chatID, userID int64, userName, fileID, fileName string, size int) *tgbotapi.Update {
+	return &tgbotapi.Update{Message: &tgbotapi.Message{
+		From: &tgbotapi.User{ID: userID, UserName: userName},
+		Chat: &tgbotapi.Chat{ID: chatID},
+		Video: &tgbotapi.Video{
+			FileID:   fileID,
+			FileName: fileName,
+			MimeType: "video/mp4",
+			FileSize: size,
+		},
+	}}
+}
@@ -43,6 +43,7 @@ func TestConfig(tempDir string) *config.Config {
 			MaxConcurrentDownloads: 1,
 			DownloadTimeout:        30 * time.Second,
 			ProgressUpdateInterval: 100 * time.Millisecond,
+			HTTPSegments:           2,
 		},
 
 		SecuritySettings: config.SecurityConfig{
@@ -128,6 +129,10 @@ func (t *TestSQLiteDatabase) runMigrations() error {
 		&database.MovieFile{},
 		&database.User{},
 		&database.TemporaryPassword{},
+		&database.Subscription{},
+		&database.SubscriptionItem{},
+		&database.FeedRule{},
+		&database.FeedRelease{},
 	)
 }
 
@@ -188,12 +193,49 @@ func (t *TestSQLiteDatabase) RemoveMovie(ctx context.Context, movieID uint) erro
 
 func (t *TestSQLiteDatabase) GetMovieList(ctx context.Context) ([]database.Movie, error) {
 	var movies []database.Movie
-	if err := t.db.WithContext(ctx).Find(&movies).Error; err != nil {
+	if err := t.db.WithContext(ctx).Where("trashed_at IS NULL").Find(&movies).Error; err != nil {
 		return nil, err
 	}
 	return movies, nil
 }
 
+func (t *TestSQLiteDatabase) GetTrashedMovies(ctx context.Context) ([]database.Movie, error) {
+	var movies []database.Movie
+	if err := t.db.WithContext(ctx).Where("trashed_at IS NOT NULL").Order("trashed_at ASC").Find(&movies).Error; err != nil {
+		return nil, err
+	}
+	return movies, nil
+}
+
+func (t *TestSQLiteDatabase) SetMovieTrashed(ctx context.Context, movieID uint, trashedAt *time.Time) error {
+	return t.db.WithContext(ctx).Model(&database.Movie{}).Where("id = ?", movieID).
+		Update("trashed_at", trashedAt).Error
+}
+
+func (t *TestSQLiteDatabase) SetMoviePinned(ctx context.Context, movieID uint, pinned bool) error {
+	return t.db.WithContext(ctx).Model(&database.Movie{}).Where("id = ?", movieID).
+		Update("pinned", pinned).Error
+}
+
+func (t *TestSQLiteDatabase) SetMovieSeedMode(ctx context.Context, movieID uint, mode string) error {
+	return t.db.WithContext(ctx).Model(&database.Movie{}).Where("id = ?", movieID).
+		Update("seed_mode", mode).Error
+}
+
+func (t *TestSQLiteDatabase) UpdateSeedingState(ctx context.Context, movieID uint, seeding bool, ratio float64) error {
+	return t.db.WithContext(ctx).Model(&database.Movie{}).Where("id = ?", movieID).
+		Updates(map[string]any{"seeding": seeding, "seed_ratio": ratio}).Error
+}
+
+func (t *TestSQLiteDatabase) SetMovieSchedule(ctx context.Context, movieID uint, startAt *time.Time, source, options string) error {
+	updates := map[string]any{"start_at": startAt, "start_options": ""}
+	if startAt != nil {
+		updates["source"] = source
+		updates["start_options"] = options
+	}
+	return t.db.WithContext(ctx).Model(&database.Movie{}).Where("id = ?", movieID).Updates(updates).Error
+}
+
 func (t *TestSQLiteDatabase) UpdateDownloadedPercentage(ctx context.Context, movieID uint, percentage int) error {
 	return t.db.WithContext(ctx).Model(&database.Movie{}).Where("id = ?", movieID).
 		Update("downloaded_percentage", percentage).Error
@@ -205,8 +247,10 @@ func (t *TestSQLiteDatabase) UpdateEpisodesProgress(ctx context.Context, movieID
 }
 
 func (t *TestSQLiteDatabase) SetLoaded(ctx context.Context, movieID uint, _ string) error {
-	return t.db.WithContext(ctx).Model(&database.Movie{}).Where("id = ?", movieID).
-		Update("downloaded_percentage", loadedPercent).Error
+	return t.db.WithContext(ctx).Model(&database.Movie{}).Where("id = ?", movieID).Updates(map[string]any{
+		"downloaded_percentage": loadedPercent,
+		"completed_at":          gorm.Expr("COALESCE(completed_at, ?)", time.Now()),
+	}).Error
 }
 
 func (t *TestSQLiteDatabase) RefreshMovieFileSizeFromDisk(ctx context.Context, movieID uint, movieRoot string) (int64, error) {
@@ -234,9 +278,27 @@ func (t *TestSQLiteDatabase) UpdateConversionStatus(ctx context.Context, movieID
 		Update("conversion_status", status).Error
 }
 
-func (t *TestSQLiteDatabase) UpdateConversionPercentage(ctx context.Context, movieID uint, percentage int) error {
+func (t *TestSQLiteDatabase) UpdateConversionPercentage(
+	ctx context.Context,
+	movieID uint,
+	progress database.ConversionProgress,
+) error {
+	return t.db.WithContext(ctx).Model(&database.Movie{}).Where("id = ?", movieID).Updates(map[string]any{
+		"conversion_percentage": progress.Percentage,
+		"conversion_file":       progress.File,
+		"conversion_speed":      progress.Speed,
+		"conversion_eta":        int(progress.ETA.Seconds()),
+	}).Error
+}
+
+func (t *TestSQLiteDatabase) UpdateExtractionStatus(ctx context.Context, movieID uint, status string) error {
+	return t.db.WithContext(ctx).Model(&database.Movie{}).Where("id = ?", movieID).
+		Update("extraction_status", status).Error
+}
+
+func (t *TestSQLiteDatabase) UpdateExtractionPercentage(ctx context.Context, movieID uint, percentage int) error {
 	return t.db.WithContext(ctx).Model(&database.Movie{}).Where("id = ?", movieID).
-		Update("conversion_percentage", percentage).Error
+		Update("extraction_percentage", percentage).Error
 }
 
 func (t *TestSQLiteDatabase) SetTvCompatibility(ctx context.Context, movieID uint, compat string) error {
@@ -244,9 +306,34 @@ func (t *TestSQLiteDatabase) SetTvCompatibility(ctx context.Context, movieID uin
 		Update("tv_compatibility", compat).Error
 }
 
-func (t *TestSQLiteDatabase) SetQBittorrentHash(ctx context.Context, movieID uint, hash string) error {
+func (t *TestSQLiteDatabase) SetTorrentClientHash(ctx context.Context, movieID uint, client, hash string) error {
+	return t.db.WithContext(ctx).Model(&database.Movie{}).Where("id = ?", movieID).
+		Updates(map[string]any{"qbittorrent_hash": hash, "torrent_client": client}).Error
+}
+
+func (t *TestSQLiteDatabase) SetMovieInfoHash(ctx context.Context, movieID uint, infoHash string) error {
+	return t.db.WithContext(ctx).Model(&database.Movie{}).Where("id = ?", movieID).
+		Update("info_hash", infoHash).Error
+}
+
+func (t *TestSQLiteDatabase) SetMovieSource(ctx context.Context, movieID uint, source string) error {
 	return t.db.WithContext(ctx).Model(&database.Movie{}).Where("id = ?", movieID).
-		Update("qbittorrent_hash", hash).Error
+		Update("source", source).Error
+}
+
+func (t *TestSQLiteDatabase) FindMovieByInfoHash(ctx context.Context, infoHash string) (*database.Movie, error) {
+	if infoHash == "" {
+		return nil, nil
+	}
+	var movies []database.Movie
+	if err := t.db.WithContext(ctx).Where("info_hash = ? AND trashed_at IS NULL", infoHash).
+		Order("id").Limit(1).Find(&movies).Error; err != nil {
+		return nil, err
+	}
+	if len(movies) == 0 {
+		return nil, nil
+	}
+	return &movies[0], nil
 }
 
 func (t *TestSQLiteDatabase) GetMovieByID(ctx context.Context, movieID uint) (database.Movie, error) {
@@ -301,7 +388,10 @@ func (t *TestSQLiteDatabase) RemoveTempFilesByMovieID(ctx context.Context, movie
 func (t *TestSQLiteDatabase) MovieExistsFiles(ctx context.Context, files []string) (bool, error) {
 	for _, file := range files {
 		var count int64
-		if err := t.db.WithContext(ctx).Model(&database.MovieFile{}).Where("file_path = ?", file).Count(&count).Error; err != nil {
+		if err := t.db.WithContext(ctx).Model(&database.MovieFile{}).
+			Joins("JOIN movies ON movies.id = movie_files.movie_id").
+			Where("movie_files.file_path = ? AND movies.trashed_at IS NULL", file).
+			Count(&count).Error; err != nil {
 			return false, err
 		}
 		if count > 0 {
@@ -359,6 +449,9 @@ func (*TestSQLiteDatabase) GenerateTemporaryPassword(_ context.Context, _ time.D
 func (*TestSQLiteDatabase) GetUserByChatID(_ context.Context, _ int64) (database.User, error) {
 	return database.User{}, nil
 }
+func (*TestSQLiteDatabase) GetUsersByRole(_ context.Context, _ database.UserRole) ([]database.User, error) {
+	return nil, nil
+}
 func (t *TestSQLiteDatabase) MovieExistsId(ctx context.Context, movieID uint) (bool, error) {
 	var count int64
 	if err := t.db.WithContext(ctx).Model(&database.Movie{}).Where("id = ?", movieID).Count(&count).Error; err != nil {
@@ -367,6 +460,129 @@ func (t *TestSQLiteDatabase) MovieExistsId(ctx context.Context, movieID uint) (b
 	return count > 0, nil
 }
 
+func (t *TestSQLiteDatabase) AddSubscription(ctx context.Context, sub *database.Subscription) error {
+	return t.db.WithContext(ctx).Create(sub).Error
+}
+
+func (t *TestSQLiteDatabase) GetSubscriptions(ctx context.Context) ([]database.Subscription, error) {
+	var subs []database.Subscription
+	if err := t.db.WithContext(ctx).Order("id ASC").Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+func (t *TestSQLiteDatabase) GetSubscriptionByID(ctx context.Context, id uint) (database.Subscription, error) {
+	var sub database.Subscription
+	if err := t.db.WithContext(ctx).First(&sub, id).Error; err != nil {
+		return database.Subscription{}, err
+	}
+	return sub, nil
+}
+
+func (t *TestSQLiteDatabase) RemoveSubscription(ctx context.Context, id uint) error {
+	if err := t.db.WithContext(ctx).Where("subscription_id = ?", id).Delete(&database.SubscriptionItem{}).Error; err != nil {
+		return err
+	}
+	return t.db.WithContext(ctx).Delete(&database.Subscription{}, id).Error
+}
+
+func (t *TestSQLiteDatabase) MarkSubscriptionChecked(ctx context.Context, id uint, title string, checkedAt time.Time) error {
+	updates := map[string]any{"last_checked_at": checkedAt}
+	if title != "" {
+		updates["title"] = title
+	}
+	return t.db.WithContext(ctx).Model(&database.Subscription{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (t *TestSQLiteDatabase) GetSubscriptionVideoIDs(ctx context.Context, id uint) (map[string]struct{}, error) {
+	var videoIDs []string
+	if err := t.db.WithContext(ctx).Model(&database.SubscriptionItem{}).
+		Where("subscription_id = ?", id).Pluck("video_id", &videoIDs).Error; err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{}, len(videoIDs))
+	for _, v := range videoIDs {
+		seen[v] = struct{}{}
+	}
+	return seen, nil
+}
+
+func (t *TestSQLiteDatabase) AddSubscriptionVideoIDs(ctx context.Context, id uint, videoIDs []string) error {
+	for _, v := range videoIDs {
+		item := database.SubscriptionItem{SubscriptionID: id, VideoID: v}
+		if err := t.db.WithContext(ctx).Where(item).FirstOrCreate(&item).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *TestSQLiteDatabase) AddFeedRule(ctx context.Context, rule *database.FeedRule) error {
+	return t.db.WithContext(ctx).Create(rule).Error
+}
+
+func (t *TestSQLiteDatabase) GetFeedRules(ctx context.Context) ([]database.FeedRule, error) {
+	var rules []database.FeedRule
+	if err := t.db.WithContext(ctx).Order("id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (t *TestSQLiteDatabase) GetFeedRuleByID(ctx context.Context, id uint) (database.FeedRule, error) {
+	var rule database.FeedRule
+	if err := t.db.WithContext(ctx).First(&rule, id).Error; err != nil {
+		return database.FeedRule{}, err
+	}
+	return rule, nil
+}
+
+func (t *TestSQLiteDatabase) RemoveFeedRule(ctx context.Context, id uint) error {
+	if err := t.db.WithContext(ctx).Where("rule_id = ? AND downloaded = ?", id, false).Delete(&database.FeedRelease{}).Error; err != nil {
+		return err
+	}
+	return t.db.WithContext(ctx).Delete(&database.FeedRule{}, id).Error
+}
+
+func (t *TestSQLiteDatabase) SetFeedRulePaused(ctx context.Context, id uint, paused bool) error {
+	return t.db.WithContext(ctx).Model(&database.FeedRule{}).Where("id = ?", id).Update("paused", paused).Error
+}
+
+func (t *TestSQLiteDatabase) MarkFeedRuleChecked(ctx context.Context, id uint, title string, checkedAt time.Time) error {
+	updates := map[string]any{"last_checked_at": checkedAt}
+	if title != "" {
+		updates["title"] = title
+	}
+	return t.db.WithContext(ctx).Model(&database.FeedRule{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (t *TestSQLiteDatabase) GetKnownFeedReleases(ctx context.Context, ruleID uint, keys []string) (map[string]struct{}, error) {
+	known := make(map[string]struct{})
+	if len(keys) == 0 {
+		return known, nil
+	}
+	var found []string
+	if err := t.db.WithContext(ctx).Model(&database.FeedRelease{}).
+		Where("key IN ? AND (rule_id = ? OR downloaded = ?)", keys, ruleID, true).Pluck("key", &found).Error; err != nil {
+		return nil, err
+	}
+	for _, k := range found {
+		known[k] = struct{}{}
+	}
+	return known, nil
+}
+
+func (t *TestSQLiteDatabase) AddFeedReleases(ctx context.Context, releases []database.FeedRelease) error {
+	for i := range releases {
+		rel := releases[i]
+		if err := t.db.WithContext(ctx).Where(database.FeedRelease{Key: rel.Key, RuleID: rel.RuleID}).FirstOrCreate(&rel).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // TempDir creates a temporary directory for testing
 func TempDir(t *testing.T) string {
 	t.Helper()
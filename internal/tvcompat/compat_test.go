@@ -16,12 +16,15 @@ func TestCompatFromVcodec(t *testing.T) {
 		{"AVC uppercase", "AVC1.64001f", TvCompatGreen},
 		{"VP9", "vp9", TvCompatRed},
 		{"VP9 profile", "vp9.0", TvCompatRed},
+		{"VP9 yt-dlp", "vp09.00.40.08", TvCompatRed},
 		{"AV1 short", "av1", TvCompatRed},
 		{"AV01 long", "av01.0.08M.08", TvCompatRed},
 		{"AV01 simple", "av01", TvCompatRed},
 		{"HEVC", "hevc", TvCompatRed},
 		{"H265", "h265", TvCompatRed},
 		{"H265 profile", "H265.Main10", TvCompatRed},
+		{"HEVC hev1", "hev1.1.6.L93.90", TvCompatRed},
+		{"HEVC hvc1", "hvc1.2.4.L153", TvCompatRed},
 		{"Empty string", "", ""},
 		{"None codec", "none", ""},
 		{"Unknown codec", "opus", ""},
@@ -0,0 +1,114 @@
+package tvcompat
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Progress is reported while a movie is converted, aggregated over all of its video files.
+type Progress struct {
+	Percent int           // 0-99; the caller stores 100 once the job is done
+	File    string        // video being converted, relative to the movie root
+	Speed   float64       // ffmpeg speed factor (media seconds per second), 0 if unknown
+	ETA     time.Duration // time left for the whole movie, 0 if unknown
+}
+
+// progressTracker turns the per-file ffmpeg position into the overall progress of a movie.
+type progressTracker struct {
+	report func(Progress)
+	total  float64 // media seconds of all files to convert
+	done   float64 // media seconds of the files already converted
+}
+
+func newProgressTracker(jobs []videoJob, report func(Progress)) *progressTracker {
+	t := &progressTracker{report: report}
+	for i := range jobs {
+		t.total += jobs[i].duration
+	}
+	return t
+}
+
+// update reports the position outTime (seconds) within file, whose duration is fileDuration.
+func (t *progressTracker) update(file string, fileDuration, outTime, speed float64) {
+	if t.report == nil {
+		return
+	}
+	if fileDuration > 0 {
+		outTime = min(outTime, fileDuration)
+	}
+	p := Progress{File: file, Speed: speed}
+	if t.total > 0 {
+		processed := t.done + outTime
+		const lastRunningPercent = 99
+		p.Percent = min(int(math.Floor(processed*100/t.total)), lastRunningPercent)
+		if speed > 0 {
+			p.ETA = time.Duration((t.total - processed) / speed * float64(time.Second)).Round(time.Second)
+		}
+	}
+	t.report(p)
+}
+
+// fileDone moves the tracker past a converted file.
+func (t *progressTracker) fileDone(fileDuration float64) {
+	t.done += fileDuration
+}
+
+// runFFmpeg runs ffmpeg with -progress on stdout and calls onProgress with the output position and speed
+// factor on every progress block. A failure includes ffmpeg's last error line.
+func runFFmpeg(ctx context.Context, args []string, onProgress func(outTime, speed float64)) error {
+	// #nosec G204 -- args are built by this package from file paths and validated settings
+	cmd := exec.CommandContext(ctx, "ffmpeg", append([]string{"-v", "error", "-progress", "pipe:1"}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	parseFFmpegProgress(stdout, onProgress)
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("ffmpeg: %w: %s", err, lastLine(stderr.Bytes()))
+	}
+	return nil
+}
+
+// parseFFmpegProgress reads the key=value blocks of "ffmpeg -progress"; each block ends with a progress= line.
+func parseFFmpegProgress(r io.Reader, onProgress func(outTime, speed float64)) {
+	var outTime, speed float64
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "out_time_us", "out_time_ms": // both are microseconds despite the name
+			if us, err := strconv.ParseInt(value, 10, 64); err == nil && us >= 0 {
+				outTime = float64(us) / 1e6
+			}
+		case "speed":
+			if v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "x"), 64); err == nil {
+				speed = v
+			}
+		case "progress":
+			if onProgress != nil {
+				onProgress(outTime, speed)
+			}
+		}
+	}
+}
+
+func lastLine(output []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	return lines[len(lines)-1]
+}
@@ -0,0 +1,74 @@
+package tvcompat
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFFmpegProgress(t *testing.T) {
+	input := strings.Join([]string{
+		"frame=10",
+		"out_time_us=N/A",
+		"speed=N/A",
+		"progress=continue",
+		"frame=250",
+		"out_time_ms=10000000",
+		"out_time=00:00:10.000000",
+		"speed=2.5x",
+		"progress=continue",
+		"out_time_us=20500000",
+		"speed= 3.1x",
+		"progress=end",
+	}, "\n")
+
+	type report struct{ outTime, speed float64 }
+	var got []report
+	parseFFmpegProgress(strings.NewReader(input), func(outTime, speed float64) {
+		got = append(got, report{outTime, speed})
+	})
+
+	want := []report{{0, 0}, {10, 2.5}, {20.5, 3.1}}
+	if len(got) != len(want) {
+		t.Fatalf("got %d progress reports, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("report %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestProgressTrackerAggregatesFiles(t *testing.T) {
+	var last Progress
+	tracker := newProgressTracker([]videoJob{
+		{path: "S01E01.mkv", duration: 1200},
+		{path: "S01E02.mkv", duration: 1800},
+	}, func(p Progress) { last = p })
+
+	tracker.update("S01E01.mkv", 1200, 600, 2)
+	if last.Percent != 20 || last.File != "S01E01.mkv" || last.ETA != 1200*time.Second {
+		t.Errorf("halfway through the first file: %+v, want 20%% with ETA 20m", last)
+	}
+
+	tracker.fileDone(1200)
+	tracker.update("S01E02.mkv", 1800, 900, 3)
+	if last.Percent != 70 || last.File != "S01E02.mkv" || last.ETA != 300*time.Second {
+		t.Errorf("halfway through the second file: %+v, want 70%% with ETA 5m", last)
+	}
+
+	tracker.update("S01E02.mkv", 1800, 1900, 3)
+	if last.Percent != 99 || last.ETA != 0 {
+		t.Errorf("past the end: %+v, want 99%% with no ETA left", last)
+	}
+}
+
+func TestProgressTrackerUnknownDuration(t *testing.T) {
+	var last Progress
+	tracker := newProgressTracker([]videoJob{{path: "clip.webm"}}, func(p Progress) { last = p })
+
+	tracker.update("clip.webm", 0, 42, 1.5)
+	if last.Percent != 0 || last.ETA != 0 || last.File != "clip.webm" || last.Speed != 1.5 {
+		t.Errorf("unknown duration: %+v, want file and speed only", last)
+	}
+}
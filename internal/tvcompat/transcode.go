@@ -0,0 +1,264 @@
+package tvcompat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	tmsconfig "github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	tmsdb "github.com/NikitaDmitryuk/telegram-media-server/internal/database"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+)
+
+// transcodedSuffix marks the H.264 copy of an incompatible video: "Movie.mkv" becomes "Movie.h264.mkv".
+const transcodedSuffix = ".h264"
+
+// Levels below 5.1 cannot carry more than 1080p, so the transcode scales larger videos down.
+const maxLevelWithoutScaling = 51
+
+// TranscodeIncompatible re-encodes every non-H.264 main video of the movie to H.264 (libx264 with the
+// configured preset and CRF, level capped at TvH264Level, AAC audio). Each copy is written next to the
+// original, checked with ffprobe and registered as a main file; the original is deleted only afterwards,
+// unless keepOriginals is set (the torrent is still seeding) — then RemoveTranscodedOriginals does it later.
+// Videos that already have a registered copy are skipped, so an interrupted job can be re-run.
+// progress receives the position across all videos of the movie.
+func TranscodeIncompatible(
+	ctx context.Context,
+	movieID uint,
+	moviePath string,
+	db tmsdb.Database,
+	videoConfig *tmsconfig.VideoConfig,
+	keepOriginals bool,
+	progress func(Progress),
+) error {
+	files, err := db.GetFilesByMovieID(ctx, movieID)
+	if err != nil {
+		return fmt.Errorf("failed to get movie files: %w", err)
+	}
+	paths := make([]string, 0, len(files))
+	for i := range files {
+		paths = append(paths, files[i].FilePath)
+	}
+
+	targetLevel := ParseH264Level(videoConfig.TvH264Level)
+	if targetLevel <= 0 {
+		targetLevel = 41
+	}
+
+	jobs, err := findTranscodeJobs(ctx, moviePath, paths)
+	if err != nil {
+		return err
+	}
+	tracker := newProgressTracker(jobs, progress)
+
+	var errs []error
+	for i := range jobs {
+		rel, out, absPath := jobs[i].path, transcodeOutputPath(jobs[i].path), filepath.Join(moviePath, jobs[i].path)
+		logutils.Log.WithFields(map[string]any{
+			"movie_id": movieID,
+			"path":     absPath,
+			"codec":    jobs[i].codec,
+		}).Info("TV compatibility: transcoding to H.264")
+		err := transcodeForTv(ctx, absPath, filepath.Join(moviePath, out), jobs[i].duration, videoConfig, targetLevel,
+			func(outTime, speed float64) { tracker.update(rel, jobs[i].duration, outTime, speed) })
+		tracker.fileDone(jobs[i].duration)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to transcode %s: %w", rel, err))
+			continue
+		}
+		paths = insertAfter(paths, rel, out)
+		if err := db.ReplaceMainMovieFiles(ctx, movieID, paths); err != nil {
+			_ = os.Remove(filepath.Join(moviePath, out))
+			paths = slices.DeleteFunc(paths, func(p string) bool { return p == out })
+			errs = append(errs, fmt.Errorf("failed to register %s: %w", out, err))
+			continue
+		}
+		logutils.Log.WithField("path", out).Info("TV compatibility: transcode done")
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if keepOriginals {
+		return nil
+	}
+	return RemoveTranscodedOriginals(ctx, movieID, moviePath, db)
+}
+
+// videoJob is a main video that needs ffmpeg work, with its probed codec and duration in seconds.
+type videoJob struct {
+	path     string
+	codec    string
+	duration float64
+}
+
+// findTranscodeJobs returns the non-H.264 videos among paths that have no registered H.264 copy yet.
+// A video that is missing or that ffprobe cannot read is an error: the movie must not be marked compatible
+// while one of its videos was never checked.
+func findTranscodeJobs(ctx context.Context, moviePath string, paths []string) ([]videoJob, error) {
+	var jobs []videoJob
+	var errs []error
+	for _, rel := range paths {
+		if !IsVideoFilePath(rel) || slices.Contains(paths, transcodeOutputPath(rel)) {
+			continue
+		}
+		absPath := filepath.Join(moviePath, rel)
+		if _, err := os.Stat(absPath); err != nil {
+			errs = append(errs, fmt.Errorf("video %s is not readable: %w", rel, err))
+			continue
+		}
+		codec, _ := probeCodecAndLevel(ctx, absPath)
+		switch codec {
+		case "":
+			errs = append(errs, fmt.Errorf("ffprobe could not read the video codec of %s", rel))
+		case "h264":
+		default:
+			jobs = append(jobs, videoJob{path: rel, codec: codec, duration: probeDuration(ctx, absPath)})
+		}
+	}
+	return jobs, errors.Join(errs...)
+}
+
+// RemoveTranscodedOriginals deletes the videos that have a registered H.264 copy, e.g. the ones kept while
+// their torrent seeded.
+func RemoveTranscodedOriginals(ctx context.Context, movieID uint, moviePath string, db tmsdb.Database) error {
+	files, err := db.GetFilesByMovieID(ctx, movieID)
+	if err != nil {
+		return fmt.Errorf("failed to get movie files: %w", err)
+	}
+	paths := make([]string, 0, len(files))
+	for i := range files {
+		paths = append(paths, files[i].FilePath)
+	}
+	var originals []string
+	for _, p := range paths {
+		if IsVideoFilePath(p) && slices.Contains(paths, transcodeOutputPath(p)) {
+			originals = append(originals, p)
+		}
+	}
+	if len(originals) == 0 {
+		return nil
+	}
+	kept := slices.DeleteFunc(slices.Clone(paths), func(p string) bool { return slices.Contains(originals, p) })
+	if err := db.ReplaceMainMovieFiles(ctx, movieID, kept); err != nil {
+		return fmt.Errorf("failed to unregister transcoded originals: %w", err)
+	}
+	for _, p := range originals {
+		if err := os.Remove(filepath.Join(moviePath, p)); err != nil && !os.IsNotExist(err) {
+			logutils.Log.WithError(err).WithField("path", p).Warn("TV compatibility: failed to delete original video")
+		}
+	}
+	return nil
+}
+
+// transcodeForTv encodes absPath (srcDuration seconds long) into a temporary file, verifies it and moves
+// it to outPath. The source file is never touched.
+func transcodeForTv(
+	ctx context.Context,
+	absPath, outPath string,
+	srcDuration float64,
+	videoConfig *tmsconfig.VideoConfig,
+	targetLevel int,
+	onProgress func(outTime, speed float64),
+) error {
+	tmpPath := filepath.Join(filepath.Dir(outPath), ".tvcompat_"+filepath.Base(outPath)+".tmp")
+	defer os.Remove(tmpPath) // best-effort cleanup
+
+	matroska := strings.EqualFold(filepath.Ext(outPath), ".mkv")
+	if err := runFFmpeg(ctx, transcodeArgs(absPath, tmpPath, matroska, videoConfig, targetLevel), onProgress); err != nil {
+		return err
+	}
+	if codec, _ := probeCodecAndLevel(ctx, tmpPath); codec != "h264" {
+		return fmt.Errorf("transcoded file has codec %q, want h264", codec)
+	}
+	dstDuration := probeDuration(ctx, tmpPath)
+	if !durationMatches(srcDuration, dstDuration) {
+		return fmt.Errorf("transcoded file lasts %.1fs, source lasts %.1fs", dstDuration, srcDuration)
+	}
+	return os.Rename(tmpPath, outPath)
+}
+
+// transcodeOutputPath returns the path of the H.264 copy: Matroska stays Matroska (subtitles and fonts are
+// kept), everything else becomes MP4.
+func transcodeOutputPath(path string) string {
+	ext := filepath.Ext(path)
+	outExt := ".mp4"
+	if strings.EqualFold(ext, ".mkv") {
+		outExt = ".mkv"
+	}
+	return strings.TrimSuffix(path, ext) + transcodedSuffix + outExt
+}
+
+// transcodeArgs builds the ffmpeg arguments: first video stream to H.264, every audio track to AAC,
+// and for Matroska the subtitles and attachments copied as is.
+func transcodeArgs(src, dst string, matroska bool, videoConfig *tmsconfig.VideoConfig, targetLevel int) []string {
+	args := []string{"-nostdin", "-i", src, "-map", "0:v:0", "-map", "0:a?"}
+	if matroska {
+		args = append(args, "-map", "0:s?", "-map", "0:t?")
+	}
+	args = append(args,
+		"-c", "copy",
+		"-c:v", "libx264",
+		"-preset", videoConfig.TranscodePreset,
+		"-crf", strconv.Itoa(videoConfig.TranscodeCRF),
+		"-profile:v", "high",
+		"-level:v", formatH264Level(targetLevel),
+		"-pix_fmt", "yuv420p",
+	)
+	if targetLevel < maxLevelWithoutScaling {
+		args = append(args, "-vf",
+			"scale=w='min(1920,iw)':h='min(1080,ih)':force_original_aspect_ratio=decrease:force_divisible_by=2")
+	}
+	args = append(args, "-c:a", "aac", "-b:a", "192k", "-max_muxing_queue_size", "1024")
+	if matroska {
+		args = append(args, "-f", "matroska")
+	} else {
+		args = append(args, "-movflags", "+faststart", "-f", "mp4")
+	}
+	return append(args, "-y", dst)
+}
+
+// probeDuration returns the container duration in seconds, 0 if unknown.
+func probeDuration(ctx context.Context, absPath string) float64 {
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "csv=p=0",
+		absPath,
+	)
+	out, err := cmd.Output()
+	if err != nil {
+		return 0
+	}
+	d, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// durationMatches reports whether a transcoded file of dst seconds is a complete copy of a src seconds source:
+// within two seconds or 1%, whichever is larger. An unknown source duration only requires a playable result.
+func durationMatches(src, dst float64) bool {
+	if dst <= 0 {
+		return false
+	}
+	if src <= 0 {
+		return true
+	}
+	return math.Abs(src-dst) <= max(2, src*0.01)
+}
+
+func insertAfter(paths []string, after, p string) []string {
+	i := slices.Index(paths, after)
+	if i < 0 {
+		return append(paths, p)
+	}
+	return slices.Insert(paths, i+1, p)
+}
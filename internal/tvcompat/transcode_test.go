@@ -0,0 +1,137 @@
+package tvcompat
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tmsconfig "github.com/NikitaDmitryuk/telegram-media-server/internal/config"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/logutils"
+	"github.com/NikitaDmitryuk/telegram-media-server/internal/testutils"
+)
+
+func TestTranscodeOutputPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"Movie.mkv", "Movie.h264.mkv"},
+		{"Show/S01E01.MKV", "Show/S01E01.h264.mkv"},
+		{"clip.webm", "clip.h264.mp4"},
+		{"old.avi", "old.h264.mp4"},
+		{"Movie.mp4", "Movie.h264.mp4"},
+	}
+	for _, tt := range tests {
+		if got := transcodeOutputPath(tt.path); got != tt.want {
+			t.Errorf("transcodeOutputPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestTranscodeArgs(t *testing.T) {
+	vc := &tmsconfig.VideoConfig{TranscodePreset: "veryfast", TranscodeCRF: 20}
+
+	args := strings.Join(transcodeArgs("in.mkv", "out.tmp", true, vc, 41), " ")
+	for _, want := range []string{
+		"-c:v libx264", "-preset veryfast", "-crf 20", "-level:v 4.1", "-c:a aac",
+		"-map 0:s?", "scale=", "-f matroska", "-y out.tmp",
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("matroska args %q do not contain %q", args, want)
+		}
+	}
+
+	args = strings.Join(transcodeArgs("in.webm", "out.tmp", false, vc, 51), " ")
+	if !strings.Contains(args, "-f mp4") || !strings.Contains(args, "+faststart") {
+		t.Errorf("mp4 args %q should select the mp4 muxer with faststart", args)
+	}
+	if strings.Contains(args, "0:s?") || strings.Contains(args, "scale=") {
+		t.Errorf("mp4 args at level 5.1 %q should neither map subtitles nor scale", args)
+	}
+}
+
+func TestDurationMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		src, dst float64
+		want     bool
+	}{
+		{"equal", 5400, 5400, true},
+		{"within 1%", 5400, 5360, true},
+		{"short clip within 2s", 30, 28.5, true},
+		{"truncated", 5400, 3000, false},
+		{"empty output", 5400, 0, false},
+		{"unknown source", 0, 12, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := durationMatches(tt.src, tt.dst); got != tt.want {
+				t.Errorf("durationMatches(%v, %v) = %v, want %v", tt.src, tt.dst, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveTranscodedOriginals(t *testing.T) {
+	logutils.InitLogger("error")
+	moviePath := testutils.TempDir(t)
+	db := testutils.TestDatabase(t)
+	ctx := context.Background()
+
+	files := []string{"movie.mkv", "movie.h264.mkv", "extra.webm", "movie.srt"}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(moviePath, f), []byte("data"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	movieID, err := db.AddMovie(ctx, "Movie", 4, files, nil, 0)
+	if err != nil {
+		t.Fatalf("AddMovie: %v", err)
+	}
+
+	if err := RemoveTranscodedOriginals(ctx, movieID, moviePath, db); err != nil {
+		t.Fatalf("RemoveTranscodedOriginals: %v", err)
+	}
+	rows, _ := db.GetFilesByMovieID(ctx, movieID)
+	var got []string
+	for i := range rows {
+		got = append(got, rows[i].FilePath)
+	}
+	slices.Sort(got)
+	if want := []string{"extra.webm", "movie.h264.mkv", "movie.srt"}; !slices.Equal(got, want) {
+		t.Errorf("main files = %v, want %v", got, want)
+	}
+	if _, statErr := os.Stat(filepath.Join(moviePath, "movie.mkv")); !os.IsNotExist(statErr) {
+		t.Errorf("original with a transcoded copy should be deleted, stat err = %v", statErr)
+	}
+	if _, statErr := os.Stat(filepath.Join(moviePath, "extra.webm")); statErr != nil {
+		t.Errorf("video without a copy should be kept: %v", statErr)
+	}
+}
+
+func TestTranscodeIncompatibleFailsOnUnreadableVideo(t *testing.T) {
+	logutils.InitLogger("error")
+	moviePath := testutils.TempDir(t)
+	db := testutils.TestDatabase(t)
+	ctx := context.Background()
+
+	// Not a real video: ffprobe (when installed) cannot read its codec either.
+	if err := os.WriteFile(filepath.Join(moviePath, "movie.mkv"), []byte("not a video"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	movieID, err := db.AddMovie(ctx, "Movie", 11, []string{"movie.mkv"}, nil, 0)
+	if err != nil {
+		t.Fatalf("AddMovie: %v", err)
+	}
+	vc := &tmsconfig.VideoConfig{TranscodePreset: "veryfast", TranscodeCRF: 20, TvH264Level: "4.1"}
+
+	if err := TranscodeIncompatible(ctx, movieID, moviePath, db, vc, false, nil); err == nil {
+		t.Fatal("TranscodeIncompatible should fail when a video cannot be probed")
+	}
+	if _, statErr := os.Stat(filepath.Join(moviePath, "movie.mkv")); statErr != nil {
+		t.Errorf("unprobed video should be kept: %v", statErr)
+	}
+}
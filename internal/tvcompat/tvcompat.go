@@ -50,7 +50,7 @@ func CompatFromTorrentFileNames(filePaths []string) string {
 }
 
 // CompatFromVcodec returns a preliminary TV compatibility from a codec string
-// (e.g. from yt-dlp JSON: "avc1.64001f", "vp9", "hevc").
+// (e.g. from yt-dlp JSON: "avc1.64001f", "vp09.00.40.08", "hev1.1.6.L93").
 // Returns green for H.264/AVC, red for VP9/AV1/HEVC, "" if unknown or empty.
 func CompatFromVcodec(vcodec string) string {
 	v := strings.ToLower(strings.TrimSpace(vcodec))
@@ -58,10 +58,10 @@ func CompatFromVcodec(vcodec string) string {
 		return ""
 	}
 	// Non-H.264 codecs → red (needs full re-encoding for old TVs).
-	if strings.Contains(v, "vp9") || strings.Contains(v, "av01") || strings.Contains(v, "av1") {
+	if strings.Contains(v, "vp9") || strings.HasPrefix(v, "vp09") || strings.Contains(v, "av01") || strings.Contains(v, "av1") {
 		return TvCompatRed
 	}
-	if strings.Contains(v, "h265") || strings.Contains(v, "hevc") {
+	if strings.Contains(v, "h265") || strings.Contains(v, "hevc") || strings.HasPrefix(v, "hev1") || strings.HasPrefix(v, "hvc1") {
 		return TvCompatRed
 	}
 	// H.264/AVC → green (even if level is high, quick remux is enough).
@@ -166,13 +166,15 @@ func probeCodecAndLevel(ctx context.Context, absPath string) (codec string, leve
 // RunTvCompatibility runs a light remux on main video files for the given movie:
 // copies video/audio streams and sets H.264 level metadata (e.g. 4.1 for old LG Smart TVs)
 // without re-encoding. Only H.264 streams with level above the target are processed.
-// Call this from the conversion worker when CompatibilityMode is on.
+// Call this from the conversion worker when CompatibilityMode is on; progress receives the position
+// across all remuxed files.
 func RunTvCompatibility(
 	ctx context.Context,
 	movieID uint,
 	moviePath string,
 	db tmsdb.Database,
 	videoConfig *tmsconfig.VideoConfig,
+	progress func(Progress),
 ) {
 	if videoConfig == nil || !videoConfig.CompatibilityMode {
 		return
@@ -190,6 +192,7 @@ func RunTvCompatibility(
 		targetLevel = 41
 	}
 
+	var jobs []videoJob
 	for i := range files {
 		rel := files[i].FilePath
 		ext := strings.ToLower(filepath.Ext(rel))
@@ -206,10 +209,19 @@ func RunTvCompatibility(
 			logutils.Log.WithError(err).WithField("path", absPath).Debug("TV compatibility: skip (probe failed)")
 			continue
 		}
-		if !needs {
-			continue
+		if needs {
+			jobs = append(jobs, videoJob{path: rel, codec: "h264", duration: probeDuration(ctx, absPath)})
 		}
-		if err := remuxForTv(ctx, absPath, targetLevel); err != nil {
+	}
+
+	tracker := newProgressTracker(jobs, progress)
+	for i := range jobs {
+		rel, absPath := jobs[i].path, filepath.Join(moviePath, jobs[i].path)
+		err := remuxForTv(ctx, absPath, targetLevel, func(outTime, speed float64) {
+			tracker.update(rel, jobs[i].duration, outTime, speed)
+		})
+		tracker.fileDone(jobs[i].duration)
+		if err != nil {
 			logutils.Log.WithError(err).WithField("path", absPath).Warn("TV compatibility: remux failed")
 		} else {
 			logutils.Log.WithField("path", absPath).Info("TV compatibility: remux done")
@@ -276,23 +288,23 @@ func needsTvRemux(ctx context.Context, absPath string, targetLevel int) (bool, e
 }
 
 // remuxForTv runs ffmpeg with -c:v copy -bsf:v h264_metadata=level=X.Y -c:a copy, then replaces original.
-func remuxForTv(ctx context.Context, absPath string, targetLevel int) error {
+func remuxForTv(ctx context.Context, absPath string, targetLevel int, onProgress func(outTime, speed float64)) error {
 	levelStr := formatH264Level(targetLevel)
 	dir := filepath.Dir(absPath)
 	tmpPath := filepath.Join(dir, ".tvcompat_"+filepath.Base(absPath)+".tmp")
 	defer os.Remove(tmpPath) // best-effort cleanup
 
 	// ffmpeg -i input -c:v copy -bsf:v h264_metadata=level=4.1 -c:a copy -y output
-	// #nosec G204 -- absPath/tmpPath from filepath, levelStr from formatH264Level(level)
-	cmd := exec.CommandContext(ctx, "ffmpeg",
+	err := runFFmpeg(ctx, []string{
+		"-nostdin",
 		"-i", absPath,
 		"-c:v", "copy",
-		"-bsf:v", "h264_metadata=level="+levelStr,
+		"-bsf:v", "h264_metadata=level=" + levelStr,
 		"-c:a", "copy",
 		"-y",
 		tmpPath,
-	)
-	if err := cmd.Run(); err != nil {
+	}, onProgress)
+	if err != nil {
 		return err
 	}
 	return os.Rename(tmpPath, absPath)